@@ -10,8 +10,11 @@ import (
 	"github.com/verbaux/grove/internal/state"
 )
 
+var adoptExitCode bool
+
 func init() {
 	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().BoolVar(&adoptExitCode, "exit-code", false, "exit with code 6 when there are no orphan worktrees to adopt")
 }
 
 var adoptCmd = &cobra.Command{
@@ -21,7 +24,9 @@ var adoptCmd = &cobra.Command{
 
 If there is only one orphan worktree, it will be selected automatically.
 Otherwise, pass a branch name or path to identify which one to adopt.
-You will be prompted for an alias (defaults to the branch name).`,
+You will be prompted for an alias (defaults to the branch name).
+
+With --exit-code, grove exits with code 6 when there is nothing to adopt.`,
 	Args: cobra.MaximumNArgs(1),
 	ValidArgsFunction: completeOrphans,
 	RunE: runAdopt,
@@ -48,9 +53,9 @@ func runAdopt(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(orphans) == 0 {
+	if len(orphans) == 0 && len(args) == 0 {
 		fmt.Println("No orphan worktrees found. All worktrees are tracked by grove.")
-		return nil
+		return nothingToDo(adoptExitCode)
 	}
 
 	var target orphanWorktree
@@ -63,10 +68,16 @@ func runAdopt(cmd *cobra.Command, args []string) error {
 				break
 			}
 		}
+		// A worktree hidden by 'grove forget' can still be adopted by name.
+		if target.Path == "" {
+			if r, err := resolveWorktree(query, s); err == nil && r != nil && !r.InState && s.IsIgnored(r.Path) {
+				target = orphanWorktree{Path: r.Path, Branch: r.Branch}
+			}
+		}
 		if target.Path == "" {
 			fmt.Println("No orphan worktree matches that query. Available orphans:")
 			for _, o := range orphans {
-				fmt.Printf("  %s → %s\n", o.Branch, o.Path)
+				fmt.Printf(plain("  %s → %s\n"), o.Branch, o.Path)
 			}
 			return nil
 		}
@@ -76,7 +87,7 @@ func runAdopt(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Println("Multiple orphan worktrees found:")
 		for i, o := range orphans {
-			fmt.Printf("  [%d] %s → %s\n", i+1, o.Branch, o.Path)
+			fmt.Printf(plain("  [%d] %s → %s\n"), i+1, o.Branch, o.Path)
 		}
 		fmt.Println()
 		answer := prompt("Which one? (number)", "")
@@ -97,13 +108,17 @@ func runAdopt(cmd *cobra.Command, args []string) error {
 	}
 
 	if s.AliasExists(alias) {
-		return fmt.Errorf("alias %q already exists — choose a different one", alias)
+		return errorf(state.ErrAliasExists, "alias %q already exists — choose a different one", alias)
 	}
 
-	if err := s.Add(alias, target.Branch, target.Path); err != nil {
-		return err
-	}
-	if err := state.Save(root, s); err != nil {
+	err = state.Change(root, func(s *state.State) error {
+		if err := s.Add(alias, target.Branch, target.Path); err != nil {
+			return err
+		}
+		s.Unignore(target.Path)
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
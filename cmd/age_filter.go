@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// ageFilter is the --since / --not-since filter of list, clean and exec:
+// it keeps worktrees by when they were last active, which is their last
+// commit, or when grove created them if that's later.
+type ageFilter struct {
+	since    string // keep worktrees active within this long
+	notSince string // keep worktrees not active within this long
+}
+
+var (
+	listAge  ageFilter
+	cleanAge ageFilter
+	execAge  ageFilter
+)
+
+func (f *ageFilter) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.since, "since", "", "only worktrees with a commit in this long, e.g. 2w or 36h")
+	cmd.Flags().StringVar(&f.notSince, "not-since", "", "only worktrees without a commit in this long, e.g. 30d")
+}
+
+// set reports whether either flag was given.
+func (f ageFilter) set() bool {
+	return f.since != "" || f.notSince != ""
+}
+
+// keep returns which of paths pass the filter. created holds when grove
+// created each managed worktree; others are judged by their commits alone.
+// A worktree whose last commit can't be read doesn't pass.
+func (f ageFilter) keep(paths []string, created map[string]time.Time, now time.Time) (map[string]bool, error) {
+	var since, notSince time.Duration
+	for _, flag := range []struct {
+		name, value string
+		into        *time.Duration
+	}{{"since", f.since, &since}, {"not-since", f.notSince, &notSince}} {
+		if flag.value == "" {
+			continue
+		}
+		d, err := parseTTL(flag.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s %q — use something like 30d, 2w or 12h", flag.name, flag.value)
+		}
+		*flag.into = d
+	}
+
+	active := make([]time.Time, len(paths))
+	errs := make([]error, len(paths))
+	parallel(len(paths), func(i int) {
+		active[i], errs[i] = git.LastCommitTime(paths[i])
+	})
+
+	kept := make(map[string]bool, len(paths))
+	for i, path := range paths {
+		if errs[i] != nil {
+			continue
+		}
+		last := active[i]
+		if c := created[path]; c.After(last) {
+			last = c
+		}
+		if since > 0 && last.Before(now.Add(-since)) {
+			continue
+		}
+		if notSince > 0 && !last.Before(now.Add(-notSince)) {
+			continue
+		}
+		kept[path] = true
+	}
+	return kept, nil
+}
+
+// filterRowsByAge keeps the rows whose worktrees pass f.
+func filterRowsByAge(root string, rows []worktreeRow, f ageFilter) ([]worktreeRow, error) {
+	s, err := state.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	created := make(map[string]time.Time, len(s.Worktrees))
+	for _, entry := range s.Worktrees {
+		created[entry.Path] = entry.Created
+	}
+	paths := make([]string, len(rows))
+	for i, r := range rows {
+		paths[i] = r.Path
+	}
+	kept, err := f.keep(paths, created, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var out []worktreeRow
+	for _, r := range rows {
+		if kept[r.Path] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// filterEntriesByAge keeps the aliases whose worktrees pass f.
+func filterEntriesByAge(s state.State, aliases []string, f ageFilter) ([]string, error) {
+	paths := make([]string, len(aliases))
+	created := make(map[string]time.Time, len(aliases))
+	for i, alias := range aliases {
+		entry := s.Worktrees[alias]
+		paths[i] = entry.Path
+		created[entry.Path] = entry.Created
+	}
+	kept, err := f.keep(paths, created, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, alias := range aliases {
+		if kept[s.Worktrees[alias].Path] {
+			out = append(out, alias)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestAgeFilter(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName, createFrom = "", ""
+	for _, branch := range []string{"feature/fresh", "feature/stale"} {
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatalf("create %s failed: %v", branch, err)
+		}
+		wtPath := filepath.Join(filepath.Dir(dir), "testproject-"+branchAlias(branch))
+		t.Cleanup(func() {
+			rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+			rm.Dir = dir
+			rm.CombinedOutput()
+		})
+	}
+
+	// Backdate the stale worktree: an old commit, created long ago.
+	stalePath := filepath.Join(filepath.Dir(dir), "testproject-stale")
+	old := time.Now().AddDate(0, 0, -60)
+	os.WriteFile(filepath.Join(stalePath, "old.txt"), []byte("old"), 0644)
+	gitCmd(stalePath, "add", "old.txt")
+	commit := exec.Command("git", "commit", "-m", "old")
+	commit.Dir = stalePath
+	commit.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+old.Format(time.RFC3339), "GIT_AUTHOR_DATE="+old.Format(time.RFC3339))
+	if out, err := commit.CombinedOutput(); err != nil {
+		t.Fatalf("commit: %v\n%s", err, out)
+	}
+	s, _ := state.Load(dir)
+	s.Update("stale", func(e *state.WorktreeEntry) { e.Created = old })
+	state.Save(dir, s)
+
+	aliases := []string{"fresh", "stale"}
+	for _, tt := range []struct {
+		filter ageFilter
+		want   string
+	}{
+		{ageFilter{notSince: "30d"}, "stale"},
+		{ageFilter{since: "2w"}, "fresh"},
+		{ageFilter{since: "90d", notSince: "30d"}, "stale"},
+		{ageFilter{since: "90d"}, "fresh stale"},
+	} {
+		got, err := filterEntriesByAge(s, aliases, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if joined := strings.Join(got, " "); joined != tt.want {
+			t.Errorf("%+v kept %q, want %q", tt.filter, joined, tt.want)
+		}
+	}
+
+	if _, err := filterEntriesByAge(s, aliases, ageFilter{since: "soon"}); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
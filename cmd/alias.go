@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasLsCmd, aliasSetCmd, aliasRmCmd)
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage worktree aliases without touching the worktrees",
+	Long: `List, set and remove the alias → worktree mappings in .grove/state.json.
+
+None of these commands create, move or delete worktrees, which makes them a
+safe way for scripts to retag trees.`,
+}
+
+var aliasLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List alias mappings",
+	Args:    cobra.NoArgs,
+	RunE:    runAliasLs,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <alias> <worktree>",
+	Short: "Point an alias at an existing worktree",
+	Long: `Give an existing worktree a new alias. <worktree> can be a current alias,
+a branch name or a path.
+
+A worktree grove already manages is renamed to the new alias; one grove
+doesn't manage yet is adopted under it.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliasSet,
+	RunE:              runAliasSet,
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:               "rm <alias>",
+	Short:             "Remove an alias mapping, keeping the worktree",
+	Long:              "Remove an alias from .grove/state.json. The worktree and its files stay where they are; 'grove adopt' or 'grove alias set' can manage it again later.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runAliasRm,
+}
+
+func runAliasLs(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(s.Worktrees, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(s.Worktrees) == 0 {
+		fmt.Println("No aliases. Create a worktree with 'grove create <branch>'.")
+		return nil
+	}
+
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias := range s.Worktrees {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, alias := range aliases {
+		entry := s.Worktrees[alias]
+		fmt.Fprintf(w, "%s\t%s\t%s\n", alias, entry.Path, entry.Branch)
+	}
+	return w.Flush()
+}
+
+func runAliasSet(cmd *cobra.Command, args []string) error {
+	alias, query := args[0], args[1]
+
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	if err := validateAlias(alias); err != nil {
+		return err
+	}
+
+	if query, err = expandRowRef(root, query); err != nil {
+		return err
+	}
+
+	// Paths may be given relative to cwd; state stores resolved absolute paths.
+	if info, err := os.Stat(query); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(query); err == nil {
+			if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+				query = resolved
+			}
+		}
+	}
+
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil {
+		return errorf(state.ErrNotFound, "no worktree matches %q — pass an alias, branch or worktree path", query)
+	}
+
+	if resolved.Alias == alias {
+		fmt.Printf("%s already points to %s.\n", alias, resolved.Path)
+		return nil
+	}
+	if s.AliasExists(alias) {
+		return errorf(state.ErrAliasExists, "alias %q already exists — remove it first with 'grove alias rm %s'", alias, alias)
+	}
+
+	err = state.Change(root, func(s *state.State) error {
+		if resolved.InState {
+			return s.Rename(resolved.Alias, alias)
+		}
+		if err := s.Add(alias, resolved.Branch, resolved.Path); err != nil {
+			return err
+		}
+		s.Unignore(resolved.Path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if resolved.InState {
+		fmt.Printf(plain("Renamed alias %s → %s (%s).\n"), resolved.Alias, alias, resolved.Path)
+	} else {
+		fmt.Printf("Worktree %q adopted (%s).\n", alias, resolved.Path)
+	}
+	return nil
+}
+
+func runAliasRm(cmd *cobra.Command, args []string) error {
+	alias := args[0]
+
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	entry, ok := s.Get(alias)
+	if !ok {
+		return errorf(state.ErrNotFound, "no worktree with alias %q — run 'grove alias ls' to see aliases", alias)
+	}
+	if err := state.Change(root, func(s *state.State) error { return s.Remove(alias) }); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed alias %s. The worktree is still at %s.\n", alias, entry.Path)
+	return nil
+}
+
+// completeAliasSet completes the second argument of `alias set` with
+// current aliases and unmanaged worktrees.
+func completeAliasSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	aliases, _ := completeAliases(cmd, nil, toComplete)
+	orphans, _ := completeOrphans(cmd, nil, toComplete)
+	return append(aliases, orphans...), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestAliasSetAndRm(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/auth"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-auth")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	// Retag a managed worktree by its branch name.
+	if err := runAliasSet(aliasSetCmd, []string{"login", "feature/auth"}); err != nil {
+		t.Fatalf("alias set failed: %v", err)
+	}
+	s, _ := state.Load(dir)
+	if entry, ok := s.Get("login"); !ok || entry.Path != wtPath {
+		t.Fatalf("login = %+v, %v; want path %s", entry, ok, wtPath)
+	}
+	if s.AliasExists("auth") {
+		t.Error("old alias should be gone after retagging")
+	}
+
+	// Removing the mapping leaves the worktree on disk.
+	if err := runAliasRm(aliasRmCmd, []string{"login"}); err != nil {
+		t.Fatalf("alias rm failed: %v", err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Fatalf("worktree should still exist: %v", err)
+	}
+	s, _ = state.Load(dir)
+	if len(s.Worktrees) != 0 {
+		t.Errorf("expected empty state, got %v", s.Worktrees)
+	}
+
+	// Now an orphan — set adopts it by path, relative to cwd.
+	rel, _ := filepath.Rel(dir, wtPath)
+	if err := runAliasSet(aliasSetCmd, []string{"auth", rel}); err != nil {
+		t.Fatalf("alias set on orphan failed: %v", err)
+	}
+	s, _ = state.Load(dir)
+	if entry, ok := s.Get("auth"); !ok || entry.Branch != "feature/auth" {
+		t.Errorf("auth = %+v, %v; want branch feature/auth", entry, ok)
+	}
+
+	if err := runAliasSet(aliasSetCmd, []string{"x", "nope"}); !errors.Is(err, state.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown worktree, got %v", err)
+	}
+	if err := runAliasRm(aliasRmCmd, []string{"nope"}); !errors.Is(err, state.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown alias, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// bannerFiles are the files the "banner" config writes into a worktree.
+var bannerFiles = map[string]string{
+	config.BannerMarkdown: "WORKTREE.md",
+	config.BannerMOTD:     ".grove-motd",
+}
+
+// writeBanner writes the kind of banner file into the worktree at
+// entry.Path, describing it for whoever opens it weeks later, and keeps the
+// file out of git status. Returns the file's name.
+func writeBanner(kind, root, alias string, entry state.WorktreeEntry) (string, error) {
+	name := bannerFiles[kind]
+	if err := excludeFromGit(root, name); err != nil {
+		return "", err
+	}
+	text := bannerText(kind, root, alias, entry)
+	if err := os.WriteFile(filepath.Join(entry.Path, name), []byte(text), 0644); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// bannerText is the banner's contents: what the worktree is, and the grove
+// commands that manage it.
+func bannerText(kind, root, alias string, entry state.WorktreeEntry) string {
+	from, mdFrom := "", ""
+	if entry.Base != "" {
+		from, mdFrom = " from "+entry.Base, " from `"+entry.Base+"`"
+	}
+	created := entry.Created.Format(time.DateOnly)
+	commands := [][2]string{
+		{"grove info " + alias, "what grove set up here"},
+		{"grove exec " + alias + " -- <command>", "run a command here"},
+		{"grove remove " + alias, "delete this worktree"},
+	}
+
+	var sb strings.Builder
+	if kind == config.BannerMOTD {
+		fmt.Fprintf(&sb, "grove worktree %s (%s)%s, created %s\n", alias, entry.Branch, from, created)
+		if entry.Note != "" {
+			fmt.Fprintf(&sb, "  %s\n", entry.Note)
+		}
+		for _, c := range commands {
+			fmt.Fprintf(&sb, "  %-*s  %s\n", 30, c[0], c[1])
+		}
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "# %s\n\n", alias)
+	fmt.Fprintf(&sb, "Grove worktree of `%s` for branch `%s`%s, created %s.\n", displayPath(root), entry.Branch, mdFrom, created)
+	if entry.Template != "" {
+		fmt.Fprintf(&sb, "Set up with the %q template.\n", entry.Template)
+	}
+	if entry.Note != "" {
+		fmt.Fprintf(&sb, "\n> %s\n", entry.Note)
+	}
+	sb.WriteString("\n| Command | |\n| --- | --- |\n")
+	for _, c := range commands {
+		fmt.Fprintf(&sb, "| `%s` | %s |\n", c[0], c[1])
+	}
+	sb.WriteString("\nGenerated by grove when the worktree was created; not tracked by git.\n")
+	return sb.String()
+}
+
+// excludeFromGit adds name to the repository's info/exclude, which every
+// worktree shares, unless it's there already.
+func excludeFromGit(root, name string) error {
+	common, err := git.CommonDir(root)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(root, common)
+	}
+	path := filepath.Join(common, "info", "exclude")
+	pattern := "/" + name
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, pattern+"\n"...)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
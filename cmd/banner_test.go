@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestCreateBanner(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}, Banner: config.BannerMarkdown})
+
+	createName = ""
+	createFrom = "main"
+	createNote = "try the new checkout flow"
+	t.Cleanup(func() { createFrom, createNote = "", "" })
+	if err := runCreate(createCmd, []string{"feature/banner"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-banner")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	data, err := os.ReadFile(filepath.Join(wtPath, "WORKTREE.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# banner", "`feature/banner` from `main`", "> try the new checkout flow", "grove remove banner"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("WORKTREE.md lacks %q:\n%s", want, data)
+		}
+	}
+
+	// The banner mustn't make the worktree look dirty.
+	if st, err := git.Status(wtPath); err != nil || !st.Clean() {
+		t.Errorf("status = %v, %v; want clean", st, err)
+	}
+
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("banner")
+	if entry.Note != createNote || entry.Setup == nil || entry.Setup.Banner != "WORKTREE.md" {
+		t.Errorf("entry = %+v, want the note and banner recorded", entry)
+	}
+}
+
+func TestBannerMOTD(t *testing.T) {
+	text := bannerText(config.BannerMOTD, "/code/shop", "auth", state.WorktreeEntry{Branch: "feature/auth", Base: "main", Note: "login rework"})
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if !strings.HasPrefix(lines[0], "grove worktree auth (feature/auth) from main, created ") {
+		t.Errorf("first line = %q", lines[0])
+	}
+	if lines[1] != "  login rework" {
+		t.Errorf("note line = %q", lines[1])
+	}
+}
+
+func TestExcludeFromGit(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{})
+
+	for range 2 {
+		if err := excludeFromGit(dir, ".grove-motd"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "/.grove-motd\n"); n != 1 {
+		t.Errorf("pattern added %d times, want once:\n%s", n, data)
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var benchRuns int
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().IntVarP(&benchRuns, "runs", "n", 5, "how many times to time each operation")
+}
+
+var benchCmd = &cobra.Command{
+	Use:    "bench",
+	Short:  "Time grove's key operations against this repository",
+	Hidden: true,
+	Long: `Time the operations that make grove feel fast or slow in this repository,
+and print the fastest, median and slowest of several runs:
+
+  worktree list   git worktree list, which nearly every command runs
+  list            grove list with live git status in every worktree
+  list (cached)   grove list answered from the status cache
+  status batch    grove status --stdin-batch over every worktree
+  create preflight
+                  what grove create checks before it runs git worktree
+                  add: config, state, alias, path and branch lookups
+
+Nothing is created or changed. Run it before and after an upgrade to put
+a number on a regression, or with GROVE_GIT pointing at another git build
+to compare them. --json prints the timings in nanoseconds for scripts.`,
+	Args: cobra.NoArgs,
+	RunE: runBench,
+}
+
+// benchResult is the timing of one operation; durations are in nanoseconds
+// in --json output.
+type benchResult struct {
+	Name   string        `json:"name"`
+	Runs   int           `json:"runs"`
+	Min    time.Duration `json:"min"`
+	Median time.Duration `json:"median"`
+	Max    time.Duration `json:"max"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// benchReport is the --json shape of `grove bench`.
+type benchReport struct {
+	Version    string        `json:"version"`
+	Platform   string        `json:"platform"`
+	GitBinary  string        `json:"gitBinary"`
+	GitVersion string        `json:"gitVersion"`
+	Worktrees  int           `json:"worktrees"`
+	Results    []benchResult `json:"results"`
+}
+
+// benchOp is one timed operation.
+type benchOp struct {
+	name string
+	run  func() error
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1, got %d", benchRuns)
+	}
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	report, err := bench(root, cfg, benchRuns)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printBenchReport(os.Stdout, report)
+	return nil
+}
+
+// bench times each operation runs times against the project at root.
+func bench(root string, cfg config.Config, runs int) (benchReport, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return benchReport{}, err
+	}
+	paths := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		paths[i] = wt.Path
+	}
+
+	report := benchReport{
+		Version:   Version,
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		GitBinary: git.Binary(),
+		Worktrees: len(worktrees),
+	}
+	if v, err := git.Version(); err == nil {
+		report.GitVersion = strings.TrimPrefix(v, "git version ")
+	}
+
+	// The operations below flip --no-cache; put it back afterwards.
+	noCache := statusNoCache
+	defer func() { statusNoCache = noCache }()
+
+	ops := []benchOp{
+		{"worktree list", func() error {
+			_, err := git.ListWorktrees()
+			return err
+		}},
+		{"list", func() error {
+			statusNoCache = true
+			_, err := buildWorktreeRows(root)
+			return err
+		}},
+		{"list (cached)", func() error {
+			statusNoCache = false
+			_, err := buildWorktreeRows(root)
+			return err
+		}},
+		{"status batch", func() error {
+			statusNoCache = true
+			return writeStatuses(io.Discard, nil, strings.NewReader(strings.Join(paths, "\n")))
+		}},
+		{"create preflight", func() error {
+			return createPreflight(root, cfg)
+		}},
+	}
+	for _, op := range ops {
+		report.Results = append(report.Results, timeOp(op, runs))
+	}
+	return report, nil
+}
+
+// timeOp runs op runs times, stopping at the first error.
+func timeOp(op benchOp, runs int) benchResult {
+	result := benchResult{Name: op.name}
+	times := make([]time.Duration, 0, runs)
+	for range runs {
+		start := time.Now()
+		if err := op.run(); err != nil {
+			result.Error = err.Error()
+			break
+		}
+		times = append(times, time.Since(start))
+	}
+	if len(times) == 0 {
+		return result
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	result.Runs = len(times)
+	result.Min, result.Median, result.Max = times[0], times[len(times)/2], times[len(times)-1]
+	return result
+}
+
+// createPreflight does the lookups grove create makes before it runs git
+// worktree add, for a branch that doesn't exist, without creating anything.
+func createPreflight(root string, cfg config.Config) error {
+	if _, err := config.Load(root); err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	const branch = "grove-bench/preflight"
+	alias := branchAlias(branch)
+	if err := validateAlias(alias); err != nil {
+		return err
+	}
+	worktreePath, err := worktreePathFor(root, cfg, alias)
+	if err != nil {
+		return err
+	}
+	if err := caseCollision(s, alias, branch, worktreePath); err != nil {
+		return err
+	}
+	git.BranchExists(branch)
+	_, err = git.CurrentBranch()
+	return err
+}
+
+func printBenchReport(w io.Writer, r benchReport) {
+	fmt.Fprintf(w, "grove %s on %s, git %s (%s), %d worktree(s)\n\n", r.Version, r.Platform, r.GitVersion, r.GitBinary, r.Worktrees)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tRUNS\tMIN\tMEDIAN\tMAX")
+	for _, res := range r.Results {
+		if res.Error != "" {
+			fmt.Fprintf(tw, "%s\t%d\tfailed: %s\t\t\n", res.Name, res.Runs, res.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", res.Name, res.Runs, benchDuration(res.Min), benchDuration(res.Median), benchDuration(res.Max))
+	}
+	tw.Flush()
+}
+
+// benchDuration rounds d to a readable precision, e.g. 12.34ms.
+func benchDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
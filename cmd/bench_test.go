@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestBench(t *testing.T) {
+	cfg := config.Default()
+	cfg.Prefix = "app"
+	root := setupIntegrationRepo(t, cfg)
+
+	createName, createFrom, createTemplate = "", "", ""
+	if _, err := createWorktree(createCmd, "feature/auth"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := bench(root, cfg, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Worktrees != 2 {
+		t.Errorf("Worktrees = %d, want 2", report.Worktrees)
+	}
+	for _, res := range report.Results {
+		if res.Error != "" || res.Runs != 3 {
+			t.Errorf("%s: runs %d, error %q", res.Name, res.Runs, res.Error)
+		}
+		if res.Min > res.Median || res.Median > res.Max {
+			t.Errorf("%s: min %v, median %v, max %v out of order", res.Name, res.Min, res.Median, res.Max)
+		}
+	}
+	if statusNoCache {
+		t.Error("bench should restore --no-cache")
+	}
+
+	var out bytes.Buffer
+	printBenchReport(&out, report)
+	for _, want := range []string{"worktree list", "list (cached)", "status batch", "create preflight", "2 worktree(s)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
 	"github.com/verbaux/grove/internal/state"
 )
 
+var cdCreate bool
+
 func init() {
+	cdCmd.Flags().BoolVar(&cdCreate, "create", false, "create the worktree for this branch if it doesn't exist, without asking")
 	rootCmd.AddCommand(cdCmd)
 }
 
@@ -26,7 +32,11 @@ Usage:
   cd $(grove cd 3)
 
 Or add a shell function (aliases can't take arguments):
-  gcd() { cd "$(grove cd "$1")"; }`,
+  gcd() { cd "$(grove cd "$1")"; }
+
+If nothing matches, grove offers to create a worktree for the argument as a
+branch, then prints its path — one step to start a new task. --create skips
+the question; in scripts without a terminal, nothing is created without it.`,
 	Args: cobra.ExactArgs(1),
 	ValidArgsFunction: completeAliases,
 	RunE: runCd,
@@ -45,6 +55,17 @@ func runCd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if strings.HasPrefix(arg, "@") {
+		path, err := expandRowRef(root, arg)
+		if err != nil {
+			return err
+		}
+		if path != arg {
+			fmt.Println(displayPath(path))
+			return nil
+		}
+	}
+
 	// If the argument is a number, resolve by index from the worktree list.
 	if idx, err := strconv.Atoi(arg); err == nil {
 		rows, err := buildWorktreeRows(root)
@@ -52,9 +73,9 @@ func runCd(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		if idx < 1 || idx > len(rows) {
-			return fmt.Errorf("index %d out of range — run 'grove list' to see available worktrees (1–%d)", idx, len(rows))
+			return errorf(state.ErrNotFound, "index %d out of range — run 'grove list' to see available worktrees (1–%d)", idx, len(rows))
 		}
-		fmt.Println(rows[idx-1].Path)
+		fmt.Println(displayPath(rows[idx-1].Path))
 		return nil
 	}
 
@@ -63,11 +84,49 @@ func runCd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	entry, ok := s.Get(arg)
-	if !ok {
-		return fmt.Errorf("no worktree with alias %q — run 'grove list' to see available worktrees", arg)
+	resolved, err := resolveWorktree(arg, s)
+	if err != nil {
+		return err
+	}
+	if resolved != nil && resolved.InState {
+		fmt.Println(displayPath(resolved.Path))
+		return nil
+	}
+
+	// Only a branch can be created; alias: and path: name worktrees that
+	// have to exist already.
+	kind, branch := splitQueryKind(arg)
+	if kind == "alias" || kind == "path" {
+		return errorf(state.ErrNotFound, "no worktree matches %q — run 'grove list' to see available worktrees", arg)
 	}
+	if !cdCreate && !offerCreate(branch) {
+		return errorf(state.ErrNotFound, "no worktree with alias %q — run 'grove list' to see available worktrees, or pass --create", arg)
+	}
+	return createForCd(root, branch)
+}
 
-	fmt.Println(entry.Path)
+// offerCreate asks whether to create a worktree for branch. The question
+// goes to stderr, since stdout is being captured by $(grove cd ...); without
+// a terminal there's nobody to ask.
+func offerCreate(branch string) bool {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return false
+	}
+	answer := promptTo(os.Stderr, fmt.Sprintf("No worktree %q. Create one for branch %s? [y/N]", branchAlias(branch), branch), "n")
+	return answer == "y" || answer == "Y"
+}
+
+// createForCd runs grove create for branch with the default settings and
+// prints the new worktree's path. Everything create prints goes to stderr,
+// so stdout carries only the path, and the "open" setting is ignored: an
+// editor or shell started inside $(grove cd ...) would hang the caller.
+func createForCd(root, branch string) error {
+	resetCreateFlags()
+	git.Progress = !jsonOutput && isatty.IsTerminal(os.Stderr.Fd())
+	report, err := createFromSpec(createCmd, branchSpec{Branch: branch}, os.Stderr)
+	if err != nil {
+		return err
+	}
+	fmt.Println(displayPath(report.Path))
 	return nil
 }
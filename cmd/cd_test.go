@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestCdCreate(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-jit")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	// Without --create and without a terminal, nothing is created.
+	cdCreate = false
+	if err := runCd(cdCmd, []string{"feature/jit"}); exitCode(err) != exitNotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+
+	// Create flags left over from earlier in the process don't apply.
+	createNote, createReadOnly = "left over", true
+	t.Cleanup(resetCreateFlags)
+
+	cdCreate = true
+	t.Cleanup(func() { cdCreate = false })
+	out := captureStdout(t, func() {
+		if err := runCd(cdCmd, []string{"feature/jit"}); err != nil {
+			t.Fatalf("cd --create failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(string(out)) != wtPath {
+		t.Errorf("stdout = %q, want only the new path %s", out, wtPath)
+	}
+	s, _ := state.Load(dir)
+	if entry, ok := s.Get("jit"); !ok {
+		t.Error("worktree not recorded in state")
+	} else if entry.Note != "" || (entry.Setup != nil && entry.Setup.ReadOnly) {
+		t.Errorf("entry = %+v, want the default create settings", entry)
+	}
+
+	// The branch name now finds the existing worktree.
+	out = captureStdout(t, func() {
+		if err := runCd(cdCmd, []string{"feature/jit"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if strings.TrimSpace(string(out)) != wtPath {
+		t.Errorf("stdout = %q, want %s", out, wtPath)
+	}
+}
+
+func TestCdCreateIgnoresOpen(t *testing.T) {
+	// An editor that fails: if cd --create tried to open it, cd would fail.
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}, Open: config.OpenEditor, Editor: "false"})
+	t.Setenv("GROVE_EDITOR", "")
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-quiet")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	cdCreate = true
+	t.Cleanup(func() { cdCreate = false })
+	out := captureStdout(t, func() {
+		if err := runCd(cdCmd, []string{"feature/quiet"}); err != nil {
+			t.Fatalf("cd --create failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(string(out)) != wtPath {
+		t.Errorf("stdout = %q, want only the new path %s", out, wtPath)
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	claimOwner   string
+	releaseForce bool
+)
+
+func init() {
+	rootCmd.AddCommand(claimCmd, releaseCmd)
+	for _, c := range []*cobra.Command{claimCmd, releaseCmd} {
+		c.Flags().StringVar(&claimOwner, "owner", "", "who holds the claim (default $GROVE_OWNER, else user@host)")
+	}
+	releaseCmd.Flags().BoolVar(&releaseForce, "force", false, "release a claim held by someone else, e.g. a job that crashed")
+}
+
+var claimCmd = &cobra.Command{
+	Use:   "claim <name>",
+	Short: "Mark a worktree as in use",
+	Long: `Mark a worktree as in use by you, or by an automation job, so another job
+doesn't enter the same tree. The owner and time are recorded in
+.grove/state.json; the check and the write happen under a lock, so of two
+jobs claiming at once exactly one wins.
+
+Claiming a worktree someone else holds fails with exit code 7. Claiming one
+you already hold succeeds and refreshes the time. 'grove release' gives it
+back. While claimed, 'grove remove' needs --force and 'grove clean' skips
+the worktree.
+
+The owner is --owner, else $GROVE_OWNER, else user@host — give each CI job
+or agent its own, e.g. GROVE_OWNER=ci-$JOB_ID. --json prints the claim with
+the worktree path, for scripts to cd into.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runClaim,
+}
+
+var releaseCmd = &cobra.Command{
+	Use:               "release <name>",
+	Short:             "Release a worktree claimed with grove claim",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runRelease,
+}
+
+// claimReport is the --json shape of `grove claim`.
+type claimReport struct {
+	Alias string    `json:"alias"`
+	Path  string    `json:"path"`
+	Owner string    `json:"owner"`
+	Since time.Time `json:"since"`
+}
+
+func runClaim(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	owner := claimOwnerName()
+	var report claimReport
+	err = state.Change(root, func(s *state.State) error {
+		alias, err := resolveManaged(root, *s, args[0])
+		if err != nil {
+			return err
+		}
+		entry, _ := s.Get(alias)
+		if c := entry.Claim; c != nil && c.Owner != owner {
+			return errorf(state.ErrClaimed, "%s is claimed by %s since %s — pick another worktree, or wait for 'grove release %s'", alias, c.Owner, c.Since.Format(time.DateTime), alias)
+		}
+		claim := &state.Claim{Owner: owner, Since: time.Now()}
+		s.Update(alias, func(e *state.WorktreeEntry) { e.Claim = claim })
+		report = claimReport{Alias: alias, Path: displayPath(entry.Path), Owner: owner, Since: claim.Since}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Printf("Claimed %s for %s.\n", report.Alias, report.Owner)
+	return nil
+}
+
+func runRelease(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	owner := claimOwnerName()
+	var alias, was string
+	err = state.Change(root, func(s *state.State) error {
+		if alias, err = resolveManaged(root, *s, args[0]); err != nil {
+			return err
+		}
+		entry, _ := s.Get(alias)
+		if entry.Claim == nil {
+			return nil
+		}
+		if entry.Claim.Owner != owner && !releaseForce {
+			return errorf(state.ErrClaimed, "%s is claimed by %s, not %s — pass --force to release it anyway", alias, entry.Claim.Owner, owner)
+		}
+		was = entry.Claim.Owner
+		s.Update(alias, func(e *state.WorktreeEntry) { e.Claim = nil })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if was == "" {
+		fmt.Printf("%s isn't claimed.\n", alias)
+		return nil
+	}
+	fmt.Printf("Released %s (claimed by %s).\n", alias, was)
+	return nil
+}
+
+// resolveManaged resolves query, as any command taking a worktree does, to
+// the alias of a managed worktree.
+func resolveManaged(root string, s state.State, query string) (string, error) {
+	path, err := expandRowRef(root, query)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveWorktree(path, s)
+	if err != nil {
+		return "", err
+	}
+	if resolved == nil || !resolved.InState {
+		return "", errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", query)
+	}
+	return resolved.Alias, nil
+}
+
+// claimOwnerName is who grove claim and release act for.
+func claimOwnerName() string {
+	if claimOwner != "" {
+		return claimOwner
+	}
+	if o := os.Getenv("GROVE_OWNER"); o != "" {
+		return o
+	}
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// claimedByOther returns the claim on entry if it's held by someone other
+// than the current owner, for commands that mustn't pull a worktree out
+// from under a job.
+func claimedByOther(entry state.WorktreeEntry) *state.Claim {
+	if entry.Claim == nil || entry.Claim.Owner == claimOwnerName() {
+		return nil
+	}
+	return entry.Claim
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestClaim(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	if _, err := createWorktree(createCmd, "feature/auth"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { claimOwner, releaseForce, removeForce = "", false, false })
+
+	claimOwner = "ci-1"
+	if err := runClaim(claimCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := state.Load(dir)
+	if entry, _ := s.Get("auth"); entry.Claim == nil || entry.Claim.Owner != "ci-1" {
+		t.Fatalf("claim = %+v", entry.Claim)
+	}
+	// Claiming again as the same owner is fine.
+	if err := runClaim(claimCmd, []string{"feature/auth"}); err != nil {
+		t.Errorf("reclaim by the owner: %v", err)
+	}
+
+	// Another job is turned away, and can't remove or release it either.
+	claimOwner = "ci-2"
+	if err := runClaim(claimCmd, []string{"auth"}); !errors.Is(err, state.ErrClaimed) || exitCode(err) != exitClaimed {
+		t.Errorf("claim by another owner = %v", err)
+	}
+	if err := runRemove(removeCmd, []string{"auth"}); !errors.Is(err, state.ErrClaimed) {
+		t.Errorf("remove of a claimed worktree = %v", err)
+	}
+	if err := runRelease(releaseCmd, []string{"auth"}); !errors.Is(err, state.ErrClaimed) {
+		t.Errorf("release by another owner = %v", err)
+	}
+
+	claimOwner = "ci-1"
+	if err := runRelease(releaseCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = state.Load(dir)
+	if entry, _ := s.Get("auth"); entry.Claim != nil {
+		t.Errorf("claim after release = %+v", entry.Claim)
+	}
+
+	// --force releases someone else's claim, e.g. after a crash.
+	if err := runClaim(claimCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	claimOwner, releaseForce = "ci-2", true
+	if err := runRelease(releaseCmd, []string{"auth"}); err != nil {
+		t.Errorf("release --force: %v", err)
+	}
+}
+
+func TestPruneKeepsClaimed(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	if _, err := createWorktree(createCmd, "feature/auth"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { claimOwner = "" })
+	claimOwner = "ci-1"
+	if err := runClaim(claimCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	err := state.Change(dir, func(s *state.State) error {
+		return s.Update("auth", func(e *state.WorktreeEntry) { e.Expires = time.Now().Add(-time.Minute) })
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another job's prune leaves it to its owner.
+	claimOwner = "ci-2"
+	if err := runPrune(pruneCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := state.Load(dir); !s.AliasExists("auth") {
+		t.Error("prune removed a worktree claimed by another job")
+	}
+}
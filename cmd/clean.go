@@ -5,18 +5,36 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
 	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/i18n"
 	"github.com/verbaux/grove/internal/state"
 )
 
-var cleanForce bool
+var (
+	cleanForce    bool
+	cleanExitCode bool
+	cleanExpired  bool
+	cleanYes      bool
+
+	cleanMerged       bool
+	cleanInto         string
+	cleanDeleteBranch bool
+)
 
 func init() {
 	rootCmd.AddCommand(cleanCmd)
 	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "remove even if worktrees have uncommitted changes")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "don't ask before removing; worktrees with changes are skipped unless --force")
+	cleanCmd.Flags().BoolVar(&cleanExitCode, "exit-code", false, "exit with code 6 when there is nothing to clean")
+	cleanCmd.Flags().BoolVar(&cleanExpired, "expired", false, "only remove worktrees past their expiry (see 'grove expire')")
+	cleanAge.addFlags(cleanCmd)
+	cleanCmd.Flags().BoolVar(&cleanMerged, "merged", false, "only remove worktrees whose branch is merged into the default branch")
+	cleanCmd.Flags().StringVar(&cleanInto, "into", "", "with --merged, check against this branch instead of the default one")
+	cleanCmd.Flags().BoolVar(&cleanDeleteBranch, "delete-branch", false, "with --merged, also delete the merged branches")
 }
 
 var cleanCmd = &cobra.Command{
@@ -25,10 +43,37 @@ var cleanCmd = &cobra.Command{
 	Long: `Remove all grove-managed worktrees, keeping the main working tree intact.
 
 Shows a list of what will be removed and asks for confirmation.
-Use --force to remove even if worktrees have uncommitted changes.`,
+Use --force to remove even if worktrees have uncommitted changes.
+
+Worktrees with uncommitted changes follow "onDirtyRemove" in .groverc.json:
+prompt (default), block (skip them), stash (save changes first) or force.
+
+With --expired, only worktrees whose expiry has passed are removed (see
+'grove expire'); orphan worktrees are left alone.
+
+With --not-since 30d, only worktrees without a commit in the last 30 days
+are removed; --since keeps to recently active ones instead. A worktree
+counts as active from when grove created it. These compose with --expired,
+and orphans are left alone.
+
+With --merged, only worktrees whose branch is fully merged into the default
+branch (or into origin's copy of it) are removed; --into <branch> checks
+against another branch instead. --delete-branch deletes those branches once
+their worktrees are gone. Branches merged by squash or rebase have new
+commits on the target, so they aren't detected. Orphans are left alone.
+
+With --yes, clean doesn't ask before removing, for cron jobs and scripts.
+Worktrees with uncommitted changes are skipped then, unless --force is
+given too or "onDirtyRemove" says otherwise.
+
+With --exit-code, grove exits with code 6 when there was nothing to clean.`,
 	RunE: runClean,
 }
 
+// cleanPreviewMax is how many worktrees clean lists before asking to go
+// ahead; the rest are summarized.
+const cleanPreviewMax = 20
+
 func runClean(cmd *cobra.Command, args []string) error {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -40,124 +85,279 @@ func runClean(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := config.Load(root)
+	if err != nil {
+		return err
+	}
+
+	policy, err := cfg.DirtyRemovePolicy()
+	if err != nil {
+		return err
+	}
+	if cleanForce {
+		policy = config.DirtyForce
+	}
+
+	if !cleanMerged && (cleanInto != "" || cleanDeleteBranch) {
+		return fmt.Errorf("--into and --delete-branch only apply with --merged — add --merged")
+	}
+
 	s, err := state.Load(root)
 	if err != nil {
 		return err
 	}
 
 	if len(s.Worktrees) == 0 {
-		fmt.Println("No managed worktrees to clean.")
-		if orphanRemoved, err := cleanOrphans(s, cleanForce); err != nil {
+		fmt.Print(i18n.T("No managed worktrees to clean.\n"))
+		if cleanExpired || cleanAge.set() || cleanMerged {
+			return nothingToDo(cleanExitCode)
+		}
+		if orphanRemoved, err := cleanOrphans(s, policy); err != nil {
 			return err
 		} else if orphanRemoved > 0 {
-			fmt.Printf("Removed %d orphan worktree(s).\n", orphanRemoved)
+			fmt.Printf(i18n.T("Removed %d orphan worktree(s).\n"), orphanRemoved)
+			return nil
 		}
-		return nil
+		return nothingToDo(cleanExitCode)
 	}
 
 	type worktreeInfo struct {
 		alias  string
 		path   string
+		branch string
 		status string
+		dirty  bool
 	}
 
+	now := time.Now()
 	aliases := make([]string, 0, len(s.Worktrees))
-	for alias := range s.Worktrees {
+	for alias, entry := range s.Worktrees {
+		if cleanExpired && !isExpired(entry, now) {
+			continue
+		}
 		aliases = append(aliases, alias)
 	}
 	sort.Strings(aliases)
+	if cleanAge.set() {
+		if aliases, err = filterEntriesByAge(s, aliases, cleanAge); err != nil {
+			return err
+		}
+	}
+	if cleanMerged {
+		if aliases, err = filterMergedEntries(s, aliases, cleanInto); err != nil {
+			return err
+		}
+	}
 
 	var toRemove []worktreeInfo
 	var dirty []string
 
-	for _, alias := range aliases {
+	// Check every worktree up front, several at a time.
+	statuses := make([]git.WorktreeStatus, len(aliases))
+	statusErrs := make([]error, len(aliases))
+	parallel(len(aliases), func(i int) {
+		statuses[i], statusErrs[i] = git.Status(s.Worktrees[aliases[i]].Path)
+	})
+
+	locked := lockedPaths()
+	for i, alias := range aliases {
 		entry := s.Worktrees[alias]
-		status, err := git.Status(entry.Path)
+		st, err := statuses[i], statusErrs[i]
+		status, isDirty := st.String(), !st.Clean()
 		if err != nil {
-			status = "unknown"
+			status, isDirty = "unknown", true
+		}
+		// A path that's already gone has nothing to lose — it's only stale state.
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			isDirty = false
 		}
-		toRemove = append(toRemove, worktreeInfo{alias, entry.Path, status})
-		if status != "clean" {
+		if c := claimedByOther(entry); c != nil && !cleanForce {
+			fmt.Printf(i18n.T("  skipping %s — claimed by %s\n"), alias, c.Owner)
+			continue
+		}
+		if locked[entry.Path] {
+			fmt.Printf(i18n.T("  skipping %s — locked (grove unlock %s, or grove remove --force)\n"), alias, alias)
+			continue
+		}
+		if isDirty && policy == config.DirtyBlock {
+			fmt.Printf(i18n.T("  skipping %s (%s) — onDirtyRemove is \"block\"\n"), alias, status)
+			continue
+		}
+		// Nobody is there to answer the question about changes.
+		if isDirty && cleanYes && policy == config.DirtyPrompt {
+			fmt.Printf(i18n.T("  skipping %s (%s) — has changes; pass --force with --yes to remove it anyway\n"), alias, status)
+			continue
+		}
+		// A merge or rebase in progress is unfinished work, not leftovers.
+		if isDirty && st.Conflicted > 0 && policy != config.DirtyForce {
+			fmt.Printf(i18n.T("  skipping %s (%s) — finish or abort the merge first, or pass --force\n"), alias, status)
+			continue
+		}
+		toRemove = append(toRemove, worktreeInfo{alias, entry.Path, entry.Branch, status, isDirty})
+		if isDirty {
 			dirty = append(dirty, fmt.Sprintf("  %s (%s)", alias, status))
 		}
 	}
 
-	if len(dirty) > 0 && !cleanForce {
-		fmt.Println("The following worktrees have uncommitted changes:")
+	if len(toRemove) == 0 {
+		fmt.Print(i18n.T("Nothing to clean.\n"))
+		return nothingToDo(cleanExitCode)
+	}
+
+	if len(dirty) > 0 && policy != config.DirtyForce {
+		fmt.Print(i18n.T("The following worktrees have uncommitted changes:\n"))
 		fmt.Println(strings.Join(dirty, "\n"))
 		fmt.Println()
 	}
 
-	fmt.Println("Will remove:")
-	for _, wt := range toRemove {
-		fmt.Printf("  %s → %s\n", wt.alias, wt.path)
+	fmt.Print(i18n.T("Will remove:\n"))
+	// Dirty worktrees were all listed above; past a screenful, the clean
+	// ones are only counted.
+	for i, wt := range toRemove {
+		if i == cleanPreviewMax && len(toRemove) > cleanPreviewMax+1 {
+			fmt.Printf(i18n.T("  … and %d more — run 'grove list --all' to see them\n"), len(toRemove)-i)
+			break
+		}
+		fmt.Printf(plain(i18n.T("  %s → %s\n")), wt.alias, wt.path)
 	}
 	fmt.Println()
 
-	if len(dirty) > 0 && !cleanForce {
-		answer := prompt("Some worktrees have changes. Remove all anyway? [y/N]", "n")
+	// With --yes, dirty worktrees needing a question were skipped above.
+	switch {
+	case cleanYes:
+	case len(dirty) > 0 && policy == config.DirtyPrompt:
+		answer := prompt(i18n.T("Some worktrees have changes. Remove all anyway? [y/N]"), "n")
 		if answer != "y" && answer != "Y" {
-			fmt.Println("Aborted.")
+			fmt.Print(i18n.T("Aborted.\n"))
 			return nil
 		}
-	} else {
-		answer := prompt(fmt.Sprintf("Remove %d worktree(s)? [y/N]", len(toRemove)), "n")
+	default:
+		answer := prompt(fmt.Sprintf(i18n.T("Remove %d worktree(s)? [y/N]"), len(toRemove)), "n")
 		if answer != "y" && answer != "Y" {
-			fmt.Println("Aborted.")
+			fmt.Print(i18n.T("Aborted.\n"))
 			return nil
 		}
 	}
 
-	// User confirmed removal of dirty worktrees — pass force to git.
-	force := cleanForce
-	if len(dirty) > 0 && !cleanForce {
-		force = true
-	}
+	// User confirmed removal of dirty worktrees (or policy allows it) — pass force to git.
+	force := cleanForce || len(dirty) > 0
 
 	// If one removal fails, keep going — state stays consistent with what was actually removed.
+	guard, err := newMainGuard()
+	if err != nil {
+		return err
+	}
+
 	var removed int
+	var gone []string
+	var leftCwd bool
 	for _, wt := range toRemove {
+		if err := guard.check(wt.path, ""); err != nil {
+			fmt.Printf(i18n.T("  failed to remove %q: %v\n"), wt.alias, err)
+			continue
+		}
+		// Step out of a worktree before deleting it, so the git calls that
+		// follow don't run in a removed directory.
+		if !leftCwd && isWithin(cwd, wt.path) {
+			if err := os.Chdir(root); err != nil {
+				return err
+			}
+			leftCwd = true
+		}
 		if _, err := os.Stat(wt.path); os.IsNotExist(err) {
 			// Path already gone — just clean up state
-			if err := s.Remove(wt.alias); err != nil {
-				fmt.Fprintf(os.Stderr, "  warning: could not remove alias %s from state: %v\n", wt.alias, err)
-			}
+			gone = append(gone, wt.alias)
 			removed++
-			fmt.Printf("  ✓ cleaned stale entry %s (path no longer exists)\n", wt.alias)
+			fmt.Printf(plain(i18n.T("  ✓ cleaned stale entry %s (path no longer exists)\n")), wt.alias)
+			deleteMergedBranch(wt.branch)
 			continue
 		}
-		if err := git.RemoveWorktree(wt.path, force); err != nil {
-			fmt.Printf("  failed to remove %q: %v\n", wt.alias, err)
+		var snapshot string
+		if wt.dirty {
+			snap, err := preserveChanges(policy, wt.alias, wt.path)
+			if err != nil {
+				fmt.Printf(i18n.T("  failed to remove %q: %v\n"), wt.alias, err)
+				continue
+			}
+			snapshot = snap
+		}
+		entry := s.Worktrees[wt.alias]
+		if _, err := teardownConfig(root, cfg, entry); err != nil {
+			fmt.Printf(i18n.T("  failed to remove %q: %v\n"), wt.alias, err)
 			continue
 		}
-		if err := s.Remove(wt.alias); err != nil {
-			fmt.Fprintf(os.Stderr, "  warning: could not remove alias %s from state: %v\n", wt.alias, err)
+		var trashed string
+		if cfg.TrashDays > 0 {
+			var err error
+			if trashed, err = moveToTrash(root, cfg, wt.alias, entry, snapshot); err != nil {
+				fmt.Printf(i18n.T("  failed to move %q to trash, not removing: %v\n"), wt.alias, err)
+				continue
+			}
+		}
+		if err := teardownWorktree(root, cfg, wt.alias, entry); err != nil {
+			if !cleanForce {
+				if trashed != "" {
+					discardTrash(root, trashed)
+				}
+				fmt.Printf(i18n.T("  failed to remove %q: %v\n"), wt.alias, err)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, i18n.T("  warning: %v\n"), err)
+		}
+		if err := git.RemoveWorktree(wt.path, force); err != nil {
+			fmt.Printf(i18n.T("  failed to remove %q: %v\n"), wt.alias, err)
+			continue
 		}
+		gone = append(gone, wt.alias)
 		removed++
-		fmt.Printf("  ✓ removed %s\n", wt.alias)
+		fmt.Printf(plain(i18n.T("  ✓ removed %s\n")), wt.alias)
+		deleteMergedBranch(wt.branch)
 	}
 
-	if err := state.Save(root, s); err != nil {
+	if err := dropAliases(root, gone); err != nil {
 		return err
 	}
+	for _, alias := range gone {
+		s.Remove(alias)
+	}
 
 	if err := git.PruneWorktrees(); err != nil {
-		fmt.Fprintf(os.Stderr, "  warning: git worktree prune failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("  warning: git worktree prune failed: %v\n"), err)
 	}
 
-	fmt.Printf("\nRemoved %d of %d worktree(s).\n", removed, len(toRemove))
+	fmt.Printf(i18n.T("\nRemoved %d of %d worktree(s).\n"), removed, len(toRemove))
+	if _, err := os.Stat(cwd); leftCwd && os.IsNotExist(err) {
+		fmt.Printf(i18n.T("Your shell was inside a removed worktree — run: cd %s\n"), root)
+	}
 
 	// Phase 2: orphan worktrees (git knows, grove doesn't)
-	if orphanRemoved, err := cleanOrphans(s, cleanForce); err != nil {
+	if cleanExpired || cleanAge.set() || cleanMerged {
+		return nil
+	}
+	if orphanRemoved, err := cleanOrphans(s, policy); err != nil {
 		return err
 	} else if orphanRemoved > 0 {
-		fmt.Printf("Removed %d orphan worktree(s).\n", orphanRemoved)
+		fmt.Printf(i18n.T("Removed %d orphan worktree(s).\n"), orphanRemoved)
 	}
 
 	return nil
 }
 
-func cleanOrphans(s state.State, force bool) (int, error) {
+// deleteMergedBranch deletes a branch clean --merged found merged, when
+// --delete-branch asks for it. Merging was checked against the target, which
+// git branch -d wouldn't know about, hence -D.
+func deleteMergedBranch(branch string) {
+	if !cleanDeleteBranch || branch == "" {
+		return
+	}
+	if err := git.DeleteBranch(branch, true); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("  warning: could not delete branch %s: %v\n"), branch, err)
+		return
+	}
+	fmt.Printf(plain(i18n.T("  ✓ deleted branch %s\n")), branch)
+}
+
+func cleanOrphans(s state.State, policy string) (int, error) {
 	orphans, err := findOrphans(s)
 	if err != nil {
 		return 0, err
@@ -167,46 +367,85 @@ func cleanOrphans(s state.State, force bool) (int, error) {
 		return 0, nil
 	}
 
-	fmt.Printf("\nFound %d orphan worktree(s) not managed by grove:\n", len(orphans))
+	fmt.Printf(i18n.T("\nFound %d orphan worktree(s) not managed by grove:\n"), len(orphans))
 
 	var dirty []string
+	var targets []orphanWorktree
+	dirtySet := make(map[string]bool)
 	for _, o := range orphans {
-		status, err := git.Status(o.Path)
+		st, err := git.Status(o.Path)
+		status, isDirty := st.String(), !st.Clean()
 		if err != nil {
-			status = "unknown"
+			status, isDirty = "unknown", true
 		}
 		marker := ""
-		if status != "clean" {
+		if isDirty {
+			if policy == config.DirtyBlock {
+				fmt.Printf(plain(i18n.T("  %s → %s (%s, skipped — onDirtyRemove is \"block\")\n")), o.Branch, o.Path, status)
+				continue
+			}
+			if st.Conflicted > 0 && policy != config.DirtyForce {
+				fmt.Printf(plain(i18n.T("  %s → %s (%s, skipped — finish or abort the merge first, or pass --force)\n")), o.Branch, o.Path, status)
+				continue
+			}
+			if cleanYes && policy == config.DirtyPrompt {
+				fmt.Printf(plain(i18n.T("  %s → %s (%s, skipped — pass --force with --yes to remove it anyway)\n")), o.Branch, o.Path, status)
+				continue
+			}
 			marker = " (" + status + ")"
 			dirty = append(dirty, o.Branch)
+			dirtySet[o.Path] = true
 		}
-		fmt.Printf("  %s → %s%s\n", o.Branch, o.Path, marker)
+		targets = append(targets, o)
+		fmt.Printf(plain(i18n.T("  %s → %s%s\n")), o.Branch, o.Path, marker)
 	}
 	fmt.Println()
 
-	if len(dirty) > 0 && !force {
-		answer := prompt("Some orphan worktrees have changes. Remove all anyway? [y/N]", "n")
+	if len(targets) == 0 {
+		return 0, nil
+	}
+	orphans = targets
+
+	force := policy == config.DirtyForce || len(dirty) > 0
+	switch {
+	case cleanYes:
+	case len(dirty) > 0 && policy == config.DirtyPrompt:
+		answer := prompt(i18n.T("Some orphan worktrees have changes. Remove all anyway? [y/N]"), "n")
 		if answer != "y" && answer != "Y" {
-			fmt.Println("Skipped orphan cleanup.")
+			fmt.Print(i18n.T("Skipped orphan cleanup.\n"))
 			return 0, nil
 		}
-		force = true
-	} else {
-		answer := prompt(fmt.Sprintf("Remove %d orphan worktree(s)? [y/N]", len(orphans)), "n")
+	default:
+		answer := prompt(fmt.Sprintf(i18n.T("Remove %d orphan worktree(s)? [y/N]"), len(orphans)), "n")
 		if answer != "y" && answer != "Y" {
-			fmt.Println("Skipped orphan cleanup.")
+			fmt.Print(i18n.T("Skipped orphan cleanup.\n"))
 			return 0, nil
 		}
 	}
 
+	guard, err := newMainGuard()
+	if err != nil {
+		return 0, err
+	}
+
 	var removed int
 	for _, o := range orphans {
+		if err := guard.check(o.Path, ""); err != nil {
+			fmt.Printf(i18n.T("  failed to remove orphan %q: %v\n"), o.Branch, err)
+			continue
+		}
+		if dirtySet[o.Path] {
+			if _, err := preserveChanges(policy, o.Branch, o.Path); err != nil {
+				fmt.Printf(i18n.T("  failed to remove orphan %q: %v\n"), o.Branch, err)
+				continue
+			}
+		}
 		if err := git.RemoveWorktree(o.Path, force); err != nil {
-			fmt.Printf("  failed to remove orphan %q: %v\n", o.Branch, err)
+			fmt.Printf(i18n.T("  failed to remove orphan %q: %v\n"), o.Branch, err)
 			continue
 		}
 		removed++
-		fmt.Printf("  ✓ removed orphan %s\n", o.Branch)
+		fmt.Printf(plain(i18n.T("  ✓ removed orphan %s\n")), o.Branch)
 	}
 
 	return removed, nil
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestCleanBlockSkipsDirty(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:   "../",
+		Prefix:        "testproject",
+		Symlink:       []string{},
+		OnDirtyRemove: config.DirtyBlock,
+	})
+	dirtyPath := createDirtyWorktree(t, dir, "feature/dirty")
+
+	createName = ""
+	if err := runCreate(createCmd, []string{"feature/tidy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader = bufio.NewReader(strings.NewReader("y\n"))
+	t.Cleanup(func() { reader = nil })
+
+	if err := runClean(cleanCmd, nil); err != nil {
+		t.Fatalf("clean failed: %v", err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.AliasExists("dirty") {
+		t.Error("dirty worktree should be kept with onDirtyRemove=block")
+	}
+	if s.AliasExists("tidy") {
+		t.Error("clean worktree should have been removed")
+	}
+	if _, err := os.Stat(dirtyPath); err != nil {
+		t.Errorf("dirty worktree directory should still exist: %v", err)
+	}
+}
+
+func TestCleanYesSkipsDirty(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	createDirtyWorktree(t, dir, "feature/dirty")
+
+	createName = ""
+	if err := runCreate(createCmd, []string{"feature/tidy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// No answer to read: --yes mustn't ask, and must keep unsaved work.
+	reader = bufio.NewReader(strings.NewReader(""))
+	cleanYes = true
+	t.Cleanup(func() { reader, cleanYes = nil, false })
+
+	if err := runClean(cleanCmd, nil); err != nil {
+		t.Fatalf("clean --yes failed: %v", err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.AliasExists("dirty") {
+		t.Error("clean --yes removed a worktree with changes")
+	}
+	if s.AliasExists("tidy") {
+		t.Error("clean --yes should have removed the clean worktree")
+	}
+}
+
+func TestCleanMerged(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName, createFrom = "", ""
+	for _, branch := range []string{"feature/done", "feature/wip", "feature/stale"} {
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, alias := range []string{"done", "wip"} {
+		wt := filepath.Join(filepath.Dir(dir), "testproject-"+alias)
+		if out, err := gitCmd(wt, "commit", "--allow-empty", "-m", alias); err != nil {
+			t.Fatalf("commit: %v\n%s", err, out)
+		}
+	}
+	if out, err := gitCmd(dir, "merge", "--no-ff", "-m", "merge done", "feature/done"); err != nil {
+		t.Fatalf("merge: %v\n%s", err, out)
+	}
+	// Still at main's tip: nothing of its own to call merged.
+	if err := runCreate(createCmd, []string{"feature/fresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanMerged, cleanDeleteBranch = true, true
+	t.Cleanup(func() { cleanMerged, cleanDeleteBranch = false, false })
+	reader = bufio.NewReader(strings.NewReader("y\n"))
+	t.Cleanup(func() { reader = nil })
+	if err := runClean(cleanCmd, nil); err != nil {
+		t.Fatalf("clean failed: %v", err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AliasExists("done") {
+		t.Error("merged worktree should have been removed")
+	}
+	// main moved past stale, but it never had a commit to merge.
+	if !s.AliasExists("wip") || !s.AliasExists("fresh") || !s.AliasExists("stale") {
+		t.Error("unmerged, fresh and stale worktrees should be kept")
+	}
+	if out, _ := gitCmd(dir, "branch", "--list", "feature/stale"); len(strings.TrimSpace(string(out))) == 0 {
+		t.Error("feature/stale has no merged work and shouldn't have been deleted")
+	}
+	if out, _ := gitCmd(dir, "branch", "--list", "feature/done"); len(strings.TrimSpace(string(out))) != 0 {
+		t.Errorf("feature/done should have been deleted, got %q", out)
+	}
+}
@@ -1,13 +1,28 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/atomicfile"
+)
+
+var (
+	completionInstallShell string
+	completionInstallPath  string
 )
 
 func init() {
 	rootCmd.AddCommand(completionCmd)
+	completionCmd.AddCommand(completionInstallCmd)
+	completionInstallCmd.Flags().StringVar(&completionInstallShell, "shell", "", "shell to install for: bash, zsh or fish (default: detected from $SHELL)")
+	completionInstallCmd.Flags().StringVar(&completionInstallPath, "path", "", "write the script here instead of the standard location")
 }
 
 var completionCmd = &cobra.Command{
@@ -27,6 +42,9 @@ Bash:
 Fish:
   grove completion fish > ~/.config/fish/completions/grove.fish
 
+Or let grove pick the location for your shell:
+  grove completion install
+
 After installing, restart your shell or run: exec zsh`,
 	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
 	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
@@ -44,3 +62,118 @@ After installing, restart your shell or run: exec zsh`,
 		return nil
 	},
 }
+
+var completionInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the completion script for your shell",
+	Long: `Write the completion script straight into the standard location for your
+shell, so setup is one command.
+
+Locations:
+  zsh   $(brew --prefix)/share/zsh/site-functions/_grove with Homebrew,
+        otherwise ~/.zsh/completions/_grove (add it to your fpath)
+  bash  $(brew --prefix)/etc/bash_completion.d/grove with Homebrew,
+        otherwise ~/.local/share/bash-completion/completions/grove
+  fish  ~/.config/fish/completions/grove.fish
+
+The shell is detected from $SHELL; override it with --shell, or choose the
+file yourself with --path.`,
+	Args: cobra.NoArgs,
+	RunE: runCompletionInstall,
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) error {
+	shell := completionInstallShell
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+
+	path, hint := completionInstallPath, ""
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path, hint, err = completionPath(shell, home, os.Getenv("XDG_DATA_HOME"), os.Getenv("XDG_CONFIG_HOME"), brewPrefix())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generated in full before anything is written, so an unsupported shell
+	// or a failed write leaves the file that was there alone.
+	var script bytes.Buffer
+	var err error
+	switch shell {
+	case "bash":
+		err = rootCmd.GenBashCompletion(&script)
+	case "zsh":
+		err = rootCmd.GenZshCompletion(&script)
+	case "fish":
+		err = rootCmd.GenFishCompletion(&script, true)
+	default:
+		err = fmt.Errorf("unsupported shell %q — use --shell bash, zsh or fish", shell)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := atomicfile.Write(path, script.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Installed %s completion to %s\n", shell, path)
+	if hint != "" {
+		fmt.Println(hint)
+	}
+	fmt.Println("Restart your shell to pick it up.")
+	return nil
+}
+
+// completionPath returns where the completion script for shell should go,
+// plus an optional hint for the user. brew is the Homebrew prefix, or "" if
+// Homebrew isn't installed.
+func completionPath(shell, home, xdgData, xdgConfig, brew string) (string, string, error) {
+	if xdgData == "" {
+		xdgData = filepath.Join(home, ".local", "share")
+	}
+	if xdgConfig == "" {
+		xdgConfig = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "zsh":
+		if brew != "" {
+			return filepath.Join(brew, "share", "zsh", "site-functions", "_grove"), "", nil
+		}
+		dir := filepath.Join(home, ".zsh", "completions")
+		return filepath.Join(dir, "_grove"),
+			fmt.Sprintf("Make sure your ~/.zshrc has: fpath=(%s $fpath); autoload -U compinit; compinit", dir), nil
+	case "bash":
+		if brew != "" {
+			return filepath.Join(brew, "etc", "bash_completion.d", "grove"), "", nil
+		}
+		return filepath.Join(xdgData, "bash-completion", "completions", "grove"),
+			"Requires the bash-completion package (v2+).", nil
+	case "fish":
+		return filepath.Join(xdgConfig, "fish", "completions", "grove.fish"), "", nil
+	case "":
+		return "", "", errors.New("could not detect your shell — pass --shell bash, zsh or fish")
+	default:
+		return "", "", fmt.Errorf("unsupported shell %q — use --shell bash, zsh or fish, or 'grove completion %s' to print the script", shell, shell)
+	}
+}
+
+// brewPrefix returns the Homebrew prefix, or "" if brew isn't installed.
+func brewPrefix() string {
+	if _, err := exec.LookPath("brew"); err != nil {
+		return ""
+	}
+	out, err := exec.Command("brew", "--prefix").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
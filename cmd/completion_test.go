@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompletionPath(t *testing.T) {
+	tests := []struct {
+		shell, brew, want string
+	}{
+		{"zsh", "/opt/homebrew", "/opt/homebrew/share/zsh/site-functions/_grove"},
+		{"zsh", "", "/home/dev/.zsh/completions/_grove"},
+		{"bash", "/usr/local", "/usr/local/etc/bash_completion.d/grove"},
+		{"bash", "", "/home/dev/.local/share/bash-completion/completions/grove"},
+		{"fish", "/opt/homebrew", "/home/dev/.config/fish/completions/grove.fish"},
+	}
+
+	for _, tt := range tests {
+		got, _, err := completionPath(tt.shell, "/home/dev", "", "", tt.brew)
+		if err != nil {
+			t.Errorf("%s (brew=%q): %v", tt.shell, tt.brew, err)
+			continue
+		}
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("%s (brew=%q) = %q, want %q", tt.shell, tt.brew, got, tt.want)
+		}
+	}
+
+	if _, _, err := completionPath("tcsh", "/home/dev", "", "", ""); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestCompletionInstallWritesScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "grove.fish")
+
+	completionInstallShell = "fish"
+	completionInstallPath = path
+	t.Cleanup(func() {
+		completionInstallShell = ""
+		completionInstallPath = ""
+	})
+
+	if err := runCompletionInstall(completionInstallCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected completion script at %s: %v", path, err)
+	}
+	if info.Size() == 0 {
+		t.Error("completion script is empty")
+	}
+
+	// An unsupported shell must leave an existing file alone.
+	os.WriteFile(path, []byte("mine"), 0644)
+	completionInstallShell = "tcsh"
+	if err := runCompletionInstall(completionInstallCmd, nil); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "mine" {
+		t.Errorf("existing file = %q, %v; want it untouched", data, err)
+	}
+}
+
+// TestWorktreeArgsComplete makes sure every command whose first argument
+// names a worktree completes it, including commands added later.
+func TestWorktreeArgsComplete(t *testing.T) {
+	worktreeArgs := map[string]bool{"<name>": true, "<alias>": true, "<name-or-number>": true}
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		fields := strings.Fields(c.Use)
+		if len(fields) > 1 && worktreeArgs[fields[1]] && c.ValidArgsFunction == nil {
+			t.Errorf("%q takes a worktree but has no ValidArgsFunction — use completeAliases", c.CommandPath())
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
+
+func TestCompleteExec(t *testing.T) {
+	if _, directive := completeExec(execCmd, []string{"auth"}, ""); directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("after the worktree, directive = %v, want the shell's default completion", directive)
+	}
+}
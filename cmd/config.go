@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with .groverc.json",
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for .groverc.json",
+	Long: `Print the JSON Schema for .groverc.json: every option with its type,
+description, allowed values and default. It is generated from the config
+grove reads, so it always matches this version of grove.
+
+Save it next to the config and point "$schema" at it for autocomplete and
+validation in your editor:
+
+  grove config schema > .groverc.schema.json
+
+  {
+    "$schema": "./.groverc.schema.json",
+    ...
+  }`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSchema,
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	// Descriptions use <placeholders>; keep them readable.
+	enc.SetEscapeHTML(false)
+	return enc.Encode(config.Schema())
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestConfigSchemaCommand(t *testing.T) {
+	out := captureStdout(t, func() {
+		if err := runConfigSchema(configSchemaCmd, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var schema map[string]any
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v\n%s", err, out)
+	}
+	if schema["$schema"] != config.SchemaDialect {
+		t.Errorf("$schema = %v", schema["$schema"])
+	}
+	if !strings.Contains(string(out), "<prefix>-<alias>") {
+		t.Error("descriptions should keep <placeholders> unescaped")
+	}
+}
@@ -1,29 +1,72 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
 	"github.com/verbaux/grove/internal/files"
 	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/i18n"
 	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trace"
 )
 
 var (
-	createName string
-	createFrom string
+	createName     string
+	createFrom     string
+	createTemplate string
+	createPush     bool
+	createApply    string
+	createOpen     string
+	createEditor   bool
+	createTmux     bool
+	createReadOnly bool
+	createFromFile string
+	createNoPool   bool
+	createEnv      string
+	createNote     string
+	createDescribe string
+
+	createKeepOnFailure bool
 )
 
 func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.Flags().StringVar(&createName, "name", "", "alias for the worktree (default: last segment of branch name)")
 	createCmd.Flags().StringVar(&createFrom, "from", "", "base branch or commit to create the new branch from")
+	createCmd.Flags().StringVar(&createTemplate, "template", "", "named template from .groverc.json to set up the worktree with")
+	createCmd.Flags().BoolVar(&createPush, "push", false, "push the branch with -u origin after creating it (default from \"push\" in config)")
+	createCmd.Flags().StringVar(&createApply, "apply", "", "apply a patch file or stash (stash@{n}, or a stash message) to the new worktree")
+	createCmd.Flags().StringVar(&createOpen, "open", "", "where to go once the worktree is ready: none, editor, tmux or shell (default from \"open\" in config)")
+	createCmd.Flags().BoolVar(&createEditor, "editor", false, "open the worktree in your editor when it's ready (same as --open editor)")
+	createCmd.Flags().BoolVar(&createTmux, "tmux", false, "open the worktree in a tmux window when it's ready (same as --open tmux)")
+	createCmd.Flags().BoolVar(&createReadOnly, "read-only", false, "make the worktree's files read-only once it's set up (undo with 'grove unlock-files')")
+	createCmd.Flags().StringVar(&createEnv, "env", "", "copy only this environment's env files, e.g. staging: .env and .env.staging (see \"envProfiles\" in config)")
+	createCmd.Flags().StringVar(&createNote, "note", "", "what the worktree is for; shown by grove info and in the \"banner\" file")
+	createCmd.Flags().StringVar(&createDescribe, "describe", "", "set the branch's git description (branch.<name>.description), shown by grove list and grove info")
+	createCmd.Flags().BoolVar(&createKeepOnFailure, "keep-on-failure", false, "keep the worktree when a setup hook fails, to finish with 'grove retry' (same as \"onHookFailure\": \"keep\")")
+	createCmd.Flags().BoolVar(&createNoPool, "no-pool", false, "check out a fresh worktree even if 'grove pool fill' has one ready")
+	createCmd.Flags().StringVar(&createFromFile, "from-file", "", "create a worktree for each line of a file (\"-\" for stdin): branch [alias] [base]")
+	createCmd.MarkFlagsMutuallyExclusive("open", "editor", "tmux")
+	for _, flag := range []string{"name", "apply", "open", "editor", "tmux"} {
+		createCmd.MarkFlagsMutuallyExclusive("from-file", flag)
+	}
+	createCmd.RegisterFlagCompletionFunc("template", completeTemplates)
+	createCmd.RegisterFlagCompletionFunc("open", cobra.FixedCompletions([]string{config.OpenNone, config.OpenEditor, config.OpenTmux, config.OpenShell}, cobra.ShellCompDirectiveNoFileComp))
 }
 
 var createCmd = &cobra.Command{
@@ -35,138 +78,690 @@ Grove will:
   - Create the worktree with git worktree add
   - Copy all .env* files found in the project
   - Create symlinks for configured directories (e.g. node_modules)
-  - Run the afterCreate command if configured
+  - Run the afterCreate command if configured, then the scripts in
+    .grove/hooks/after-create
+
+The branch will be created if it doesn't already exist.
+
+Use --template to apply a named template from .groverc.json (base branch,
+symlinks, extra files to copy, sparse checkout, afterCreate, tags).
+
+Use --apply to bring a patch file (e.g. from a mailing list or CI artifact)
+or a stash into the fresh worktree right after checkout. The changes are
+left uncommitted; a stash is applied, not dropped.
+
+Use --editor, --tmux or --open to go straight into the new worktree: in
+your editor (see 'grove open'), a tmux window, or a subshell.
+Set "open" in .groverc.json to make one of them the default.
+
+Use --read-only for trees that are only for looking at, like CI
+verification: once set up, the worktree's files lose their write
+permission so nothing gets edited there by mistake. 'grove unlock-files'
+undoes it; remove and clean do so by themselves.
+
+Use --from-file to create many worktrees at once, e.g. for every open pull
+request assigned to you. Each line is a branch, optionally followed by an
+alias and a base branch ("-" keeps the default alias); blank lines and
+lines starting with # are skipped. "-" reads the list from stdin:
 
-The branch will be created if it doesn't already exist.`,
-	Args: cobra.ExactArgs(1),
+  gh pr list --assignee @me --json headRefName -q '.[].headRefName' |
+    grove create --from-file -
+
+Up to --jobs worktrees are created at once. A worktree that fails is
+rolled back and reported; the rest are still created. A summary table
+follows at the end.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runCreate,
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
-	branch := args[0]
+	if createFromFile != "" {
+		return runCreateFromFile(cmd, args)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("create needs a branch — run 'grove create <branch>', or pass --from-file")
+	}
 
-	cwd, err := os.Getwd()
+	report, err := createWorktree(cmd, args[0])
 	if err != nil {
 		return err
 	}
 
+	if jsonOutput {
+		report.Path = displayPath(report.Path)
+		data, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf(i18n.T("Worktree %q ready.\n"), report.Alias)
+	if report.open == config.OpenNone {
+		fmt.Printf(i18n.T("  cd $(grove cd %s)\n"), report.Alias)
+		return nil
+	}
+	return openWorktree(report.open, report.editor, report.Alias, report.Path, hookEnv(report.root, report.Alias, report.Branch, report.Path))
+}
+
+// createWorktree creates and sets up the worktree for branch, using the
+// create flags, and records it in state. Printing the result and opening
+// the worktree is left to the caller.
+func createWorktree(cmd *cobra.Command, branch string) (createReport, error) {
+	// Large checkouts can take minutes — show git's progress when a human is
+	// watching, stay quiet when output is piped or captured.
+	git.Progress = !jsonOutput && isatty.IsTerminal(os.Stdout.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
+	return createFromSpec(cmd, branchSpec{Branch: branch, Alias: createName, Base: createFrom}, os.Stdout)
+}
+
+// resetCreateFlags puts every create flag back to its default, for the
+// commands that create a worktree the way a plain 'grove create' would.
+func resetCreateFlags() {
+	createName, createFrom, createTemplate, createApply, createOpen = "", "", "", "", ""
+	createEnv, createNote, createDescribe, createFromFile = "", "", "", ""
+	createPush, createEditor, createTmux, createReadOnly = false, false, false, false
+	createNoPool, createKeepOnFailure = false, false
+}
+
+// createFromSpec is createWorktree for the branch, alias and base in spec,
+// with its progress lines and hook output written to out. It leaves
+// git.Progress alone, so several can run at once.
+func createFromSpec(cmd *cobra.Command, spec branchSpec, out io.Writer) (createReport, error) {
+	// In --json mode the decorative progress lines are dropped and a single
+	// JSON result is printed at the end instead.
+	hookOut := out
+	if jsonOutput {
+		out, hookOut = io.Discard, os.Stderr
+	}
+	branch := spec.Branch
+	report := createReport{Branch: branch}
+	step := func(name string, start time.Time) {
+		elapsed := time.Since(start)
+		report.Steps = append(report.Steps, createStep{Name: name, DurationMs: elapsed.Milliseconds()})
+		trace.Add("setup", name, start, elapsed)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return report, err
+	}
+
 	root, err := config.FindRoot(cwd)
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	cfg, err := config.Load(root)
 	if err != nil {
-		return err
+		return report, err
+	}
+
+	var tpl config.Template
+	if createTemplate != "" {
+		cfg, tpl, err = cfg.WithTemplate(createTemplate)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	// --from on the command line wins over the template's base branch.
+	from := spec.Base
+	if from == "" {
+		from = tpl.From
 	}
 
 	s, err := state.Load(root)
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	// Derive alias from branch name unless --name was provided.
 	// "feature/auth" → "auth", "main" → "main"
-	alias := createName
+	alias := spec.Alias
 	if alias == "" {
 		alias = branchAlias(branch)
 	}
 
 	if err := validateAlias(alias); err != nil {
-		return err
+		return report, err
 	}
 
 	if s.AliasExists(alias) {
-		return fmt.Errorf("alias %q already exists — use --name to choose a different one", alias)
+		return report, errorf(state.ErrAliasExists, "alias %q already exists — use --name to choose a different one", alias)
 	}
 
-	// Build the worktree path: worktreeDir + prefix + "-" + alias
-	// e.g. "../" + "myproject" + "-" + "auth" → "../myproject-auth"
-	// If prefix is empty, use just the alias to avoid a leading dash.
-	wtName := alias
-	if cfg.Prefix != "" {
-		wtName = cfg.Prefix + "-" + alias
+	worktreePath, err := worktreePathFor(root, cfg, alias)
+	if err != nil {
+		return report, err
+	}
+	if err := caseCollision(s, alias, branch, worktreePath); err != nil {
+		return report, err
 	}
-	worktreePath := filepath.Join(root, cfg.WorktreeDir, wtName)
-	worktreePath, err = filepath.Abs(worktreePath)
+
+	linkMode, err := cfg.EffectiveLinkMode()
 	if err != nil {
-		return err
+		return report, err
 	}
-	// EvalSymlinks resolves /tmp → /private/tmp on macOS so path lookups
-	// match what git worktree list returns.
-	if resolved, err := filepath.EvalSymlinks(filepath.Dir(worktreePath)); err == nil {
-		worktreePath = filepath.Join(resolved, filepath.Base(worktreePath))
+
+	openMode, err := createOpenMode(cmd, cfg)
+	if err != nil {
+		return report, err
+	}
+	banner, err := cfg.EffectiveBanner()
+	if err != nil {
+		return report, err
 	}
+	hookFailure, err := cfg.EffectiveHookFailure()
+	if err != nil {
+		return report, err
+	}
+	keepOnFailure := createKeepOnFailure || hookFailure == config.HookFailureKeep
 
-	fmt.Printf("Creating worktree for branch %q at %s\n", branch, worktreePath)
+	var expire time.Duration
+	if tpl.Expire != "" {
+		if expire, err = parseTTL(tpl.Expire); err != nil {
+			return report, fmt.Errorf("template %q: %w", createTemplate, err)
+		}
+	}
 
-	if err := git.AddWorktree(worktreePath, branch, createFrom); err != nil {
-		return err
+	// Ask about unapproved hooks up front, not halfway through the setup.
+	hooks, err := createHooks(root, cfg)
+	if err != nil {
+		return report, err
+	}
+	if err := requireTrust(root, hooks); err != nil {
+		return report, err
 	}
-	fmt.Println("  ✓ git worktree created")
+
+	// Resolve --apply before creating anything, so a typo doesn't cost a checkout.
+	var applyPatch, applyStash string
+	if createApply != "" {
+		if applyPatch, applyStash, err = resolveApply(createApply); err != nil {
+			return report, err
+		}
+	}
+
+	// Likewise the namespace, which an alias like "___" leaves empty.
+	var ns string
+	if cfg.Kube.Namespace != "" {
+		if ns, err = kubeNamespace(cfg.Kube, cfg.Prefix, alias, branch); err != nil {
+			return report, err
+		}
+	}
+
+	envPatterns, err := envProfilePatterns(root, cfg, createEnv)
+	if err != nil {
+		return report, err
+	}
+
+	fmt.Fprintf(out, i18n.T("Creating worktree for branch %q at %s\n"), branch, displayPath(worktreePath))
+
+	// Remember what the branch was based on for divergence reporting.
+	// A new branch without --from starts at the current HEAD.
+	base := from
+	if base == "" && !git.BranchExists(branch) {
+		if current, err := git.CurrentBranch(); err == nil && current != "HEAD" {
+			base = current
+		}
+	}
+
+	start := time.Now()
+	// A pooled worktree has the checkout done already; sparse checkouts
+	// need a worktree of their own.
+	var pooled state.PoolEntry
+	claimed := false
+	if !createNoPool && len(tpl.Sparse) == 0 {
+		// Under the state lock, so two creates can't take the same one.
+		err := state.Change(root, func(locked *state.State) error {
+			pooled, claimed = claimPoolEntry(locked, cfg, linkMode)
+			s.Pool = locked.Pool
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if claimed {
+		if err := claimWorktree(root, pooled, worktreePath, branch, from); err != nil {
+			return report, err
+		}
+		step("worktree", start)
+		fmt.Fprint(out, plain(i18n.T("  ✓ git worktree claimed from the pool\n")))
+	} else {
+		if err := git.AddWorktree(worktreePath, branch, from); err != nil {
+			return report, err
+		}
+		step("worktree", start)
+		fmt.Fprint(out, plain(i18n.T("  ✓ git worktree created\n")))
+	}
+
+	// Everything below is recorded in state so `grove info` can show it.
+	setup := &state.Setup{}
 
 	// If any step after this fails, clean up the worktree so we don't leave
 	// an orphaned directory that git knows about but grove doesn't.
 	var setupErr error
+	kubeUp := false
 	defer func() {
 		if setupErr != nil {
-			fmt.Printf("  rolling back: removing worktree at %s\n", worktreePath)
+			if kubeUp {
+				if err := kubeDown(root, cfg, alias, branch, worktreePath, setup.Namespace, hookOut); err != nil {
+					fmt.Fprintf(os.Stderr, i18n.T("  warning: %v\n"), err)
+				}
+			}
+			if setup.Database != "" {
+				if err := dropDatabase(root, cfg, alias, branch, worktreePath, setup.Database, hookOut); err != nil {
+					fmt.Fprintf(os.Stderr, i18n.T("  warning: %v\n"), err)
+				}
+			}
+			if setup.ReadOnly {
+				files.SetReadOnly(worktreePath, false, setup.Hardlinks)
+			}
+			fmt.Fprintf(out, i18n.T("  rolling back: removing worktree at %s\n"), worktreePath)
 			if rbErr := git.RemoveWorktree(worktreePath, true); rbErr != nil {
-				fmt.Fprintf(os.Stderr, "  warning: rollback failed, manual cleanup needed: %v\n", rbErr)
+				fmt.Fprintf(os.Stderr, i18n.T("  warning: rollback failed, manual cleanup needed: %v\n"), rbErr)
 			}
 		}
 	}()
 
-	copied, err := files.CopyEnvFiles(root, worktreePath)
+	// In git's config rather than grove's state, so it stays with the branch.
+	if createDescribe != "" {
+		if err := git.SetBranchDescription(branch, createDescribe); err != nil {
+			setupErr = err
+			return report, setupErr
+		}
+		fmt.Fprint(out, plain(i18n.T("  ✓ set the branch description\n")))
+	}
+
+	if len(tpl.Sparse) > 0 {
+		start := time.Now()
+		if err := git.SparseCheckout(worktreePath, tpl.Sparse); err != nil {
+			setupErr = err
+			return report, setupErr
+		}
+		fmt.Fprintf(out, plain(i18n.T("  ✓ sparse checkout: %s\n")), strings.Join(tpl.Sparse, ", "))
+		setup.Sparse = tpl.Sparse
+		step("sparse", start)
+	}
+
+	if applyPatch != "" || applyStash != "" {
+		start := time.Now()
+		if applyPatch != "" {
+			err = git.ApplyPatch(worktreePath, applyPatch)
+		} else {
+			err = git.ApplyStash(worktreePath, applyStash)
+		}
+		if err != nil {
+			setupErr = fmt.Errorf("--apply %s: %w", createApply, err)
+			return report, setupErr
+		}
+		fmt.Fprintf(out, plain(i18n.T("  ✓ applied %s\n")), createApply)
+		setup.Applied = createApply
+		step("apply", start)
+	}
+
+	start = time.Now()
+	copied, err := files.CopyEnvFiles(root, worktreePath, envPatterns...)
 	if err != nil {
 		setupErr = err
-		return setupErr
+		return report, setupErr
+	}
+	switch {
+	case createEnv != "":
+		fmt.Fprintf(out, plain(i18n.T("  ✓ copied %d .env file(s) for %s\n")), len(copied), createEnv)
+	case len(copied) > 0:
+		fmt.Fprintf(out, plain(i18n.T("  ✓ copied %d .env file(s)\n")), len(copied))
+	}
+	setup.EnvFiles = copied
+	setup.EnvProfile = createEnv
+	step("env", start)
+
+	if cfg.Database.Name != "" {
+		start := time.Now()
+		name, err := setupDatabase(root, cfg, alias, branch, worktreePath, copied, out)
+		// Record the name even on failure: the create command may have
+		// half-succeeded, and rollback below should try to drop it.
+		setup.Database = name
+		if err != nil {
+			setupErr = err
+			return report, setupErr
+		}
+		fmt.Fprintf(out, plain(i18n.T("  ✓ database %s\n")), name)
+		step("database", start)
+	}
+
+	if ns != "" {
+		if err := injectEnv(worktreePath, copied, cfg.Kube.Env, kubeVars(cfg, alias, branch, ns)); err != nil {
+			setupErr = err
+			return report, setupErr
+		}
+		setup.Namespace = ns
+		fmt.Fprintf(out, plain(i18n.T("  ✓ namespace %s\n")), ns)
 	}
-	if len(copied) > 0 {
-		fmt.Printf("  ✓ copied %d .env file(s)\n", len(copied))
+
+	if len(tpl.Copy) > 0 {
+		start := time.Now()
+		extra, err := files.CopyPaths(root, worktreePath, tpl.Copy)
+		if err != nil {
+			setupErr = err
+			return report, setupErr
+		}
+		if len(extra) > 0 {
+			fmt.Fprintf(out, plain(i18n.T("  ✓ copied %d template file(s)\n")), len(extra))
+		}
+		setup.Copied = extra
+		step("copy", start)
 	}
 
-	var symlinked []string
+	if len(cfg.Render) > 0 {
+		start := time.Now()
+		vars := renderVars(root, cfg.Prefix, alias, branch, worktreePath, setup)
+		rendered, err := files.RenderPaths(root, worktreePath, cfg.Render, func(text string) string {
+			return expandTemplate(text, vars)
+		})
+		if err != nil {
+			setupErr = err
+			return report, setupErr
+		}
+		if len(rendered) > 0 {
+			fmt.Fprintf(out, plain(i18n.T("  ✓ rendered %s\n")), strings.Join(rendered, ", "))
+		}
+		setup.Rendered = rendered
+		step("render", start)
+	}
+
+	start = time.Now()
+	link := files.Symlink
+	if linkMode == config.LinkHardlink {
+		link = files.HardlinkTree
+	}
+	var symlinked, missing []string
 	for _, name := range cfg.Symlink {
-		created, err := files.Symlink(root, worktreePath, name)
+		if claimed && slices.Contains(pooled.Linked, name) {
+			symlinked = append(symlinked, name)
+			continue
+		}
+		created, err := link(root, worktreePath, name)
 		if err != nil {
-			if errors.Is(err, files.ErrSymlinkDestinationConflict) {
-				fmt.Fprintf(os.Stderr, "  warning: skipping symlink %s: %v\n", name, err)
+			if errors.Is(err, files.ErrSymlinkDestinationConflict) || errors.Is(err, files.ErrDestinationExists) {
+				fmt.Fprintf(os.Stderr, i18n.T("  warning: skipping symlink %s: %v\n"), name, err)
 				continue
 			}
 			setupErr = fmt.Errorf("symlink %s: %w", name, err)
-			return setupErr
+			return report, setupErr
 		}
 		if created {
 			symlinked = append(symlinked, name)
+		} else if _, err := os.Lstat(filepath.Join(root, name)); os.IsNotExist(err) {
+			missing = append(missing, name)
 		}
 	}
-	if len(symlinked) > 0 {
-		fmt.Printf("  ✓ symlinked %s\n", strings.Join(symlinked, ", "))
+	if len(symlinked) > 0 && linkMode == config.LinkHardlink {
+		fmt.Fprintf(out, plain(i18n.T("  ✓ hard-linked %s\n")), strings.Join(symlinked, ", "))
+		setup.Hardlinks = symlinked
+	} else if len(symlinked) > 0 {
+		fmt.Fprintf(out, plain(i18n.T("  ✓ symlinked %s\n")), strings.Join(symlinked, ", "))
+		setup.Symlinks = symlinked
+	}
+	if len(missing) > 0 && cfg.InstallCommand == "" {
+		fmt.Fprintf(out, i18n.T("  skipped symlink %s (not in the main worktree; set installCommand to install instead)\n"), strings.Join(missing, ", "))
+	}
+	step("symlink", start)
+
+	push := cfg.Push
+	if cmd.Flags().Changed("push") {
+		push = createPush
+	}
+
+	// On a fresh machine there is nothing to symlink yet — install into
+	// the worktree instead, before afterCreate relies on it. Read-only
+	// comes last, so the steps before it can still write to the worktree.
+	var steps []string
+	if len(missing) > 0 && cfg.InstallCommand != "" {
+		fmt.Fprintf(out, i18n.T("  %s missing in the main worktree, running: %s\n"), strings.Join(missing, ", "), cfg.InstallCommand)
+		steps = append(steps, stepInstall)
+	}
+	steps = append(steps, stepAfterCreate)
+	if setup.Namespace != "" && cfg.Kube.Up != "" {
+		steps = append(steps, stepKubeUp)
+	}
+	if push {
+		steps = append(steps, stepPush)
 	}
+	if banner != config.BannerNone {
+		steps = append(steps, stepBanner)
+	}
+	if createReadOnly {
+		steps = append(steps, stepReadOnly)
+	}
+
+	run := &setupRun{
+		root: root, alias: alias, branch: branch, path: worktreePath,
+		cfg: cfg, setup: setup, banner: banner,
+		entry: state.WorktreeEntry{Branch: branch, Path: worktreePath, Created: time.Now(), Template: createTemplate, Base: base, Note: createNote},
+		out:   out, hookOut: hookOut, step: step,
+	}
+	record := func() error {
+		// After the database and kube settings went in, so grove sync --env
+		// can tell them from edits made in the worktree.
+		setup.EnvHashes = envHashes(worktreePath, setup.EnvFiles)
 
-	if cfg.AfterCreate != "" {
-		fmt.Printf("  running: %s\n", cfg.AfterCreate)
-		if err := runShell(cfg.AfterCreate, worktreePath); err != nil {
-			setupErr = fmt.Errorf("afterCreate command failed: %w", err)
-			return setupErr
+		err := state.Change(root, func(s *state.State) error {
+			if err := s.Add(alias, branch, worktreePath); err != nil {
+				return err
+			}
+			return s.Update(alias, func(e *state.WorktreeEntry) {
+				e.Upstream = run.upstream
+				e.Base = base
+				e.Note = createNote
+				e.Setup = setup
+				if createTemplate != "" {
+					e.Template = createTemplate
+					e.Tags = tpl.Tags
+					if expire > 0 {
+						e.Expires = time.Now().Add(expire)
+					}
+				}
+			})
+		})
+		if err != nil {
+			return err
 		}
-		fmt.Println("  ✓ afterCreate done")
+		registerProject(root, cfg)
+		return nil
 	}
 
-	if err := s.Add(alias, branch, worktreePath); err != nil {
+	for i, name := range steps {
+		err := run.run(name)
+		report.HookExitCode = run.hookExitCode
+		kubeUp = run.kubeUp
+		if err == nil {
+			continue
+		}
+		// Keep what's there for grove retry rather than rolling it all back.
+		if keepOnFailure && hookSteps[name] {
+			setup.Failed, setup.Pending = name, steps[i+1:]
+			if recErr := record(); recErr != nil {
+				setupErr = recErr
+				return report, setupErr
+			}
+			report.Alias, report.Path = alias, worktreePath
+			return report, fmt.Errorf("%w — worktree %s kept; fix the problem and run 'grove retry %s'", err, alias, alias)
+		}
 		setupErr = err
-		return setupErr
+		return report, setupErr
 	}
-	if err := state.Save(root, s); err != nil {
+
+	if err := record(); err != nil {
 		setupErr = err
-		return setupErr
+		return report, setupErr
 	}
 
-	fmt.Println()
-	fmt.Printf("Worktree %q ready.\n", alias)
-	fmt.Printf("  cd $(grove cd %s)\n", alias)
+	report.Alias = alias
+	report.Path = worktreePath
+	report.Upstream = run.upstream
+	report.root = root
+	report.open = openMode
+	report.editor = editorCommand(cfg, "")
+	return report, nil
+}
+
+// createOpenMode picks where create takes the user afterwards: --editor,
+// --tmux or --open if given, else the "open" config.
+func createOpenMode(cmd *cobra.Command, cfg config.Config) (string, error) {
+	switch {
+	case createEditor:
+		return config.OpenEditor, nil
+	case createTmux:
+		return config.OpenTmux, nil
+	case cmd.Flags().Changed("open"):
+		switch createOpen {
+		case config.OpenNone, config.OpenEditor, config.OpenTmux, config.OpenShell:
+			return createOpen, nil
+		}
+		return "", fmt.Errorf("invalid --open %q — use none, editor, tmux or shell", createOpen)
+	}
+	return cfg.EffectiveOpen()
+}
 
+// createReport is the result printed by `grove create --json`.
+type createReport struct {
+	Alias        string       `json:"alias"`
+	Branch       string       `json:"branch"`
+	Path         string       `json:"path"`
+	Upstream     string       `json:"upstream,omitempty"`
+	Steps        []createStep `json:"steps"`
+	HookExitCode *int         `json:"hookExitCode,omitempty"` // nil when no afterCreate ran
+
+	root   string // project the worktree belongs to
+	open   string // where to take the user afterwards, see createOpenMode
+	editor string // editor command for --open editor, see editorCommand
+}
+
+// createStep records one setup step and how long it took.
+type createStep struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// resolveApply turns the --apply value into either an absolute patch path or
+// a stash ref. Existing files win; "stash@{n}" is used as is; anything else
+// is matched against stash messages, newest first.
+func resolveApply(value string) (patch, stash string, err error) {
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		// git apply runs inside the new worktree, so relative paths won't do.
+		abs, err := filepath.Abs(value)
+		return abs, "", err
+	}
+	if strings.HasPrefix(value, "stash@{") {
+		return "", value, nil
+	}
+	ref, err := git.FindStash(value)
+	if err != nil {
+		return "", "", err
+	}
+	if ref == "" {
+		return "", "", errorf(state.ErrNotFound, "--apply %q is neither a file nor a stash — check the path, or run 'git stash list'", value)
+	}
+	return "", ref, nil
+}
+
+// claimWorktree moves the pool worktree pooled to path and switches it to
+// branch. If either step fails, the worktree goes back to the pool — to its
+// old path and into state — so a failed claim costs nothing. Only when it
+// can't be moved back is it removed, as a failed git worktree add would
+// leave nothing behind.
+func claimWorktree(root string, pooled state.PoolEntry, path, branch, from string) error {
+	if err := git.MoveWorktree(pooled.Path, path); err != nil {
+		returnToPool(root, pooled)
+		return err
+	}
+	if err := git.SwitchWorktree(path, branch, from); err != nil {
+		if mvErr := git.MoveWorktree(path, pooled.Path); mvErr != nil {
+			if rbErr := git.RemoveWorktree(path, true); rbErr != nil {
+				fmt.Fprintf(os.Stderr, i18n.T("  warning: rollback failed, manual cleanup needed: %v\n"), rbErr)
+			}
+			return err
+		}
+		returnToPool(root, pooled)
+		return err
+	}
+	return nil
+}
+
+// returnToPool puts a pool entry claimPoolEntry took back into state. It
+// only warns if it can't: the worktree is still there, an orphan for
+// 'grove clean' to find.
+func returnToPool(root string, pooled state.PoolEntry) {
+	err := state.Change(root, func(s *state.State) error {
+		if !s.InPool(pooled.Path) {
+			s.Pool = append(s.Pool, pooled)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("  warning: could not return %s to the pool: %v\n"), pooled.Path, err)
+	}
+}
+
+// worktreePathFor builds the absolute worktree path for an alias:
+// worktreeDir + prefix + "-" + alias
+// e.g. "../" + "myproject" + "-" + "auth" → "../myproject-auth"
+// If prefix is empty, use just the alias to avoid a leading dash.
+func worktreePathFor(root string, cfg config.Config, alias string) (string, error) {
+	wtName := alias
+	if cfg.Prefix != "" {
+		wtName = cfg.Prefix + "-" + alias
+	}
+	worktreePath, err := filepath.Abs(filepath.Join(root, cfg.WorktreeDir, wtName))
+	if err != nil {
+		return "", err
+	}
+	// EvalSymlinks resolves /tmp → /private/tmp on macOS so path lookups
+	// match what git worktree list returns.
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(worktreePath)); err == nil {
+		worktreePath = filepath.Join(resolved, filepath.Base(worktreePath))
+	}
+	return worktreePath, nil
+}
+
+// caseInsensitiveFS reports whether the filesystem holding dir ignores
+// case in file names. Replaced in tests.
+var caseInsensitiveFS = files.CaseInsensitive
+
+// caseCollision rejects an alias or branch that differs from an existing
+// worktree's only in case, when the worktree directory is on a filesystem
+// that ignores case (the macOS and Windows default): the two worktrees would
+// share one directory, and git one loose ref. Filesystems that can't be
+// probed, e.g. read-only ones, are left to git to complain about.
+func caseCollision(s state.State, alias, branch, worktreePath string) error {
+	dir := filepath.Dir(worktreePath)
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	if insensitive, err := caseInsensitiveFS(dir); err != nil || !insensitive {
+		return nil
+	}
+
+	aliases := make([]string, 0, len(s.Worktrees))
+	for other := range s.Worktrees {
+		aliases = append(aliases, other)
+	}
+	sort.Strings(aliases)
+	for _, other := range aliases {
+		entry := s.Worktrees[other]
+		if strings.EqualFold(other, alias) || strings.EqualFold(entry.Path, worktreePath) {
+			return errorf(state.ErrAliasExists, "alias %q differs from %q only in case, and this filesystem ignores case, so both would use %s — use --name to choose a different one", alias, other, entry.Path)
+		}
+		if entry.Branch != branch && strings.EqualFold(entry.Branch, branch) {
+			return fmt.Errorf("branch %q differs from %q (worktree %s) only in case, and this filesystem ignores case, so git can't tell them apart — check out %q or rename one with 'git branch -m'", branch, entry.Branch, other, entry.Branch)
+		}
+	}
 	return nil
 }
 
@@ -180,9 +775,221 @@ func branchAlias(branch string) string {
 // runShell runs a command string in the given directory.
 // Uses "sh -c" so the string can include pipes, env vars, etc.
 func runShell(command, dir string) error {
-	cmd := exec.Command("sh", "-c", command)
+	return runHook(config.HookConfig{}, command, dir, nil, os.Stdout)
+}
+
+// runHook is runShell with the command's stdout sent to w, env added to the
+// environment, and the resource limits and sandbox from the "hooks" config
+// applied.
+func runHook(hooks config.HookConfig, command, dir string, env []string, w io.Writer) error {
+	defer trace.Begin("hook", command)()
+	hooks, err := userSandbox(hooks, os.Getenv("GROVE_HOOK_SANDBOX"))
+	if err != nil {
+		return err
+	}
+	argv, err := hookArgv(hooks, command, dir, env, exec.LookPath)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
 	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
+	// Hooks often run git themselves — don't let an inherited GIT_DIR
+	// point them at the wrong repository.
+	cmd.Env = append(hookBaseEnv(hooks), env...)
+	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
+
+// hookEnv describes the worktree to a hook, so one script can serve every
+// worktree (e.g. to name a database or compose project after the alias).
+func hookEnv(root, alias, branch, path string) []string {
+	return []string{
+		"GROVE_ROOT=" + root,
+		"GROVE_ALIAS=" + alias,
+		"GROVE_BRANCH=" + branch,
+		"GROVE_PATH=" + path,
+	}
+}
+
+// hookContext is the JSON file GROVE_CONTEXT points hooks at: everything
+// grove knows about the worktree, for hooks in Python or Node that would
+// rather read structured data than pick apart GROVE_* variables.
+type hookContext struct {
+	Hook     string              `json:"hook"` // installCommand, afterCreate, beforeRemove or verify
+	Root     string              `json:"root"`
+	Alias    string              `json:"alias"`
+	Worktree state.WorktreeEntry `json:"worktree"` // with its setup record, as far as setup got
+	Config   config.Config       `json:"config"`   // the template's, for a worktree created from one
+}
+
+// withHookContext writes ctx to a temporary file and returns env with
+// GROVE_CONTEXT set to its path. Call done once the hooks have run, to
+// delete the file.
+func withHookContext(env []string, ctx hookContext) (_ []string, done func(), err error) {
+	f, err := os.CreateTemp("", "grove-context-*.json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("writing the hook context: %w", err)
+	}
+	done = func() { os.Remove(f.Name()) }
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(ctx)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		done()
+		return nil, nil, fmt.Errorf("writing the hook context: %w", err)
+	}
+	return append(env, "GROVE_CONTEXT="+f.Name()), done, nil
+}
+
+// userSandbox adds the sandbox switches named in GROVE_HOOK_SANDBOX
+// ("noNetwork", "cleanEnv", comma-separated) to hooks. They live outside
+// .groverc.json so a shared config can't turn off the sandbox meant to
+// restrict it. Unknown names are an error rather than ignored, so a typo
+// doesn't leave hooks unsandboxed.
+func userSandbox(hooks config.HookConfig, value string) (config.HookConfig, error) {
+	for _, name := range strings.Split(value, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "noNetwork":
+			hooks.NoNetwork = true
+		case "cleanEnv":
+			hooks.CleanEnv = true
+		default:
+			return hooks, fmt.Errorf("unknown %q in GROVE_HOOK_SANDBOX — use noNetwork, cleanEnv or both, comma-separated", name)
+		}
+	}
+	return hooks, nil
+}
+
+// hookArgv builds the argv for a hook: the wrapper outermost, then nice and
+// ionice, then the network sandbox and the container, then "sh -c command".
+// lookPath decides which of nice/ionice exist; a missing one is skipped
+// rather than failing the hook. The sandbox is never skipped: if it can't be
+// set up, the hook doesn't run.
+//
+// In a container, env (the GROVE_* variables) would only reach the
+// container client, so it's passed inside with "env NAME=value" before
+// "sh -c".
+func hookArgv(hooks config.HookConfig, command, dir string, env []string, lookPath func(string) (string, error)) ([]string, error) {
+	var argv []string
+	argv = append(argv, hooks.Wrapper...)
+	if hooks.Nice > 0 {
+		if _, err := lookPath("nice"); err == nil {
+			argv = append(argv, "nice", "-n", strconv.Itoa(hooks.Nice))
+		}
+		// Best-effort class at its lowest priority: slower, but never starved
+		// the way the idle class can be on a busy disk.
+		if _, err := lookPath("ionice"); err == nil {
+			argv = append(argv, "ionice", "-c", "2", "-n", "7")
+		}
+	}
+	if hooks.NoNetwork {
+		if len(hooks.Container) > 0 {
+			return nil, fmt.Errorf("hooks.noNetwork can't sandbox hooks.container — remove it and give the container no network instead (e.g. docker run --network none)")
+		}
+		sandbox, err := noNetworkArgv(runtime.GOOS, lookPath)
+		if err != nil {
+			return nil, err
+		}
+		argv = append(argv, sandbox...)
+	}
+	if len(hooks.Container) == 0 {
+		return append(argv, "sh", "-c", command), nil
+	}
+	gitDir := ""
+	for _, arg := range hooks.Container {
+		if strings.Contains(arg, "{{gitdir}}") && gitDir == "" {
+			var err error
+			if gitDir, err = hookGitDir(dir); err != nil {
+				return nil, err
+			}
+		}
+		arg = strings.ReplaceAll(arg, "{{path}}", dir)
+		argv = append(argv, strings.ReplaceAll(arg, "{{gitdir}}", gitDir))
+	}
+	if len(env) > 0 {
+		argv = append(append(argv, "env"), env...)
+	}
+	return append(argv, "sh", "-c", command), nil
+}
+
+// hookGitDir is the common git directory of the worktree at dir, which its
+// .git file points into: a container that mounts only the worktree can't
+// run git in it without this mounted too.
+func hookGitDir(dir string) (string, error) {
+	gitDir, err := git.CommonDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("finding the git directory for {{gitdir}} in hooks.container: %w", err)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// noNetworkArgv is the command that runs a hook without network access on
+// goos: a new network namespace, entered as an unprivileged user, on Linux;
+// a sandbox profile denying the network on macOS.
+func noNetworkArgv(goos string, lookPath func(string) (string, error)) ([]string, error) {
+	switch goos {
+	case "linux":
+		if _, err := lookPath("unshare"); err != nil {
+			return nil, fmt.Errorf("hooks.noNetwork needs unshare (util-linux) — install it, or remove noNetwork from %s", config.FileName)
+		}
+		return []string{"unshare", "--net", "--map-root-user"}, nil
+	case "darwin":
+		return []string{"sandbox-exec", "-p", "(version 1)(allow default)(deny network*)"}, nil
+	default:
+		return nil, fmt.Errorf("hooks.noNetwork isn't supported on %s — remove it from %s, or use hooks.container", goos, config.FileName)
+	}
+}
+
+// hookEnvKeep is what a hook keeps of grove's environment with
+// hooks.cleanEnv: enough to find programs, write temp files and print text.
+var hookEnvKeep = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "TERM", "LANG", "LC_ALL"}
+
+// hookBaseEnv is the environment a hook starts from, before the GROVE_*
+// variables: grove's own, or with hooks.cleanEnv only hookEnvKeep and
+// hooks.passEnv.
+func hookBaseEnv(hooks config.HookConfig) []string {
+	env := git.Environ()
+	if !hooks.CleanEnv {
+		return env
+	}
+	var kept []string
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if slices.Contains(hookEnvKeep, name) || slices.Contains(hooks.PassEnv, name) {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
+
+// envProfilePatterns returns the env file patterns grove create --env
+// copies for profile, or nil to copy every .env* file. A profile that isn't
+// in envProfiles must have a .env.<profile> somewhere, so a typo doesn't
+// quietly leave the worktree with only .env.
+func envProfilePatterns(root string, cfg config.Config, profile string) ([]string, error) {
+	if profile == "" {
+		return nil, nil
+	}
+	patterns, configured := cfg.EnvProfile(profile)
+	if configured {
+		return patterns, nil
+	}
+	found, err := files.FindEnvFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range found {
+		if filepath.Base(rel) == ".env."+profile {
+			return patterns, nil
+		}
+	}
+	return nil, fmt.Errorf("no env profile %q: no .env.%s file and no %q in envProfiles — add one to %s, or drop --env", profile, profile, profile, config.FileName)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/git"
+)
+
+// branchSpec is one line of a create --from-file list.
+type branchSpec struct {
+	Branch string
+	Alias  string // empty: derived from the branch
+	Base   string // empty: --from, or the template's base branch
+}
+
+// createResult is one worktree of create --from-file: what create reported,
+// or why it failed.
+type createResult struct {
+	createReport
+	Error string `json:"error,omitempty"`
+}
+
+// runCreateFromFile creates a worktree for every line of --from-file, up to
+// jobs at a time, and prints a summary. A failed worktree doesn't stop the
+// rest.
+func runCreateFromFile(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("--from-file takes the branches from the file — drop the %q argument", args[0])
+	}
+	if createDescribe != "" {
+		return fmt.Errorf("--describe would give every branch the same description — drop it, or create the worktrees one at a time")
+	}
+	specs, err := readBranchList(createFromFile)
+	if err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("no branches in %s — list one per line: branch [alias] [base]", createFromFile)
+	}
+
+	// Asked once here, rather than by every worktree at the same time.
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	if createTemplate != "" {
+		if cfg, _, err = cfg.WithTemplate(createTemplate); err != nil {
+			return err
+		}
+	}
+	hooks, err := createHooks(root, cfg)
+	if err != nil {
+		return err
+	}
+	if err := requireTrust(root, hooks); err != nil {
+		return err
+	}
+
+	// An alias the list already used fails up front: created at the same
+	// time, either of the two could get it.
+	results := make([]createResult, len(specs))
+	var todo []int
+	seen := map[string]bool{}
+	for i, spec := range specs {
+		if spec.Alias == "" {
+			specs[i].Alias = branchAlias(spec.Branch)
+		}
+		if spec.Base == "" {
+			specs[i].Base = createFrom
+		}
+		alias := specs[i].Alias
+		if seen[alias] {
+			results[i] = createResult{Error: fmt.Sprintf("alias %q already exists — give it another one in %s", alias, createFromFile)}
+			results[i].Branch, results[i].Alias = spec.Branch, alias
+			continue
+		}
+		seen[alias] = true
+		todo = append(todo, i)
+	}
+
+	// Several checkouts at once would garble git's progress meters.
+	git.Progress = false
+	parallel(len(todo), func(j int) {
+		i := todo[j]
+		spec := specs[i]
+		w := newLabelWriter(os.Stdout, "["+spec.Alias+"] ")
+		defer w.Flush()
+		report, err := createFromSpec(cmd, spec, w)
+		result := createResult{createReport: report}
+		result.Branch, result.Alias = spec.Branch, spec.Alias
+		if err != nil {
+			result.Error = err.Error()
+			if !jsonOutput {
+				fmt.Fprintf(w, "  ✗ %v\n", err)
+			}
+		}
+		results[i] = result
+	})
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		data, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Println()
+		if err := printCreateSummary(os.Stdout, results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d worktrees failed — see the summary above", failed, len(specs))
+	}
+	return nil
+}
+
+// readBranchList reads a create --from-file list from path, or stdin for "-".
+func readBranchList(path string) ([]branchSpec, error) {
+	if path == "-" {
+		return parseBranchList(os.Stdin, "stdin")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseBranchList(f, path)
+}
+
+// parseBranchList parses "branch [alias] [base]" lines. Blank lines and
+// "#" comments are skipped, and "-" as the alias keeps the default one, so
+// a base can be given without it. name is used in error messages.
+func parseBranchList(r io.Reader, name string) ([]branchSpec, error) {
+	var specs []branchSpec
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("%s:%d: too many fields in %q — use: branch [alias] [base]", name, n, line)
+		}
+		spec := branchSpec{Branch: fields[0]}
+		if len(fields) > 1 && fields[1] != "-" {
+			if err := validateAlias(fields[1]); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+			}
+			spec.Alias = fields[1]
+		}
+		if len(fields) > 2 {
+			spec.Base = fields[2]
+		}
+		specs = append(specs, spec)
+	}
+	return specs, scanner.Err()
+}
+
+// printCreateSummary prints one row per worktree of create --from-file.
+func printCreateSummary(w io.Writer, results []createResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BRANCH\tALIAS\tRESULT")
+	for _, r := range results {
+		result := r.Path
+		if r.Error != "" {
+			result = "failed: " + r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Branch, r.Alias, result)
+	}
+	return tw.Flush()
+}
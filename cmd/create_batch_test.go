@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestParseBranchList(t *testing.T) {
+	input := `# open PRs
+feature/auth
+
+fix/login   login
+feature/api - develop
+`
+	specs, err := parseBranchList(strings.NewReader(input), "prs.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []branchSpec{
+		{Branch: "feature/auth"},
+		{Branch: "fix/login", Alias: "login"},
+		{Branch: "feature/api", Base: "develop"},
+	}
+	if len(specs) != len(want) {
+		t.Fatalf("specs = %+v, want %+v", specs, want)
+	}
+	for i := range want {
+		if specs[i] != want[i] {
+			t.Errorf("spec %d = %+v, want %+v", i, specs[i], want[i])
+		}
+	}
+
+	if _, err := parseBranchList(strings.NewReader("a b c d\n"), "prs.txt"); err == nil || !strings.Contains(err.Error(), "prs.txt:1") {
+		t.Errorf("too many fields: err = %v, want it to name the line", err)
+	}
+}
+
+func TestCreateFromFile(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	list := filepath.Join(t.TempDir(), "branches.txt")
+	// The third line reuses the first one's alias, so it fails on its own.
+	if err := os.WriteFile(list, []byte("feature/one\nfeature/two two2\nother/one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, name := range []string{"one", "two2"} {
+			rm := exec.Command("git", "worktree", "remove", "--force", filepath.Join(filepath.Dir(dir), "testproject-"+name))
+			rm.Dir = dir
+			rm.CombinedOutput()
+		}
+	})
+
+	createName, createFrom, createFromFile = "", "", list
+	jobs = 3
+	t.Cleanup(func() { createFromFile, jobs = "", 1 })
+	var err error
+	out := captureStdout(t, func() { err = runCreate(createCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Errorf("err = %v, want 1 of 3 failed", err)
+	}
+	if !strings.Contains(string(out), "BRANCH") || !strings.Contains(string(out), "failed: alias \"one\" already exists") {
+		t.Errorf("summary missing:\n%s", out)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := s.Get("two2"); !ok || entry.Branch != "feature/two" {
+		t.Errorf("two2 = %+v, %v", entry, ok)
+	}
+	if entry, ok := s.Get("one"); !ok || entry.Branch != "feature/one" {
+		t.Errorf("one = %+v, %v", entry, ok)
+	}
+}
@@ -1,15 +1,30 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
 	"github.com/verbaux/grove/internal/state"
 )
 
+// TestMain keeps the tests, which check grove's English output, off any
+// built-in catalog for the developer's locale.
+func TestMain(m *testing.M) {
+	os.Setenv("GROVE_LANG", "C")
+	os.Exit(m.Run())
+}
+
 // setupIntegrationRepo creates a real git repo with a .groverc.json and
 // changes cwd into it. Returns the repo directory and a cleanup function.
 func setupIntegrationRepo(t *testing.T, cfg config.Config) string {
@@ -20,6 +35,13 @@ func setupIntegrationRepo(t *testing.T, cfg config.Config) string {
 		t.Fatal(err)
 	}
 
+	// Keep the machine-wide project registry out of the user's config dir.
+	t.Setenv("GROVE_REGISTRY", filepath.Join(t.TempDir(), "projects.json"))
+	// Same for hook approvals; tests run their hooks as if with --trust.
+	t.Setenv("GROVE_TRUST_STORE", filepath.Join(t.TempDir(), "trusted.json"))
+	trustHooks = true
+	t.Cleanup(func() { trustHooks = false })
+
 	for _, args := range [][]string{
 		{"git", "init", "-b", "main"},
 		{"git", "config", "user.email", "test@test.com"},
@@ -173,3 +195,588 @@ func TestCreateSkipsSymlinkConflict(t *testing.T) {
 		t.Fatalf("cleanup failed: %s", out)
 	}
 }
+
+func TestCreateRunsInstallForMissingSymlink(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:    "../",
+		Prefix:         "testproject",
+		Symlink:        []string{"node_modules"},
+		InstallCommand: "mkdir node_modules",
+	})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/install"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-install")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	info, err := os.Lstat(filepath.Join(wtPath, "node_modules"))
+	if err != nil {
+		t.Fatalf("expected installCommand to create node_modules: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 || !info.IsDir() {
+		t.Error("expected node_modules to be a real directory in the worktree")
+	}
+
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("install")
+	if entry.Setup == nil || len(entry.Setup.Hooks) != 1 || entry.Setup.Hooks[0].Name != "installCommand" {
+		t.Errorf("expected the install run to be recorded, got %+v", entry.Setup)
+	}
+}
+
+func TestCreateWithTemplate(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		Templates: map[string]config.Template{
+			"backend": {
+				Copy:   []string{"local.yml"},
+				Tags:   []string{"api"},
+				Expire: "3d",
+			},
+		},
+	})
+
+	if err := os.WriteFile(filepath.Join(dir, "local.yml"), []byte("port: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	createName = ""
+	createFrom = ""
+	createTemplate = "backend"
+	t.Cleanup(func() { createTemplate = "" })
+
+	if err := runCreate(createCmd, []string{"feature/tpl"}); err != nil {
+		t.Fatalf("create with template failed: %v", err)
+	}
+
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-tpl")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	if _, err := os.Stat(filepath.Join(wtPath, "local.yml")); err != nil {
+		t.Errorf("expected template copy file in worktree: %v", err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := s.Get("tpl")
+	if !ok {
+		t.Fatal("expected alias to be present in state")
+	}
+	if entry.Template != "backend" {
+		t.Errorf("template = %q, want %q", entry.Template, "backend")
+	}
+	if len(entry.Tags) != 1 || entry.Tags[0] != "api" {
+		t.Errorf("tags = %v, want [api]", entry.Tags)
+	}
+	if left := time.Until(entry.Expires); left < 71*time.Hour || left > 72*time.Hour {
+		t.Errorf("expires in %v, want 3 days from the template", left)
+	}
+}
+
+func TestCreatePushSetsUpstream(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		Push:        true,
+	})
+
+	remote := t.TempDir()
+	for _, args := range [][]string{
+		{"git", "init", "--bare", remote},
+		{"git", "remote", "add", "origin", remote},
+	} {
+		c := exec.Command(args[0], args[1:]...)
+		c.Dir = dir
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", args, out)
+		}
+	}
+
+	createName = ""
+	createFrom = ""
+
+	if err := runCreate(createCmd, []string{"feature/pushed"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-pushed")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := s.Get("pushed")
+	if entry.Upstream != "origin/feature/pushed" {
+		t.Errorf("upstream = %q, want %q", entry.Upstream, "origin/feature/pushed")
+	}
+	// New branch without --from is based on the current HEAD.
+	if entry.Base != "main" {
+		t.Errorf("base = %q, want %q", entry.Base, "main")
+	}
+
+	c := exec.Command("git", "rev-parse", "--verify", "refs/heads/feature/pushed")
+	c.Dir = remote
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Errorf("branch not found on remote: %s", out)
+	}
+}
+
+func TestCreateJSONOutput(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		AfterCreate: "echo hook output",
+	})
+
+	createName = ""
+	createFrom = ""
+	jsonOutput = true
+	t.Cleanup(func() { jsonOutput = false })
+
+	out := captureStdout(t, func() {
+		if err := runCreate(createCmd, []string{"feature/machine"}); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	})
+
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-machine")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	// Stdout must hold exactly one JSON document — no progress lines, no hook output.
+	var got createReport
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("stdout is not a single JSON object: %v\n%s", err, out)
+	}
+	if got.Alias != "machine" || got.Branch != "feature/machine" || got.Path != wtPath {
+		t.Errorf("report = %+v", got)
+	}
+	if got.HookExitCode == nil || *got.HookExitCode != 0 {
+		t.Errorf("hookExitCode = %v, want 0", got.HookExitCode)
+	}
+	var names []string
+	for _, s := range got.Steps {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "worktree,env,symlink,afterCreate" {
+		t.Errorf("steps = %v", names)
+	}
+}
+
+// captureStdout runs fn with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestHookArgv(t *testing.T) {
+	all := func(string) (string, error) { return "/usr/bin/x", nil }
+	none := func(string) (string, error) { return "", exec.ErrNotFound }
+
+	tests := []struct {
+		name     string
+		hooks    config.HookConfig
+		lookPath func(string) (string, error)
+		want     string
+	}{
+		{"plain", config.HookConfig{}, all, "sh -c npm ci"},
+		{"nice", config.HookConfig{Nice: 10}, all, "nice -n 10 ionice -c 2 -n 7 sh -c npm ci"},
+		{"nice unavailable", config.HookConfig{Nice: 10}, none, "sh -c npm ci"},
+		{"wrapper", config.HookConfig{Nice: 5, Wrapper: []string{"systemd-run", "--user", "--scope"}}, all,
+			"systemd-run --user --scope nice -n 5 ionice -c 2 -n 7 sh -c npm ci"},
+		{"container", config.HookConfig{Container: []string{"docker", "run", "--rm", "-v", "{{path}}:{{path}}", "-w", "{{path}}", "node:20"}}, all,
+			"docker run --rm -v /wt:/wt -w /wt node:20 sh -c npm ci"},
+	}
+	for _, tt := range tests {
+		argv, err := hookArgv(tt.hooks, "npm ci", "/wt", nil, tt.lookPath)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := strings.Join(argv, " "); got != tt.want {
+			t.Errorf("%s: argv = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHookSandbox(t *testing.T) {
+	all := func(string) (string, error) { return "/usr/bin/x", nil }
+	none := func(string) (string, error) { return "", exec.ErrNotFound }
+
+	if argv, err := noNetworkArgv("linux", all); err != nil || strings.Join(argv, " ") != "unshare --net --map-root-user" {
+		t.Errorf("linux: argv = %q, err = %v", argv, err)
+	}
+	if argv, err := noNetworkArgv("darwin", none); err != nil || argv[0] != "sandbox-exec" {
+		t.Errorf("darwin: argv = %q, err = %v", argv, err)
+	}
+	// The sandbox fails closed: no unshare, or no way to sandbox at all,
+	// means no hook.
+	if _, err := noNetworkArgv("linux", none); err == nil {
+		t.Error("linux without unshare: want an error")
+	}
+	if _, err := noNetworkArgv("windows", all); err == nil {
+		t.Error("windows: want an error")
+	}
+	if _, err := hookArgv(config.HookConfig{NoNetwork: true, Container: []string{"docker", "run"}}, "npm ci", "/wt", nil, all); err == nil {
+		t.Error("noNetwork with container: want an error")
+	}
+
+	t.Setenv("NPM_TOKEN", "secret")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	names := func(env []string) []string {
+		var names []string
+		for _, kv := range env {
+			name, _, _ := strings.Cut(kv, "=")
+			names = append(names, name)
+		}
+		return names
+	}
+	env := names(hookBaseEnv(config.HookConfig{CleanEnv: true, PassEnv: []string{"NPM_TOKEN"}}))
+	if !slices.Contains(env, "PATH") || !slices.Contains(env, "NPM_TOKEN") || slices.Contains(env, "AWS_SECRET_ACCESS_KEY") {
+		t.Errorf("cleanEnv kept %v", env)
+	}
+	if env := names(hookBaseEnv(config.HookConfig{})); !slices.Contains(env, "AWS_SECRET_ACCESS_KEY") {
+		t.Error("without cleanEnv, hooks should get grove's environment")
+	}
+}
+
+func TestHookContainer(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	all := func(string) (string, error) { return "/usr/bin/x", nil }
+
+	// GROVE_* go inside the container, and {{gitdir}} lets it mount the
+	// repository the worktree's .git file points at.
+	hooks := config.HookConfig{Container: []string{"docker", "run", "-v", "{{gitdir}}:{{gitdir}}", "img"}}
+	argv, err := hookArgv(hooks, "npm ci", dir, []string{"GROVE_ALIAS=a b"}, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitDir := filepath.Join(dir, ".git")
+	want := []string{"docker", "run", "-v", gitDir + ":" + gitDir, "img", "env", "GROVE_ALIAS=a b", "sh", "-c", "npm ci"}
+	if !slices.Equal(argv, want) {
+		t.Errorf("argv = %q, want %q", argv, want)
+	}
+
+	// GROVE_HOOK_SANDBOX turns the sandbox on whatever the shared config says.
+	got, err := userSandbox(config.HookConfig{}, "noNetwork, cleanEnv")
+	if err != nil || !got.NoNetwork || !got.CleanEnv {
+		t.Errorf("userSandbox = %+v, %v", got, err)
+	}
+	if _, err := userSandbox(config.HookConfig{}, "nonetwork"); err == nil {
+		t.Error("a misspelled GROVE_HOOK_SANDBOX should be refused, not ignored")
+	}
+}
+
+func TestCreateApply(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	// Make a patch and a stash from changes to a tracked file.
+	os.WriteFile(filepath.Join(dir, "app.txt"), []byte("v1\n"), 0644)
+	gitCmd(dir, "add", "app.txt")
+	gitCmd(dir, "commit", "-m", "app")
+	os.WriteFile(filepath.Join(dir, "app.txt"), []byte("v2\n"), 0644)
+	patch, err := gitCmd(dir, "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "fix.patch"), patch, 0644)
+	gitCmd(dir, "stash", "push", "-m", "review-fix", "--", "app.txt")
+
+	createName = ""
+	createFrom = ""
+	t.Cleanup(func() { createApply = "" })
+
+	for _, tt := range []struct{ branch, apply string }{
+		{"feature/from-patch", "fix.patch"},
+		{"feature/from-stash", "review-fix"},
+	} {
+		createApply = tt.apply
+		if err := runCreate(createCmd, []string{tt.branch}); err != nil {
+			t.Fatalf("create --apply %s failed: %v", tt.apply, err)
+		}
+		wtPath := filepath.Join(filepath.Dir(dir), "testproject-"+branchAlias(tt.branch))
+		t.Cleanup(func() {
+			rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+			rm.Dir = dir
+			rm.CombinedOutput()
+		})
+		data, _ := os.ReadFile(filepath.Join(wtPath, "app.txt"))
+		if string(data) != "v2\n" {
+			t.Errorf("--apply %s: app.txt = %q, want v2", tt.apply, data)
+		}
+	}
+
+	createApply = "no-such-thing"
+	if err := runCreate(createCmd, []string{"feature/nope"}); !errors.Is(err, state.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown --apply, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "testproject-nope")); !os.IsNotExist(err) {
+		t.Error("worktree should not be created when --apply can't be resolved")
+	}
+}
+
+func TestCreateEnvProfile(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		EnvProfiles: map[string][]string{"ci": {".env", ".env.test*"}},
+	})
+	for _, name := range []string{".env", ".env.local", ".env.staging", ".env.production", ".env.test", ".env.test.local"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("X=1\n"), 0644)
+	}
+
+	createName = ""
+	createFrom = ""
+	t.Cleanup(func() { createEnv = "" })
+
+	for _, tt := range []struct {
+		env, branch string
+		want        []string
+	}{
+		{"staging", "feature/staging", []string{".env", ".env.staging"}},
+		{"ci", "feature/ci", []string{".env", ".env.test", ".env.test.local"}},
+	} {
+		createEnv = tt.env
+		if err := runCreate(createCmd, []string{tt.branch}); err != nil {
+			t.Fatalf("create --env %s failed: %v", tt.env, err)
+		}
+		wtPath := filepath.Join(filepath.Dir(dir), "testproject-"+branchAlias(tt.branch))
+		t.Cleanup(func() {
+			rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+			rm.Dir = dir
+			rm.CombinedOutput()
+		})
+		entries, _ := os.ReadDir(wtPath)
+		var got []string
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), ".env") {
+				got = append(got, e.Name())
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("--env %s copied %v, want %v", tt.env, got, tt.want)
+		}
+		s, _ := state.Load(dir)
+		if e, _ := s.Get(branchAlias(tt.branch)); e.Setup == nil || e.Setup.EnvProfile != tt.env {
+			t.Errorf("--env %s not recorded in state: %+v", tt.env, e.Setup)
+		}
+	}
+
+	createEnv = "stagin"
+	if err := runCreate(createCmd, []string{"feature/typo"}); err == nil || !strings.Contains(err.Error(), "envProfiles") {
+		t.Errorf("expected an unknown-profile error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "testproject-typo")); !os.IsNotExist(err) {
+		t.Error("worktree should not be created for an unknown env profile")
+	}
+}
+
+func TestCreateOpenInvalid(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Open:        "browser",
+	})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/open"}); err == nil {
+		t.Fatal("expected error for invalid open in config")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "testproject-open")); !os.IsNotExist(err) {
+		t.Error("worktree should not be created when open is invalid")
+	}
+}
+
+func TestCreateOpenShell(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Open:        config.OpenShell,
+	})
+	// A "shell" that records where and for which worktree it was started.
+	marker := filepath.Join(t.TempDir(), "opened")
+	shell := filepath.Join(t.TempDir(), "fake-shell")
+	script := "#!/bin/sh\necho \"$GROVE_ALIAS $(pwd)\" > " + marker + "\nexit 3\n"
+	if err := os.WriteFile(shell, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SHELL", shell)
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/shell"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-shell")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	got, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("shell was not started: %v", err)
+	}
+	if want := "shell " + wtPath + "\n"; string(got) != want {
+		t.Errorf("shell ran as %q, want %q", got, want)
+	}
+}
+
+func TestTmuxArgs(t *testing.T) {
+	if got := strings.Join(tmuxArgs("auth", "/wt", true), " "); got != "new-window -n auth -c /wt" {
+		t.Errorf("inside tmux: %q", got)
+	}
+	if got := strings.Join(tmuxArgs("auth", "/wt", false), " "); got != "new-session -A -s auth -c /wt" {
+		t.Errorf("outside tmux: %q", got)
+	}
+}
+
+func TestCreateCaseCollision(t *testing.T) {
+	root := t.TempDir()
+	s := state.State{Worktrees: map[string]state.WorktreeEntry{
+		"auth": {Branch: "feature/Auth", Path: filepath.Join(root, "app-auth")},
+	}}
+
+	insensitive := false
+	caseInsensitiveFS = func(string) (bool, error) { return insensitive, nil }
+	t.Cleanup(func() { caseInsensitiveFS = files.CaseInsensitive })
+
+	if err := caseCollision(s, "Auth", "feature/auth", filepath.Join(root, "app-Auth")); err != nil {
+		t.Errorf("a case-sensitive filesystem keeps both apart, got %v", err)
+	}
+
+	insensitive = true
+	err := caseCollision(s, "Auth", "other", filepath.Join(root, "app-Auth"))
+	if !errors.Is(err, state.ErrAliasExists) {
+		t.Errorf("expected an alias collision, got %v", err)
+	}
+	err = caseCollision(s, "auth2", "feature/auth", filepath.Join(root, "app-auth2"))
+	if err == nil || !strings.Contains(err.Error(), `"feature/Auth"`) {
+		t.Errorf("expected a branch collision naming feature/Auth, got %v", err)
+	}
+	if err := caseCollision(s, "billing", "feature/billing", filepath.Join(root, "nested", "app-billing")); err != nil {
+		t.Errorf("unrelated worktree rejected: %v", err)
+	}
+}
+
+func TestHookContextFile(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "context.json")
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		AfterCreate: `cp "$GROVE_CONTEXT" ` + saved,
+	})
+
+	createName, createFrom = "", ""
+	if err := runCreate(createCmd, []string{"feature/ctx"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-ctx")
+	t.Cleanup(func() { gitCmd(dir, "worktree", "remove", "--force", wtPath) })
+
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatalf("hook didn't get a context file: %v", err)
+	}
+	var ctx hookContext
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		t.Fatalf("context isn't JSON: %v\n%s", err, data)
+	}
+	if ctx.Hook != "afterCreate" || ctx.Alias != "ctx" || ctx.Worktree.Branch != "feature/ctx" || ctx.Worktree.Path != wtPath {
+		t.Errorf("context = %+v", ctx)
+	}
+	if ctx.Config.Prefix != "testproject" || ctx.Worktree.Setup == nil {
+		t.Errorf("context lacks the config or setup: %s", data)
+	}
+
+	// The file is gone once the hooks have run.
+	matches, _ := filepath.Glob(filepath.Join(os.TempDir(), "grove-context-*.json"))
+	for _, m := range matches {
+		if b, _ := os.ReadFile(m); strings.Contains(string(b), wtPath) {
+			t.Errorf("context file %s left behind", m)
+		}
+	}
+}
+
+func TestCreateDescribe(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	createName, createFrom, createDescribe = "", "", "spike: new cache layer\nbenchmarks in #12"
+	t.Cleanup(func() { createDescribe, createFromFile = "", "" })
+	if err := runCreate(createCmd, []string{"feature/cache"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-cache")
+	t.Cleanup(func() { gitCmd(dir, "worktree", "remove", "--force", wtPath) })
+
+	out, err := gitCmd(dir, "config", "--get", "branch.feature/cache.description")
+	if err != nil || strings.TrimSpace(string(out)) != createDescribe {
+		t.Fatalf("branch description = %q, %v", out, err)
+	}
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("cache")
+	if info := formatInfo("cache", entry, git.BranchDescription("feature/cache")); !strings.Contains(info, "Description: spike: new cache layer\n             benchmarks in #12\n") {
+		t.Errorf("grove info doesn't show the description:\n%s", info)
+	}
+
+	// The description lives in git, so it's still listed with grove's
+	// state gone.
+	if err := os.RemoveAll(filepath.Join(dir, ".grove")); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := buildWorktreeRows(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := renderTable(rows, false)
+	if !strings.Contains(table, "DESCRIPTION") || !strings.Contains(table, "spike: new cache layer") || strings.Contains(table, "benchmarks") {
+		t.Errorf("grove list should show the description's first line:\n%s", table)
+	}
+
+	createFromFile = filepath.Join(t.TempDir(), "branches.txt")
+	if err := runCreate(createCmd, nil); err == nil || !strings.Contains(err.Error(), "--describe") {
+		t.Errorf("--describe with --from-file = %v, want it refused", err)
+	}
+}
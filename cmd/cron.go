@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+)
+
+var (
+	cronSchedule string
+	cronUnused   string
+	cronBackend  string
+)
+
+func init() {
+	rootCmd.AddCommand(cronCmd)
+	cronCmd.AddCommand(cronInstallCmd, cronStatusCmd, cronRemoveCmd)
+	cronCmd.PersistentFlags().StringVar(&cronBackend, "backend", "", "cron, systemd or launchd (default: launchd on macOS, a systemd user timer where systemd runs, else cron)")
+	cronInstallCmd.Flags().StringVar(&cronSchedule, "schedule", "0 3 * * *", "cron schedule expression")
+	cronInstallCmd.Flags().StringVar(&cronUnused, "unused", "45d", "also remove worktrees without a commit in this long, or \"off\"")
+}
+
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Schedule periodic maintenance for this project",
+	Long: `Manage a job that keeps this project tidy on a schedule. It runs
+'grove prune', 'grove clean --expired --yes' and, for worktrees without a
+commit in 45 days (--unused), 'grove clean --not-since 45d --yes'. Worktrees
+with uncommitted changes, locked or claimed by someone else are left alone.
+
+The job is a launchd agent on macOS, a systemd user timer where systemd
+runs, and a crontab entry otherwise; --backend picks one. Each project gets
+its own job, so it can be found and replaced later.
+
+--schedule takes a cron expression. launchd and systemd take its common
+forms: "M H * * *", "M H * * D", "M * * * *", @hourly, @daily and @weekly.`,
+}
+
+var cronInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install or update the maintenance job",
+	Args:  cobra.NoArgs,
+	RunE:  runCronInstall,
+}
+
+var cronStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the installed maintenance job",
+	Args:  cobra.NoArgs,
+	RunE:  runCronStatus,
+}
+
+var cronRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the maintenance job",
+	Args:  cobra.NoArgs,
+	RunE:  runCronRemove,
+}
+
+// Where the maintenance job can be registered.
+const (
+	cronCrontab = "cron"
+	cronSystemd = "systemd"
+	cronLaunchd = "launchd"
+)
+
+// cronJobs installs, finds and removes a project's maintenance job with one
+// scheduler. install returns where the job went; status returns what it
+// found, and whether there was anything.
+type cronJobs struct {
+	install func(root, schedule string, steps [][]string) (string, error)
+	status  func(root string) (string, bool, error)
+	remove  func(root string) (bool, error)
+}
+
+var cronBackends = map[string]cronJobs{
+	cronCrontab: {installCrontab, crontabStatus, removeCrontab},
+	cronSystemd: {installSystemd, systemdStatus, removeSystemd},
+	cronLaunchd: {installLaunchd, launchdStatus, removeLaunchd},
+}
+
+func runCronInstall(cmd *cobra.Command, args []string) error {
+	root, err := cronRoot()
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	unused := cronUnused
+	if unused == "off" {
+		unused = ""
+	} else if _, err := parseTTL(unused); err != nil {
+		return fmt.Errorf("invalid --unused %q — use something like 45d or 8w, or off", unused)
+	}
+
+	backend, err := pickCronBackend()
+	if err != nil {
+		return err
+	}
+
+	// A job left with another scheduler would run everything twice.
+	for _, other := range installedCronBackends() {
+		if other == backend {
+			continue
+		}
+		if removed, err := cronBackends[other].remove(root); err != nil {
+			return err
+		} else if removed {
+			fmt.Printf("Removed the previous job from %s.\n", other)
+		}
+	}
+
+	steps := maintenanceSteps(exe, unused)
+	where, err := cronBackends[backend].install(root, cronSchedule, steps)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Installed maintenance job (%s), running %s:\n", where, cronSchedule)
+	for _, step := range steps {
+		fmt.Printf("  grove %s\n", strings.Join(step[1:], " "))
+	}
+	return nil
+}
+
+func runCronStatus(cmd *cobra.Command, args []string) error {
+	root, err := cronRoot()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, backend := range installedCronBackends() {
+		job, ok, err := cronBackends[backend].status(root)
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Println(job)
+			found = true
+		}
+	}
+	if !found {
+		fmt.Println("No maintenance job installed. Run 'grove cron install' to add one.")
+	}
+	return nil
+}
+
+func runCronRemove(cmd *cobra.Command, args []string) error {
+	root, err := cronRoot()
+	if err != nil {
+		return err
+	}
+
+	removed := false
+	for _, backend := range installedCronBackends() {
+		ok, err := cronBackends[backend].remove(root)
+		if err != nil {
+			return err
+		}
+		removed = removed || ok
+	}
+	if !removed {
+		fmt.Println("No maintenance job installed.")
+		return nil
+	}
+	fmt.Println("Removed maintenance job.")
+	return nil
+}
+
+func cronRoot() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return config.FindRoot(cwd)
+}
+
+// pickCronBackend is --backend, or else the scheduler this machine runs:
+// launchd on macOS, systemd where a user manager answers, else cron.
+func pickCronBackend() (string, error) {
+	switch cronBackend {
+	case cronCrontab, cronSystemd, cronLaunchd:
+		return cronBackend, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown --backend %q — use cron, systemd or launchd", cronBackend)
+	}
+	if runtime.GOOS == "darwin" {
+		return cronLaunchd, nil
+	}
+	if systemdAvailable() {
+		return cronSystemd, nil
+	}
+	return cronCrontab, nil
+}
+
+// installedCronBackends lists the schedulers a job may be registered with:
+// just --backend if given, else every one, leaving out cron where there's
+// no crontab to read.
+func installedCronBackends() []string {
+	if cronBackend != "" {
+		return []string{cronBackend}
+	}
+	backends := []string{cronLaunchd, cronSystemd}
+	if _, err := exec.LookPath("crontab"); err == nil {
+		backends = append(backends, cronCrontab)
+	}
+	return backends
+}
+
+// maintenanceSteps is the commands the job runs, in order: prune, clean
+// up expired worktrees, then ones unused for unused ("" to skip that).
+func maintenanceSteps(exe, unused string) [][]string {
+	steps := [][]string{
+		{exe, "prune"},
+		{exe, "clean", "--expired", "--yes"},
+	}
+	if unused != "" {
+		steps = append(steps, []string{exe, "clean", "--not-since", unused, "--yes"})
+	}
+	return steps
+}
+
+// maintenanceScript is steps as one sh command line. Each step runs even
+// if the one before failed.
+func maintenanceScript(steps [][]string) string {
+	lines := make([]string, len(steps))
+	for i, step := range steps {
+		words := make([]string, len(step))
+		for j, word := range step {
+			words[j] = shellArg(word)
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	return strings.Join(lines, "; ")
+}
+
+func installCrontab(root, schedule string, steps [][]string) (string, error) {
+	current, err := readCrontab()
+	if err != nil {
+		return "", err
+	}
+	updated, _ := removeCronEntry(current, root)
+	updated = append(updated, cronLine(schedule, maintenanceScript(steps), root))
+	if err := writeCrontab(updated); err != nil {
+		return "", err
+	}
+	return "crontab", nil
+}
+
+func crontabStatus(root string) (string, bool, error) {
+	current, err := readCrontab()
+	if err != nil {
+		return "", false, err
+	}
+	for _, line := range current {
+		if strings.HasSuffix(line, cronMarker(root)) {
+			return line, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func removeCrontab(root string) (bool, error) {
+	current, err := readCrontab()
+	if err != nil {
+		return false, err
+	}
+	updated, removed := removeCronEntry(current, root)
+	if !removed {
+		return false, nil
+	}
+	return true, writeCrontab(updated)
+}
+
+// cronMarker tags a crontab line as belonging to the grove project at root.
+func cronMarker(root string) string {
+	return "# grove:" + root
+}
+
+// cronLine builds the crontab entry for a project, running script from
+// root. cron turns % into a newline, so it's escaped.
+// "0 3 * * * cd '/p' && { /bin/grove prune; ... } >/dev/null 2>&1 # grove:/p"
+func cronLine(schedule, script, root string) string {
+	command := fmt.Sprintf("cd %s && { %s; } >/dev/null 2>&1", shellQuote(root), script)
+	return fmt.Sprintf("%s %s %s", schedule, strings.ReplaceAll(command, "%", `\%`), cronMarker(root))
+}
+
+// removeCronEntry drops the line for root from a crontab.
+// Returns the remaining lines and whether anything was removed.
+func removeCronEntry(lines []string, root string) ([]string, bool) {
+	var kept []string
+	removed := false
+	for _, line := range lines {
+		if strings.HasSuffix(line, cronMarker(root)) {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept, removed
+}
+
+// readCrontab returns the current user's crontab lines.
+// A missing crontab is treated as empty.
+func readCrontab() ([]string, error) {
+	var stderr bytes.Buffer
+	c := exec.Command("crontab", "-l")
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "no crontab") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("crontab -l: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+func writeCrontab(lines []string) error {
+	c := exec.Command("crontab", "-")
+	c.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("crontab -: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// shellQuote wraps s in single quotes for sh, escaping embedded quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellArg is s as one sh word: as it is when it's only letters, digits
+// and ._/-, else quoted with shellQuote.
+func shellArg(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._/-") == "" {
+		return s
+	}
+	return shellQuote(s)
+}
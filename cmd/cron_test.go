@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCronLine(t *testing.T) {
+	script := maintenanceScript(maintenanceSteps("/usr/local/bin/grove", "45d"))
+	got := cronLine("0 3 * * *", script, "/home/dev/it's 100%")
+	want := `0 3 * * * cd '/home/dev/it'\''s 100\%' && { /usr/local/bin/grove prune; /usr/local/bin/grove clean --expired --yes; /usr/local/bin/grove clean --not-since 45d --yes; } >/dev/null 2>&1 # grove:/home/dev/it's 100%`
+	if got != want {
+		t.Errorf("cronLine =\n  %s\nwant\n  %s", got, want)
+	}
+
+	if steps := maintenanceSteps("grove", ""); len(steps) != 2 {
+		t.Errorf("--unused off: steps = %v, want prune and clean --expired only", steps)
+	}
+}
+
+func TestParseCalendar(t *testing.T) {
+	for _, tt := range []struct{ schedule, want string }{
+		{"0 3 * * *", "*-*-* 03:00:00"},
+		{"30 22 * * 1", "Mon *-*-* 22:30:00"},
+		{"15 * * * *", "*-*-* *:15:00"},
+		{"@weekly", "Sun *-*-* 00:00:00"},
+		{"0 4 * * 7", "Sun *-*-* 04:00:00"},
+	} {
+		cal, err := parseCalendar(tt.schedule, cronSystemd)
+		if err != nil {
+			t.Errorf("%q: %v", tt.schedule, err)
+			continue
+		}
+		if got := cal.onCalendar(); got != tt.want {
+			t.Errorf("%q: OnCalendar = %q, want %q", tt.schedule, got, tt.want)
+		}
+	}
+	for _, schedule := range []string{"*/5 * * * *", "0 3 1 * *", "0 25 * * *", "@reboot"} {
+		if _, err := parseCalendar(schedule, cronLaunchd); err == nil {
+			t.Errorf("%q: want an error, launchd and systemd can't express it", schedule)
+		}
+	}
+}
+
+func TestTimerUnits(t *testing.T) {
+	steps := maintenanceSteps("/opt/my tools/grove", "45d")
+	cal := cronCalendar{0, 3, -1}
+
+	service, timer := systemdUnits("/home/dev/app", cal, steps)
+	for _, want := range []string{
+		"WorkingDirectory=/home/dev/app\n",
+		`ExecStart=-"/opt/my tools/grove" prune` + "\n",
+		`ExecStart=-"/opt/my tools/grove" clean --not-since 45d --yes` + "\n",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("service unit lacks %q:\n%s", want, service)
+		}
+	}
+	if !strings.Contains(timer, "OnCalendar=*-*-* 03:00:00\n") {
+		t.Errorf("timer unit:\n%s", timer)
+	}
+
+	plist := launchdPlist("dev.grove-maintenance-x", "/home/dev/a&b", cal, steps)
+	for _, want := range []string{
+		"<string>/home/dev/a&amp;b</string>",
+		"<string>&#39;/opt/my tools/grove&#39; prune; ",
+		"<key>Hour</key>\n\t\t<integer>3</integer>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist lacks %q:\n%s", want, plist)
+		}
+	}
+	if strings.Contains(plist, "Weekday") {
+		t.Errorf("a daily job shouldn't pin a weekday:\n%s", plist)
+	}
+}
+
+func TestRemoveCronEntry(t *testing.T) {
+	lines := []string{
+		"MAILTO=dev@example.com",
+		cronLine("@daily", "grove prune", "/a"),
+		cronLine("@daily", "grove prune", "/b"),
+	}
+
+	kept, removed := removeCronEntry(lines, "/a")
+	if !removed {
+		t.Fatal("expected entry for /a to be removed")
+	}
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 remaining lines, got %v", kept)
+	}
+	for _, line := range kept {
+		if strings.HasSuffix(line, "# grove:/a") {
+			t.Errorf("entry for /a still present: %s", line)
+		}
+	}
+
+	if _, removed := removeCronEntry(kept, "/a"); removed {
+		t.Error("expected no removal on second call")
+	}
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cronCalendar is a schedule launchd and systemd can both express: a
+// minute, and an hour and weekday that are -1 for "every".
+type cronCalendar struct {
+	minute, hour, weekday int
+}
+
+// parseCalendar turns the cron expressions launchd and systemd can express
+// into a cronCalendar: "M H * * *", "M H * * D", "M * * * *", @hourly,
+// @daily (or @midnight) and @weekly.
+func parseCalendar(schedule, backend string) (cronCalendar, error) {
+	unsupported := fmt.Errorf("--schedule %q can't be used with %s — use \"M H * * *\", \"M H * * D\", \"M * * * *\", @hourly, @daily or @weekly, or pass --backend cron", schedule, backend)
+	switch schedule {
+	case "@hourly":
+		return cronCalendar{0, -1, -1}, nil
+	case "@daily", "@midnight":
+		return cronCalendar{0, 0, -1}, nil
+	case "@weekly":
+		return cronCalendar{0, 0, 0}, nil
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 || fields[2] != "*" || fields[3] != "*" {
+		return cronCalendar{}, unsupported
+	}
+	field := func(s string, max int) (int, bool) {
+		if s == "*" {
+			return -1, true
+		}
+		n, err := strconv.Atoi(s)
+		return n, err == nil && n >= 0 && n <= max
+	}
+	minute, ok1 := field(fields[0], 59)
+	hour, ok2 := field(fields[1], 23)
+	weekday, ok3 := field(fields[4], 7)
+	if !ok1 || !ok2 || !ok3 || minute < 0 || (hour < 0 && weekday >= 0) {
+		return cronCalendar{}, unsupported
+	}
+	return cronCalendar{minute, hour, weekday % 7}, nil
+}
+
+// onCalendar is c as a systemd OnCalendar expression, e.g. "Mon *-*-* 03:00:00".
+func (c cronCalendar) onCalendar() string {
+	day := ""
+	if c.weekday >= 0 {
+		day = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}[c.weekday] + " "
+	}
+	hour := "*"
+	if c.hour >= 0 {
+		hour = fmt.Sprintf("%02d", c.hour)
+	}
+	return fmt.Sprintf("%s*-*-* %s:%02d:00", day, hour, c.minute)
+}
+
+// cronJobName names the project's job for launchd and systemd, which need
+// a plain name rather than the root path.
+func cronJobName(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return "grove-maintenance-" + hex.EncodeToString(sum[:4])
+}
+
+// systemdAvailable reports whether a systemd user manager is running to
+// take a timer.
+func systemdAvailable() bool {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	return exec.Command("systemctl", "--user", "show-environment").Run() == nil
+}
+
+// systemdUnitDir is where systemd looks for the user's own units.
+func systemdUnitDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user"), nil
+}
+
+// systemdWord is s as one word on an ExecStart line: quoted when it has
+// spaces or quotes, with systemd's % specifiers and $ variables escaped.
+func systemdWord(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// systemdUnits returns the service and timer units running steps from root
+// at cal. Each step is prefixed with "-", so a failed one doesn't stop the
+// rest.
+func systemdUnits(root string, cal cronCalendar, steps [][]string) (service, timer string) {
+	escaped := strings.ReplaceAll(root, "%", "%%")
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=grove maintenance for %s\n\n[Service]\nType=oneshot\nWorkingDirectory=%s\n", escaped, escaped)
+	for _, step := range steps {
+		words := make([]string, len(step))
+		for i, word := range step {
+			words[i] = systemdWord(word)
+		}
+		fmt.Fprintf(&b, "ExecStart=-%s\n", strings.Join(words, " "))
+	}
+	timer = fmt.Sprintf("[Unit]\nDescription=Run grove maintenance for %s\n\n[Timer]\nOnCalendar=%s\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n", escaped, cal.onCalendar())
+	return b.String(), timer
+}
+
+func installSystemd(root, schedule string, steps [][]string) (string, error) {
+	cal, err := parseCalendar(schedule, cronSystemd)
+	if err != nil {
+		return "", err
+	}
+	dir, err := systemdUnitDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := cronJobName(root)
+	service, timer := systemdUnits(root, cal, steps)
+	if err := os.WriteFile(filepath.Join(dir, name+".service"), []byte(service), 0644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".timer"), []byte(timer), 0644); err != nil {
+		return "", err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return "", err
+	}
+	if err := systemctl("enable", "--now", name+".timer"); err != nil {
+		return "", err
+	}
+	return "systemd timer " + name + ".timer", nil
+}
+
+func systemdStatus(root string) (string, bool, error) {
+	dir, err := systemdUnitDir()
+	if err != nil {
+		return "", false, err
+	}
+	path := filepath.Join(dir, cronJobName(root)+".timer")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if when, ok := strings.CutPrefix(line, "OnCalendar="); ok {
+			return fmt.Sprintf("systemd timer %s (%s)", displayPath(path), when), true, nil
+		}
+	}
+	return "systemd timer " + displayPath(path), true, nil
+}
+
+func removeSystemd(root string) (bool, error) {
+	dir, err := systemdUnitDir()
+	if err != nil {
+		return false, err
+	}
+	name := cronJobName(root)
+	timer := filepath.Join(dir, name+".timer")
+	if _, err := os.Stat(timer); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err := systemctl("disable", "--now", name+".timer"); err != nil {
+		return false, err
+	}
+	for _, path := range []string{timer, filepath.Join(dir, name+".service")} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	return true, systemctl("daemon-reload")
+}
+
+func systemctl(args ...string) error {
+	args = append([]string{"--user"}, args...)
+	if out, err := exec.Command("systemctl", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// launchdPlist returns the launch agent running steps from root at cal,
+// through sh so each step runs even if the one before failed.
+func launchdPlist(label, root string, cal cronCalendar, steps [][]string) string {
+	esc := func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	var interval strings.Builder
+	for _, key := range []struct {
+		name  string
+		value int
+	}{{"Minute", cal.minute}, {"Hour", cal.hour}, {"Weekday", cal.weekday}} {
+		if key.value >= 0 {
+			fmt.Fprintf(&interval, "\t\t<key>%s</key>\n\t\t<integer>%d</integer>\n", key.name, key.value)
+		}
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/bin/sh</string>
+		<string>-c</string>
+		<string>%s</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>StartCalendarInterval</key>
+	<dict>
+%s	</dict>
+</dict>
+</plist>
+`, esc(label), esc(maintenanceScript(steps)), esc(root), interval.String())
+}
+
+// launchdPath is where the project's launch agent lives.
+func launchdPath(root string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", "dev."+cronJobName(root)+".plist"), nil
+}
+
+func installLaunchd(root, schedule string, steps [][]string) (string, error) {
+	cal, err := parseCalendar(schedule, cronLaunchd)
+	if err != nil {
+		return "", err
+	}
+	path, err := launchdPath(root)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	label := strings.TrimSuffix(filepath.Base(path), ".plist")
+	// Unloaded first, so launchd picks up a changed schedule.
+	exec.Command("launchctl", "unload", path).Run()
+	if err := os.WriteFile(path, []byte(launchdPlist(label, root, cal, steps)), 0644); err != nil {
+		return "", err
+	}
+	if out, err := exec.Command("launchctl", "load", "-w", path).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("launchctl load: %s", strings.TrimSpace(string(out)))
+	}
+	return "launchd agent " + label, nil
+}
+
+func launchdStatus(root string) (string, bool, error) {
+	path, err := launchdPath(root)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return "launchd agent " + displayPath(path), true, nil
+}
+
+func removeLaunchd(root string) (bool, error) {
+	path, err := launchdPath(root)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if out, err := exec.Command("launchctl", "unload", "-w", path).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("launchctl unload: %s", strings.TrimSpace(string(out)))
+	}
+	return true, os.Remove(path)
+}
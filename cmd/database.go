@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+)
+
+// unsafeDBChars are replaced in template values that end up in database
+// names — "feature/auth-v2" must not turn into an invalid identifier.
+var unsafeDBChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// expandTemplate replaces {{key}} placeholders with values from vars.
+func expandTemplate(tpl string, vars map[string]string) string {
+	for k, v := range vars {
+		tpl = strings.ReplaceAll(tpl, "{{"+k+"}}", v)
+	}
+	return tpl
+}
+
+// expandCommand is expandTemplate for a command run by sh -c. Values are
+// substituted as single words, so a branch named "x;$(curl ...)" is passed
+// along rather than run.
+func expandCommand(command string, vars map[string]string) string {
+	quoted := make(map[string]string, len(vars))
+	for k, v := range vars {
+		quoted[k] = shellArg(v)
+	}
+	return expandTemplate(command, quoted)
+}
+
+// databaseName renders the configured database name for a worktree.
+func databaseName(db config.DatabaseConfig, prefix, alias, branch string) string {
+	vars := map[string]string{
+		"alias":  unsafeDBChars.ReplaceAllString(alias, "_"),
+		"branch": unsafeDBChars.ReplaceAllString(branch, "_"),
+		"prefix": unsafeDBChars.ReplaceAllString(prefix, "_"),
+	}
+	return expandTemplate(db.Name, vars)
+}
+
+// setupDatabase creates the worktree's database and writes its settings
+// into the env files copied to the worktree. Returns the database name.
+func setupDatabase(root string, cfg config.Config, alias, branch, path string, envFiles []string, w io.Writer) (string, error) {
+	db := cfg.Database
+	name := databaseName(db, cfg.Prefix, alias, branch)
+	vars := map[string]string{"name": name, "alias": alias, "branch": branch, "prefix": cfg.Prefix}
+
+	if db.Create != "" {
+		command := expandCommand(db.Create, vars)
+		fmt.Fprintf(w, "  running: %s\n", command)
+		env := append(hookEnv(root, alias, branch, path), "GROVE_DB_NAME="+name)
+		if err := runHook(cfg.Hooks, command, path, env, w); err != nil {
+			return name, fmt.Errorf("database create command failed: %w", err)
+		}
+	}
+
+	if err := injectEnv(path, envFiles, db.Env, vars); err != nil {
+		return name, err
+	}
+	return name, nil
+}
+
+// injectEnv expands the templated values in env and sets them in the env
+// files copied to the worktree at path. Keys no copied file defines go into
+// the worktree's top-level .env.
+func injectEnv(path string, envFiles []string, env, vars map[string]string) error {
+	if len(env) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(env))
+	for k, v := range env {
+		values[k] = expandTemplate(v, vars)
+	}
+	set := make(map[string]bool)
+	for _, rel := range envFiles {
+		keys, err := files.SetEnvVars(filepath.Join(path, rel), values, false)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			set[k] = true
+		}
+	}
+	missing := make(map[string]string)
+	for k, v := range values {
+		if !set[k] {
+			missing[k] = v
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	_, err := files.SetEnvVars(filepath.Join(path, ".env"), missing, true)
+	return err
+}
+
+// dropDatabase runs the configured drop command for a worktree's database.
+func dropDatabase(root string, cfg config.Config, alias, branch, path, name string, w io.Writer) error {
+	if cfg.Database.Drop == "" {
+		return nil
+	}
+	vars := map[string]string{"name": name, "alias": alias, "branch": branch, "prefix": cfg.Prefix}
+	command := expandCommand(cfg.Database.Drop, vars)
+	fmt.Fprintf(w, "  running: %s\n", command)
+	env := append(hookEnv(root, alias, branch, path), "GROVE_DB_NAME="+name)
+	if err := runHook(cfg.Hooks, command, path, env, w); err != nil {
+		return fmt.Errorf("database drop command failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestDatabaseCommandQuotesBranch(t *testing.T) {
+	dir := t.TempDir()
+	branch := "x;touch pwned;$(touch pwned2)"
+	cfg := config.Config{Database: config.DatabaseConfig{
+		Name:   "app_{{branch}}",
+		Create: "printf '%s\\n' {{branch}} {{name}} > created",
+	}}
+	name, err := setupDatabase(dir, cfg, "x", branch, dir, nil, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"pwned", "pwned2"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+			t.Errorf("the branch name ran a command: %s exists", f)
+		}
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "created"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(out)), branch+"\n"+name; got != want {
+		t.Errorf("create command got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/i18n"
+)
+
+// trashRefPrefix is where snapshots of force-removed worktrees are kept.
+const trashRefPrefix = "refs/grove/trash/"
+
+// trashRef is the ref a snapshot of label is kept under. Labels aren't
+// always valid ref names — an orphan's is "(detached abc1234)" — so anything
+// git wouldn't accept in a ref becomes "-".
+func trashRef(label string) string {
+	var parts []string
+	for _, part := range strings.Split(label, "/") {
+		part = strings.Map(func(r rune) rune {
+			if r == '.' || r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '-'
+		}, part)
+		part = strings.ReplaceAll(part, "..", "-")
+		part = strings.TrimSuffix(part, ".lock")
+		if part = strings.Trim(part, "-."); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return trashRefPrefix + "worktree"
+	}
+	return trashRefPrefix + strings.Join(parts, "/")
+}
+
+// handleDirty applies the onDirtyRemove policy to a single worktree with
+// uncommitted changes. Returns true if removal should go ahead (with force);
+// by then the changes have been saved with preserveChanges, whose snapshot
+// commit is returned as well.
+func handleDirty(policy, label, path string, status git.WorktreeStatus) (bool, string, error) {
+	// git stash refuses unmerged files, so don't get as far as trying.
+	if policy == config.DirtyStash && status.Conflicted > 0 {
+		return false, "", errorf(git.ErrDirty, "worktree %q has %s, which can't be stashed — finish or abort the merge first, or pass --force", label, status)
+	}
+	switch policy {
+	case config.DirtyBlock:
+		return false, "", errorf(git.ErrDirty, "worktree %q has %s — commit or stash it first, or pass --force (onDirtyRemove is \"block\")", label, status)
+	case config.DirtyPrompt:
+		fmt.Printf(i18n.T("Worktree %q has %s.\n"), label, status)
+		answer := prompt(i18n.T("Remove anyway? [y/N]"), "n")
+		if answer != "y" && answer != "Y" {
+			return false, "", nil
+		}
+	}
+
+	snapshot, err := preserveChanges(policy, label, path)
+	if err != nil {
+		return false, "", err
+	}
+	return true, snapshot, nil
+}
+
+// confirmForcedRemove is the speed bump on a forced removal of a worktree
+// holding both uncommitted changes and commits nothing else has: the user
+// types its name, as when deleting a GitHub repository. --yes skips it.
+func confirmForcedRemove(label, path, branch string, status git.WorktreeStatus) error {
+	if strings.HasPrefix(branch, "(detached") {
+		branch = ""
+	}
+	unpushed, err := git.UnpushedCount(path, branch)
+	if err != nil || unpushed == 0 {
+		return nil
+	}
+	fmt.Printf(i18n.T("Worktree %q has %s and %d commit(s) that aren't pushed or on another branch.\n"), label, status, unpushed)
+	if answer := prompt(fmt.Sprintf(i18n.T("Type %s to remove it"), label), ""); answer != label {
+		return errorf(git.ErrDirty, "%q wasn't typed, nothing removed — pass --yes to skip the question", label)
+	}
+	return nil
+}
+
+// preserveChanges saves a dirty worktree's uncommitted changes before it is
+// force-removed: to the stash list with the "stash" policy, otherwise as a
+// snapshot commit under trashRef(label). An error means the changes
+// could not be saved and the worktree must not be removed — except with the
+// "force" policy, where a failed snapshot is only a warning so broken
+// worktrees can still be removed.
+// Returns the snapshot commit, or "" if the changes went to the stash list
+// or could not be saved.
+func preserveChanges(policy, label, path string) (string, error) {
+	if policy == config.DirtyStash {
+		if err := git.Stash(path, "grove: removed "+label); err != nil {
+			return "", fmt.Errorf("could not stash changes in %q, not removing: %w", label, err)
+		}
+		fmt.Printf(plain(i18n.T("  ✓ stashed changes in %s (recover with: git stash list)\n")), label)
+		return "", nil
+	}
+
+	ref := trashRef(label)
+	snapshot, err := git.Snapshot(path, ref, "grove: removed "+label)
+	if err != nil {
+		if policy == config.DirtyForce {
+			fmt.Fprintf(os.Stderr, i18n.T("  warning: could not save changes in %q: %v\n"), label, err)
+			return "", nil
+		}
+		return "", fmt.Errorf("could not save changes in %q, not removing: %w", label, err)
+	}
+	fmt.Printf(plain(i18n.T("  ✓ saved uncommitted changes to %s\n")), ref)
+	fmt.Printf(i18n.T("    recover with: git cherry-pick --no-commit %s\n"), ref)
+	return snapshot, nil
+}
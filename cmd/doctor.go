@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/version"
+)
+
+var doctorFix bool
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "repair what can be repaired safely")
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the project and grove's records for known problems",
+	Long: `Look for known problems with how this project shares dependencies
+between worktrees, and suggest the config that avoids each one:
+
+  - yarn berry: .yarn install state shared through a symlink, Plug'n'Play
+    projects symlinking node_modules
+  - pnpm: node_modules shared between worktrees, a store inside the project
+  - Metro and webpack resolving modules through a symlinked node_modules
+  - .groverc.json or .grove/state.json written by a newer grove
+
+Then cross-check .grove/state.json against 'git worktree list' and the
+filesystem:
+
+  - aliases whose directory is gone, or isn't a git worktree any more
+  - worktrees on another branch than grove recorded
+  - symlinks grove created that point nowhere
+  - worktrees git still lists after their directory was deleted, and ones
+    grove doesn't manage
+  - lock files left by a grove or git that crashed
+
+With --fix, grove repairs what it safely can: it drops dead aliases,
+records the checked-out branch, links symlinks again when their target is
+back, runs 'git worktree prune' and deletes its own stale lock. Nothing in a
+worktree's files is touched, and the rest is only reported.
+
+Exits non-zero when something was found and not fixed.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+// finding is one problem grove doctor reports, with the suggested fix.
+type finding struct {
+	problem string
+	fix     string
+	repair  func() error // applies fix for --fix; nil when it's up to the user
+}
+
+// doctorChecks run in order; each returns what it found wrong.
+var doctorChecks = []func(root string, cfg config.Config) []finding{
+	checkYarnBerry,
+	checkPnpm,
+	checkBundlers,
+	checkVersions,
+	checkLocks,
+	checkState,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	var found []finding
+	for _, check := range doctorChecks {
+		found = append(found, check(root, cfg)...)
+	}
+
+	if len(found) == 0 {
+		fmt.Println(plain("✓ No problems found."))
+		return nil
+	}
+	var left, fixable int
+	for _, f := range found {
+		if doctorFix && f.repair != nil {
+			err := f.repair()
+			if err == nil {
+				fmt.Printf(plain("  ✓ fixed: %s\n"), f.problem)
+				continue
+			}
+			f.fix = fmt.Sprintf("%s (--fix failed: %v)", f.fix, err)
+		} else if f.repair != nil {
+			fixable++
+		}
+		left++
+		fmt.Printf("warning: %s\n", f.problem)
+		fmt.Printf("  fix: %s\n", f.fix)
+	}
+	if left == 0 {
+		return nil
+	}
+	if fixable > 0 {
+		return fmt.Errorf("%d problem(s) found — 'grove doctor --fix' repairs %d of them, see the suggested fixes for the rest", left, fixable)
+	}
+	return fmt.Errorf("%d problem(s) found — see the suggested fixes above", left)
+}
+
+// checkYarnBerry covers yarn 2+, which keeps per-checkout install state
+// under .yarn and, with Plug'n'Play, doesn't use node_modules at all.
+func checkYarnBerry(root string, cfg config.Config) []finding {
+	if !fileExists(root, ".yarnrc.yml") {
+		return nil
+	}
+	var found []finding
+	if slices.Contains(cfg.Symlink, ".yarn") {
+		found = append(found, finding{
+			problem: `yarn berry: "symlink" shares all of .yarn, including install-state.gz and unplugged/, so an install in one worktree breaks the others`,
+			fix:     `symlink only the download cache: "symlink": [".yarn/cache"]`,
+		})
+	}
+	pnp := fileExists(root, ".pnp.cjs") || yarnrcValue(root, "nodeLinker") == "pnp"
+	if pnp && slices.Contains(cfg.Symlink, "node_modules") {
+		found = append(found, finding{
+			problem: `yarn berry uses Plug'n'Play here, so the symlinked node_modules isn't used and .pnp.cjs is missing in new worktrees`,
+			fix:     `share the cache and install instead: "symlink": [".yarn/cache"], "installCommand": "yarn install"`,
+		})
+	}
+	return found
+}
+
+// checkPnpm covers pnpm, whose node_modules is a tree of links into a
+// global content-addressed store — so a per-worktree install is cheap, but
+// only if the store is shared.
+func checkPnpm(root string, cfg config.Config) []finding {
+	if !fileExists(root, "pnpm-lock.yaml") {
+		return nil
+	}
+	var found []finding
+	if slices.Contains(cfg.Symlink, "node_modules") && cfg.LinkMode != config.LinkHardlink {
+		found = append(found, finding{
+			problem: "pnpm: node_modules is symlinked, so pnpm install in any worktree rewrites the dependencies of all of them",
+			fix:     `let each worktree install from the shared store: remove "node_modules" from "symlink" and set "installCommand": "pnpm install --prefer-offline"`,
+		})
+	}
+	if dir := npmrcValue(root, "store-dir"); dir != "" && !filepath.IsAbs(dir) && !strings.HasPrefix(dir, "~") {
+		found = append(found, finding{
+			problem: fmt.Sprintf("pnpm: store-dir %q in .npmrc is relative, so every worktree gets its own store and installs download everything again", dir),
+			fix:     "point store-dir at an absolute path outside the project, or remove it to use pnpm's default store",
+		})
+	}
+	return found
+}
+
+// checkBundlers covers Metro and webpack, which resolve a symlinked
+// node_modules to its real path in the main worktree: Metro refuses files
+// outside its watch folders, webpack ends up with two copies of React.
+func checkBundlers(root string, cfg config.Config) []finding {
+	if !slices.Contains(cfg.Symlink, "node_modules") || cfg.LinkMode == config.LinkHardlink {
+		return nil
+	}
+	deps := packageDeps(root)
+	var found []finding
+	if deps["react-native"] || deps["metro"] || fileExists(root, "metro.config.js") {
+		found = append(found, finding{
+			problem: "Metro resolves the symlinked node_modules into the main worktree, outside its watch folders, and fails to bundle",
+			fix:     `give each worktree its own hard-linked copy: "linkMode": "hardlink"`,
+		})
+	}
+	if deps["webpack"] || deps["next"] || fileExists(root, "webpack.config.js") {
+		found = append(found, finding{
+			problem: "webpack resolves the symlinked node_modules to the main worktree, which can bundle two copies of a package and breaks watching",
+			fix:     `use "linkMode": "hardlink", or set resolve.symlinks: false in the webpack config`,
+		})
+	}
+	return found
+}
+
+// checkVersions covers files stamped by a newer grove: this one ignores the
+// settings it doesn't know, and refuses to rewrite them.
+func checkVersions(root string, cfg config.Config) []finding {
+	var found []finding
+	if version.Newer(cfg.GroveVersion, version.Current) {
+		found = append(found, finding{
+			problem: fmt.Sprintf("%s was written by grove %s, newer than this one (%s), so settings it added are ignored", config.FileName, cfg.GroveVersion, version.Current),
+			fix:     "upgrade grove to " + cfg.GroveVersion + " or later",
+		})
+	}
+	if s, err := state.Load(root); err == nil && version.Newer(s.Version, version.Current) {
+		found = append(found, finding{
+			problem: fmt.Sprintf(".grove/state.json was written by grove %s, newer than this one (%s), so commands that change worktrees refuse to run", s.Version, version.Current),
+			fix:     "upgrade grove to " + s.Version + " or later",
+		})
+	}
+	return found
+}
+
+func fileExists(root, name string) bool {
+	_, err := os.Stat(filepath.Join(root, name))
+	return err == nil
+}
+
+// packageDeps returns the names in package.json's dependencies and
+// devDependencies. A missing or broken package.json has none.
+func packageDeps(root string) map[string]bool {
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil || json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+	deps := map[string]bool{}
+	for name := range pkg.Dependencies {
+		deps[name] = true
+	}
+	for name := range pkg.DevDependencies {
+		deps[name] = true
+	}
+	return deps
+}
+
+// yarnrcValue reads a top-level "key: value" from .yarnrc.yml. Good enough
+// for the scalar settings doctor cares about, without a YAML parser.
+func yarnrcValue(root, key string) string {
+	return configValue(filepath.Join(root, ".yarnrc.yml"), key, ":")
+}
+
+// npmrcValue reads "key=value" from the project's .npmrc.
+func npmrcValue(root, key string) string {
+	return configValue(filepath.Join(root, ".npmrc"), key, "=")
+}
+
+func configValue(path, key, sep string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), sep)
+		if ok && strings.TrimSpace(k) == key {
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return ""
+}
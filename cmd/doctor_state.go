@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// staleIndexLock is the age past which an index.lock in a worktree is
+// probably left by a git that crashed; git itself holds it for seconds.
+const staleIndexLock = 10 * time.Minute
+
+// checkState cross-checks .grove/state.json against 'git worktree list' and
+// the filesystem.
+func checkState(root string, cfg config.Config) []finding {
+	s, err := state.Load(root)
+	if err != nil {
+		return []finding{{
+			problem: fmt.Sprintf(".grove/state.json can't be read: %v", err),
+			fix:     "run 'grove repair' to restore it from a backup or rebuild it from git",
+		}}
+	}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return []finding{{
+			problem: fmt.Sprintf("'git worktree list' failed: %v", err),
+			fix:     "run grove doctor from inside the repository",
+		}}
+	}
+
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias := range s.Worktrees {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var found []finding
+	for _, alias := range aliases {
+		entry := s.Worktrees[alias]
+		wt := gitWorktreeAt(worktrees, entry.Path)
+		if wt != nil && wt.Locked {
+			continue // its storage may only be unmounted
+		}
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			found = append(found, finding{
+				problem: fmt.Sprintf("%s: %s no longer exists", alias, displayPath(entry.Path)),
+				fix:     "drop the alias and prune git's record of the worktree, as 'grove prune' does",
+				repair: func() error {
+					if err := forgetAlias(root, alias); err != nil {
+						return err
+					}
+					return git.PruneWorktrees()
+				},
+			})
+			continue
+		}
+		if wt == nil {
+			found = append(found, finding{
+				problem: fmt.Sprintf("%s: %s exists, but git doesn't know it as a worktree", alias, displayPath(entry.Path)),
+				fix:     "drop the alias; the directory is left as it is",
+				repair:  func() error { return forgetAlias(root, alias) },
+			})
+			continue
+		}
+		if wt.Branch != entry.Branch && wt.Branch != "" && !strings.HasPrefix(wt.Branch, "(detached") {
+			branch := wt.Branch
+			found = append(found, finding{
+				problem: fmt.Sprintf("%s: grove has it on %s, but %s is checked out", alias, entry.Branch, branch),
+				fix:     "record " + branch + " as its branch",
+				repair: func() error {
+					return state.Change(root, func(s *state.State) error {
+						s.Update(alias, func(e *state.WorktreeEntry) { e.Branch = branch })
+						return nil
+					})
+				},
+			})
+		}
+		found = append(found, checkSymlinks(root, alias, entry)...)
+	}
+
+	tracked := map[string]bool{}
+	for _, entry := range s.Worktrees {
+		tracked[entry.Path] = true
+	}
+	for _, wt := range worktrees {
+		if wt.IsMain || !wt.Prunable || wt.Locked || tracked[wt.Path] {
+			continue
+		}
+		found = append(found, finding{
+			problem: fmt.Sprintf("git still lists %s, whose directory is gone", displayPath(wt.Path)),
+			fix:     "run 'git worktree prune'",
+			repair:  git.PruneWorktrees,
+		})
+	}
+	if orphans, err := findOrphans(s); err == nil {
+		for _, o := range orphans {
+			if wt := gitWorktreeAt(worktrees, o.Path); wt != nil && wt.Prunable {
+				continue
+			}
+			found = append(found, finding{
+				problem: fmt.Sprintf("%s (%s) is a worktree grove doesn't manage", displayPath(o.Path), o.Branch),
+				fix:     fmt.Sprintf("'grove adopt %s' to manage it, or 'grove clean' to remove it", o.Branch),
+			})
+		}
+	}
+	return found
+}
+
+// checkSymlinks finds the links grove created in a worktree that no longer
+// point anywhere. One whose target is back in the main worktree — moved, or
+// reinstalled — can be linked again.
+func checkSymlinks(root, alias string, entry state.WorktreeEntry) []finding {
+	if entry.Setup == nil {
+		return nil
+	}
+	var found []finding
+	for _, rel := range entry.Setup.Symlinks {
+		link := filepath.Join(entry.Path, rel)
+		info, err := os.Lstat(link)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		if _, err := os.Stat(link); err == nil {
+			continue
+		}
+		f := finding{
+			problem: fmt.Sprintf("%s: the symlink %s points nowhere", alias, rel),
+			fix:     fmt.Sprintf("create %s in the main worktree (e.g. install there), or delete the link", rel),
+		}
+		if fileExists(root, rel) {
+			f.fix = fmt.Sprintf("link it to %s again", displayPath(filepath.Join(root, rel)))
+			f.repair = func() error {
+				if err := os.Remove(link); err != nil {
+					return err
+				}
+				_, err := files.Symlink(root, entry.Path, rel)
+				return err
+			}
+		}
+		found = append(found, f)
+	}
+	return found
+}
+
+// checkLocks finds lock files left behind by a grove or git that crashed.
+func checkLocks(root string, cfg config.Config) []finding {
+	var found []finding
+	if path := state.StaleLock(root); path != "" {
+		found = append(found, finding{
+			problem: fmt.Sprintf("%s is left over from a grove that didn't finish", displayPath(path)),
+			fix:     "delete it",
+			repair: func() error {
+				// Gone already if another fix took the lock, which clears it.
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				return nil
+			},
+		})
+	}
+
+	// Deleting git's lock under a running git would corrupt the index, and
+	// there's no telling whether one is running, so these are only reported.
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return found
+	}
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			continue
+		}
+		gitDir, err := worktreeGitDir(wt.Path)
+		if err != nil {
+			continue
+		}
+		lock := filepath.Join(gitDir, "index.lock")
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleIndexLock {
+			found = append(found, finding{
+				problem: fmt.Sprintf("%s is %s old, so git commands in %s fail", displayPath(lock), time.Since(info.ModTime()).Round(time.Minute), displayPath(wt.Path)),
+				fix:     "if no git is running there, delete it",
+			})
+		}
+	}
+	return found
+}
+
+// forgetAlias drops alias from the state.
+func forgetAlias(root, alias string) error {
+	return state.Change(root, func(s *state.State) error {
+		return s.Remove(alias)
+	})
+}
+
+// gitWorktreeAt returns the worktree git lists at path, or nil.
+func gitWorktreeAt(worktrees []git.Worktree, path string) *git.Worktree {
+	for i := range worktrees {
+		if worktrees[i].Path == path {
+			return &worktrees[i]
+		}
+	}
+	for i := range worktrees {
+		if samePath(worktrees[i].Path, path) {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// worktreeGitDir returns the git directory of the worktree at path: .git
+// itself in the main worktree, the directory a linked worktree's .git file
+// points to otherwise.
+func worktreeGitDir(path string) (string, error) {
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s: not a gitdir file", dotGit)
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return dir, nil
+}
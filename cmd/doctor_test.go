@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/version"
+)
+
+func TestDoctorChecks(t *testing.T) {
+	write := func(t *testing.T, root, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := version.Current
+	version.Current = "v0.5.0"
+	t.Cleanup(func() { version.Current = old })
+	nodeModules := config.Config{Symlink: []string{"node_modules"}}
+	hardlink := config.Config{Symlink: []string{"node_modules"}, LinkMode: config.LinkHardlink}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		cfg   config.Config
+		check func(string, config.Config) []finding
+		want  []string // substrings of the problems found, in order
+	}{
+		{
+			name:  "yarn berry sharing all of .yarn",
+			files: map[string]string{".yarnrc.yml": "nodeLinker: node-modules\n"},
+			cfg:   config.Config{Symlink: []string{".yarn"}},
+			check: checkYarnBerry,
+			want:  []string{"install-state.gz"},
+		},
+		{
+			name:  "yarn berry pnp with node_modules",
+			files: map[string]string{".yarnrc.yml": "nodeLinker: \"pnp\"\n"},
+			cfg:   nodeModules,
+			check: checkYarnBerry,
+			want:  []string{"Plug'n'Play"},
+		},
+		{
+			name:  "yarn classic is fine",
+			files: map[string]string{"yarn.lock": ""},
+			cfg:   config.Config{Symlink: []string{".yarn", "node_modules"}},
+			check: checkYarnBerry,
+		},
+		{
+			name:  "pnpm with symlinked node_modules and a relative store",
+			files: map[string]string{"pnpm-lock.yaml": "", ".npmrc": "store-dir=.pnpm-store\n"},
+			cfg:   nodeModules,
+			check: checkPnpm,
+			want:  []string{"rewrites the dependencies", `store-dir ".pnpm-store"`},
+		},
+		{
+			name:  "pnpm with an absolute store",
+			files: map[string]string{"pnpm-lock.yaml": "", ".npmrc": "store-dir = /var/cache/pnpm\n"},
+			cfg:   config.Config{},
+			check: checkPnpm,
+		},
+		{
+			name:  "react native and next",
+			files: map[string]string{"package.json": `{"dependencies": {"react-native": "0.74.0"}, "devDependencies": {"next": "14.0.0"}}`},
+			cfg:   nodeModules,
+			check: checkBundlers,
+			want:  []string{"Metro", "webpack"},
+		},
+		{
+			name:  "bundlers with hardlink mode",
+			files: map[string]string{"metro.config.js": ""},
+			cfg:   hardlink,
+			check: checkBundlers,
+		},
+		{
+			name:  "config and state from a newer grove",
+			files: map[string]string{".grove/state.json": `{"worktrees": {}, "groveVersion": "v0.7.0"}`},
+			cfg:   config.Config{GroveVersion: "v0.6.0"},
+			check: checkVersions,
+			want:  []string{"grove v0.6.0", "grove v0.7.0"},
+		},
+		{
+			name:  "files from this grove or a dev build",
+			files: map[string]string{".grove/state.json": `{"worktrees": {}, "groveVersion": "3f2a9c1e-dirty"}`},
+			cfg:   config.Config{GroveVersion: "v0.5.0"},
+			check: checkVersions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				write(t, root, name, content)
+			}
+			found := tt.check(root, tt.cfg)
+			if len(found) != len(tt.want) {
+				t.Fatalf("found %d problems, want %d: %+v", len(found), len(tt.want), found)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(found[i].problem, want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, found[i].problem, want)
+				}
+				if found[i].fix == "" {
+					t.Errorf("problem %d has no suggested fix", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDoctorFixState(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{"shared"}})
+	if err := os.Mkdir(filepath.Join(dir, "shared"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	createName, createFrom = "", ""
+	for _, branch := range []string{"feature/gone", "feature/drift"} {
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gone := filepath.Join(filepath.Dir(dir), "testproject-gone")
+	drift := filepath.Join(filepath.Dir(dir), "testproject-drift")
+	t.Cleanup(func() { gitCmd(dir, "worktree", "remove", "--force", drift) })
+
+	// A deleted directory, a branch switched behind grove's back, a link
+	// into a main worktree that has since moved, and a crashed grove's lock.
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := gitCmd(drift, "switch", "-c", "feature/other"); err != nil {
+		t.Fatalf("switch: %v\n%s", err, out)
+	}
+	link := filepath.Join(drift, "shared")
+	os.Remove(link)
+	if err := os.Symlink(filepath.Join(t.TempDir(), "moved", "shared"), link); err != nil {
+		t.Fatal(err)
+	}
+	lock := filepath.Join(dir, ".grove", "state.lock")
+	if err := os.WriteFile(lock, []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lock, old, old)
+
+	found := append(checkState(dir, config.Config{}), checkLocks(dir, config.Config{})...)
+	var problems []string
+	for _, f := range found {
+		if f.repair == nil {
+			t.Errorf("%q should be fixable", f.problem)
+		}
+		problems = append(problems, f.problem)
+	}
+	for _, want := range []string{"gone:", "feature/other is checked out", "symlink shared", "state.lock"} {
+		if !strings.Contains(strings.Join(problems, "\n"), want) {
+			t.Errorf("no problem mentions %q: %q", want, problems)
+		}
+	}
+
+	doctorFix = true
+	t.Cleanup(func() { doctorFix = false })
+	if err := runDoctor(doctorCmd, nil); err != nil {
+		t.Fatalf("doctor --fix: %v", err)
+	}
+
+	s, _ := state.Load(dir)
+	if s.AliasExists("gone") {
+		t.Error("the alias of the deleted worktree should be dropped")
+	}
+	if entry, _ := s.Get("drift"); entry.Branch != "feature/other" {
+		t.Errorf("drift branch = %q, want feature/other", entry.Branch)
+	}
+	if _, err := os.Stat(link); err != nil {
+		t.Errorf("symlink should resolve again: %v", err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Error("the stale lock should be deleted")
+	}
+}
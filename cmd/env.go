@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/registry"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print grove's view of the environment",
+	Long: `Print everything grove resolved about the current environment: project root,
+config file and effective values, state file, git binary and version, and
+platform quirks that affect grove's behavior.
+
+Include this output when filing a bug. Use --json for a machine-readable version.`,
+	Args: cobra.NoArgs,
+	RunE: runEnv,
+}
+
+// envReport is grove's resolved view of the environment.
+type envReport struct {
+	Version    string         `json:"version"`
+	Platform   string         `json:"platform"`
+	Cwd        string         `json:"cwd"`
+	Root       string         `json:"root,omitempty"`
+	RootError  string         `json:"rootError,omitempty"`
+	ConfigPath string         `json:"configPath,omitempty"`
+	Config     *config.Config `json:"config,omitempty"`
+	ConfigErr  string         `json:"configError,omitempty"`
+	StatePath  string         `json:"statePath,omitempty"`
+	Worktrees  int            `json:"worktrees"`
+	Registry   string         `json:"registry,omitempty"`
+	GitBinary  string         `json:"gitBinary"`
+	GitArgs    []string       `json:"gitArgs,omitempty"`
+	GitVersion string         `json:"gitVersion"`
+	Quirks     []string       `json:"quirks,omitempty"`
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	r, err := collectEnv()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Version:     %s\n", r.Version)
+	fmt.Printf("Platform:    %s\n", r.Platform)
+	fmt.Printf("Cwd:         %s\n", r.Cwd)
+	if r.RootError != "" {
+		fmt.Printf("Root:        (not found: %s)\n", r.RootError)
+	} else {
+		fmt.Printf("Root:        %s\n", r.Root)
+		fmt.Printf("Config:      %s\n", r.ConfigPath)
+		fmt.Printf("State:       %s (%d worktree(s))\n", r.StatePath, r.Worktrees)
+	}
+	if r.Registry != "" {
+		fmt.Printf("Registry:    %s\n", r.Registry)
+	}
+	fmt.Printf("Git:         %s (%s)\n", r.GitBinary, r.GitVersion)
+	if len(r.GitArgs) > 0 {
+		fmt.Printf("Git args:    %s\n", strings.Join(r.GitArgs, " "))
+	}
+
+	if r.ConfigErr != "" {
+		fmt.Printf("\nConfig error: %s\n", r.ConfigErr)
+	} else if r.Config != nil {
+		data, err := json.MarshalIndent(r.Config, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nEffective config:\n  %s\n", data)
+	}
+
+	if len(r.Quirks) > 0 {
+		fmt.Println("\nPlatform notes:")
+		for _, q := range r.Quirks {
+			fmt.Printf("  - %s\n", q)
+		}
+	}
+	return nil
+}
+
+func collectEnv() (envReport, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return envReport{}, err
+	}
+
+	r := envReport{
+		Version:   Version,
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Cwd:       cwd,
+		GitBinary: git.Binary(),
+		GitArgs:   git.GlobalArgs(),
+	}
+	if r.Version == "" {
+		r.Version = "unknown"
+	}
+
+	if p, err := registry.Path(); err == nil {
+		r.Registry = p
+	}
+
+	if v, err := git.Version(); err == nil {
+		r.GitVersion = strings.TrimPrefix(v, "git version ")
+	} else {
+		r.GitVersion = "unavailable: " + err.Error()
+	}
+
+	root, err := config.FindRoot(cwd)
+	if err != nil {
+		r.RootError = err.Error()
+	} else {
+		r.Root = root
+		r.ConfigPath = filepath.Join(root, config.FileName)
+		r.StatePath = state.Path(root)
+
+		if cfg, err := config.Load(root); err != nil {
+			r.ConfigErr = err.Error()
+		} else {
+			r.Config = &cfg
+		}
+		if s, err := state.Load(root); err == nil {
+			r.Worktrees = len(s.Worktrees)
+		}
+	}
+
+	r.Quirks = platformQuirks()
+	return r, nil
+}
+
+// platformQuirks lists environment details that commonly explain odd behavior.
+func platformQuirks() []string {
+	var quirks []string
+
+	if data, err := os.ReadFile("/proc/version"); err == nil && strings.Contains(strings.ToLower(string(data)), "microsoft") {
+		quirks = append(quirks, "running under WSL — worktrees on /mnt/* drives are slow and case-insensitive")
+	}
+
+	tmp := os.TempDir()
+	if resolved, err := filepath.EvalSymlinks(tmp); err == nil && resolved != tmp {
+		quirks = append(quirks, fmt.Sprintf(plain("temp dir is a symlink (%s → %s); grove compares resolved paths"), tmp, resolved))
+	}
+
+	if runtime.GOOS == "windows" {
+		quirks = append(quirks, "afterCreate runs via 'sh -c' and needs a POSIX shell on PATH")
+	}
+
+	if vars := git.ScrubbedVars(); len(vars) > 0 {
+		quirks = append(quirks, fmt.Sprintf("%s set in the environment — ignored for grove's git calls", strings.Join(vars, ", ")))
+	}
+
+	for _, name := range []string{"GROVE_GIT", "GROVE_GIT_ARGS"} {
+		if v := os.Getenv(name); v != "" {
+			quirks = append(quirks, fmt.Sprintf("%s=%s overrides the git config", name, v))
+		}
+	}
+
+	if !isatty.IsTerminal(os.Stdout.Fd()) {
+		quirks = append(quirks, "stdout is not a terminal — git progress output is suppressed")
+	}
+
+	return quirks
+}
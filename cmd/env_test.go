@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestCollectEnv(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+	})
+	t.Setenv("GIT_DIR", "/nowhere")
+
+	r, err := collectEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Root != dir {
+		t.Errorf("root = %q, want %q", r.Root, dir)
+	}
+	if r.ConfigPath != filepath.Join(dir, config.FileName) {
+		t.Errorf("config path = %q", r.ConfigPath)
+	}
+	if r.Config == nil || r.Config.Prefix != "testproject" {
+		t.Errorf("expected loaded config with prefix testproject, got %+v", r.Config)
+	}
+	if r.GitVersion == "" || strings.HasPrefix(r.GitVersion, "unavailable") {
+		t.Errorf("git version = %q", r.GitVersion)
+	}
+
+	found := false
+	for _, q := range r.Quirks {
+		if strings.Contains(q, "GIT_DIR") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a quirk mentioning GIT_DIR, got %v", r.Quirks)
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// Exit codes are part of grove's public contract — scripts and editor
+// integrations branch on them, so never renumber an existing code.
+const (
+	exitOK          = 0
+	exitError       = 1 // anything not covered below
+	exitNotFound    = 2 // alias, branch, path or index doesn't match a worktree
+	exitDirty       = 3 // worktree has uncommitted changes
+	exitNoConfig    = 4 // no .groverc.json for this project
+	exitAliasExists = 5 // alias is already taken
+	exitNothingToDo = 6 // --exit-code was passed and there was nothing to do
+	exitClaimed     = 7 // worktree is claimed by someone else (grove claim)
+	exitLocked      = 8 // worktree is locked (grove lock)
+)
+
+// errNothingToDo is returned by commands run with --exit-code when there was
+// nothing to clean, prune or adopt. The command has already said so on
+// stdout, so Execute doesn't print it again.
+var errNothingToDo = errors.New("nothing to do")
+
+// nothingToDo returns errNothingToDo when --exit-code semantics are enabled,
+// nil otherwise.
+func nothingToDo(exitCodeFlag bool) error {
+	if !exitCodeFlag {
+		return nil
+	}
+	return errNothingToDo
+}
+
+// commandExit is returned when a command grove ran on the user's behalf
+// (grove exec) exits non-zero. Its code becomes grove's exit code, and the
+// command has already reported its own error, so Execute prints nothing.
+type commandExit struct {
+	code int
+}
+
+func (e commandExit) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.code)
+}
+
+// codedError keeps a human-readable message while letting errors.Is match
+// the sentinel it was created for. Use it when the message shouldn't mention
+// the sentinel's own text (e.g. "no worktree with alias ..." vs "not found").
+type codedError struct {
+	sentinel error
+	msg      string
+}
+
+func (e codedError) Error() string {
+	return e.msg
+}
+
+func (e codedError) Unwrap() error {
+	return e.sentinel
+}
+
+// errorf formats a message and tags it with sentinel for exit code mapping.
+func errorf(sentinel error, format string, args ...any) error {
+	return codedError{sentinel: sentinel, msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	var ce commandExit
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &ce):
+		return ce.code
+	case errors.Is(err, state.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, git.ErrDirty):
+		return exitDirty
+	case errors.Is(err, config.ErrNoConfig):
+		return exitNoConfig
+	case errors.Is(err, state.ErrAliasExists):
+		return exitAliasExists
+	case errors.Is(err, errNothingToDo):
+		return exitNothingToDo
+	case errors.Is(err, state.ErrClaimed):
+		return exitClaimed
+	case errors.Is(err, git.ErrLocked):
+		return exitLocked
+	default:
+		return exitError
+	}
+}
+
+// errorCodeNames are the stable identifiers used for "code" in JSON errors.
+var errorCodeNames = map[int]string{
+	exitError:       "error",
+	exitNotFound:    "not_found",
+	exitDirty:       "dirty",
+	exitNoConfig:    "no_config",
+	exitAliasExists: "alias_exists",
+	exitNothingToDo: "nothing_to_do",
+	exitClaimed:     "claimed",
+	exitLocked:      "locked",
+}
+
+// jsonError is the shape of an error printed in --json mode.
+type jsonError struct {
+	Code     string `json:"code"`
+	ExitCode int    `json:"exitCode"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// newJSONError splits a grove error message into message and hint.
+// Messages follow the "what went wrong — what to do" convention, so
+// everything after the first " — " is treated as the hint.
+func newJSONError(err error) jsonError {
+	code := exitCode(err)
+	msg, hint, _ := strings.Cut(err.Error(), " — ")
+	return jsonError{
+		Code:     errorCodeNames[code],
+		ExitCode: code,
+		Message:  msg,
+		Hint:     hint,
+	}
+}
+
+// writeError prints err to w, as JSON when --json is active.
+func writeError(w io.Writer, err error) {
+	if !jsonOutput {
+		fmt.Fprintln(w, err)
+		return
+	}
+	data, mErr := json.Marshal(newJSONError(err))
+	if mErr != nil {
+		fmt.Fprintln(w, err)
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
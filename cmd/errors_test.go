@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"generic", errors.New("boom"), exitError},
+		{"not found", errorf(state.ErrNotFound, "no worktree with alias %q", "x"), exitNotFound},
+		{"dirty", fmt.Errorf("%w: git worktree remove", git.ErrDirty), exitDirty},
+		{"no config", config.ErrNoConfig, exitNoConfig},
+		{"alias exists", errorf(state.ErrAliasExists, "alias %q already exists", "x"), exitAliasExists},
+		{"nothing to do", nothingToDo(true), exitNothingToDo},
+		{"nothing to do without flag", nothingToDo(false), exitOK},
+		{"claimed", errorf(state.ErrClaimed, "auth is claimed by ci-42"), exitClaimed},
+		{"locked", fmt.Errorf("%w: git worktree remove", git.ErrLocked), exitLocked},
+		{"exec'd command", commandExit{code: 42}, 42},
+	}
+
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCodedErrorKeepsMessage(t *testing.T) {
+	err := errorf(state.ErrNotFound, "no worktree with alias %q", "auth")
+	if err.Error() != `no worktree with alias "auth"` {
+		t.Errorf("message = %q", err.Error())
+	}
+	if !errors.Is(err, state.ErrNotFound) {
+		t.Error("expected errors.Is to match ErrNotFound")
+	}
+}
+
+func TestNewJSONErrorSplitsHint(t *testing.T) {
+	err := errorf(state.ErrNotFound, "no worktree with alias %q — run 'grove list' to see available worktrees", "auth")
+
+	got := newJSONError(err)
+	if got.Code != "not_found" {
+		t.Errorf("code = %q, want %q", got.Code, "not_found")
+	}
+	if got.ExitCode != exitNotFound {
+		t.Errorf("exitCode = %d, want %d", got.ExitCode, exitNotFound)
+	}
+	if got.Message != `no worktree with alias "auth"` {
+		t.Errorf("message = %q", got.Message)
+	}
+	if got.Hint != "run 'grove list' to see available worktrees" {
+		t.Errorf("hint = %q", got.Hint)
+	}
+}
+
+func TestWriteErrorJSON(t *testing.T) {
+	jsonOutput = true
+	t.Cleanup(func() { jsonOutput = false })
+
+	var buf bytes.Buffer
+	writeError(&buf, errors.New("boom"))
+
+	var got jsonError
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%q)", err, buf.String())
+	}
+	if got.Code != "error" || got.Message != "boom" || got.Hint != "" {
+		t.Errorf("unexpected JSON error: %+v", got)
+	}
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	execInteractive bool
+	execEnvFiles    []string
+	execDotenv      bool
+	execAll         bool
+)
+
+func init() {
+	execCmd.Flags().BoolVarP(&execInteractive, "interactive", "i", false, "attach the terminal to the command (for psql, rails console, vim, ...)")
+	execCmd.Flags().StringArrayVar(&execEnvFiles, "env-file", nil, "load variables from this env file, relative to the worktree (repeatable)")
+	execCmd.Flags().BoolVar(&execDotenv, "dotenv", false, "load the worktree's .env and .env.local, like dotenv-based dev tooling does")
+	execCmd.Flags().BoolVar(&execAll, "all", false, "run the command in every managed worktree, --jobs at a time")
+	execAge.addFlags(execCmd)
+	rootCmd.AddCommand(execCmd)
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec <name>|--all -- <command> [args...]",
+	Short: "Run a command inside one or all worktrees",
+	Long: `Run a command in a worktree's directory without cd-ing there. Accepts an
+alias, branch or path. The command gets the same GROVE_* variables as hooks,
+and grove exits with the command's exit code.
+
+With --interactive the command takes over the terminal — stdin included — so
+REPLs and editors work as if started by hand. Ctrl-C and Ctrl-Z go to the
+command, not to grove.
+
+--env-file and --dotenv load env files into the command's environment, so
+one-off commands see the same settings as the project's dev tooling. Later
+files win over earlier ones, and all of them over the inherited environment.
+
+With --all the command runs in every managed worktree, up to --jobs at
+once, and each line of its output is prefixed with "[alias] ". Commands
+that write to the shared repository, like git fetch, can trip over each
+other's locks — pass --jobs 1 to run them one after another. A failure doesn't
+stop the rest; a summary of the failed worktrees goes to stderr, and grove
+exits with the highest exit code among them. --json prints each worktree's
+exit code, and sends the commands' output to stderr. --since and
+--not-since narrow --all down by when each worktree last had a commit, e.g.
+--not-since 2w for the ones left alone for two weeks.
+
+Usage:
+  grove exec auth -- npm test
+  grove exec -i auth -- psql
+  grove exec --dotenv auth -- node scripts/migrate.js
+  grove exec --all -- git fetch`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if execAll {
+			return cobra.MinimumNArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
+	ValidArgsFunction: completeExec,
+	RunE:              runExec,
+}
+
+// completeExec completes the worktree name, then leaves the command and its
+// arguments to the shell's own completion.
+func completeExec(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 && !execAll {
+		return completeAliases(cmd, args, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	if execAge.set() && !execAll {
+		return fmt.Errorf("--since and --not-since pick worktrees for --all — add --all, or drop them")
+	}
+	if execAll {
+		if execInteractive {
+			return fmt.Errorf("--interactive can't be combined with --all — run the command in one worktree at a time")
+		}
+		return execEverywhere(root, cfg, s, args)
+	}
+	query, err := expandRowRef(root, args[0])
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil {
+		return errorf(state.ErrNotFound, "no worktree matches %q — run 'grove list' to see available worktrees", args[0])
+	}
+
+	if execInteractive && !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("--interactive needs a terminal on stdin — drop it when piping input")
+	}
+
+	c, err := execCommand(root, cfg, s, resolved.Alias, resolved.Branch, resolved.Path, args[1:])
+	if err != nil {
+		return err
+	}
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if execInteractive {
+		c.Stdin = os.Stdin
+	}
+	return runForwardingSignals(c)
+}
+
+// execCommand prepares command to run in the worktree at path, with the
+// GROVE_* variables and any env files asked for.
+func execCommand(root string, cfg config.Config, s state.State, alias, branch, path string, command []string) (*exec.Cmd, error) {
+	env := hookEnv(root, alias, branch, path)
+	if entry, ok := s.Get(alias); ok && entry.Setup != nil {
+		env = append(env, kubeEnv(cfg.Kube, entry.Setup.Namespace)...)
+		if entry.Setup.Database != "" {
+			env = append(env, "GROVE_DB_NAME="+entry.Setup.Database)
+		}
+	}
+
+	fileEnv, err := execFileEnv(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := exec.Command(command[0], command[1:]...)
+	c.Dir = path
+	c.Env = append(append(git.Environ(), fileEnv...), env...)
+	return c, nil
+}
+
+// execResult is how the command went in one worktree, for grove exec --all.
+type execResult struct {
+	Alias    string `json:"alias"`
+	Path     string `json:"path"`
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"` // the command couldn't be run at all
+}
+
+// execEverywhere runs command in every managed worktree, at most jobs at a
+// time.
+func execEverywhere(root string, cfg config.Config, s state.State, command []string) error {
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias := range s.Worktrees {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	if execAge.set() {
+		var err error
+		if aliases, err = filterEntriesByAge(s, aliases, execAge); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if jsonOutput {
+		out = os.Stderr
+	}
+	results := make([]execResult, len(aliases))
+	parallel(len(aliases), func(i int) {
+		alias := aliases[i]
+		entry := s.Worktrees[alias]
+		result := execResult{Alias: alias, Path: displayPath(entry.Path)}
+		if err := runLabeled(root, cfg, s, alias, entry, command, out); err != nil {
+			var ce commandExit
+			if errors.As(err, &ce) {
+				result.ExitCode = ce.code
+			} else {
+				result.ExitCode, result.Error = exitError, err.Error()
+				fmt.Fprintf(out, "[%s] %v\n", alias, err)
+			}
+		}
+		results[i] = result
+	})
+
+	worst := 0
+	var failed []string
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			worst = max(worst, r.ExitCode)
+			failed = append(failed, fmt.Sprintf("%s (exit %d)", r.Alias, r.ExitCode))
+		}
+	}
+	if jsonOutput {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "\nFailed in %d of %d worktree(s): %s\n", len(failed), len(results), strings.Join(failed, ", "))
+	}
+	if worst > 0 {
+		return commandExit{code: worst}
+	}
+	return nil
+}
+
+// runLabeled runs command in one worktree with each line of its output
+// prefixed by the alias.
+func runLabeled(root string, cfg config.Config, s state.State, alias string, entry state.WorktreeEntry, command []string, out io.Writer) error {
+	if _, err := os.Stat(entry.Path); err != nil {
+		return fmt.Errorf("%s is missing — run 'grove prune' to drop the entry", entry.Path)
+	}
+	c, err := execCommand(root, cfg, s, alias, entry.Branch, entry.Path, command)
+	if err != nil {
+		return err
+	}
+	w := newLabelWriter(out, "["+alias+"] ")
+	defer w.Flush()
+	c.Stdout, c.Stderr = w, w
+	return runForwardingSignals(c)
+}
+
+// labelWriter prefixes every line written to it. A trailing partial line
+// is held until it's finished or Flush is called.
+type labelWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	label   string
+	pending []byte
+}
+
+func newLabelWriter(out io.Writer, label string) *labelWriter {
+	return &labelWriter{out: out, label: label}
+}
+
+func (w *labelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s", w.label, w.pending[:i+1]); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes out a trailing line that didn't end in a newline.
+func (w *labelWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		fmt.Fprintf(w.out, "%s%s\n", w.label, w.pending)
+		w.pending = nil
+	}
+}
+
+// execFileEnv reads the env files asked for with --dotenv and --env-file in
+// the worktree at dir, in that order.
+func execFileEnv(dir string) ([]string, error) {
+	var paths []string
+	if execDotenv {
+		for _, name := range []string{".env", ".env.local"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				paths = append(paths, filepath.Join(dir, name))
+			}
+		}
+		if len(paths) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: --dotenv: no .env or .env.local in %s\n", dir)
+		}
+	}
+	for _, p := range execEnvFiles {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		paths = append(paths, p)
+	}
+
+	var env []string
+	for _, p := range paths {
+		vars, err := files.ReadEnvFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("--env-file: %w", err)
+		}
+		env = append(env, vars...)
+	}
+	return env, nil
+}
+
+// runForwardingSignals runs c until it exits, keeping grove alive meanwhile.
+// Ctrl-C and Ctrl-\ already reach c through the terminal's foreground process
+// group, so grove only swallows them — forwarding would deliver them twice and
+// kill a REPL that expects to survive one. SIGTERM and SIGHUP are sent to
+// grove alone, so those are passed on. A non-zero exit becomes grove's own.
+func runForwardingSignals(c *exec.Cmd) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				if sig == syscall.SIGTERM || sig == syscall.SIGHUP {
+					c.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := c.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return commandExit{code: exitErr.ExitCode()}
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestExecRunsInWorktree(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/exec"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-exec")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	out := filepath.Join(t.TempDir(), "out")
+	execInteractive = false
+	if err := runExec(execCmd, []string{"exec", "sh", "-c", `echo "$PWD $GROVE_ALIAS" > ` + out}); err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	got := strings.TrimSpace(string(data))
+	if !strings.HasSuffix(got, "testproject-exec exec") {
+		t.Errorf("command saw %q, want the worktree dir and alias", got)
+	}
+
+	err := runExec(execCmd, []string{"exec", "sh", "-c", "exit 7"})
+	if exitCode(err) != 7 {
+		t.Errorf("exit code = %d (err %v), want 7", exitCode(err), err)
+	}
+
+	if err := runExec(execCmd, []string{"nope", "true"}); exitCode(err) != exitNotFound {
+		t.Errorf("unknown worktree: err = %v, want not found", err)
+	}
+}
+
+func TestExecEnvFiles(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("APP_MODE=dev\nPORT=3000\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.local"), []byte("PORT=3001 # mine\n"), 0644)
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/envs"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-envs")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+	os.WriteFile(filepath.Join(wtPath, "ci.env"), []byte("export APP_MODE='ci'\n"), 0644)
+
+	out := filepath.Join(t.TempDir(), "out")
+	t.Setenv("APP_MODE", "from-shell")
+	execInteractive = false
+	execDotenv = true
+	execEnvFiles = []string{"ci.env"}
+	t.Cleanup(func() { execDotenv, execEnvFiles = false, nil })
+	if err := runExec(execCmd, []string{"envs", "sh", "-c", `echo "$APP_MODE $PORT" > ` + out}); err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	// .env.local wins over .env, --env-file over both, all over the shell.
+	if got := strings.TrimSpace(string(data)); got != "ci 3001" {
+		t.Errorf("command saw %q, want %q", got, "ci 3001")
+	}
+
+	execDotenv = false
+	execEnvFiles = []string{"missing.env"}
+	if err := runExec(execCmd, []string{"envs", "true"}); err == nil {
+		t.Error("expected an error for a missing --env-file")
+	}
+}
+
+func TestExecAll(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	for _, branch := range []string{"feature/auth", "fix/login"} {
+		if _, err := createWorktree(createCmd, branch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	execAll = true
+	t.Cleanup(func() { execAll = false })
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runExec(execCmd, []string{"sh", "-c", `printf "in $GROVE_ALIAS\nno newline"; test "$GROVE_ALIAS" = auth || exit 3`})
+	})
+	want := "[auth] in auth\n[auth] no newline\n[login] in login\n[login] no newline\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if exitCode(err) != 3 {
+		t.Errorf("exit code = %d (err %v), want 3 from login", exitCode(err), err)
+	}
+
+	if err := runExec(execCmd, []string{"true"}); err != nil {
+		t.Errorf("all succeeded, got %v", err)
+	}
+
+	// With --jobs the worktrees run at once, so only the lines are in order.
+	jobs = 2
+	t.Cleanup(func() { jobs = 1 })
+	out = captureStdout(t, func() {
+		err = runExec(execCmd, []string{"sh", "-c", `echo "in $GROVE_ALIAS"`})
+	})
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	sort.Strings(lines)
+	if err != nil || strings.Join(lines, "\n") != "[auth] in auth\n[login] in login" {
+		t.Errorf("parallel output = %q (err %v)", out, err)
+	}
+}
+
+func TestLabelWriter(t *testing.T) {
+	var sb strings.Builder
+	w := newLabelWriter(&sb, "[a] ")
+	w.Write([]byte("one\ntw"))
+	w.Write([]byte("o\n\nthree"))
+	w.Flush()
+	if got, want := sb.String(), "[a] one\n[a] two\n[a] \n[a] three\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(expireCmd)
+}
+
+var expireCmd = &cobra.Command{
+	Use:   "expire <name> <duration|never>",
+	Short: "Set when a worktree may be removed automatically",
+	Long: `Give a worktree an expiry, counted from now. Durations are like 7d, 2w,
+12h or 90m; "never" clears the expiry. Accepts an alias, branch or path.
+
+'grove list' counts down to the expiry. Once it has passed, 'grove clean
+--expired' removes the worktree, and 'grove prune' does too if it has no
+local changes. 'grove review' sets an expiry on its worktrees by itself.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliases,
+	RunE:              runExpire,
+}
+
+func runExpire(cmd *cobra.Command, args []string) error {
+	var ttl time.Duration
+	if args[1] != "never" {
+		var err error
+		if ttl, err = parseTTL(args[1]); err != nil {
+			return err
+		}
+	}
+
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	query, err := expandRowRef(root, args[0])
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil || !resolved.InState {
+		return errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", args[0])
+	}
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	err = state.Change(root, func(s *state.State) error {
+		return s.Update(resolved.Alias, func(e *state.WorktreeEntry) { e.Expires = expires })
+	})
+	if err != nil {
+		return err
+	}
+
+	if expires.IsZero() {
+		fmt.Printf("%s no longer expires.\n", resolved.Alias)
+		return nil
+	}
+	fmt.Printf("%s expires in %s (%s).\n", resolved.Alias, expiresIn(expires, time.Now()), expires.Format(time.DateTime))
+	fmt.Println("  'grove clean --expired' removes it after that.")
+	return nil
+}
+
+// parseTTL parses an expiry duration. On top of time.ParseDuration's units
+// it takes whole days (7d) and weeks (2w), which is what expiries are
+// usually measured in.
+func parseTTL(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q — use something like 7d, 2w, 12h or never", s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, invalid
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, invalid
+	}
+	return d, nil
+}
+
+// expiresIn renders the time left until t as a short countdown: "3d",
+// "5h", "20m", or "expired" once it has passed.
+func expiresIn(t, now time.Time) string {
+	left := t.Sub(now)
+	switch {
+	case left <= 0:
+		return "expired"
+	case left >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(left.Hours()/24))
+	case left >= time.Hour:
+		return fmt.Sprintf("%dh", int(left.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(left.Minutes())+1)
+	}
+}
+
+// isExpired reports whether e has an expiry that has passed.
+func isExpired(e state.WorktreeEntry, now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
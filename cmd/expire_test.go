@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"7d", 7 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseTTL(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseTTL(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "d", "-1d", "0h", "soon", "1.5d"} {
+		if _, err := parseTTL(in); err == nil {
+			t.Errorf("parseTTL(%q) should fail", in)
+		}
+	}
+}
+
+func TestExpiresIn(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		left time.Duration
+		want string
+	}{
+		{-time.Minute, "expired"},
+		{0, "expired"},
+		{10 * time.Minute, "11m"},
+		{5 * time.Hour, "5h"},
+		{47 * time.Hour, "47h"},
+		{7*24*time.Hour - time.Minute, "6d"},
+	}
+	for _, tt := range tests {
+		if got := expiresIn(now.Add(tt.left), now); got != tt.want {
+			t.Errorf("expiresIn(now+%v) = %q, want %q", tt.left, got, tt.want)
+		}
+	}
+}
+
+func TestExpireAndCleanExpired(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+	})
+	createName = ""
+	createFrom = ""
+	for _, branch := range []string{"feature/old", "feature/kept"} {
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runExpire(expireCmd, []string{"kept", "7d"}); err != nil {
+		t.Fatalf("expire failed: %v", err)
+	}
+	if err := runExpire(expireCmd, []string{"old", "1h"}); err != nil {
+		t.Fatalf("expire failed: %v", err)
+	}
+	// Backdate "old" past its expiry.
+	s, _ := state.Load(dir)
+	s.Update("old", func(e *state.WorktreeEntry) { e.Expires = time.Now().Add(-time.Minute) })
+	if err := state.Save(dir, s); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanExpired = true
+	reader = bufio.NewReader(strings.NewReader("y\n"))
+	t.Cleanup(func() { cleanExpired, reader = false, nil })
+	if err := runClean(cleanCmd, nil); err != nil {
+		t.Fatalf("clean --expired failed: %v", err)
+	}
+
+	s, _ = state.Load(dir)
+	if s.AliasExists("old") {
+		t.Error("expired worktree should have been removed")
+	}
+	kept, ok := s.Get("kept")
+	if !ok {
+		t.Fatal("worktree that hasn't expired yet should be kept")
+	}
+	if kept.Expires.IsZero() {
+		t.Error("expected kept to have an expiry")
+	}
+
+	if err := runExpire(expireCmd, []string{"kept", "never"}); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = state.Load(dir)
+	if e, _ := s.Get("kept"); !e.Expires.IsZero() {
+		t.Errorf("expire never should clear the expiry, got %v", e.Expires)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/registry"
+)
+
+// offerInit is called when a command fails because the project has no
+// .groverc.json. In a terminal it offers to run the init wizard for the
+// repository right away; "never" is remembered in the registry so the
+// question isn't asked again there. Returns true if a config was created and
+// the command should be retried.
+func offerInit() bool {
+	if jsonOutput || !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return false
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	root, err := repoRoot(cwd)
+	if err != nil {
+		return false // not a git repository — grove can't help here
+	}
+
+	regPath, err := registry.Path()
+	if err != nil {
+		return false
+	}
+	reg, err := registry.Load(regPath)
+	if err != nil || reg.IsDeclined(root) {
+		return false
+	}
+
+	fmt.Printf("Grove isn't set up in %s yet.\n", root)
+	switch strings.ToLower(prompt("Set it up now? [Y/n/never]", "y")) {
+	case "y", "yes":
+	case "never":
+		if reg.Decline(root) {
+			if err := registry.Save(regPath, reg); err != nil {
+				fmt.Fprintf(os.Stderr, "  warning: could not remember the answer: %v\n", err)
+			}
+		}
+		fmt.Println("Okay, won't ask again for this repository. Run 'grove init' to set it up later.")
+		return false
+	default:
+		return false
+	}
+
+	// The config belongs in the main worktree's root, wherever the command
+	// was run from.
+	if err := os.Chdir(root); err != nil {
+		return false
+	}
+	defer os.Chdir(cwd)
+	fmt.Println()
+	if err := runInit(initCmd, nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	fmt.Println()
+	return true
+}
+
+// repoRoot returns the main worktree of the repository containing dir.
+func repoRoot(dir string) (string, error) {
+	common, err := git.CommonDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return filepath.EvalSymlinks(filepath.Dir(common))
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestRepoRootFromSubdirectory(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject"})
+	sub := filepath.Join(dir, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := repoRoot(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != dir {
+		t.Errorf("repoRoot = %s, want %s", root, dir)
+	}
+
+	if _, err := repoRoot(t.TempDir()); err == nil {
+		t.Error("repoRoot outside a repository should fail")
+	}
+}
+
+func TestOfferInitNeedsTerminal(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject"})
+	// go test's stdin is not a terminal, so nothing is asked.
+	if offerInit() {
+		t.Error("offerInit ran the wizard without a terminal")
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(forgetCmd)
+}
+
+var forgetCmd = &cobra.Command{
+	Use:   "forget <name>",
+	Short: "Stop managing a worktree without removing it",
+	Long: `Remove a worktree from grove's state without touching the git worktree or
+its files — the inverse of 'grove adopt'. Accepts an alias, branch or path.
+
+Unlike 'grove alias rm', the worktree is also hidden from the orphan list,
+so 'grove clean' won't offer to delete it. Use it for trees that become
+long-lived secondary checkouts. 'grove adopt <branch>' brings it back.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runForget,
+}
+
+func runForget(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	query, err := expandRowRef(root, args[0])
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil || !resolved.InState {
+		return errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", args[0])
+	}
+
+	err = state.Change(root, func(s *state.State) error {
+		if err := s.Remove(resolved.Alias); err != nil {
+			return err
+		}
+		s.Ignore(resolved.Path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Forgot %s. The worktree is still at %s and grove won't touch it.\n", resolved.Alias, resolved.Path)
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestForgetHidesFromCleanAndAdoptRestores(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/longlived"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-longlived")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	if err := runForget(forgetCmd, []string{"longlived"}); err != nil {
+		t.Fatalf("forget failed: %v", err)
+	}
+	s, _ := state.Load(dir)
+	if s.AliasExists("longlived") || !s.IsIgnored(wtPath) {
+		t.Fatalf("state after forget = %+v", s)
+	}
+
+	orphans, err := findOrphans(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("forgotten worktree should not be an orphan, got %v", orphans)
+	}
+
+	// Clean has nothing to do and the tree survives.
+	cleanForce = true
+	t.Cleanup(func() { cleanForce = false })
+	if err := runClean(cleanCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Fatalf("forgotten worktree was removed: %v", err)
+	}
+
+	// Adopting it by branch brings it back under management.
+	reader = bufio.NewReader(strings.NewReader("longlived\n"))
+	t.Cleanup(func() { reader = nil })
+	if err := runAdopt(adoptCmd, []string{"feature/longlived"}); err != nil {
+		t.Fatalf("adopt failed: %v", err)
+	}
+	s, _ = state.Load(dir)
+	if !s.AliasExists("longlived") || s.IsIgnored(wtPath) {
+		t.Errorf("state after adopt = %+v", s)
+	}
+}
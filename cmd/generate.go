@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/version"
+)
+
+var (
+	generateTemplate string
+	generateEnv      string
+	generateRoot     string
+)
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateCICmd)
+	generateCICmd.Flags().StringVar(&generateTemplate, "template", "", "create preview worktrees with this template from .groverc.json")
+	generateCICmd.Flags().StringVar(&generateEnv, "env", "", "copy only this environment's env files into preview worktrees (see 'grove create --env')")
+	generateCICmd.Flags().StringVar(&generateRoot, "root", "", "where the runner keeps its clone of the project (default /srv/grove/<project>)")
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Print files that put grove to work elsewhere, like CI pipelines",
+}
+
+var generateCICmd = &cobra.Command{
+	Use:   "ci [github|gitlab]",
+	Short: "Print a CI pipeline that keeps a preview worktree per pull request",
+	Long: `Print a pipeline for GitHub Actions (the default) or GitLab CI that gives
+every pull request — merge request, on GitLab — a worktree of its own on a
+self-hosted runner, and removes it when the request is closed:
+
+  grove generate ci > .github/workflows/grove-preview.yml
+  grove generate ci gitlab --template web   # to include from .gitlab-ci.yml
+
+The worktree, pr-<number> (mr-<number> on GitLab), is made with
+'grove create --json' in the runner's clone of the project, at --root,
+then reset to the branch on later pushes and checked with 'grove verify'.
+The runner has to keep that clone between jobs, and have Go to install
+grove with; run 'grove init' in the clone once. Requests from forks are
+left out, since their branches aren't on origin.
+
+--template and --env are passed on to grove create, and checked against
+.groverc.json now rather than on the first failing pipeline. Regenerate
+the file after upgrading grove instead of editing its grove commands.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{ciGitHub, ciGitLab},
+	RunE:      runGenerateCI,
+}
+
+// CI providers grove generate ci writes pipelines for.
+const (
+	ciGitHub = "github"
+	ciGitLab = "gitlab"
+)
+
+// ciPipeline is what the pipeline templates are filled in with.
+type ciPipeline struct {
+	Provider    string
+	Root        string // the runner's clone of the project
+	Version     string // grove version to install: the running one, or latest
+	CreateFlags string // grove create flags from --template and --env, with a leading space
+}
+
+func runGenerateCI(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	provider := ciGitHub
+	if len(args) > 0 {
+		provider = args[0]
+	}
+	p, err := newCIPipeline(root, cfg, provider)
+	if err != nil {
+		return err
+	}
+	if err := p.write(os.Stdout); err != nil {
+		return err
+	}
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		fmt.Fprintf(os.Stderr, "\nSave it as %s, and 'grove init' the clone at %s on the runner.\n", ciFile(provider), p.Root)
+	}
+	return nil
+}
+
+// newCIPipeline checks the generate ci flags against cfg and fills in a
+// pipeline for provider.
+func newCIPipeline(root string, cfg config.Config, provider string) (ciPipeline, error) {
+	p := ciPipeline{Provider: provider, Root: generateRoot, Version: "latest"}
+	if provider != ciGitHub && provider != ciGitLab {
+		return p, fmt.Errorf("no CI pipeline for %q — use github or gitlab", provider)
+	}
+	if p.Root == "" {
+		p.Root = "/srv/grove/" + filepath.Base(root)
+	}
+	if version.IsRelease(version.Current) {
+		p.Version = version.Current
+	}
+	if generateTemplate != "" {
+		if _, _, err := cfg.WithTemplate(generateTemplate); err != nil {
+			return p, err
+		}
+		p.CreateFlags += " --template " + shellArg(generateTemplate)
+	}
+	if generateEnv != "" {
+		if _, err := envProfilePatterns(root, cfg, generateEnv); err != nil {
+			return p, err
+		}
+		p.CreateFlags += " --env " + shellArg(generateEnv)
+	}
+	return p, nil
+}
+
+func (p ciPipeline) write(w io.Writer) error {
+	text := githubPipeline
+	if p.Provider == ciGitLab {
+		text = gitlabPipeline
+	}
+	// GitHub's expressions are ${{ ... }}, so the template uses [[ ... ]].
+	tmpl, err := template.New(p.Provider).Delims("[[", "]]").Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, p)
+}
+
+// ciFile is where provider looks for the pipeline.
+func ciFile(provider string) string {
+	if provider == ciGitLab {
+		return "a file included from .gitlab-ci.yml"
+	}
+	return ".github/workflows/grove-preview.yml"
+}
+
+const githubPipeline = `# A grove worktree per pull request, on a self-hosted runner that keeps
+# [[.Root]] between jobs. Generated by 'grove generate ci github';
+# regenerate it rather than editing the grove commands.
+name: grove preview
+
+on:
+  pull_request:
+    types: [opened, synchronize, reopened, closed]
+
+concurrency:
+  group: grove-preview-${{ github.event.number }}
+
+env:
+  GROVE_ROOT: [[.Root]]
+  ALIAS: pr-${{ github.event.number }}
+  BRANCH: ${{ github.head_ref }}
+
+jobs:
+  preview:
+    if: github.event.action != 'closed' && github.event.pull_request.head.repo.full_name == github.repository
+    runs-on: self-hosted
+    steps:
+      - name: Install grove
+        run: |
+          go install github.com/verbaux/grove@[[.Version]]
+          echo "$(go env GOPATH)/bin" >> "$GITHUB_PATH"
+      # steps.worktree.outputs.path is the worktree, for deploy steps of your own.
+      # shell: bash runs with -o pipefail, so a failed create fails the step
+      # instead of being masked by tee.
+      - name: Create or update the worktree
+        id: worktree
+        working-directory: ${{ env.GROVE_ROOT }}
+        shell: bash
+        run: |
+          git fetch origin "$BRANCH"
+          if grove info "$ALIAS" >/dev/null 2>&1; then
+            git -C "$(grove cd "$ALIAS")" reset --hard "origin/$BRANCH"
+          else
+            grove create "$BRANCH" --name "$ALIAS" --from "origin/$BRANCH"[[.CreateFlags]] --trust --json | tee "$RUNNER_TEMP/grove-create.json"
+          fi
+          echo "path=$(grove cd "$ALIAS")" >> "$GITHUB_OUTPUT"
+      - name: Verify the worktree
+        working-directory: ${{ env.GROVE_ROOT }}
+        run: grove verify "$ALIAS" --trust
+
+  remove:
+    if: github.event.action == 'closed' && github.event.pull_request.head.repo.full_name == github.repository
+    runs-on: self-hosted
+    steps:
+      - name: Install grove
+        run: |
+          go install github.com/verbaux/grove@[[.Version]]
+          echo "$(go env GOPATH)/bin" >> "$GITHUB_PATH"
+      - name: Remove the worktree
+        working-directory: ${{ env.GROVE_ROOT }}
+        run: grove remove "$ALIAS" --force --yes --trust
+`
+
+const gitlabPipeline = `# A grove worktree per merge request, on a shell runner tagged "grove"
+# that keeps [[.Root]] between jobs. Generated by
+# 'grove generate ci gitlab'; regenerate it rather than editing the grove
+# commands.
+.grove:
+  tags: [grove]
+  variables:
+    GROVE_ROOT: [[.Root]]
+    ALIAS: mr-$CI_MERGE_REQUEST_IID
+    BRANCH: $CI_MERGE_REQUEST_SOURCE_BRANCH_NAME
+  before_script:
+    - go install github.com/verbaux/grove@[[.Version]]
+    - export PATH="$(go env GOPATH)/bin:$PATH"
+    - cd "$GROVE_ROOT"
+
+grove-preview:
+  extends: .grove
+  stage: deploy
+  rules:
+    - if: $CI_PIPELINE_SOURCE == "merge_request_event" && $CI_MERGE_REQUEST_SOURCE_PROJECT_ID == $CI_PROJECT_ID
+  environment:
+    name: preview/mr-$CI_MERGE_REQUEST_IID
+    on_stop: grove-remove
+  script:
+    - set -o pipefail
+    - git fetch origin "$BRANCH"
+    - |
+      if grove info "$ALIAS" >/dev/null 2>&1; then
+        git -C "$(grove cd "$ALIAS")" reset --hard "origin/$BRANCH"
+      else
+        grove create "$BRANCH" --name "$ALIAS" --from "origin/$BRANCH"[[.CreateFlags]] --trust --json | tee "$CI_PROJECT_DIR/grove-create.json"
+      fi
+    - grove verify "$ALIAS" --trust
+  artifacts:
+    when: always
+    paths: [grove-create.json]
+
+grove-remove:
+  extends: .grove
+  stage: deploy
+  rules:
+    - if: $CI_PIPELINE_SOURCE == "merge_request_event" && $CI_MERGE_REQUEST_SOURCE_PROJECT_ID == $CI_PROJECT_ID
+      when: manual
+      allow_failure: true
+  environment:
+    name: preview/mr-$CI_MERGE_REQUEST_IID
+    action: stop
+  script:
+    - grove remove "$ALIAS" --force --yes --trust
+`
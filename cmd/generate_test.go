@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+// groveCall finds grove commands in a pipeline line, up to the next pipe,
+// redirect or closing parenthesis.
+var groveCall = regexp.MustCompile(`(?:^|[\s(])grove ([a-z-]+)([^|)>]*)`)
+
+func TestGenerateCIUsesRealFlags(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env.staging"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{Templates: map[string]config.Template{"web": {}}}
+	generateTemplate, generateEnv = "web", "staging"
+	t.Cleanup(func() { generateTemplate, generateEnv = "", "" })
+
+	for _, provider := range []string{ciGitHub, ciGitLab} {
+		p, err := newCIPipeline(dir, cfg, provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := p.write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "--template web --env staging --trust --json") {
+			t.Errorf("%s: grove create doesn't get --template and --env:\n%s", provider, out)
+		}
+		if !strings.Contains(out, "pipefail") && !strings.Contains(out, "shell: bash") {
+			t.Errorf("%s: grove create | tee would hide a failed create:\n%s", provider, out)
+		}
+		if !strings.Contains(out, filepath.Join("/srv/grove", filepath.Base(dir))) {
+			t.Errorf("%s: default --root missing:\n%s", provider, out)
+		}
+
+		// Every grove command and flag in the pipeline must exist, so the
+		// snippet can't drift from the CLI.
+		calls := 0
+		for _, line := range strings.Split(out, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "name:") {
+				continue
+			}
+			for _, m := range groveCall.FindAllStringSubmatch(line, -1) {
+				calls++
+				sub, _, err := rootCmd.Find([]string{m[1]})
+				if err != nil || sub == rootCmd {
+					t.Errorf("%s: no grove %s command: %s", provider, m[1], line)
+					continue
+				}
+				for _, field := range strings.Fields(m[2]) {
+					name, ok := strings.CutPrefix(field, "--")
+					if !ok {
+						continue
+					}
+					if sub.Flags().Lookup(name) == nil && sub.InheritedFlags().Lookup(name) == nil {
+						t.Errorf("%s: grove %s has no --%s: %s", provider, m[1], name, line)
+					}
+				}
+			}
+		}
+		if calls < 5 {
+			t.Errorf("%s: found only %d grove commands:\n%s", provider, calls, out)
+		}
+	}
+
+	generateTemplate = "nope"
+	if _, err := newCIPipeline(dir, cfg, ciGitHub); err == nil {
+		t.Error("an unknown --template should be refused")
+	}
+	generateTemplate, generateEnv = "", "prod"
+	if _, err := newCIPipeline(dir, cfg, ciGitHub); err == nil {
+		t.Error("an --env without a profile or .env.prod should be refused")
+	}
+	generateEnv = ""
+	if _, err := newCIPipeline(dir, cfg, "jenkins"); err == nil {
+		t.Error("an unknown provider should be refused")
+	}
+}
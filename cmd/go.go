@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/registry"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(goCmd)
+}
+
+var goCmd = &cobra.Command{
+	Use:   "go <project>[/<alias>]",
+	Short: "Print the path to a worktree in any registered project",
+	Long: `Print the path to a worktree of any grove project on this machine,
+regardless of the current directory. With only a project name, prints the
+project root.
+
+Projects are registered by grove init and grove create; see them with
+'grove list --all-repos'.
+
+Usage:
+  cd $(grove go shop/auth)
+  cd $(grove go shop)
+
+One shell function covers every repository:
+  gg() { cd "$(grove go "$1")"; }`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProjectAliases,
+	RunE:              runGo,
+}
+
+func runGo(cmd *cobra.Command, args []string) error {
+	name, alias, _ := strings.Cut(args[0], "/")
+
+	path, err := registry.Path()
+	if err != nil {
+		return err
+	}
+	reg, err := registry.Load(path)
+	if err != nil {
+		return err
+	}
+
+	project, err := findProject(reg, name)
+	if err != nil {
+		return err
+	}
+
+	if alias == "" {
+		fmt.Println(displayPath(project.Root))
+		return nil
+	}
+
+	s, err := state.Load(project.Root)
+	if err != nil {
+		return err
+	}
+	entry, ok := s.Get(alias)
+	if !ok {
+		return errorf(state.ErrNotFound, "no worktree with alias %q in project %q — run 'grove list --all-repos' to see available worktrees", alias, name)
+	}
+
+	fmt.Println(displayPath(entry.Path))
+	return nil
+}
+
+// findProject looks up a registered project by name. Two clones registered
+// under the same name can't be told apart, so that's an error too.
+func findProject(reg registry.Registry, name string) (registry.Project, error) {
+	found := reg.Find(name)
+	switch len(found) {
+	case 0:
+		return registry.Project{}, errorf(state.ErrNotFound, "no project named %q — run 'grove list --all-repos' to see registered projects", name)
+	case 1:
+		return found[0], nil
+	}
+
+	roots := make([]string, len(found))
+	for i, p := range found {
+		roots[i] = p.Root
+	}
+	return registry.Project{}, fmt.Errorf("project name %q is ambiguous (%s) — set a distinct \"prefix\" in one project's .groverc.json", name, strings.Join(roots, ", "))
+}
+
+// completeProjectAliases suggests "project/alias" for every managed worktree
+// on the machine, plus bare project names.
+func completeProjectAliases(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	path, err := registry.Path()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	reg, err := registry.Load(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var out []string
+	for _, p := range reg.Projects {
+		out = append(out, p.Name)
+		s, err := state.Load(p.Root)
+		if err != nil {
+			continue
+		}
+		for alias := range s.Worktrees {
+			out = append(out, p.Name+"/"+alias)
+		}
+	}
+	sort.Strings(out)
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
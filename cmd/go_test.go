@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/registry"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestGoResolvesAcrossProjects(t *testing.T) {
+	shop := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop", Symlink: []string{}})
+	s, err := state.Load(shop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add("auth", "feature/auth", "/code/shop-auth")
+	if err := state.Save(shop, s); err != nil {
+		t.Fatal(err)
+	}
+
+	regPath := os.Getenv("GROVE_REGISTRY")
+	var reg registry.Registry
+	reg.Register("shop", shop)
+	if err := registry.Save(regPath, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run from somewhere that isn't a grove project at all.
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runGo(goCmd, []string{"shop/auth"}); err != nil {
+			t.Fatalf("go shop/auth failed: %v", err)
+		}
+	})
+	if got := strings.TrimSpace(string(out)); got != "/code/shop-auth" {
+		t.Errorf("go shop/auth = %q, want /code/shop-auth", got)
+	}
+
+	out = captureStdout(t, func() {
+		if err := runGo(goCmd, []string{"shop"}); err != nil {
+			t.Fatalf("go shop failed: %v", err)
+		}
+	})
+	if got := strings.TrimSpace(string(out)); got != shop {
+		t.Errorf("go shop = %q, want %q", got, shop)
+	}
+
+	if err := runGo(goCmd, []string{"shop/nope"}); !errors.Is(err, state.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown alias, got %v", err)
+	}
+	if err := runGo(goCmd, []string{"blog/auth"}); !errors.Is(err, state.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown project, got %v", err)
+	}
+
+	reg.Register("shop", filepath.Join(t.TempDir(), "shop-clone"))
+	registry.Save(regPath, reg)
+	if err := runGo(goCmd, []string{"shop/auth"}); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguity error, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/verbaux/grove/internal/git"
+)
+
+// errMainWorktree is returned when a command would delete or move the main
+// worktree. Grove never does either, whatever state.json says.
+var errMainWorktree = errors.New("refusing to touch the main worktree")
+
+// mainGuard protects the main worktree. Every command that deletes or moves
+// worktrees checks its targets with it right before acting, so a stale or
+// hand-edited state entry pointing at main can't slip through.
+type mainGuard struct {
+	path   string
+	branch string
+}
+
+// newMainGuard looks up the main worktree. Commands checking several
+// targets create one guard up front rather than asking git each time.
+func newMainGuard() (mainGuard, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return mainGuard{}, err
+	}
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			return mainGuard{path: wt.Path, branch: wt.Branch}, nil
+		}
+	}
+	return mainGuard{}, errors.New("git reported no main worktree")
+}
+
+// check refuses path if it is the main worktree or a directory containing
+// it, and branch if it is the branch checked out in main. Either may be
+// empty to skip that check.
+func (g mainGuard) check(path, branch string) error {
+	if path != "" && isWithin(g.path, path) {
+		return errorf(errMainWorktree, "%s is the main worktree — grove never removes or moves it", path)
+	}
+	if branch != "" && branch == g.branch {
+		return errorf(errMainWorktree, "%s is checked out in the main worktree %s — grove never removes or moves it", branch, g.path)
+	}
+	return nil
+}
+
+// guardMain is a one-off mainGuard check.
+func guardMain(path, branch string) error {
+	g, err := newMainGuard()
+	if err != nil {
+		return err
+	}
+	return g.check(path, branch)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestMainGuardCheck(t *testing.T) {
+	g := mainGuard{path: "/code/app", branch: "main"}
+	tests := []struct {
+		path, branch string
+		refused      bool
+	}{
+		{"/code/app", "", true},
+		{"/code", "", true}, // a parent would take main with it
+		{"", "main", true},
+		{"/code/app-auth", "feature/auth", false},
+		{"/code/app/sub", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		err := g.check(tt.path, tt.branch)
+		if got := errors.Is(err, errMainWorktree); got != tt.refused {
+			t.Errorf("check(%q, %q) = %v, want refused=%v", tt.path, tt.branch, err, tt.refused)
+		}
+	}
+}
+
+func TestDestructiveCommandsRefuseMain(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	// A hand-edited state entry pointing at the main worktree.
+	s := state.State{Worktrees: map[string]state.WorktreeEntry{
+		"oops": {Branch: "main", Path: dir},
+	}}
+	if err := state.Save(dir, s); err != nil {
+		t.Fatal(err)
+	}
+
+	removeForce = true
+	t.Cleanup(func() { removeForce = false })
+	for _, query := range []string{"oops", "main", "."} {
+		if err := runRemove(removeCmd, []string{query}); !errors.Is(err, errMainWorktree) {
+			t.Errorf("remove %s: err = %v, want errMainWorktree", query, err)
+		}
+	}
+
+	reader = bufio.NewReader(strings.NewReader("y\ny\n"))
+	t.Cleanup(func() { reader = nil })
+	if err := runClean(cleanCmd, nil); err != nil {
+		t.Fatalf("clean failed: %v", err)
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("main worktree is gone: %v", err)
+	}
+	if s, _ := state.Load(dir); !s.AliasExists("oops") {
+		t.Error("entry for main was dropped from state")
+	}
+}
@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
 	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/i18n"
+	"github.com/verbaux/grove/internal/listcache"
+	"github.com/verbaux/grove/internal/registry"
+	"github.com/verbaux/grove/internal/rowref"
 	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/statuscache"
 )
 
 // isNumericAlias returns true if the alias is purely numeric (e.g. "3", "42").
@@ -23,23 +34,159 @@ func validateAlias(alias string) error {
 	if isNumericAlias(alias) {
 		return fmt.Errorf("alias %q is not allowed — numeric-only names are reserved for index-based access (grove cd 3)", alias)
 	}
+	if kind, _ := splitQueryKind(alias); kind != "" {
+		return fmt.Errorf("alias %q is not allowed — names starting with %s: are reserved for picking a worktree by %s", alias, kind, kind)
+	}
 	return nil
 }
 
+// dropAliases removes aliases from the saved state, under its lock. Any
+// alias another grove removed in the meantime is skipped.
+func dropAliases(root string, aliases []string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	return state.Change(root, func(s *state.State) error {
+		for _, alias := range aliases {
+			if s.AliasExists(alias) {
+				if err := s.Remove(alias); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// isWithin reports whether path is dir itself or somewhere below it.
+// Symlinks are resolved first, so /tmp and /private/tmp on macOS compare equal.
+func isWithin(path, dir string) bool {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+	if d, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = d
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// loadRootConfig finds the project root from cwd and loads its config.
+func loadRootConfig() (string, config.Config, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", config.Config{}, err
+	}
+	root, err := config.FindRoot(cwd)
+	if err != nil {
+		return "", config.Config{}, err
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return "", config.Config{}, err
+	}
+	return root, cfg, nil
+}
+
+// projectName is the name a project is registered under: its prefix, or the
+// root directory's name when no prefix is configured.
+func projectName(root string, cfg config.Config) string {
+	if cfg.Prefix != "" {
+		return cfg.Prefix
+	}
+	return filepath.Base(root)
+}
+
+// registerProject adds the project to the machine-wide registry. The
+// registry is a convenience for cross-project commands, so failures only warn.
+func registerProject(root string, cfg config.Config) {
+	path, err := registry.Path()
+	if err == nil {
+		var r registry.Registry
+		if r, err = registry.Load(path); err == nil && r.Register(projectName(root, cfg), root) {
+			err = registry.Save(path, r)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  warning: could not update project registry: %v\n", err)
+	}
+}
+
+// statusCacheTTL bounds how long a cached status is trusted. git.StatusKey
+// catches commits, checkouts and staging; plain edits to tracked files only
+// show up once the entry expires.
+const statusCacheTTL = 5 * time.Second
+
+// statusNoCache is set by `grove list --no-cache`.
+var statusNoCache bool
+
+// rowLimit, when positive, makes buildRows skip git status for the rows
+// past it, which grove list won't show. Set by `grove list --limit`.
+var rowLimit int
+
+// cachedStatus returns git.QuickStatus for path, formatted for display,
+// reusing the cached result while the worktree's HEAD and index are unchanged.
+func cachedStatus(cache *statuscache.Cache, path string) (string, error) {
+	key, err := git.StatusKey(path)
+	if err != nil {
+		status, err := git.QuickStatus(path)
+		return status.String(), err
+	}
+	now := time.Now()
+	if !statusNoCache {
+		if status, ok := cache.Get(path, key, statusCacheTTL, now); ok {
+			return status, nil
+		}
+	}
+	st, err := git.QuickStatus(path)
+	if err != nil {
+		return "", err
+	}
+	status := st.String()
+	// QuickStatus runs with --no-optional-locks, so it never rewrites the
+	// index and the fingerprint taken before still holds.
+	cache.Put(path, key, status, now)
+	return status, nil
+}
+
 // worktreeRow holds display info for a single worktree in the list.
 type worktreeRow struct {
-	Index  int
-	Name   string
-	Branch string
-	Path   string
-	Status string
-	IsMain bool
+	Index   int
+	Name    string
+	Branch  string
+	Path    string
+	Status  string
+	IsMain  bool
+	Base    string    // base branch recorded at create time, "" if unknown
+	Expires time.Time // zero if the worktree doesn't expire
+	Tags    []string
+	Locked  bool   // git worktree lock, e.g. by grove lock
+	Reason  string // why it's locked, may be empty
+
+	Description string // the branch's git description, "" if none
+
+	Cached *listcache.Info // precomputed by `grove refresh`, nil when rendering live
 }
 
 // buildWorktreeRows builds an ordered list of worktree rows.
 // The order matches `git worktree list` so that numbering is stable
 // and consistent between `grove list` and `grove cd`.
 func buildWorktreeRows(root string) ([]worktreeRow, error) {
+	cache := statuscache.Open(root)
+	rows, err := buildRows(root, func(path string) string {
+		status, err := cachedStatus(cache, path)
+		if err != nil {
+			return "unknown"
+		}
+		return status
+	})
+	// The cache is only an optimization — a failed write just means a slower next run.
+	cache.Save()
+	return rows, err
+}
+
+// buildRows is buildWorktreeRows with the status lookup supplied by the caller.
+func buildRows(root string, statusOf func(path string) string) ([]worktreeRow, error) {
 	worktrees, err := git.ListWorktrees()
 	if err != nil {
 		return nil, err
@@ -50,40 +197,63 @@ func buildWorktreeRows(root string) ([]worktreeRow, error) {
 		return nil, err
 	}
 
+	// Best-effort: a list without descriptions beats no list.
+	descs, _ := git.BranchDescriptions()
+
 	pathToAlias := make(map[string]string)
+	pathToEntry := make(map[string]state.WorktreeEntry)
 	for alias, entry := range s.Worktrees {
 		pathToAlias[entry.Path] = alias
+		pathToEntry[entry.Path] = entry
 	}
 
+	// git status is the slow part; run it for several worktrees at once.
+	statuses := make([]string, len(worktrees))
+	parallel(len(worktrees), func(i int) {
+		switch wt := worktrees[i]; {
+		case wt.Locked && unavailable(wt.Path):
+			statuses[i] = "unavailable"
+		case rowLimit <= 0 || i < rowLimit:
+			statuses[i] = statusOf(wt.Path)
+		}
+	})
+
 	var rows []worktreeRow
 	for i, wt := range worktrees {
 		name := pathToAlias[wt.Path]
 		if name == "" {
 			if wt.IsMain {
 				name = "main"
+			} else if s.InPool(wt.Path) {
+				name = "(pool)"
 			} else {
 				name = "?"
 			}
 		}
 
-		status, err := git.Status(wt.Path)
-		if err != nil {
-			status = "unknown"
-		}
-
 		rows = append(rows, worktreeRow{
-			Index:  i + 1,
-			Name:   name,
-			Branch: wt.Branch,
-			Path:   wt.Path,
-			Status: status,
-			IsMain: wt.IsMain,
+			Index:   i + 1,
+			Name:    name,
+			Branch:  wt.Branch,
+			Path:    wt.Path,
+			Status:  statuses[i],
+			IsMain:  wt.IsMain,
+			Base:    pathToEntry[wt.Path].Base,
+			Expires: pathToEntry[wt.Path].Expires,
+			Tags:    pathToEntry[wt.Path].Tags,
+			Locked:  wt.Locked,
+			Reason:  wt.LockReason,
+
+			Description: descs[wt.Branch],
 		})
 	}
 
 	return rows, nil
 }
 
+// completeAliases completes a command's first argument with the aliases in
+// state. Every command taking a worktree name uses it, so they all complete
+// the same way; TestWorktreeArgsComplete catches commands that forget to.
 func completeAliases(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 	if len(args) > 0 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
@@ -136,12 +306,34 @@ func completeOrphans(_ *cobra.Command, args []string, _ string) ([]string, cobra
 	return branches, cobra.ShellCompDirectiveNoFileComp
 }
 
+func completeTemplates(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	root, err := config.FindRoot(cwd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]string, 0, len(cfg.Templates))
+	for name := range cfg.Templates {
+		names = append(names, name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 type orphanWorktree struct {
 	Path   string
 	Branch string
 }
 
 // findOrphans returns worktrees that git knows about but Grove doesn't track.
+// Ignored and pooled worktrees aren't orphans.
 func findOrphans(s state.State) ([]orphanWorktree, error) {
 	worktrees, err := git.ListWorktrees()
 	if err != nil {
@@ -155,7 +347,7 @@ func findOrphans(s state.State) ([]orphanWorktree, error) {
 
 	var orphans []orphanWorktree
 	for _, wt := range worktrees {
-		if wt.IsMain {
+		if wt.IsMain || s.IsIgnored(wt.Path) || s.InPool(wt.Path) {
 			continue
 		}
 		if !tracked[wt.Path] {
@@ -170,37 +362,114 @@ type resolvedWorktree struct {
 	Path    string
 	Branch  string
 	InState bool
+
+	MatchedBy string   // "alias", "branch" or "path", for managed worktrees
+	Ambiguous []string // other worktrees the query names, e.g. "branch of auth-2"
+}
+
+// expandRowRef turns "@N" into the path shown in row N of the last
+// grove list, so any command taking a worktree accepts "@3". Other queries
+// are returned unchanged.
+func expandRowRef(root, query string) (string, error) {
+	rest, ok := strings.CutPrefix(query, "@")
+	if !ok {
+		return query, nil
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil {
+		return query, nil
+	}
+	path, ok, err := rowref.Lookup(root, n, time.Now())
+	if errors.Is(err, rowref.ErrStale) {
+		return "", errorf(state.ErrNotFound, "%s: no 'grove list' in the last %v — run it to number the worktrees", query, rowref.TTL)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errorf(state.ErrNotFound, "%s: the last 'grove list' has no row %d — run 'grove list' to see the numbers", query, n)
+	}
+	return path, nil
+}
+
+// queryKinds are the prefixes that pin a worktree query to one way of
+// naming it: "alias:auth", "branch:feature/auth", "path:../myapp-auth".
+var queryKinds = []string{"alias", "branch", "path"}
+
+// splitQueryKind splits a kind prefix off query. kind is "" when there is
+// none and query may be any of them.
+func splitQueryKind(query string) (kind, name string) {
+	for _, k := range queryKinds {
+		if name, ok := strings.CutPrefix(query, k+":"); ok {
+			return k, name
+		}
+	}
+	return "", query
 }
 
-// resolveWorktree tries to find a worktree by alias, branch name, or path.
+// resolveWorktree tries to find a worktree by alias, branch name, or path,
+// in that order, or by the kind a prefix names. When an unprefixed query
+// names more than one worktree, it warns which one it picked.
 // Returns nil if nothing matches.
 func resolveWorktree(query string, s state.State) (*resolvedWorktree, error) {
-	// 1. Alias
-	if entry, ok := s.Get(query); ok {
-		return &resolvedWorktree{
-			Alias: query, Path: entry.Path, Branch: entry.Branch, InState: true,
-		}, nil
+	resolved, err := lookupWorktree(query, s)
+	if err == nil && resolved != nil && len(resolved.Ambiguous) > 0 {
+		fmt.Fprintf(os.Stderr, i18n.T("warning: %s — using %s; write alias:, branch: or path: in front to pick another\n"), ambiguityText(query, resolved), resolved.Alias)
 	}
+	return resolved, err
+}
 
-	// 2. Branch name in state
-	for alias, entry := range s.Worktrees {
-		if entry.Branch == query {
-			return &resolvedWorktree{
-				Alias: alias, Path: entry.Path, Branch: entry.Branch, InState: true,
-			}, nil
-		}
+// lookupWorktree is resolveWorktree without the warning, for commands that
+// turn an ambiguous query into an error instead.
+func lookupWorktree(query string, s state.State) (*resolvedWorktree, error) {
+	kind, name := splitQueryKind(query)
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias := range s.Worktrees {
+		aliases = append(aliases, alias)
 	}
+	sort.Strings(aliases)
 
-	// 3. Path in state
-	for alias, entry := range s.Worktrees {
-		if entry.Path == query {
-			return &resolvedWorktree{
-				Alias: alias, Path: entry.Path, Branch: entry.Branch, InState: true,
-			}, nil
+	// Every managed worktree the query names, best match first.
+	var matches []resolvedWorktree
+	match := func(alias, how string) {
+		entry := s.Worktrees[alias]
+		matches = append(matches, resolvedWorktree{
+			Alias: alias, Path: entry.Path, Branch: entry.Branch, InState: true, MatchedBy: how,
+		})
+	}
+	if kind == "" || kind == "alias" {
+		if _, ok := s.Get(name); ok {
+			match(name, "alias")
 		}
 	}
+	if kind == "" || kind == "branch" {
+		for _, alias := range aliases {
+			if s.Worktrees[alias].Branch == name {
+				match(alias, "branch")
+			}
+		}
+	}
+	if kind == "" || kind == "path" {
+		for _, alias := range aliases {
+			if pathMatches(kind, s.Worktrees[alias].Path, name) {
+				match(alias, "path")
+			}
+		}
+	}
+	if len(matches) > 0 {
+		resolved := matches[0]
+		for _, m := range matches[1:] {
+			if m.Alias != resolved.Alias && !slices.Contains(resolved.Ambiguous, m.MatchedBy+" of "+m.Alias) {
+				resolved.Ambiguous = append(resolved.Ambiguous, m.MatchedBy+" of "+m.Alias)
+			}
+		}
+		return &resolved, nil
+	}
+	if kind == "alias" {
+		return nil, nil
+	}
 
-	// 4. Orphan worktree (git knows, grove doesn't)
+	// Orphan worktree (git knows, grove doesn't)
 	worktrees, err := git.ListWorktrees()
 	if err != nil {
 		return nil, err
@@ -209,7 +478,7 @@ func resolveWorktree(query string, s state.State) (*resolvedWorktree, error) {
 		if wt.IsMain {
 			continue
 		}
-		if wt.Branch == query || wt.Path == query {
+		if (kind != "path" && wt.Branch == name) || (kind != "branch" && pathMatches(kind, wt.Path, name)) {
 			return &resolvedWorktree{
 				Path: wt.Path, Branch: wt.Branch, InState: false,
 			}, nil
@@ -218,3 +487,21 @@ func resolveWorktree(query string, s state.State) (*resolvedWorktree, error) {
 
 	return nil, nil
 }
+
+// pathMatches reports whether a worktree at path is the one name points
+// to. An explicit path: query may be relative or go through a symlink;
+// without the prefix the path has to match exactly, so an alias or branch
+// that happens to be a relative path isn't mistaken for one.
+func pathMatches(kind, path, name string) bool {
+	if kind != "path" {
+		return path == name
+	}
+	abs, err := filepath.Abs(name)
+	return err == nil && samePath(path, abs)
+}
+
+// ambiguityText says which worktrees an ambiguous query names, e.g.
+// `"auth" is the alias of auth and the branch of auth-2`.
+func ambiguityText(query string, resolved *resolvedWorktree) string {
+	return fmt.Sprintf("%q is the %s of %s and the %s", query, resolved.MatchedBy, resolved.Alias, strings.Join(resolved.Ambiguous, " and the "))
+}
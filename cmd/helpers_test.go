@@ -5,10 +5,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/rowref"
 	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/statuscache"
 )
 
 func TestCompleteAliases(t *testing.T) {
@@ -144,3 +147,120 @@ func TestCompleteAliasesNoRoot(t *testing.T) {
 		t.Errorf("expected 0 completions when no grove root, got %v", completions)
 	}
 }
+
+func TestCachedStatus(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Symlink: []string{}})
+	// .groverc.json itself is untracked; commit it so the tree starts clean.
+	gitCmd(dir, "add", ".")
+	gitCmd(dir, "commit", "-m", "config")
+
+	cache := statuscache.Open(dir)
+	if status, err := cachedStatus(cache, dir); err != nil || status != "clean" {
+		t.Fatalf("first status = %q, %v; want clean", status, err)
+	}
+
+	// An edit to an untracked file doesn't touch HEAD or the index, so the
+	// cached result is served until it expires...
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0644)
+	if status, _ := cachedStatus(cache, dir); status != "clean" {
+		t.Errorf("cached status = %q, want clean", status)
+	}
+
+	// ...unless --no-cache asks for a fresh one.
+	statusNoCache = true
+	t.Cleanup(func() { statusNoCache = false })
+	if status, _ := cachedStatus(cache, dir); status != "1 untracked" {
+		t.Errorf("uncached status = %q, want \"1 untracked\"", status)
+	}
+
+	// Staging changes the index, which invalidates the entry.
+	statusNoCache = false
+	gitCmd(dir, "add", "new.txt")
+	if status, _ := cachedStatus(cache, dir); status != "1 staged" {
+		t.Errorf("status after add = %q, want \"1 staged\"", status)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/code/app-auth", "/code/app-auth", true},
+		{"/code/app-auth/src", "/code/app-auth", true},
+		{"/code/app-auth-v2", "/code/app-auth", false},
+		{"/code/app", "/code/app-auth", false},
+		{"/code/..foo", "/code", true},
+	}
+	for _, tt := range tests {
+		if got := isWithin(tt.path, tt.dir); got != tt.want {
+			t.Errorf("isWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestExpandRowRef(t *testing.T) {
+	root := t.TempDir()
+
+	if got, err := expandRowRef(root, "auth"); err != nil || got != "auth" {
+		t.Errorf("plain alias = %q, %v; want unchanged", got, err)
+	}
+	if _, err := expandRowRef(root, "@1"); exitCode(err) != exitNotFound {
+		t.Errorf("@1 without a listing: err = %v, want not found", err)
+	}
+
+	if err := rowref.Save(root, []string{"/code/app", "/code/app-auth"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := expandRowRef(root, "@2"); err != nil || got != "/code/app-auth" {
+		t.Errorf("@2 = %q, %v", got, err)
+	}
+	if _, err := expandRowRef(root, "@5"); exitCode(err) != exitNotFound {
+		t.Errorf("@5: err = %v, want not found", err)
+	}
+	if got, _ := expandRowRef(root, "@home"); got != "@home" {
+		t.Errorf("@home = %q, want unchanged", got)
+	}
+}
+
+func TestLookupWorktreeKinds(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{})
+	authPath := filepath.Join(dir, "auth")
+	if err := os.Mkdir(authPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := state.State{Worktrees: map[string]state.WorktreeEntry{
+		"auth": {Branch: "feature/auth", Path: authPath},
+		"api":  {Branch: "auth", Path: filepath.Join(dir, "api")},
+	}}
+
+	tests := []struct {
+		query, alias string
+		ambiguous    bool
+	}{
+		{"auth", "auth", true},
+		{"alias:auth", "auth", false},
+		{"branch:auth", "api", false},
+		{"feature/auth", "auth", false},
+		{"path:auth", "auth", false},
+		{"alias:feature/auth", "", false},
+		{"branch:api", "", false},
+	}
+	for _, tt := range tests {
+		got, err := lookupWorktree(tt.query, s)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		alias := ""
+		if got != nil {
+			alias = got.Alias
+		}
+		if alias != tt.alias || (got != nil && (len(got.Ambiguous) > 0) != tt.ambiguous) {
+			t.Errorf("lookupWorktree(%q) = %+v, want alias %q, ambiguous %v", tt.query, got, tt.alias, tt.ambiguous)
+		}
+	}
+
+	if err := validateAlias("branch:x"); err == nil {
+		t.Error("validateAlias should reject a kind prefix")
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// hooksDir holds hook scripts, next to state.json in the project's .grove
+// directory. Teams that want them versioned ignore .grove/* rather than
+// .grove/, and un-ignore them with !.grove/hooks/.
+const hooksDir = ".grove/hooks"
+
+// hookEvents are the events hook scripts can be written for.
+var hookEvents = []string{"after-create", "pre-remove"}
+
+// hookCommand is one hook to run: label is what grove prints and records,
+// command is what it hands to sh -c. script is the hook script's path, or
+// empty for the command from the config.
+type hookCommand struct {
+	label   string
+	command string
+	script  string
+}
+
+// hookCommands lists the hooks for an event: the command from the config
+// first, then the scripts found by hookFiles.
+func hookCommands(root, configured, event string) ([]hookCommand, error) {
+	var hooks []hookCommand
+	if configured != "" {
+		hooks = append(hooks, hookCommand{label: configured, command: configured})
+	}
+	scripts, err := hookFiles(root, event)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range scripts {
+		label, _ := filepath.Rel(root, path)
+		hooks = append(hooks, hookCommand{label: filepath.ToSlash(label), command: shellQuote(path), script: path})
+	}
+	return hooks, nil
+}
+
+// hookFiles returns the hook scripts for event (e.g. "after-create"): the
+// executable file .grove/hooks/<event>, or the executable files inside a
+// .grove/hooks/<event>/ directory in name order — prefix them with numbers
+// ("10-deps", "20-db") to control the order. Dotfiles are skipped, so a
+// .gitkeep doesn't count.
+func hookFiles(root, event string) ([]string, error) {
+	path := filepath.Join(root, hooksDir, event)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if !isExecutable(info) {
+			return nil, nil
+		}
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var scripts []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(path, e.Name()))
+		if err != nil || info.IsDir() || !isExecutable(info) {
+			continue
+		}
+		scripts = append(scripts, filepath.Join(path, e.Name()))
+	}
+	sort.Strings(scripts)
+	return scripts, nil
+}
+
+// isExecutable reports whether a hook script may be run. Windows has no
+// executable bit, so every file counts there.
+func isExecutable(info os.FileInfo) bool {
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestHookCommands(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, hooksDir, "after-create")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "20-db"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(dir, "10-deps"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(dir, "README"), []byte("not a hook\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitkeep"), nil, 0755)
+
+	hooks, err := hookCommands(root, "npm ci", "after-create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, h := range hooks {
+		labels = append(labels, h.label)
+	}
+	want := []string{"npm ci", ".grove/hooks/after-create/10-deps", ".grove/hooks/after-create/20-db"}
+	if len(labels) != len(want) {
+		t.Fatalf("labels = %v, want %v", labels, want)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Errorf("labels[%d] = %q, want %q", i, labels[i], want[i])
+		}
+	}
+	if hooks[1].command != shellQuote(filepath.Join(dir, "10-deps")) {
+		t.Errorf("command = %q, want the quoted absolute path", hooks[1].command)
+	}
+
+	// A single file named after the event works too; no hooks is not an error.
+	os.WriteFile(filepath.Join(root, hooksDir, "pre-remove"), []byte("#!/bin/sh\n"), 0755)
+	if files, _ := hookFiles(root, "pre-remove"); len(files) != 1 {
+		t.Errorf("pre-remove files = %v, want one", files)
+	}
+	if hooks, err := hookCommands(root, "", "missing"); err != nil || len(hooks) != 0 {
+		t.Errorf("missing event = %v, %v", hooks, err)
+	}
+}
+
+func TestCreateRunsHookFiles(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	hooks := filepath.Join(dir, hooksDir, "after-create")
+	os.MkdirAll(hooks, 0755)
+	os.WriteFile(filepath.Join(hooks, "10-mark"), []byte("#!/bin/sh\necho \"$GROVE_ALIAS\" > marker\n"), 0755)
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/hookfiles"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-hookfiles")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	data, err := os.ReadFile(filepath.Join(wtPath, "marker"))
+	if err != nil || strings.TrimSpace(string(data)) != "hookfiles" {
+		t.Errorf("marker = %q, %v; want the hook to run in the worktree", data, err)
+	}
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("hookfiles")
+	if entry.Setup == nil || len(entry.Setup.Hooks) != 1 || entry.Setup.Hooks[0].Command != ".grove/hooks/after-create/10-mark" {
+		t.Errorf("recorded hooks = %+v", entry.Setup)
+	}
+}
@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(importToolCmd)
+}
+
+var importToolCmd = &cobra.Command{
+	Use:   "import-tool <worktrees|script|wtp> [file]",
+	Short: "Migrate to grove from another worktree setup",
+	Long: `Bring an existing worktree setup under grove in one step.
+
+  worktrees         Adopt every git worktree grove doesn't manage yet.
+                    Worktree managers like wt keep their state in git itself,
+                    so this covers them.
+  script <file>     Convert a shell script that set up new worktrees: its
+                    "ln -s" lines become "symlink" entries, .env copies are
+                    dropped (grove copies .env* itself) and everything else,
+                    including links made only under an if or a loop,
+                    becomes the hook .grove/hooks/after-create/50-<file>,
+                    under the script's own shebang. An existing hook of
+                    that name is never overwritten. Existing worktrees are
+                    adopted as well.
+  wtp [file]        Convert wtp's .wtp.yml (or the file given): base_dir
+                    becomes worktreeDir, symlink hooks become "symlink"
+                    entries, .env copies are dropped, and the other copy
+                    and command hooks become .grove/hooks/after-create/
+                    50-wtp.sh. Existing worktrees are adopted as well.
+
+Without a .groverc.json, one is written with worktreeDir and prefix guessed
+from where the existing worktrees live. Worktrees whose alias is taken or
+invalid are skipped with a warning; adopt those by hand.`,
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: []string{"worktrees", "script", "wtp"},
+	RunE:      runImportTool,
+}
+
+func runImportTool(cmd *cobra.Command, args []string) error {
+	var script string
+	switch args[0] {
+	case "worktrees":
+		if len(args) > 1 {
+			return fmt.Errorf("import-tool worktrees takes no file")
+		}
+	case "script":
+		if len(args) < 2 {
+			return fmt.Errorf("import-tool script needs the setup script — e.g. grove import-tool script ./new-worktree.sh")
+		}
+		script = args[1]
+	case "wtp":
+		if len(args) > 1 {
+			script = args[1]
+		}
+	default:
+		return fmt.Errorf("unknown source %q — use 'worktrees' (any tool built on git worktree), 'script' or 'wtp'", args[0])
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := repoRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("not inside a git repository: %w", err)
+	}
+	var symlinks []string
+	var worktreeDir, hook, hookPath string
+	if args[0] == "wtp" {
+		if script == "" {
+			script = filepath.Join(root, ".wtp.yml")
+		}
+		data, err := os.ReadFile(script)
+		if err != nil {
+			return err
+		}
+		if worktreeDir, symlinks, hook, err = parseWtpConfig(string(data)); err != nil {
+			return fmt.Errorf("%s: %w", script, err)
+		}
+		hookPath = filepath.Join(root, hooksDir, "after-create", "50-wtp.sh")
+	} else if script != "" {
+		data, err := os.ReadFile(script)
+		if err != nil {
+			return err
+		}
+		symlinks, hook = parseSetupScript(string(data))
+		hookPath = filepath.Join(root, hooksDir, "after-create", "50-"+filepath.Base(script))
+	}
+	if hook != "" {
+		// Checked before anything is written, so a second import doesn't
+		// leave the config changed and the hook not.
+		if _, err := os.Lstat(hookPath); err == nil {
+			rel, _ := filepath.Rel(root, hookPath)
+			return fmt.Errorf("%s already exists — remove or rename it, then import again", filepath.ToSlash(rel))
+		}
+	}
+
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	orphans, err := findOrphans(s)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(root)
+	switch {
+	case errors.Is(err, config.ErrNoConfig):
+		cfg = config.Default()
+		cfg.WorktreeDir, cfg.Prefix = inferLayout(root, orphans)
+		if worktreeDir != "" {
+			// wtp names a worktree after its branch, with no prefix.
+			cfg.WorktreeDir, cfg.Prefix = worktreeDir, ""
+		}
+		if script != "" {
+			cfg.Symlink = symlinks
+		}
+		if err := config.Save(root, cfg); err != nil {
+			return err
+		}
+		fmt.Printf(plain("  ✓ wrote %s (worktreeDir %q, prefix %q)\n"), config.FileName, cfg.WorktreeDir, cfg.Prefix)
+	case err != nil:
+		return err
+	default:
+		added := false
+		for _, name := range symlinks {
+			if !slices.Contains(cfg.Symlink, name) {
+				cfg.Symlink = append(cfg.Symlink, name)
+				added = true
+			}
+		}
+		if added {
+			if err := config.Save(root, cfg); err != nil {
+				return err
+			}
+			fmt.Printf(plain("  ✓ symlink in %s: %s\n"), config.FileName, strings.Join(cfg.Symlink, ", "))
+		}
+	}
+	registerProject(root, cfg)
+
+	if hook != "" {
+		if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(hookPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(hook)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, hookPath)
+		fmt.Printf(plain("  ✓ hook %s — check it runs on its own\n"), filepath.ToSlash(rel))
+	}
+
+	adopted := 0
+	err = state.Change(root, func(s *state.State) error {
+		for _, o := range orphans {
+			alias := branchAlias(o.Branch)
+			if o.Branch == "" || validateAlias(alias) != nil || s.AliasExists(alias) {
+				fmt.Fprintf(os.Stderr, "  warning: skipped %s — adopt it with 'grove adopt %s'\n", o.Path, o.Path)
+				continue
+			}
+			if err := s.Add(alias, o.Branch, o.Path); err != nil {
+				return err
+			}
+			adopted++
+			fmt.Printf(plain("  ✓ adopted %s → %s\n"), alias, o.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d worktree(s).\n", adopted)
+	return nil
+}
+
+// inferLayout guesses worktreeDir and prefix from existing worktrees: the
+// directory they share, and the "<prefix>-" their names start with before
+// the alias. Falls back to the defaults when they don't agree.
+func inferLayout(root string, worktrees []orphanWorktree) (worktreeDir, prefix string) {
+	worktreeDir, prefix = config.Default().WorktreeDir, filepath.Base(root)
+	if len(worktrees) == 0 {
+		return worktreeDir, prefix
+	}
+
+	dirs := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for _, wt := range worktrees {
+		dirs[filepath.Dir(wt.Path)] = true
+		name := filepath.Base(wt.Path)
+		alias := branchAlias(wt.Branch)
+		switch {
+		case name == alias:
+			prefixes[""] = true
+		case strings.HasSuffix(name, "-"+alias):
+			prefixes[strings.TrimSuffix(name, "-"+alias)] = true
+		default:
+			return worktreeDir, prefix
+		}
+	}
+	if len(dirs) != 1 || len(prefixes) != 1 {
+		return worktreeDir, prefix
+	}
+
+	for dir := range dirs {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return worktreeDir, prefix
+		}
+		worktreeDir = filepath.ToSlash(rel) + "/"
+	}
+	for p := range prefixes {
+		prefix = p
+	}
+	return worktreeDir, prefix
+}
+
+// parseSetupScript splits a worktree setup script into what grove handles
+// itself — symlinked directories, .env copies — and the rest, returned as a
+// hook script: the script's shebang (#!/bin/sh if it has none), set -e, and
+// the remaining commands in their original order. Commands inside if, case,
+// loop and function blocks stay in the hook, since they don't always run.
+func parseSetupScript(text string) (symlinks []string, hook string) {
+	shebang := "#!/bin/sh"
+	var rest []string
+	depth := 0
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if i == 0 && strings.HasPrefix(trimmed, "#!") {
+			shebang = trimmed
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "set -e" {
+			continue
+		}
+		fields := strings.Fields(trimmed)
+		inBlock := depth > 0
+		depth = max(depth+blockDepth(fields), 0)
+		switch {
+		case inBlock:
+			rest = append(rest, line)
+		case fields[0] == "ln" && len(fields) >= 3 && strings.HasPrefix(fields[1], "-") && strings.Contains(fields[1], "s"):
+			// "ln -s ../app/node_modules node_modules", or "... ." for
+			// a link named after its source.
+			name := filepath.Base(strings.Trim(fields[len(fields)-1], `"'`))
+			if name == "." || name == "/" {
+				name = filepath.Base(strings.Trim(fields[len(fields)-2], `"'`))
+			}
+			if !slices.Contains(symlinks, name) {
+				symlinks = append(symlinks, name)
+			}
+		case fields[0] == "cp" && strings.Contains(trimmed, ".env"):
+			// grove create copies every .env* file already.
+		default:
+			rest = append(rest, line)
+		}
+	}
+	sort.Strings(symlinks)
+	if len(rest) > 0 {
+		hook = shebang + "\nset -e\n" + strings.Join(rest, "\n") + "\n"
+	}
+	return symlinks, hook
+}
+
+// blockDepth is how many shell blocks (if, case, loops, { } bodies) a line
+// opens, less how many it closes. Keywords only count where a command
+// starts, so "echo done" closes nothing.
+func blockDepth(fields []string) int {
+	depth := 0
+	start := true
+	for _, field := range fields {
+		word := strings.TrimSuffix(field, ";")
+		if start {
+			switch word {
+			case "if", "case", "for", "while", "until", "select", "{":
+				depth++
+			case "fi", "esac", "done", "}":
+				depth--
+			}
+		} else if word == "{" {
+			depth++ // "setup() {"
+		}
+		start = word != field || slices.Contains([]string{"then", "do", "else", "&&", "||", "|", "{"}, word)
+	}
+	return depth
+}
+
+// wtpHook is one post_create hook of wtp's .wtp.yml.
+type wtpHook struct {
+	Type, From, To, Command, WorkDir string
+	Env                              []string // NAME=value, in file order
+}
+
+// parseWtpConfig reads wtp's .wtp.yml: defaults.base_dir and the
+// hooks.post_create list of copy, symlink and command hooks. Like
+// internal/manifest, it understands only that subset of YAML — block
+// mappings and lists, with plain or quoted scalars — rather than pull in a
+// YAML dependency. It returns base_dir as a worktreeDir, the symlink hooks
+// as symlink entries, and the rest as a hook script for after-create. Copies
+// of .env* files are dropped, since grove copies those itself.
+func parseWtpConfig(text string) (worktreeDir string, symlinks []string, hook string, err error) {
+	var hooks []wtpHook
+	section, list := "", ""
+	listIndent, envIndent := 0, -1
+	for i, line := range strings.Split(text, "\n") {
+		line = stripYAMLComment(line)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			section, list = yamlKey(trimmed), ""
+			continue
+		}
+		item, isItem := strings.CutPrefix(trimmed, "- ")
+		key, value, ok := strings.Cut(item, ":")
+		if !ok {
+			return "", nil, "", fmt.Errorf("line %d: expected \"key: value\", got %q", i+1, trimmed)
+		}
+		key, value = strings.TrimSpace(key), yamlScalar(value)
+		switch {
+		case section == "defaults":
+			if key == "base_dir" {
+				worktreeDir = strings.TrimSuffix(value, "/") + "/"
+			}
+		case section != "hooks":
+		case !isItem && value == "" && (list == "" || indent <= listIndent):
+			list, listIndent = key, indent
+		case list != "post_create":
+		case isItem:
+			hooks = append(hooks, wtpHook{})
+			envIndent = -1
+			fallthrough
+		default:
+			if len(hooks) == 0 {
+				return "", nil, "", fmt.Errorf("line %d: %q outside a hook", i+1, trimmed)
+			}
+			h := &hooks[len(hooks)-1]
+			if envIndent >= 0 && indent > envIndent {
+				h.Env = append(h.Env, key+"="+value)
+				continue
+			}
+			envIndent = -1
+			switch key {
+			case "type":
+				h.Type = value
+			case "from":
+				h.From = value
+			case "to":
+				h.To = value
+			case "command":
+				h.Command = value
+			case "work_dir":
+				h.WorkDir = value
+			case "env":
+				envIndent = indent
+			}
+		}
+	}
+
+	var rest []string
+	for _, h := range hooks {
+		switch h.Type {
+		case "symlink":
+			name := filepath.Base(cmp.Or(h.To, h.From))
+			if !slices.Contains(symlinks, name) {
+				symlinks = append(symlinks, name)
+			}
+		case "copy":
+			if strings.HasPrefix(filepath.Base(h.From), ".env") {
+				continue // grove create copies every .env* file already.
+			}
+			rest = append(rest, fmt.Sprintf(`cp -R "$GROVE_ROOT"/%s %s`, shellArg(h.From), shellArg(cmp.Or(h.To, h.From))))
+		case "command":
+			command := h.Command
+			for j := len(h.Env) - 1; j >= 0; j-- {
+				name, value, _ := strings.Cut(h.Env[j], "=")
+				command = name + "=" + shellArg(value) + " " + command
+			}
+			if h.WorkDir != "" && h.WorkDir != "." {
+				command = fmt.Sprintf("(cd %s && %s)", shellArg(h.WorkDir), command)
+			}
+			rest = append(rest, command)
+		default:
+			return "", nil, "", fmt.Errorf("unknown post_create hook type %q — convert it to a grove hook by hand", h.Type)
+		}
+	}
+	sort.Strings(symlinks)
+	if len(rest) > 0 {
+		hook = "#!/bin/sh\nset -e\n" + strings.Join(rest, "\n") + "\n"
+	}
+	return worktreeDir, symlinks, hook, nil
+}
+
+// yamlKey is the key of a "key:" or "key: value" line.
+func yamlKey(line string) string {
+	key, _, _ := strings.Cut(line, ":")
+	return strings.TrimSpace(key)
+}
+
+// yamlScalar is a YAML scalar value without its quotes.
+func yamlScalar(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// stripYAMLComment drops a "# comment" from line, unless the # is inside
+// quotes or part of a word.
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return line
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestParseSetupScript(t *testing.T) {
+	script := `#!/bin/bash
+set -e
+# new worktree setup
+cp ../app/.env .env
+ln -s ../app/node_modules node_modules
+ln -sf "$ROOT/vendor" .
+npm run build
+if [ -f Makefile ]; then make deps; fi
+if [ -d ../app/.cache ]; then
+  ln -s ../app/.cache .cache
+fi
+`
+	symlinks, hook := parseSetupScript(script)
+	// A link made only sometimes stays in the hook, under the same condition.
+	if strings.Join(symlinks, ",") != "node_modules,vendor" {
+		t.Errorf("symlinks = %v", symlinks)
+	}
+	want := "#!/bin/bash\nset -e\nnpm run build\nif [ -f Makefile ]; then make deps; fi\nif [ -d ../app/.cache ]; then\n  ln -s ../app/.cache .cache\nfi\n"
+	if hook != want {
+		t.Errorf("hook = %q, want %q", hook, want)
+	}
+
+	if _, hook := parseSetupScript("echo done\nmake\n"); hook != "#!/bin/sh\nset -e\necho done\nmake\n" {
+		t.Errorf("without a shebang: hook = %q", hook)
+	}
+}
+
+func TestImportScriptKeepsExistingHook(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	script := filepath.Join(t.TempDir(), "setup.sh")
+	os.WriteFile(script, []byte("ln -s ../app/node_modules node_modules\nnpm ci\n"), 0755)
+
+	hook := filepath.Join(dir, hooksDir, "after-create", "50-setup.sh")
+	os.MkdirAll(filepath.Dir(hook), 0755)
+	os.WriteFile(hook, []byte("#!/bin/sh\nmine\n"), 0755)
+
+	if err := runImportTool(importToolCmd, []string{"script", script}); err == nil {
+		t.Fatal("expected import to refuse to overwrite an existing hook")
+	}
+	if data, _ := os.ReadFile(hook); string(data) != "#!/bin/sh\nmine\n" {
+		t.Errorf("hook overwritten: %q", data)
+	}
+	if cfg, _ := config.Load(dir); len(cfg.Symlink) != 0 {
+		t.Errorf("config changed before the refusal: symlink = %v", cfg.Symlink)
+	}
+}
+
+func TestInferLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		worktrees []orphanWorktree
+		dir, pfx  string
+	}{
+		{"prefixed siblings", []orphanWorktree{
+			{Path: "/code/shop-auth", Branch: "feature/auth"},
+			{Path: "/code/shop-pay", Branch: "pay"},
+		}, "../", "shop"},
+		{"bare aliases in a subdir", []orphanWorktree{
+			{Path: "/code/wt/auth", Branch: "feature/auth"},
+		}, "../wt/", ""},
+		{"no pattern", []orphanWorktree{
+			{Path: "/code/scratch", Branch: "feature/auth"},
+		}, "../", "app"},
+	}
+	for _, tt := range tests {
+		dir, pfx := inferLayout("/code/app", tt.worktrees)
+		if dir != tt.dir || pfx != tt.pfx {
+			t.Errorf("%s: inferLayout = %q, %q; want %q, %q", tt.name, dir, pfx, tt.dir, tt.pfx)
+		}
+	}
+}
+
+func TestImportWorktrees(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{})
+	os.Remove(filepath.Join(dir, config.FileName))
+
+	wtPath := filepath.Join(filepath.Dir(dir), "legacy-login")
+	add := exec.Command("git", "worktree", "add", "-b", "feature/login", wtPath)
+	add.Dir = dir
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add: %s", out)
+	}
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	if err := runImportTool(importToolCmd, []string{"worktrees"}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WorktreeDir != "../" || cfg.Prefix != "legacy" {
+		t.Errorf("config = %+v, want worktreeDir ../ and prefix legacy", cfg)
+	}
+	s, _ := state.Load(dir)
+	if e, ok := s.Get("login"); !ok || e.Path != wtPath {
+		t.Errorf("state = %+v, want login adopted", s.Worktrees)
+	}
+}
+
+func TestParseWtpConfig(t *testing.T) {
+	// As in wtp's README.
+	wtp := `version: "1.0"
+defaults:
+  # Base directory for worktrees (relative to repository root)
+  base_dir: "../worktrees"
+
+hooks:
+  post_create:
+    # Copy gitignored files from the main worktree
+    - type: copy
+      from: ".env"     # Relative to main worktree
+      to: ".env"
+    - type: copy
+      from: "config/master.key"
+      to: "config/master.key"
+
+    # Share directories with symlinks
+    - type: symlink
+      from: "node_modules"
+      to: "node_modules"
+
+    - type: command
+      command: "npm ci"
+      env:
+        NODE_ENV: "development"
+        NPM_TOKEN: 'it''s secret'
+    - type: command
+      command: "make db # with a hash"
+      work_dir: "backend"
+`
+	dir, symlinks, hook, err := parseWtpConfig(wtp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != "../worktrees/" {
+		t.Errorf("worktreeDir = %q", dir)
+	}
+	if strings.Join(symlinks, ",") != "node_modules" {
+		t.Errorf("symlinks = %v", symlinks)
+	}
+	want := "#!/bin/sh\nset -e\n" +
+		`cp -R "$GROVE_ROOT"/config/master.key config/master.key` + "\n" +
+		`NODE_ENV=development NPM_TOKEN='it'\''s secret' npm ci` + "\n" +
+		"(cd backend && make db # with a hash)\n"
+	if hook != want {
+		t.Errorf("hook = %q, want %q", hook, want)
+	}
+
+	if _, _, _, err := parseWtpConfig("hooks:\n  post_create:\n    - type: rsync\n"); err == nil {
+		t.Error("an unknown hook type should be an error")
+	}
+}
+
+func TestImportWtp(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{})
+	os.Remove(filepath.Join(dir, config.FileName))
+	wtp := "defaults:\n  base_dir: ../worktrees\nhooks:\n  post_create:\n    - type: symlink\n      from: vendor\n      to: vendor\n    - type: command\n      command: make deps\n"
+	if err := os.WriteFile(filepath.Join(dir, ".wtp.yml"), []byte(wtp), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runImportTool(importToolCmd, []string{"wtp"}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WorktreeDir != "../worktrees/" || cfg.Prefix != "" || strings.Join(cfg.Symlink, ",") != "vendor" {
+		t.Errorf("config = %+v, want worktreeDir ../worktrees/, no prefix, symlink vendor", cfg)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, hooksDir, "after-create", "50-wtp.sh")); err != nil || string(data) != "#!/bin/sh\nset -e\nmake deps\n" {
+		t.Errorf("hook = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info <name>",
+	Short: "Show what grove knows about a worktree",
+	Long: `Show a worktree's entry from .grove/state.json: branch, path, base,
+upstream, template and tags, plus what grove create set up in it — copied
+.env and template files, symlinks, sparse checkout, applied patch and the
+hooks that ran with their exit codes.
+
+Accepts an alias, branch or path. Use --json for the raw entry.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runInfo,
+}
+
+// worktreeInfo is the --json shape of `grove info`.
+type worktreeInfo struct {
+	Alias       string `json:"alias"`
+	Description string `json:"description,omitempty"` // the branch's, from git config
+	state.WorktreeEntry
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	query, err := expandRowRef(root, args[0])
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil || !resolved.InState {
+		return errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", args[0])
+	}
+	entry, _ := s.Get(resolved.Alias)
+	desc := git.BranchDescription(entry.Branch)
+
+	if jsonOutput {
+		entry.Path = displayPath(entry.Path)
+		data, err := json.MarshalIndent(worktreeInfo{Alias: resolved.Alias, Description: desc, WorktreeEntry: entry}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Print(formatInfo(resolved.Alias, entry, desc))
+	return nil
+}
+
+// formatInfo renders an entry, and its branch's description, as aligned
+// "Label: value" lines, skipping fields that are empty.
+func formatInfo(alias string, e state.WorktreeEntry, description string) string {
+	var sb strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%-10s %s\n", label+":", value)
+		}
+	}
+
+	line("Alias", alias)
+	line("Branch", e.Branch)
+	// Later lines of a description line up under the first.
+	line("Description", strings.ReplaceAll(description, "\n", "\n"+strings.Repeat(" ", len("Description: "))))
+	line("Path", displayPath(e.Path))
+	if !e.Created.IsZero() {
+		created := e.Created.Format(time.DateTime)
+		if e.Version != "" {
+			created += " by grove " + e.Version
+		}
+		line("Created", created)
+	}
+	line("Base", e.Base)
+	line("Upstream", e.Upstream)
+	line("Template", e.Template)
+	line("Tags", strings.Join(e.Tags, ", "))
+	line("Review", e.Review)
+	if e.PR > 0 {
+		line("Pull request", fmt.Sprintf("#%d (grove sync --prs)", e.PR))
+	}
+	if !e.Expires.IsZero() {
+		line("Expires", e.Expires.Format(time.DateTime))
+	}
+	line("Note", e.Note)
+	if e.Claim != nil {
+		line("Claimed", fmt.Sprintf("by %s since %s", e.Claim.Owner, e.Claim.Since.Format(time.DateTime)))
+	}
+
+	sb.WriteString("\n")
+	if e.Setup == nil {
+		sb.WriteString("Setup: not recorded (adopted, or created by an older grove)\n")
+		return sb.String()
+	}
+	sb.WriteString("Setup:\n")
+	if e.Setup.Failed != "" {
+		fmt.Fprintf(&sb, "  failed at %s — run 'grove retry %s' to finish\n", e.Setup.Failed, alias)
+	}
+	setupLine := func(label string, values []string) {
+		if len(values) > 0 {
+			fmt.Fprintf(&sb, "  %-10s %s\n", label+":", strings.Join(values, ", "))
+		}
+	}
+	setupLine("env files", e.Setup.EnvFiles)
+	if e.Setup.EnvProfile != "" {
+		setupLine("env", []string{e.Setup.EnvProfile})
+	}
+	setupLine("copied", e.Setup.Copied)
+	setupLine("rendered", e.Setup.Rendered)
+	setupLine("symlinks", e.Setup.Symlinks)
+	setupLine("hardlinks", e.Setup.Hardlinks)
+	setupLine("sparse", e.Setup.Sparse)
+	if e.Setup.Banner != "" {
+		setupLine("banner", []string{e.Setup.Banner})
+	}
+	if e.Setup.Applied != "" {
+		setupLine("applied", []string{e.Setup.Applied})
+	}
+	if e.Setup.Database != "" {
+		setupLine("database", []string{e.Setup.Database})
+	}
+	if e.Setup.Namespace != "" {
+		setupLine("namespace", []string{e.Setup.Namespace})
+	}
+	if e.Setup.ReadOnly {
+		setupLine("read-only", []string{"yes (grove unlock-files to undo)"})
+	}
+	for _, h := range e.Setup.Hooks {
+		fmt.Fprintf(&sb, plain("  %-10s %s → exit %d (%s)\n"), h.Name+":", h.Command, h.ExitCode, h.Ran.Format(time.DateTime))
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestCreateRecordsSetup(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{"node_modules"},
+		AfterCreate: "true",
+	})
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=3000\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "node_modules"), 0755)
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/setup"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-setup")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("setup")
+	if entry.Setup == nil {
+		t.Fatal("expected setup to be recorded")
+	}
+	if strings.Join(entry.Setup.EnvFiles, ",") != ".env" {
+		t.Errorf("envFiles = %v, want [.env]", entry.Setup.EnvFiles)
+	}
+	if strings.Join(entry.Setup.Symlinks, ",") != "node_modules" {
+		t.Errorf("symlinks = %v, want [node_modules]", entry.Setup.Symlinks)
+	}
+	if len(entry.Setup.Hooks) != 1 || entry.Setup.Hooks[0].Command != "true" || entry.Setup.Hooks[0].ExitCode != 0 {
+		t.Errorf("hooks = %+v", entry.Setup.Hooks)
+	}
+
+	out := formatInfo("setup", entry, "")
+	for _, want := range []string{"Branch:    feature/setup", "env files: .env", "afterCreate: true → exit 0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("info output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -61,6 +62,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if err := config.Save(cwd, cfg); err != nil {
 		return err
 	}
+	registerProject(cwd, cfg)
 
 	fmt.Println()
 	fmt.Println("Created .groverc.json")
@@ -84,11 +86,17 @@ func runInit(cmd *cobra.Command, args []string) error {
 var reader *bufio.Reader
 
 func prompt(question, defaultVal string) string {
+	return promptTo(os.Stdout, question, defaultVal)
+}
+
+// promptTo is prompt with the question written to w — stderr for commands
+// whose stdout is captured, like grove cd.
+func promptTo(w io.Writer, question, defaultVal string) string {
 	if reader == nil {
 		reader = bufio.NewReader(os.Stdin)
 	}
 
-	fmt.Print(question + ": ")
+	fmt.Fprint(w, question+": ")
 
 	line, _ := reader.ReadString('\n')
 	line = strings.TrimSpace(line)
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+// jobsFlag is --jobs; 0 means "jobs" from .groverc.json.
+var jobsFlag int
+
+// jobs is how many worktrees grove works on at once — git status for list
+// and clean, grove refresh's snapshot, exec --all and create --from-file.
+// Set by configureJobs.
+var jobs = 1
+
+// configureJobs reads the concurrency limit from --jobs, or "jobs" in cfg,
+// defaulting to the number of CPUs.
+func configureJobs(cfg config.Config) {
+	jobs, _ = config.Config{}.EffectiveJobs()
+	if jobsFlag < 0 {
+		fmt.Fprintf(os.Stderr, "warning: --jobs must be 1 or more, got %d — using %d\n", jobsFlag, jobs)
+		return
+	}
+	if jobsFlag > 0 {
+		jobs = jobsFlag
+		return
+	}
+	if n, err := cfg.EffectiveJobs(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	} else {
+		jobs = n
+	}
+}
+
+// parallel calls fn(i) for every i in [0, n), running at most jobs calls at
+// a time, and returns once all of them have. fn must be safe to run
+// concurrently; writing to its own slot of a slice is.
+func parallel(n int, fn func(i int)) {
+	limit := make(chan struct{}, max(jobs, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
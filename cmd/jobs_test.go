@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestParallel(t *testing.T) {
+	jobs = 3
+	t.Cleanup(func() { jobs = 1 })
+
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := map[int]bool{}
+	parallel(10, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != 10 {
+		t.Errorf("ran %d of 10 calls", len(seen))
+	}
+	if peak.Load() > 3 {
+		t.Errorf("ran %d calls at once, want at most 3", peak.Load())
+	}
+}
+
+func TestConfigureJobs(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{Jobs: 2})
+	t.Cleanup(func() { jobs, jobsFlag = 1, 0 })
+
+	_, cfg := startupConfig()
+	configureJobs(cfg)
+	if jobs != 2 {
+		t.Errorf("jobs = %d, want 2 from the config", jobs)
+	}
+	jobsFlag = 5
+	configureJobs(cfg)
+	if jobs != 5 {
+		t.Errorf("jobs = %d, want 5 from --jobs", jobs)
+	}
+
+	if _, err := (config.Config{Jobs: -1}).EffectiveJobs(); err == nil {
+		t.Error("expected an error for negative jobs")
+	}
+	if n, _ := (config.Config{}).EffectiveJobs(); n < 1 || n > config.MaxDefaultJobs {
+		t.Errorf("default jobs = %d, want 1–%d", n, config.MaxDefaultJobs)
+	}
+}
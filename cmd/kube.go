@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+// unsafeNamespaceChars are replaced in Kubernetes namespace names, which must
+// be DNS labels: lowercase letters, digits and '-'.
+var unsafeNamespaceChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// kubeNamespace renders the configured namespace for a worktree, turned into
+// a valid DNS label ("feature/Auth_v2" → "feature-auth-v2"). Fails when
+// nothing of it is left, e.g. for the alias "___" with "{{alias}}".
+func kubeNamespace(kube config.KubeConfig, prefix, alias, branch string) (string, error) {
+	vars := map[string]string{"alias": alias, "branch": branch, "prefix": prefix}
+	name := unsafeNamespaceChars.ReplaceAllString(strings.ToLower(expandTemplate(kube.Namespace, vars)), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	if name = strings.Trim(name, "-"); name == "" {
+		return "", fmt.Errorf("kube namespace %q gives no valid name for %q — use letters or digits in the alias, or change \"namespace\" in .groverc.json", kube.Namespace, alias)
+	}
+	return name, nil
+}
+
+// kubeVars are the template values for the "kube" config's commands and env.
+func kubeVars(cfg config.Config, alias, branch, namespace string) map[string]string {
+	return map[string]string{
+		"namespace": namespace,
+		"context":   cfg.Kube.Context,
+		"alias":     alias,
+		"branch":    branch,
+		"prefix":    cfg.Prefix,
+	}
+}
+
+// kubeEnv exports a worktree's namespace and context to hooks, so
+// afterCreate and beforeRemove scripts can target the right cluster objects.
+func kubeEnv(kube config.KubeConfig, namespace string) []string {
+	if namespace == "" {
+		return nil
+	}
+	env := []string{"GROVE_K8S_NAMESPACE=" + namespace}
+	if kube.Context != "" {
+		env = append(env, "GROVE_K8S_CONTEXT="+kube.Context)
+	}
+	return env
+}
+
+// runKube runs one of the "kube" config's commands (up or down) in the
+// worktree at path. An empty command does nothing.
+func runKube(root string, cfg config.Config, name, command, alias, branch, path, namespace string, w io.Writer) error {
+	if command == "" {
+		return nil
+	}
+	command = expandCommand(command, kubeVars(cfg, alias, branch, namespace))
+	fmt.Fprintf(w, "  running: %s\n", command)
+	env := append(hookEnv(root, alias, branch, path), kubeEnv(cfg.Kube, namespace)...)
+	if err := runHook(cfg.Hooks, command, path, env, w); err != nil {
+		return fmt.Errorf("kube %s command failed: %w", name, err)
+	}
+	return nil
+}
+
+// kubeDown runs the configured down command for a worktree's namespace.
+func kubeDown(root string, cfg config.Config, alias, branch, path, namespace string, w io.Writer) error {
+	return runKube(root, cfg, "down", cfg.Kube.Down, alias, branch, path, namespace, w)
+}
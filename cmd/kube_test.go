@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestKubeNamespace(t *testing.T) {
+	tests := []struct {
+		tpl, alias, branch, want string
+	}{
+		{"{{prefix}}-{{alias}}", "auth", "feature/auth", "myapp-auth"},
+		{"dev-{{branch}}", "auth", "feature/Auth_v2", "dev-feature-auth-v2"},
+		{"{{alias}}-", "x", "x", "x"},
+	}
+	for _, tt := range tests {
+		got, err := kubeNamespace(config.KubeConfig{Namespace: tt.tpl}, "myapp", tt.alias, tt.branch)
+		if err != nil || got != tt.want {
+			t.Errorf("kubeNamespace(%q, %q) = %q, %v, want %q", tt.tpl, tt.branch, got, err, tt.want)
+		}
+	}
+
+	if got, err := kubeNamespace(config.KubeConfig{Namespace: "{{alias}}"}, "myapp", "___", "x"); err == nil {
+		t.Errorf("kubeNamespace for alias ___ = %q, want an error", got)
+	}
+}
+
+func TestKubeEnv(t *testing.T) {
+	if env := kubeEnv(config.KubeConfig{Context: "kind-dev"}, ""); env != nil {
+		t.Errorf("kubeEnv without namespace = %v, want nil", env)
+	}
+	env := kubeEnv(config.KubeConfig{Context: "kind-dev"}, "myapp-auth")
+	if len(env) != 2 || env[0] != "GROVE_K8S_NAMESPACE=myapp-auth" || env[1] != "GROVE_K8S_CONTEXT=kind-dev" {
+		t.Errorf("kubeEnv = %v", env)
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+)
+
+func init() {
+	rootCmd.AddCommand(lintConfigCmd)
+}
+
+var lintConfigCmd = &cobra.Command{
+	Use:   "lint-config [file...]",
+	Short: "Check .groverc.json and hook scripts, for pre-commit hooks",
+	Long: `Check .groverc.json and .grove/hooks without running anything, and exit
+non-zero if there are errors — so a broken team config never lands on main:
+
+  - the file is valid JSON, with no unknown or mistyped settings (typos
+    are reported with the setting that was probably meant)
+  - settings with fixed values (linkMode, onDirtyRemove, ...) have one
+  - symlink, copy and render paths stay inside the project
+  - template expiries parse
+  - every file in .grove/hooks is executable and named after an event
+    (after-create, pre-remove); grove skips the rest without a word
+
+It never asks anything and doesn't touch git, so it's quick enough for a
+pre-commit hook. It checks the project's config, or the config files
+passed, each with the .grove/hooks next to it. Warnings are printed but don't fail the
+check. --json prints the findings for tools.`,
+	RunE: runLintConfig,
+}
+
+// lintFinding is a config.Finding with the file it's in, for output.
+type lintFinding struct {
+	File string `json:"file"`
+	config.Finding
+}
+
+func runLintConfig(cmd *cobra.Command, args []string) error {
+	files := args
+	if len(files) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		root, err := config.FindRoot(cwd)
+		if err != nil {
+			return err
+		}
+		files = []string{filepath.Join(root, config.FileName)}
+	}
+
+	var findings []lintFinding
+	for _, file := range files {
+		found, err := lintConfigFile(file)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, found...)
+	}
+
+	errs := 0
+	for _, f := range findings {
+		if f.Severity == config.SeverityError {
+			errs++
+		}
+	}
+	if jsonOutput {
+		if findings == nil {
+			findings = []lintFinding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, f := range findings {
+			where := f.File
+			if f.Field != "" {
+				where += ": " + f.Field
+			}
+			fmt.Printf("%s: %s: %s\n", where, f.Severity, f.Message)
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf("%d error(s) in the grove config — fix them before committing", errs)
+	}
+	if !jsonOutput && len(findings) == 0 {
+		fmt.Println("Config OK.")
+	}
+	return nil
+}
+
+// lintConfigFile checks one .groverc.json and the hook scripts next to it.
+// Paths in the findings are relative to the current directory, like the
+// ones pre-commit passes in.
+func lintConfigFile(file string) ([]lintFinding, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	display := file
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			display = rel
+		}
+	}
+
+	var findings []lintFinding
+	for _, f := range config.Lint(data) {
+		findings = append(findings, lintFinding{File: display, Finding: f})
+	}
+
+	// Expiries use grove's own duration syntax, which config can't parse.
+	var cfg config.Config
+	if json.Unmarshal(data, &cfg) == nil {
+		names := make([]string, 0, len(cfg.Templates))
+		for name := range cfg.Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if expire := cfg.Templates[name].Expire; expire != "" {
+				if _, err := parseTTL(expire); err != nil {
+					findings = append(findings, lintFinding{File: display, Finding: config.Finding{Field: "templates." + name + ".expire", Severity: config.SeverityError, Message: err.Error()}})
+				}
+			}
+		}
+	}
+
+	hooks, err := lintHookScripts(filepath.Dir(file), filepath.Dir(display))
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, hooks...), nil
+}
+
+// lintHookScripts reports files in .grove/hooks under root that grove
+// would skip: not executable, or not named after a hook event. Their paths
+// are reported under display, root as the user spells it.
+func lintHookScripts(root, display string) ([]lintFinding, error) {
+	dir := filepath.Join(root, hooksDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []lintFinding
+	add := func(path, severity, msg string) {
+		rel, _ := filepath.Rel(root, path)
+		findings = append(findings, lintFinding{File: filepath.ToSlash(filepath.Join(display, rel)), Finding: config.Finding{Severity: severity, Message: msg}})
+	}
+	checkScript := func(path string) {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return
+		}
+		if !isExecutable(info) {
+			add(path, config.SeverityError, "not executable, so grove skips it — run 'chmod +x "+filepath.ToSlash(path)+"' and commit the mode")
+		}
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if e.Name() == "before-remove" {
+			add(path, config.SeverityWarning, "the event is called pre-remove, so grove never runs this — rename it to pre-remove")
+			continue
+		}
+		if !slices.Contains(hookEvents, e.Name()) {
+			add(path, config.SeverityWarning, "not a hook event, so grove never runs it — name it "+strings.Join(hookEvents, " or "))
+			continue
+		}
+		if !e.IsDir() {
+			checkScript(path)
+			continue
+		}
+		scripts, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range scripts {
+			if !strings.HasPrefix(s.Name(), ".") {
+				checkScript(filepath.Join(path, s.Name()))
+			}
+		}
+	}
+	return findings, nil
+}
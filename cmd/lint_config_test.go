@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestLintConfig(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop", Symlink: []string{"node_modules"}})
+
+	out := captureStdout(t, func() {
+		if err := runLintConfig(lintConfigCmd, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(string(out), "Config OK.") {
+		t.Errorf("a valid config should pass, got:\n%s", out)
+	}
+
+	// A bad template expiry, a hook that isn't executable and one with a
+	// name grove doesn't know.
+	if err := os.WriteFile(filepath.Join(dir, config.FileName), []byte(`{"prefix": "shop", "templates": {"spike": {"expire": "soon"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks := filepath.Join(dir, hooksDir)
+	if err := os.MkdirAll(filepath.Join(hooks, "after-create"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range map[string]os.FileMode{"after-create/10-deps": 0644, "after-create/20-db": 0755, "after_create": 0755, "before-remove": 0755} {
+		if err := os.WriteFile(filepath.Join(hooks, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var err error
+	out = captureStdout(t, func() { err = runLintConfig(lintConfigCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "2 error(s)") {
+		t.Errorf("expected 2 errors, got %v", err)
+	}
+	for _, want := range []string{
+		`.groverc.json: templates.spike.expire: error: invalid duration "soon"`,
+		".grove/hooks/after-create/10-deps: error: not executable",
+		".grove/hooks/after_create: warning: not a hook event",
+		".grove/hooks/before-remove: warning: the event is called pre-remove",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "20-db") {
+		t.Errorf("an executable hook shouldn't be reported:\n%s", out)
+	}
+}
@@ -3,26 +3,80 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/registry"
+	"github.com/verbaux/grove/internal/rowref"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/statuscache"
 )
 
+var (
+	listLimit   int
+	listAll     bool
+	listGroupBy string
+)
+
+// listPageSize is how many worktrees grove list shows in a terminal before
+// summarizing the rest.
+const listPageSize = 50
+
 func init() {
-	listCmd.Flags().BoolP("plain", "p", false, "Print only worktree aliases, one per line")
+	listCmd.Flags().BoolP("names", "p", false, "Print only worktree aliases, one per line")
+	listCmd.Flags().Bool("base", false, "Show a BASE column with commits ahead of the branch each worktree was created from")
+	listCmd.Flags().Bool("all-repos", false, "List managed worktrees of every registered project on this machine")
+	listCmd.Flags().BoolVar(&statusNoCache, "no-cache", false, "Run git status in every worktree instead of reusing recent results")
+	listCmd.Flags().IntVar(&listLimit, "limit", listPageSize, "Show at most this many worktrees (0 = all)")
+	listCmd.Flags().BoolVar(&listAll, "all", false, "Show every worktree, however many there are")
+	listCmd.MarkFlagsMutuallyExclusive("limit", "all")
+	listAge.addFlags(listCmd)
+	listCmd.Flags().StringVar(&listGroupBy, "group-by", "", "Show worktrees in sections by tag, dir (parent directory) or base (branch created from)")
+	listCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions([]string{groupByTag, groupByDir, groupByBase}, cobra.ShellCompDirectiveNoFileComp))
 	rootCmd.AddCommand(listCmd)
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all worktrees",
-	Long:  "Show a table of all active worktrees with their branch, path, and git status.",
+	Long: `Show a table of all active worktrees with their branch, path, and git status.
+
+With --all-repos, show the grove-managed worktrees of every project on this
+machine, grouped by project. Projects are registered by grove init and
+grove create.
+
+Status results are cached for a few seconds while a worktree's HEAD and index
+are unchanged, so prompts and repeated calls stay fast. Use --no-cache to
+force a fresh git status everywhere.
+
+In a terminal, only the first 50 worktrees are shown, followed by a count of
+the rest — grove doesn't even run git status for those. --limit changes the
+number, --all shows everything. Piped output is never cut short unless
+--limit is given.
+
+--group-by tag|dir|base shows the worktrees in sections: by tag (a worktree
+with several tags appears under each), by parent directory, or by the
+branch each was created from.
+
+--since and --not-since filter by when each worktree last had a commit (or
+was created, if later): --not-since 30d shows the ones idle for a month,
+--since 2w the ones worked on lately. They combine with --names, so
+'grove list --names --not-since 30d' feeds scripts the stale aliases.`,
 	RunE:  runList,
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	if allRepos, _ := cmd.Flags().GetBool("all-repos"); allRepos {
+		return runListAllRepos()
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return err
@@ -33,18 +87,43 @@ func runList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	rows, err := buildWorktreeRows(root)
+	switch listGroupBy {
+	case "", groupByTag, groupByDir, groupByBase:
+	default:
+		return fmt.Errorf("invalid --group-by %q — use tag, dir or base", listGroupBy)
+	}
+
+	names, _ := cmd.Flags().GetBool("names")
+	limit := 0
+	if !names && !listAll && (cmd.Flags().Changed("limit") || isatty.IsTerminal(os.Stdout.Fd())) {
+		if listLimit < 0 {
+			return fmt.Errorf("--limit must be 0 or more, got %d", listLimit)
+		}
+		limit = listLimit
+	}
+	// Rows past the limit skip git status — unless a filter could drop the
+	// ones before them.
+	if !listAge.set() {
+		rowLimit = limit
+	}
+	defer func() { rowLimit = 0 }()
+
+	rows, refreshed, err := listRows(root)
 	if err != nil {
 		return err
 	}
+	if listAge.set() {
+		if rows, err = filterRowsByAge(root, rows, listAge); err != nil {
+			return err
+		}
+	}
 
 	if len(rows) == 0 {
 		fmt.Println("No worktrees found.")
 		return nil
 	}
 
-	plain, _ := cmd.Flags().GetBool("plain")
-	if plain {
+	if names {
 		for _, r := range rows {
 			if r.Name != "?" && r.Name != "main" {
 				fmt.Println(r.Name)
@@ -53,11 +132,300 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Println(renderTable(rows))
+	shown := rows
+	if limit > 0 && len(rows) > limit {
+		shown = rows[:limit]
+	}
+	showBase, _ := cmd.Flags().GetBool("base")
+	switch {
+	case listGroupBy != "" && plainOutput:
+		fmt.Print(renderPlainGroups(groupRows(shown, listGroupBy), showBase))
+	case listGroupBy != "":
+		fmt.Println(renderGroups(groupRows(shown, listGroupBy), showBase))
+	case plainOutput:
+		fmt.Print(renderPlainRows(shown, showBase))
+	default:
+		fmt.Println(renderTable(shown, showBase))
+	}
+	if hidden := len(rows) - len(shown); hidden > 0 {
+		fmt.Printf("… %d more worktree(s) of %d — run 'grove list --all' to show them, or 'grove cd <name>' to jump to one\n", hidden, len(rows))
+	}
+
+	// Remember the numbering for "@N" shortcuts. Best-effort: a read-only
+	// .grove shouldn't break listing.
+	paths := make([]string, len(rows))
+	for i, r := range rows {
+		paths[i] = r.Path
+	}
+	rowref.Save(root, paths, time.Now())
+	if !refreshed.IsZero() {
+		age := time.Since(refreshed).Round(time.Second)
+		fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(
+			fmt.Sprintf("from grove refresh, %s old — --no-cache for live data", age)))
+	}
 	return nil
 }
 
-func renderTable(rows []worktreeRow) string {
+// descriptionSummary is a branch description's first line, cut to fit a
+// table column.
+func descriptionSummary(desc string) string {
+	first, _, _ := strings.Cut(desc, "\n")
+	return shortTitle(strings.TrimSpace(first))
+}
+
+// projectWorktrees is one project's section in `grove list --all-repos`.
+type projectWorktrees struct {
+	Project registry.Project
+	Missing bool // project root no longer exists
+	Rows    []worktreeRow
+}
+
+func runListAllRepos() error {
+	path, err := registry.Path()
+	if err != nil {
+		return err
+	}
+	reg, err := registry.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(reg.Projects) == 0 {
+		fmt.Println("No projects registered yet — run 'grove init' or 'grove create' in a project.")
+		return nil
+	}
+
+	groups, err := collectAllRepos(reg)
+	if err != nil {
+		return err
+	}
+	fmt.Print(renderProjects(groups))
+	return nil
+}
+
+// collectAllRepos loads the managed worktrees of every registered project.
+// Only grove's own state is read — orphans and the main worktree are left
+// out, since listing them would need git to run inside every repository.
+func collectAllRepos(reg registry.Registry) ([]projectWorktrees, error) {
+	var groups []projectWorktrees
+	for _, p := range reg.Projects {
+		group := projectWorktrees{Project: p}
+		if _, err := os.Stat(p.Root); os.IsNotExist(err) {
+			group.Missing = true
+			groups = append(groups, group)
+			continue
+		}
+
+		s, err := state.Load(p.Root)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Root, err)
+		}
+		aliases := make([]string, 0, len(s.Worktrees))
+		for alias := range s.Worktrees {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+
+		cache := statuscache.Open(p.Root)
+		for i, alias := range aliases {
+			entry := s.Worktrees[alias]
+			status, err := cachedStatus(cache, entry.Path)
+			if _, statErr := os.Stat(entry.Path); os.IsNotExist(statErr) {
+				status = "missing"
+			} else if err != nil {
+				status = "unknown"
+			}
+			group.Rows = append(group.Rows, worktreeRow{
+				Index:  i + 1,
+				Name:   alias,
+				Branch: entry.Branch,
+				Path:   entry.Path,
+				Status: status,
+				Base:   entry.Base,
+			})
+		}
+		cache.Save()
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// renderProjects prints each project as a heading followed by its worktrees.
+// Columns are aligned across all projects so the output scans as one table.
+func renderProjects(groups []projectWorktrees) string {
+	projectStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33")) // blue
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	cleanStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34"))  // green
+	dirtyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")) // orange
+
+	nameW, branchW, pathW := 0, 0, 0
+	for _, g := range groups {
+		for _, r := range g.Rows {
+			nameW = max(nameW, len(r.Name))
+			branchW = max(branchW, len(r.Branch))
+			pathW = max(pathW, len(displayPath(r.Path)))
+		}
+	}
+
+	pad := func(s string, w int) string {
+		return s + strings.Repeat(" ", w-len(s)+2)
+	}
+
+	var sb strings.Builder
+	for i, g := range groups {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(projectStyle.Render(g.Project.Name) + " " + mutedStyle.Render(g.Project.Root) + "\n")
+
+		switch {
+		case g.Missing:
+			sb.WriteString(mutedStyle.Render("  project root no longer exists") + "\n")
+			continue
+		case len(g.Rows) == 0:
+			sb.WriteString(mutedStyle.Render("  no managed worktrees") + "\n")
+			continue
+		}
+
+		for _, r := range g.Rows {
+			status := cleanStyle.Render(plain("✓ clean"))
+			if r.Status != "clean" {
+				status = dirtyStyle.Render(r.Status)
+			}
+			sb.WriteString("  " + nameStyle.Render(pad(r.Name, nameW)) + pad(r.Branch, branchW) + pad(displayPath(r.Path), pathW) + status + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// Keys for grove list --group-by.
+const (
+	groupByTag  = "tag"
+	groupByDir  = "dir"
+	groupByBase = "base"
+)
+
+// rowGroup is one section of grove list --group-by.
+type rowGroup struct {
+	Name string
+	Rows []worktreeRow
+}
+
+// groupRows sorts rows into sections by their tags, parent directory or
+// base branch, in name order with the rows lacking the key last. Rows keep
+// their order, and their numbers, within a section.
+func groupRows(rows []worktreeRow, by string) []rowGroup {
+	none := "(no base recorded)"
+	if by == groupByTag {
+		none = "(no tags)"
+	}
+	keys := func(r worktreeRow) []string {
+		switch by {
+		case groupByTag:
+			return r.Tags
+		case groupByDir:
+			return []string{displayPath(filepath.Dir(r.Path))}
+		default:
+			if r.Base == "" {
+				return nil
+			}
+			return []string{r.Base}
+		}
+	}
+	index := map[string]int{}
+	var groups []rowGroup
+	for _, r := range rows {
+		names := keys(r)
+		if len(names) == 0 {
+			names = []string{none}
+		}
+		for _, name := range names {
+			i, ok := index[name]
+			if !ok {
+				i = len(groups)
+				index[name] = i
+				groups = append(groups, rowGroup{Name: name})
+			}
+			groups[i].Rows = append(groups[i].Rows, r)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == none) != (groups[j].Name == none) {
+			return groups[j].Name == none
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// renderGroups prints the table of renderTable with a heading above each
+// section. The rows are rendered as one table so the columns line up
+// across sections.
+func renderGroups(groups []rowGroup, showBase bool) string {
+	groupStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("33")) // blue
+
+	var rows []worktreeRow
+	for _, g := range groups {
+		rows = append(rows, g.Rows...)
+	}
+	lines := strings.Split(strings.TrimSuffix(renderTable(rows, showBase), "\n"), "\n")
+
+	var sb strings.Builder
+	sb.WriteString(lines[0] + "\n")
+	next := 1
+	for _, g := range groups {
+		sb.WriteString("\n" + groupStyle.Render(g.Name) + "\n")
+		for range g.Rows {
+			sb.WriteString(lines[next] + "\n")
+			next++
+		}
+	}
+	return sb.String()
+}
+
+// baseSummaries is baseSummary for every row, counting with one git call
+// per base rather than one per row where git supports it.
+func baseSummaries(rows []worktreeRow) []string {
+	byBase := make(map[string][]string)
+	for _, r := range rows {
+		if r.Base != "" && r.Branch != "" {
+			byBase[r.Base] = append(byBase[r.Base], r.Branch)
+		}
+	}
+	ahead := make(map[string]map[string]int, len(byBase))
+	for base, branches := range byBase {
+		counts, err := git.BatchAheadOf(base, branches)
+		if err != nil {
+			continue // git is too old, or base is gone: counted row by row below
+		}
+		ahead[base] = counts
+	}
+
+	summaries := make([]string, len(rows))
+	for i, r := range rows {
+		if n, ok := ahead[r.Base][r.Branch]; ok {
+			summaries[i] = fmt.Sprintf("+%d %s", n, r.Base)
+		} else {
+			summaries[i] = baseSummary(r)
+		}
+	}
+	return summaries
+}
+
+// baseSummary describes how far a worktree has diverged from its base,
+// e.g. "+3 main". Returns "-" when the base is unknown or can't be compared.
+func baseSummary(r worktreeRow) string {
+	if r.Base == "" {
+		return "-"
+	}
+	ahead, err := git.AheadCount(r.Path, r.Base)
+	if err != nil {
+		return "-"
+	}
+	return fmt.Sprintf("+%d %s", ahead, r.Base)
+}
+
+func renderTable(rows []worktreeRow, showBase bool) string {
 	header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("241"))
 	idxStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	mainStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("33"))   // blue
@@ -70,6 +438,79 @@ func renderTable(rows []worktreeRow) string {
 	nameW := len("NAME")
 	branchW := len("BRANCH")
 	pathW := len("PATH")
+	baseW := len("BASE")
+	syncW := len("SYNC")
+	sizeW := len("SIZE")
+	expiresW := len("EXPIRES")
+	lockedW := len("LOCKED")
+	descW := len("DESCRIPTION")
+
+	// SYNC and SIZE come from the refresher's snapshot; live rendering skips them.
+	showCached := false
+	for _, r := range rows {
+		if r.Cached != nil {
+			showCached = true
+		}
+	}
+	syncs := make([]string, len(rows))
+	sizes := make([]string, len(rows))
+	if showCached {
+		for i, r := range rows {
+			syncs[i], sizes[i] = syncSummary(r.Cached), "-"
+			if r.Cached != nil {
+				sizes[i] = humanBytes(r.Cached.DiskBytes)
+			}
+			syncW = max(syncW, len([]rune(syncs[i])))
+			sizeW = max(sizeW, len(sizes[i]))
+		}
+	}
+
+	// EXPIRES only shows up once some worktree has an expiry.
+	now := time.Now()
+	showExpires := false
+	expires := make([]string, len(rows))
+	for i, r := range rows {
+		expires[i] = "-"
+		if !r.Expires.IsZero() {
+			showExpires = true
+			expires[i] = expiresIn(r.Expires, now)
+			expiresW = max(expiresW, len(expires[i]))
+		}
+	}
+
+	// So does LOCKED, once some worktree is locked.
+	showLocked := false
+	locks := make([]string, len(rows))
+	for i, r := range rows {
+		locks[i] = "-"
+		if r.Locked {
+			showLocked = true
+			locks[i] = lockedSummary(r)
+			lockedW = max(lockedW, len([]rune(locks[i])))
+		}
+	}
+
+	// And DESCRIPTION, once some branch has a git description.
+	showDesc := false
+	descs := make([]string, len(rows))
+	for i, r := range rows {
+		descs[i] = "-"
+		if r.Description != "" {
+			showDesc = true
+			descs[i] = descriptionSummary(r.Description)
+			descW = max(descW, len([]rune(descs[i])))
+		}
+	}
+
+	bases := make([]string, len(rows))
+	if showBase {
+		bases = baseSummaries(rows)
+		for i := range rows {
+			if len(bases[i]) > baseW {
+				baseW = len(bases[i])
+			}
+		}
+	}
 
 	for _, r := range rows {
 		w := len(fmt.Sprintf("%d", r.Index))
@@ -82,8 +523,8 @@ func renderTable(rows []worktreeRow) string {
 		if len(r.Branch) > branchW {
 			branchW = len(r.Branch)
 		}
-		if len(r.Path) > pathW {
-			pathW = len(r.Path)
+		if len(displayPath(r.Path)) > pathW {
+			pathW = len(displayPath(r.Path))
 		}
 	}
 
@@ -97,11 +538,26 @@ func renderTable(rows []worktreeRow) string {
 		header.Render(pad("#", idxW)) +
 			header.Render(pad("NAME", nameW)) +
 			header.Render(pad("BRANCH", branchW)) +
-			header.Render(pad("PATH", pathW)) +
-			header.Render("STATUS") + "\n",
+			header.Render(pad("PATH", pathW)),
 	)
+	if showBase {
+		sb.WriteString(header.Render(pad("BASE", baseW)))
+	}
+	if showCached {
+		sb.WriteString(header.Render(pad("SYNC", syncW)) + header.Render(pad("SIZE", sizeW)))
+	}
+	if showExpires {
+		sb.WriteString(header.Render(pad("EXPIRES", expiresW)))
+	}
+	if showLocked {
+		sb.WriteString(header.Render(pad("LOCKED", lockedW)))
+	}
+	if showDesc {
+		sb.WriteString(header.Render(pad("DESCRIPTION", descW)))
+	}
+	sb.WriteString(header.Render("STATUS") + "\n")
 
-	for _, r := range rows {
+	for i, r := range rows {
 		statusStr := "✓ clean"
 		statusRendered := cleanStyle.Render(statusStr)
 		if r.Status != "clean" {
@@ -116,13 +572,24 @@ func renderTable(rows []worktreeRow) string {
 
 		idx := idxStyle.Render(pad(fmt.Sprintf("%d", r.Index), idxW))
 
-		sb.WriteString(
-			idx +
-				name +
-				pad(r.Branch, branchW) +
-				pad(r.Path, pathW) +
-				statusRendered + "\n",
-		)
+		sb.WriteString(idx + name + pad(r.Branch, branchW) + pad(displayPath(r.Path), pathW))
+		if showBase {
+			sb.WriteString(pad(bases[i], baseW))
+		}
+		if showCached {
+			// ↑ and ↓ are multi-byte, so pad by runes rather than bytes.
+			sb.WriteString(syncs[i] + strings.Repeat(" ", syncW-len([]rune(syncs[i]))+2) + pad(sizes[i], sizeW))
+		}
+		if showExpires {
+			sb.WriteString(pad(expires[i], expiresW))
+		}
+		if showLocked {
+			sb.WriteString(locks[i] + strings.Repeat(" ", lockedW-len([]rune(locks[i]))+2))
+		}
+		if showDesc {
+			sb.WriteString(descs[i] + strings.Repeat(" ", descW-len([]rune(descs[i]))+2))
+		}
+		sb.WriteString(statusRendered + "\n")
 	}
 
 	return sb.String()
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/registry"
+)
+
+func TestListAllRepos(t *testing.T) {
+	regPath := filepath.Join(t.TempDir(), "projects.json")
+
+	createIn := func(prefix, branch string) string {
+		dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: prefix, Symlink: []string{}})
+		// setupIntegrationRepo gives each repo its own registry; share one here.
+		t.Setenv("GROVE_REGISTRY", regPath)
+		createName = ""
+		createFrom = ""
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatalf("create %s failed: %v", branch, err)
+		}
+		t.Cleanup(func() {
+			rm := exec.Command("git", "worktree", "remove", "--force", filepath.Join(filepath.Dir(dir), prefix+"-"+branchAlias(branch)))
+			rm.Dir = dir
+			rm.CombinedOutput()
+		})
+		return dir
+	}
+	shop := createIn("shop", "feature/auth")
+	blog := createIn("blog", "feature/drafts")
+
+	reg, err := registry.Load(regPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.Projects) != 2 {
+		t.Fatalf("expected 2 registered projects, got %v", reg.Projects)
+	}
+
+	groups, err := collectAllRepos(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ project, root, alias string }{
+		{"blog", blog, "drafts"},
+		{"shop", shop, "auth"},
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Project.Name != w.project || g.Project.Root != w.root {
+			t.Errorf("group %d = %s (%s), want %s (%s)", i, g.Project.Name, g.Project.Root, w.project, w.root)
+		}
+		if len(g.Rows) != 1 || g.Rows[0].Name != w.alias || g.Rows[0].Status != "clean" {
+			t.Errorf("group %d rows = %+v, want one clean %q", i, g.Rows, w.alias)
+		}
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop", Symlink: []string{}})
+	for _, branch := range []string{"feature/auth", "feature/billing"} {
+		createName, createFrom, createTemplate = "", "", ""
+		if _, err := createWorktree(createCmd, branch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := listCmd.Flags().Set("limit", "2"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listLimit = listPageSize
+		listCmd.Flags().Lookup("limit").Changed = false
+	})
+	out := string(captureStdout(t, func() {
+		if err := runList(listCmd, nil); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	if !strings.Contains(out, "auth") || strings.Contains(out, "billing") {
+		t.Errorf("expected only the first 2 worktrees:\n%s", out)
+	}
+	if !strings.Contains(out, "1 more worktree(s) of 3") {
+		t.Errorf("expected a count of the hidden worktrees:\n%s", out)
+	}
+	if rowLimit != 0 {
+		t.Error("list should reset rowLimit")
+	}
+}
+
+func TestBaseSummaries(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop", Symlink: []string{}})
+	createName, createFrom, createTemplate = "", "", ""
+	report, err := createWorktree(createCmd, "feature/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := gitCmd(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A tag named like the branch mustn't throw off the batch lookup.
+	for _, args := range [][]string{{"commit", "--allow-empty", "-m", "work"}, {"tag", "feature/auth"}} {
+		if _, err := gitCmd(report.Path, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := buildWorktreeRows(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows = append(rows, worktreeRow{Name: "stale", Branch: "feature/auth", Path: report.Path, Base: "no-such-branch"})
+	got := baseSummaries(rows)
+	want := map[string]string{"main": "-", "auth": "+1 " + strings.TrimSpace(string(base)), "stale": "-"}
+	for i, r := range rows {
+		if got[i] != want[r.Name] {
+			t.Errorf("base of %s = %q, want %q", r.Name, got[i], want[r.Name])
+		}
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	rows := []worktreeRow{
+		{Index: 1, Name: "main", Path: "/src/shop", IsMain: true},
+		{Index: 2, Name: "auth", Path: "/src/shop-auth", Base: "main", Tags: []string{"backend", "security"}},
+		{Index: 3, Name: "web", Path: "/src/wt/shop-web", Base: "develop", Tags: []string{"frontend"}},
+		{Index: 4, Name: "api", Path: "/src/shop-api", Base: "main", Tags: []string{"backend"}},
+	}
+	summary := func(groups []rowGroup) string {
+		var parts []string
+		for _, g := range groups {
+			var names []string
+			for _, r := range g.Rows {
+				names = append(names, r.Name)
+			}
+			parts = append(parts, g.Name+"="+strings.Join(names, ","))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	for _, tt := range []struct{ by, want string }{
+		{groupByTag, "backend=auth,api frontend=web security=auth (no tags)=main"},
+		{groupByDir, "/src=main,auth,api /src/wt=web"},
+		{groupByBase, "develop=web main=auth,api (no base recorded)=main"},
+	} {
+		if got := summary(groupRows(rows, tt.by)); got != tt.want {
+			t.Errorf("group by %s:\n got %s\nwant %s", tt.by, got, tt.want)
+		}
+	}
+
+	out := renderGroups(groupRows(rows, groupByBase), false)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.Contains(lines[0], "NAME") || !strings.Contains(out, "\ndevelop\n") {
+		t.Errorf("expected one header and a heading per group:\n%s", out)
+	}
+	if strings.Index(lines[3], "/src/") != strings.Index(lines[6], "/src/") {
+		t.Errorf("columns should line up across groups:\n%s", out)
+	}
+}
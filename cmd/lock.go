@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var lockReason string
+
+func init() {
+	rootCmd.AddCommand(lockCmd, unlockCmd)
+	lockCmd.Flags().StringVar(&lockReason, "reason", "", "why the worktree is locked, shown by grove list and git")
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock <name>",
+	Short: "Lock a worktree so it isn't pruned or removed",
+	Long: `Lock a worktree with 'git worktree lock', for one on removable or network
+storage that isn't always mounted. While it's locked:
+
+  - 'grove prune', 'grove clean' and 'git worktree prune' keep it, even
+    when its directory is missing
+  - 'grove remove' refuses, unless --force is given
+  - 'grove list' shows it in a LOCKED column, with the reason, and its
+    status as "unavailable" while the directory is missing
+
+Locking a locked worktree again with a new --reason replaces the reason.
+'grove unlock' undoes it.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runLock,
+}
+
+var unlockCmd = &cobra.Command{
+	Use:               "unlock <name>",
+	Short:             "Unlock a worktree locked with grove lock",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runUnlock,
+}
+
+func runLock(cmd *cobra.Command, args []string) error {
+	alias, entry, err := lockTarget(args[0])
+	if err != nil {
+		return err
+	}
+	if reason, locked := worktreeLock(entry.Path); locked {
+		if lockReason == "" || lockReason == reason {
+			fmt.Printf("%s is already locked%s.\n", alias, lockSuffix(reason))
+			return nil
+		}
+		// git keeps the first reason; unlock to give a new one.
+		if err := git.UnlockWorktree(entry.Path); err != nil {
+			return err
+		}
+	}
+	if err := git.LockWorktree(entry.Path, lockReason); err != nil {
+		return err
+	}
+	fmt.Printf(plain("  ✓ locked %s%s\n"), alias, lockSuffix(lockReason))
+	return nil
+}
+
+func runUnlock(cmd *cobra.Command, args []string) error {
+	alias, entry, err := lockTarget(args[0])
+	if err != nil {
+		return err
+	}
+	if _, locked := worktreeLock(entry.Path); !locked {
+		fmt.Printf("%s isn't locked.\n", alias)
+		return nil
+	}
+	if err := git.UnlockWorktree(entry.Path); err != nil {
+		return err
+	}
+	fmt.Printf(plain("  ✓ unlocked %s\n"), alias)
+	return nil
+}
+
+// lockTarget resolves the managed worktree grove lock or unlock was asked
+// for.
+func lockTarget(query string) (string, state.WorktreeEntry, error) {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return "", state.WorktreeEntry{}, err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return "", state.WorktreeEntry{}, err
+	}
+	alias, err := resolveManaged(root, s, query)
+	if err != nil {
+		return "", state.WorktreeEntry{}, err
+	}
+	entry, _ := s.Get(alias)
+	return alias, entry, nil
+}
+
+// worktreeLock reports whether the worktree at path is locked, and why.
+func worktreeLock(path string) (string, bool) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return "", false
+	}
+	if wt := gitWorktreeAt(worktrees, path); wt != nil && wt.Locked {
+		return wt.LockReason, true
+	}
+	return "", false
+}
+
+// lockedPaths returns the paths of the locked worktrees.
+func lockedPaths() map[string]bool {
+	locked := map[string]bool{}
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return locked
+	}
+	for _, wt := range worktrees {
+		if wt.Locked {
+			locked[wt.Path] = true
+		}
+	}
+	return locked
+}
+
+// lockSuffix formats a lock reason to follow a name, e.g. " (on the USB disk)".
+func lockSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + reason + ")"
+}
+
+// lockedSummary is a locked row's LOCKED column: the reason, or "yes".
+func lockedSummary(r worktreeRow) string {
+	if r.Reason == "" {
+		return "yes"
+	}
+	return shortTitle(r.Reason)
+}
+
+// unavailable reports whether path is missing, as a locked worktree's is
+// while its storage isn't mounted.
+func unavailable(path string) bool {
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
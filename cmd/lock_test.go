@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestLock(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	if _, err := createWorktree(createCmd, "feature/auth"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lockReason, removeForce, removeYes = "", false, false })
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("auth")
+
+	lockReason = "on the USB disk"
+	if err := runLock(lockCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	if reason, locked := worktreeLock(entry.Path); !locked || reason != "on the USB disk" {
+		t.Fatalf("after grove lock: locked %v, reason %q", locked, reason)
+	}
+	// A new reason replaces the old one.
+	lockReason = "on the NAS"
+	if err := runLock(lockCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	if reason, _ := worktreeLock(entry.Path); reason != "on the NAS" {
+		t.Errorf("reason after locking again = %q", reason)
+	}
+
+	rows, err := buildWorktreeRows(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table := renderTable(rows, false); !strings.Contains(table, "LOCKED") || !strings.Contains(table, "on the NAS") {
+		t.Errorf("grove list doesn't show the lock:\n%s", table)
+	}
+
+	if err := runRemove(removeCmd, []string{"auth"}); !errors.Is(err, git.ErrLocked) || exitCode(err) != exitLocked {
+		t.Errorf("remove of a locked worktree = %v", err)
+	}
+
+	// While the storage is unmounted, the worktree is unavailable, not stale.
+	moved := entry.Path + ".unmounted"
+	if err := os.Rename(entry.Path, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := runPrune(pruneCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = state.Load(dir)
+	if !s.AliasExists("auth") {
+		t.Fatal("grove prune dropped a locked worktree")
+	}
+	if findings := checkState(dir, config.Config{}); len(findings) > 0 {
+		t.Errorf("grove doctor reports the locked worktree: %+v", findings[0])
+	}
+	rows, _ = buildWorktreeRows(dir)
+	for _, r := range rows {
+		if r.Name == "auth" && r.Status != "unavailable" {
+			t.Errorf("status while unmounted = %q, want unavailable", r.Status)
+		}
+	}
+	if err := os.Rename(moved, entry.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runUnlock(unlockCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, locked := worktreeLock(entry.Path); locked {
+		t.Fatal("still locked after grove unlock")
+	}
+
+	// --force removes a locked worktree.
+	if err := runLock(lockCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	removeForce, removeYes = true, true
+	if err := runRemove(removeCmd, []string{"auth"}); err != nil {
+		t.Fatalf("remove --force of a locked worktree: %v", err)
+	}
+	if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+		t.Errorf("worktree still at %s after remove --force", entry.Path)
+	}
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(mergedCmd)
+}
+
+var mergedCmd = &cobra.Command{
+	Use:   "merged",
+	Short: "Report which worktree branches are merged",
+	Long: `Show which grove-managed worktrees have branches merged into the default
+branch, which have open pull requests, and which are unmerged.
+
+Merge detection checks both the local default branch and origin's copy of it.
+Open pull requests are looked up with the GitHub CLI (gh) when it's installed.
+
+This command is read-only — use it to decide what to remove before 'grove clean'.`,
+	Args: cobra.NoArgs,
+	RunE: runMerged,
+}
+
+// mergeReport is the merge status of one managed worktree's branch.
+type mergeReport struct {
+	Alias    string
+	Branch   string
+	MergedIn []string // refs the branch is merged into, e.g. ["main", "origin/main"]
+	PR       *pullRequest
+}
+
+type pullRequest struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+func runMerged(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	root, err := config.FindRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	if len(s.Worktrees) == 0 {
+		fmt.Println("No managed worktrees.")
+		return nil
+	}
+
+	defaultBranch, err := git.DefaultBranch()
+	if err != nil {
+		return err
+	}
+
+	reports := buildMergeReports(s, defaultBranch)
+
+	var merged, withPR, unmerged []mergeReport
+	for _, r := range reports {
+		switch {
+		case len(r.MergedIn) > 0:
+			merged = append(merged, r)
+		case r.PR != nil:
+			withPR = append(withPR, r)
+		default:
+			unmerged = append(unmerged, r)
+		}
+	}
+
+	fmt.Printf("Merged into %s:\n", defaultBranch)
+	printMergeSection(merged, func(r mergeReport) string {
+		return fmt.Sprintf("(%s)", strings.Join(r.MergedIn, ", "))
+	})
+
+	fmt.Println("\nOpen pull requests:")
+	printMergeSection(withPR, func(r mergeReport) string {
+		return fmt.Sprintf("#%d %s", r.PR.Number, r.PR.URL)
+	})
+
+	fmt.Println("\nUnmerged:")
+	printMergeSection(unmerged, func(mergeReport) string { return "" })
+
+	return nil
+}
+
+// buildMergeReports checks every managed worktree's branch against the
+// default branch (local and origin). Sorted by alias.
+func buildMergeReports(s state.State, defaultBranch string) []mergeReport {
+	targets := mergeTargets(defaultBranch)
+	ghAvailable := hasGH()
+
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias := range s.Worktrees {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var reports []mergeReport
+	for _, alias := range aliases {
+		entry := s.Worktrees[alias]
+		r := mergeReport{Alias: alias, Branch: entry.Branch}
+
+		if entry.Branch != defaultBranch {
+			r.MergedIn = mergedInto(entry.Branch, targets)
+		}
+		if len(r.MergedIn) == 0 && ghAvailable {
+			r.PR = openPR(entry.Branch)
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// mergeTargets is branch, and origin's copy of it if there is one.
+func mergeTargets(branch string) []string {
+	targets := []string{branch}
+	if remote := "origin/" + branch; git.RefExists(remote) {
+		targets = append(targets, remote)
+	}
+	return targets
+}
+
+// mergedInto returns the targets branch is merged into.
+func mergedInto(branch string, targets []string) []string {
+	// Without commits of its own, a branch is reachable from its base
+	// without anything having been merged.
+	if n, err := git.OwnCommits(branch); err != nil || n == 0 {
+		return nil
+	}
+	var merged []string
+	for _, target := range targets {
+		if ok, err := git.IsMerged(branch, target); err == nil && ok {
+			merged = append(merged, target)
+		}
+	}
+	return merged
+}
+
+func printMergeSection(reports []mergeReport, detail func(mergeReport) string) {
+	if len(reports) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, r := range reports {
+		line := fmt.Sprintf(plain("  %s → %s"), r.Alias, r.Branch)
+		if d := detail(r); d != "" {
+			line += "  " + d
+		}
+		fmt.Println(line)
+	}
+}
+
+func hasGH() bool {
+	_, err := exec.LookPath("gh")
+	return err == nil
+}
+
+// openPR returns the open pull request for branch, or nil if there is none
+// or gh can't tell (not authenticated, no GitHub remote, etc.).
+func openPR(branch string) *pullRequest {
+	out, err := exec.Command("gh", "pr", "list", "--head", branch, "--state", "open", "--json", "number,url", "--limit", "1").Output()
+	if err != nil {
+		return nil
+	}
+	var prs []pullRequest
+	if err := json.Unmarshal(out, &prs); err != nil || len(prs) == 0 {
+		return nil
+	}
+	return &prs[0]
+}
+
+// filterMergedEntries keeps the aliases whose branch is merged into into, or
+// into the default branch (locally or on origin) when into is empty. A
+// branch without commits of its own since it was created is kept out
+// rather than counted as merged, even once the target has moved past it.
+func filterMergedEntries(s state.State, aliases []string, into string) ([]string, error) {
+	var targets []string
+	if into != "" {
+		if !git.RefExists(into) {
+			return nil, fmt.Errorf("%q is not a branch or ref here — check the name passed to --into", into)
+		}
+		targets = []string{into}
+	} else {
+		defaultBranch, err := git.DefaultBranch()
+		if err != nil {
+			return nil, err
+		}
+		into, targets = defaultBranch, mergeTargets(defaultBranch)
+	}
+	var kept []string
+	for _, alias := range aliases {
+		branch := s.Worktrees[alias].Branch
+		if branch == "" || branch == into {
+			continue
+		}
+		if len(mergedInto(branch, targets)) > 0 {
+			kept = append(kept, alias)
+		}
+	}
+	return kept, nil
+}
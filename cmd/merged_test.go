@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestBuildMergeReports(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+	})
+
+	createName = ""
+	createFrom = ""
+	for _, branch := range []string{"feature/done", "feature/wip"} {
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	donePath := filepath.Join(filepath.Dir(dir), "testproject-done")
+	wipPath := filepath.Join(filepath.Dir(dir), "testproject-wip")
+	t.Cleanup(func() {
+		git.RemoveWorktree(donePath, true)
+		git.RemoveWorktree(wipPath, true)
+	})
+
+	for _, c := range []*exec.Cmd{
+		exec.Command("git", "-C", donePath, "commit", "--allow-empty", "-m", "done"),
+		exec.Command("git", "-C", wipPath, "commit", "--allow-empty", "-m", "wip"),
+		exec.Command("git", "-C", dir, "merge", "--ff-only", "feature/done"),
+	} {
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", c.Args, out)
+		}
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports := buildMergeReports(s, "main")
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].Alias != "done" || len(reports[0].MergedIn) != 1 || reports[0].MergedIn[0] != "main" {
+		t.Errorf("expected done merged into main, got %+v", reports[0])
+	}
+	if reports[1].Alias != "wip" || len(reports[1].MergedIn) != 0 {
+		t.Errorf("expected wip unmerged, got %+v", reports[1])
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var openEditorFlag string
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().StringVar(&openEditorFlag, "editor", "", "editor command to use this time, e.g. \"vim\" or \"code --new-window\"")
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open <name>",
+	Short: "Open a worktree in your editor",
+	Long: `Open a worktree in your editor — the same as cd $(grove cd <name>) && code .
+
+The editor is the first of:
+
+  --editor
+  GROVE_EDITOR
+  "editor" in .groverc.json
+  VISUAL, then EDITOR
+  code (VS Code), if it's on PATH
+
+The editor command may carry arguments, e.g. "code --new-window"; the
+worktree path is added last. With no editor at all, the path is printed
+instead, so the command still works in scripts. Accepts an alias, branch,
+path or @N from the last grove list.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runOpen,
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	path, err := openTarget(root, s, args[0])
+	if err != nil {
+		return err
+	}
+
+	editor := editorCommand(cfg, openEditorFlag)
+	if editor == "" {
+		fmt.Fprintln(os.Stderr, "No editor configured — set \"editor\" in .groverc.json or GROVE_EDITOR; printing the path instead.")
+		fmt.Println(displayPath(path))
+		return nil
+	}
+	return openInEditor(editor, path)
+}
+
+// openTarget resolves the worktree grove open was asked for to its path.
+func openTarget(root string, s state.State, query string) (string, error) {
+	query, err := expandRowRef(root, query)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return "", err
+	}
+	if resolved == nil {
+		return "", errorf(state.ErrNotFound, "no worktree matches %q — run 'grove list' to see available worktrees", query)
+	}
+	if _, err := os.Stat(resolved.Path); err != nil {
+		return "", fmt.Errorf("%s is missing — run 'grove prune' to drop the entry", resolved.Path)
+	}
+	return resolved.Path, nil
+}
+
+// editorCommand picks the editor to open worktrees in: override (--editor),
+// then GROVE_EDITOR, the project's "editor", VISUAL and EDITOR, and finally
+// VS Code if it's installed. Returns "" when there's none.
+func editorCommand(cfg config.Config, override string) string {
+	if override != "" {
+		return override
+	}
+	if e := os.Getenv("GROVE_EDITOR"); e != "" {
+		return e
+	}
+	if cfg.Editor != "" {
+		return cfg.Editor
+	}
+	for _, v := range []string{"VISUAL", "EDITOR"} {
+		if e := os.Getenv(v); e != "" {
+			return e
+		}
+	}
+	if _, err := exec.LookPath("code"); err == nil {
+		return "code"
+	}
+	return ""
+}
+
+// openWorktree takes the user into a worktree after creating it, the way the
+// "open" config or --open asks: in their editor, a tmux window, or a
+// subshell. env is added to the shell's environment.
+func openWorktree(mode, editor, alias, dir string, env []string) error {
+	switch mode {
+	case config.OpenEditor:
+		return openInEditor(editor, dir)
+	case config.OpenTmux:
+		return openInTmux(alias, dir)
+	case config.OpenShell:
+		return openShell(dir, env)
+	}
+	return nil
+}
+
+// openInEditor opens dir with editor, from editorCommand. The editor command
+// may carry arguments (e.g. "code --new-window"). Without one, it prints how
+// to get there.
+func openInEditor(editor, dir string) error {
+	if editor == "" {
+		fmt.Printf("  set \"editor\" in .groverc.json or GROVE_EDITOR to open worktrees automatically; for now: cd %s\n", dir)
+		return nil
+	}
+
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], dir)...)
+	c.Dir = dir
+	c.Env = git.Environ()
+	// Terminal editors need the terminal; GUI editors return right away.
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("opening editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+// openInTmux opens dir in a new tmux window named after the alias, or
+// attaches a session of that name when grove isn't running inside tmux.
+func openInTmux(alias, dir string) error {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux not found on PATH — install it, or use --open editor or --open shell")
+	}
+	c := exec.Command("tmux", tmuxArgs(alias, dir, os.Getenv("TMUX") != "")...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("opening tmux: %w", err)
+	}
+	return nil
+}
+
+// tmuxArgs builds the tmux command line for openInTmux. -A reattaches an
+// existing session of the same name instead of failing.
+func tmuxArgs(alias, dir string, insideTmux bool) []string {
+	if insideTmux {
+		return []string{"new-window", "-n", alias, "-c", dir}
+	}
+	return []string{"new-session", "-A", "-s", alias, "-c", dir}
+}
+
+// openShell starts the user's shell in dir and waits for it to exit. The
+// shell's exit status is its own business, not grove's.
+func openShell(dir string, env []string) error {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	fmt.Printf("  starting %s in %s — exit it to return\n", shell, dir)
+	c := exec.Command(shell)
+	c.Dir = dir
+	c.Env = append(git.Environ(), env...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no code on PATH
+	for _, v := range []string{"GROVE_EDITOR", "VISUAL", "EDITOR"} {
+		t.Setenv(v, "")
+	}
+	cfg := config.Config{}
+	if got := editorCommand(cfg, ""); got != "" {
+		t.Errorf("no editor anywhere = %q", got)
+	}
+
+	t.Setenv("EDITOR", "vi")
+	if got := editorCommand(cfg, ""); got != "vi" {
+		t.Errorf("EDITOR = %q", got)
+	}
+	cfg.Editor = "code --new-window"
+	if got := editorCommand(cfg, ""); got != "code --new-window" {
+		t.Errorf("config should win over EDITOR, got %q", got)
+	}
+	t.Setenv("GROVE_EDITOR", "zed")
+	if got := editorCommand(cfg, ""); got != "zed" {
+		t.Errorf("GROVE_EDITOR should win over config, got %q", got)
+	}
+	if got := editorCommand(cfg, "nano"); got != "nano" {
+		t.Errorf("--editor should win over everything, got %q", got)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	report, err := createWorktree(createCmd, "feature/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No editor: the path is printed instead. Keep git on PATH, and check
+	// only where VS Code isn't installed next to it.
+	for _, v := range []string{"GROVE_EDITOR", "VISUAL", "EDITOR"} {
+		t.Setenv(v, "")
+	}
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", filepath.Dir(gitBin))
+	if _, err := exec.LookPath("code"); err != nil {
+		out := captureStdout(t, func() {
+			if err := runOpen(openCmd, []string{"auth"}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if strings.TrimSpace(string(out)) != report.Path {
+			t.Errorf("without an editor, expected the path, got %q", out)
+		}
+	}
+
+	// The editor gets the worktree path as its last argument.
+	got := filepath.Join(t.TempDir(), "args")
+	editor := filepath.Join(t.TempDir(), "editor")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\necho \"$@\" > "+got+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	openEditorFlag = editor + " --wait"
+	t.Cleanup(func() { openEditorFlag = "" })
+	if err := runOpen(openCmd, []string{"feature/auth"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != "--wait "+report.Path {
+		t.Errorf("editor args = %q", data)
+	}
+
+	if err := runOpen(openCmd, []string{"nope"}); err == nil {
+		t.Error("expected an error for an unknown worktree")
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+// pathStyle is how grove writes the worktree paths it prints, so a path
+// can be handed across the WSL boundary: to a Windows editor from grove in
+// WSL, or to WSL tools from grove on Windows. Set by configurePathStyle.
+var pathStyle = config.PathAuto
+
+// configurePathStyle reads the path style from GROVE_PATH_STYLE, or
+// "pathStyle" in cfg. The variable wins, since it's usually the caller on
+// the other side (an editor extension, a Windows terminal) that knows which
+// style it needs.
+func configurePathStyle(cfg config.Config) {
+	pathStyle = config.PathAuto
+	if v := os.Getenv("GROVE_PATH_STYLE"); v != "" {
+		cfg = config.Config{PathStyle: v}
+	}
+	style, err := cfg.EffectivePathStyle()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return
+	}
+	pathStyle = style
+}
+
+// displayPath returns path written in the configured path style. Use it
+// for paths that are printed, never for ones grove goes on to use itself.
+func displayPath(path string) string {
+	return translatePath(path, pathStyle, os.Getenv("WSL_DISTRO_NAME"))
+}
+
+// translatePath writes path in style. distro is the WSL distribution grove
+// runs in, needed to reach its own files from Windows; paths that can't be
+// translated are returned unchanged.
+//
+//	/mnt/c/code/shop       ↔ C:\code\shop
+//	/home/me/shop          ↔ \\wsl.localhost\<distro>\home\me\shop
+func translatePath(path, style, distro string) string {
+	switch style {
+	case config.PathUnix:
+		return strings.ReplaceAll(path, `\`, "/")
+	case config.PathWindows:
+		if drive, rest, ok := windowsDrive(path); ok {
+			return drive + ":" + strings.ReplaceAll(rest, "/", `\`)
+		}
+		if drive, rest, ok := wslMount(path); ok {
+			return strings.ToUpper(drive) + ":" + strings.ReplaceAll(rest, "/", `\`)
+		}
+		if strings.HasPrefix(path, "/") && distro != "" {
+			return `\\wsl.localhost\` + distro + strings.ReplaceAll(path, "/", `\`)
+		}
+	case config.PathWSL:
+		if drive, rest, ok := windowsDrive(path); ok {
+			return "/mnt/" + strings.ToLower(drive) + strings.ReplaceAll(rest, `\`, "/")
+		}
+		for _, share := range []string{`\\wsl.localhost\`, `\\wsl$\`} {
+			if rest, ok := strings.CutPrefix(path, share); ok {
+				if _, inDistro, ok := strings.Cut(rest, `\`); ok {
+					return "/" + strings.ReplaceAll(inDistro, `\`, "/")
+				}
+			}
+		}
+	}
+	return path
+}
+
+// windowsDrive splits "C:\code" or "C:/code" into "C" and "\code".
+func windowsDrive(path string) (drive, rest string, ok bool) {
+	if len(path) < 2 || path[1] != ':' || !isLetter(path[0]) {
+		return "", "", false
+	}
+	if len(path) > 2 && path[2] != '\\' && path[2] != '/' {
+		return "", "", false
+	}
+	return strings.ToUpper(path[:1]), path[2:], true
+}
+
+// wslMount splits "/mnt/c/code" into "c" and "/code".
+func wslMount(path string) (drive, rest string, ok bool) {
+	after, ok := strings.CutPrefix(path, "/mnt/")
+	if !ok || len(after) == 0 || !isLetter(after[0]) || (len(after) > 1 && after[1] != '/') {
+		return "", "", false
+	}
+	return after[:1], after[1:], true
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestTranslatePath(t *testing.T) {
+	tests := []struct {
+		path, style, want string
+	}{
+		{"/mnt/c/code/shop", config.PathAuto, "/mnt/c/code/shop"},
+		{"/mnt/c/code/shop", config.PathWindows, `C:\code\shop`},
+		{"/mnt/d", config.PathWindows, `D:`},
+		{"/home/me/shop", config.PathWindows, `\\wsl.localhost\Ubuntu\home\me\shop`},
+		{`C:\code\shop`, config.PathWindows, `C:\code\shop`},
+		{"c:/code/shop", config.PathWindows, `C:\code\shop`},
+		{"/mnt/code/shop", config.PathWindows, `\\wsl.localhost\Ubuntu\mnt\code\shop`},
+		{`C:\code\shop`, config.PathWSL, "/mnt/c/code/shop"},
+		{"C:/code/shop", config.PathWSL, "/mnt/c/code/shop"},
+		{`\\wsl.localhost\Ubuntu\home\me\shop`, config.PathWSL, "/home/me/shop"},
+		{`\\wsl$\Ubuntu\home\me\shop`, config.PathWSL, "/home/me/shop"},
+		{"/home/me/shop", config.PathWSL, "/home/me/shop"},
+		{`C:\code\shop`, config.PathUnix, "C:/code/shop"},
+		{"relative/path", config.PathWindows, "relative/path"},
+	}
+	for _, tt := range tests {
+		if got := translatePath(tt.path, tt.style, "Ubuntu"); got != tt.want {
+			t.Errorf("translatePath(%q, %s) = %q, want %q", tt.path, tt.style, got, tt.want)
+		}
+	}
+
+	if got := translatePath("/home/me/shop", config.PathWindows, ""); got != "/home/me/shop" {
+		t.Errorf("outside WSL, a Linux path should stay unchanged, got %q", got)
+	}
+}
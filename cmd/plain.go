@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plainOutput is --plain: output for screen readers and dumb terminals, with
+// no color, no symbols and labeled lines instead of tables.
+var plainOutput bool
+
+// plainSymbols spells out the symbols grove's output uses.
+var plainSymbols = strings.NewReplacer(
+	"✓ ", "ok: ",
+	" → ", " to ",
+	"→", "to",
+	"↑", "ahead ",
+	"↓", "behind ",
+)
+
+// configurePlain turns on plain mode for TERM=dumb or GROVE_PLAIN, and drops
+// colors from everything lipgloss renders.
+func configurePlain() {
+	if os.Getenv("TERM") == "dumb" || os.Getenv("GROVE_PLAIN") != "" {
+		plainOutput = true
+	}
+	if plainOutput {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// plain returns s with symbols spelled out in plain mode, and unchanged
+// otherwise. Wrap messages (or format strings) that contain ✓, → and the like.
+func plain(s string) string {
+	if !plainOutput {
+		return s
+	}
+	return plainSymbols.Replace(s)
+}
+
+// renderPlainRows is grove list in plain mode: one labeled line per
+// worktree, so a screen reader doesn't have to make sense of columns.
+func renderPlainRows(rows []worktreeRow, showBase bool) string {
+	var sb strings.Builder
+	for _, r := range rows {
+		fmt.Fprintf(&sb, "%d  name: %s  branch: %s  path: %s", r.Index, r.Name, r.Branch, r.Path)
+		if r.IsMain {
+			sb.WriteString("  main: yes")
+		}
+		if showBase {
+			fmt.Fprintf(&sb, "  base: %s", baseSummary(r))
+		}
+		if r.Cached != nil {
+			fmt.Fprintf(&sb, "  sync: %s  size: %s", syncSummary(r.Cached), humanBytes(r.Cached.DiskBytes))
+		}
+		if !r.Expires.IsZero() {
+			fmt.Fprintf(&sb, "  expires: %s", expiresIn(r.Expires, time.Now()))
+		}
+		if r.Locked {
+			fmt.Fprintf(&sb, "  locked: %s", lockedSummary(r))
+		}
+		if r.Description != "" {
+			fmt.Fprintf(&sb, "  description: %s", descriptionSummary(r.Description))
+		}
+		fmt.Fprintf(&sb, "  status: %s\n", r.Status)
+	}
+	return sb.String()
+}
+
+// renderPlainGroups is renderPlainRows for grove list --group-by, with a
+// "group:" line before each section.
+func renderPlainGroups(groups []rowGroup, showBase bool) string {
+	var sb strings.Builder
+	for _, g := range groups {
+		fmt.Fprintf(&sb, "group: %s\n", g.Name)
+		sb.WriteString(renderPlainRows(g.Rows, showBase))
+	}
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/listcache"
+)
+
+func TestPlain(t *testing.T) {
+	plainOutput = false
+	if got := plain("  ✓ removed auth"); got != "  ✓ removed auth" {
+		t.Errorf("plain off = %q, want unchanged", got)
+	}
+
+	plainOutput = true
+	t.Cleanup(func() { plainOutput = false })
+	if got := plain("  ✓ removed auth"); got != "  ok: removed auth" {
+		t.Errorf("plain(✓) = %q", got)
+	}
+	if got := plain("  feature/auth → /code/app-auth"); got != "  feature/auth to /code/app-auth" {
+		t.Errorf("plain(→) = %q", got)
+	}
+	if got := syncSummary(&listcache.Info{Against: "origin/main", Ahead: 2, Behind: 1}); got != "ahead 2 behind 1" {
+		t.Errorf("syncSummary = %q", got)
+	}
+}
+
+func TestRenderPlainRows(t *testing.T) {
+	rows := []worktreeRow{
+		{Index: 1, Name: "main", Branch: "main", Path: "/code/app", Status: "clean", IsMain: true},
+		{Index: 2, Name: "auth", Branch: "feature/auth", Path: "/code/app-auth", Status: "3 modified"},
+	}
+	want := "1  name: main  branch: main  path: /code/app  main: yes  status: clean\n" +
+		"2  name: auth  branch: feature/auth  path: /code/app-auth  status: 3 modified\n"
+	if got := renderPlainRows(rows, false); got != want {
+		t.Errorf("renderPlainRows =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestListPlain(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	if _, err := createWorktree(createCmd, "feature/auth"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		plainOutput = false
+		rootCmd.PersistentFlags().Set("plain", "false")
+		rootCmd.SetArgs(nil)
+	})
+
+	rootCmd.SetArgs([]string{"list", "--plain"})
+	out := captureStdout(t, func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Error(err)
+		}
+	})
+	if !plainOutput {
+		t.Error("grove list --plain didn't turn on plain output")
+	}
+	if !strings.Contains(string(out), "name: auth  branch: feature/auth") {
+		t.Errorf("grove list --plain = %q, want labeled lines", out)
+	}
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(poolCmd)
+	poolCmd.AddCommand(poolListCmd, poolFillCmd, poolDrainCmd)
+}
+
+var poolCmd = &cobra.Command{
+	Use:   "pool",
+	Short: "Keep blank worktrees ready for grove create",
+	Long: `In a huge repository, most of grove create is git checking out files.
+A pool moves that work ahead of time: 'grove pool fill N' creates blank
+worktrees, detached at the default branch with the "symlink" entries
+already linked, and the next grove create claims one — it moves the
+worktree into place and switches it to the branch, which only touches the
+files that differ.
+
+Pooled worktrees show up in grove list as "(pool)" and are never treated
+as orphans. Templates with a sparse checkout, and --no-pool, always get a
+fresh worktree. Run 'grove pool fill' again (e.g. from cron) to top the
+pool up, and 'grove pool drain' to remove what's left.`,
+	Args: cobra.NoArgs,
+	RunE: runPoolList,
+}
+
+var poolListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the worktrees waiting in the pool",
+	Args:  cobra.NoArgs,
+	RunE:  runPoolList,
+}
+
+var poolFillCmd = &cobra.Command{
+	Use:   "fill <n>",
+	Short: "Create blank worktrees until the pool holds n",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPoolFill,
+}
+
+var poolDrainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Remove every worktree in the pool",
+	Args:  cobra.NoArgs,
+	RunE:  runPoolDrain,
+}
+
+func runPoolList(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	if len(s.Pool) == 0 {
+		fmt.Println("The pool is empty — run 'grove pool fill <n>' to fill it.")
+		return nil
+	}
+	for _, p := range s.Pool {
+		line := fmt.Sprintf(plain("  %s  at %s, created %s"), displayPath(p.Path), shortCommit(p.Commit), p.Created.Format("2006-01-02 15:04"))
+		if len(p.Linked) > 0 {
+			line += "  [" + strings.Join(p.Linked, ", ") + "]"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runPoolFill(cmd *cobra.Command, args []string) error {
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid pool size %q — expected a number like 3", args[0])
+	}
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	linkMode, err := cfg.EffectiveLinkMode()
+	if err != nil {
+		return err
+	}
+	// Forget worktrees someone removed by hand.
+	var s state.State
+	err = state.Change(root, func(locked *state.State) error {
+		pool := locked.Pool[:0]
+		for _, p := range locked.Pool {
+			if _, err := os.Stat(p.Path); err == nil {
+				pool = append(pool, p)
+			}
+		}
+		locked.Pool = pool
+		s = *locked
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(s.Pool) >= n {
+		fmt.Printf("The pool already holds %d worktree(s).\n", len(s.Pool))
+		return nil
+	}
+
+	branch, err := git.DefaultBranch()
+	if err != nil {
+		return err
+	}
+	commit, err := git.ResolveCommit(branch)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; len(s.Pool) < n; i++ {
+		path, err := worktreePathFor(root, cfg, "pool-"+strconv.Itoa(i))
+		if err != nil {
+			return err
+		}
+		if poolPathTaken(s, path) {
+			continue
+		}
+		entry, err := fillPoolEntry(root, cfg, linkMode, path, commit)
+		if err != nil {
+			return err
+		}
+		// Save after each one, so an interrupted fill keeps what it made.
+		err = state.Change(root, func(locked *state.State) error {
+			locked.Pool = append(locked.Pool, entry)
+			s = *locked
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf(plain("  ✓ %s\n"), displayPath(path))
+	}
+	fmt.Printf("\nThe pool holds %d worktree(s) at %s (%s).\n", len(s.Pool), branch, shortCommit(commit))
+	return nil
+}
+
+// poolPathTaken reports whether path can't hold a new pool worktree: it's
+// already in use, or something is there on disk.
+func poolPathTaken(s state.State, path string) bool {
+	if s.InPool(path) {
+		return true
+	}
+	for _, entry := range s.Worktrees {
+		if entry.Path == path {
+			return true
+		}
+	}
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// fillPoolEntry creates one blank worktree at path, detached at commit, and
+// links cfg.Symlink into it the way grove create would.
+func fillPoolEntry(root string, cfg config.Config, linkMode, path, commit string) (state.PoolEntry, error) {
+	if err := git.AddDetachedWorktree(path, commit); err != nil {
+		return state.PoolEntry{}, err
+	}
+	link := files.Symlink
+	if linkMode == config.LinkHardlink {
+		link = files.HardlinkTree
+	}
+	var linked []string
+	for _, name := range cfg.Symlink {
+		created, err := link(root, path, name)
+		if errors.Is(err, files.ErrSymlinkDestinationConflict) || errors.Is(err, files.ErrDestinationExists) {
+			continue // tracked in git; grove create skips it too
+		}
+		if err != nil {
+			if rbErr := git.RemoveWorktree(path, true); rbErr != nil {
+				fmt.Fprintf(os.Stderr, "  warning: rollback failed, manual cleanup needed: %v\n", rbErr)
+			}
+			return state.PoolEntry{}, fmt.Errorf("symlink %s: %w", name, err)
+		}
+		if created {
+			linked = append(linked, name)
+		}
+	}
+	return state.PoolEntry{
+		Path:     path,
+		Commit:   commit,
+		Linked:   linked,
+		LinkMode: linkMode,
+		Created:  time.Now(),
+	}, nil
+}
+
+func runPoolDrain(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	if len(s.Pool) == 0 {
+		fmt.Println("The pool is empty.")
+		return nil
+	}
+
+	removed := 0
+	var kept []state.PoolEntry
+	for _, p := range s.Pool {
+		if _, err := os.Stat(p.Path); err == nil {
+			if err := git.RemoveWorktree(p.Path, true); err != nil {
+				fmt.Fprintf(os.Stderr, "  warning: could not remove %s: %v\n", p.Path, err)
+				kept = append(kept, p)
+				continue
+			}
+		}
+		removed++
+		fmt.Printf(plain("  ✓ removed %s\n"), displayPath(p.Path))
+	}
+	// Drop only what was drained here; the pool may have changed since.
+	drained := map[string]bool{}
+	for _, p := range s.Pool {
+		drained[p.Path] = true
+	}
+	for _, p := range kept {
+		drained[p.Path] = false
+	}
+	err = state.Change(root, func(s *state.State) error {
+		pool := s.Pool[:0]
+		for _, p := range s.Pool {
+			if !drained[p.Path] {
+				pool = append(pool, p)
+			}
+		}
+		s.Pool = pool
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(kept) > 0 {
+		return fmt.Errorf("%d pool worktree(s) could not be removed — remove them with 'git worktree remove --force', then run 'grove pool drain' again", len(kept))
+	}
+	fmt.Printf("\nRemoved %d pool worktree(s).\n", removed)
+	return nil
+}
+
+// claimPoolEntry takes a worktree from the pool that grove create can use
+// for a worktree linked with linkMode, and removes it from s. Only entries
+// linked the same way, and with nothing linked that cfg doesn't ask for,
+// qualify.
+func claimPoolEntry(s *state.State, cfg config.Config, linkMode string) (state.PoolEntry, bool) {
+	for i, p := range s.Pool {
+		if p.LinkMode != linkMode || !allIn(p.Linked, cfg.Symlink) {
+			continue
+		}
+		if _, err := os.Stat(p.Path); err != nil {
+			continue
+		}
+		s.Pool = append(s.Pool[:i:i], s.Pool[i+1:]...)
+		return p, true
+	}
+	return state.PoolEntry{}, false
+}
+
+// allIn reports whether every name is in list.
+func allIn(names, list []string) bool {
+	for _, name := range names {
+		if !slices.Contains(list, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
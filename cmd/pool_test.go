@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestPool(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "shop",
+		Symlink:     []string{"node_modules"},
+	})
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPoolFill(poolFillCmd, []string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Pool) != 2 {
+		t.Fatalf("pool = %v, want 2 entries", s.Pool)
+	}
+	first := s.Pool[0]
+	if info, err := os.Lstat(filepath.Join(first.Path, "node_modules")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("pool worktree should have node_modules linked: %v", err)
+	}
+	if orphans, _ := findOrphans(s); len(orphans) != 0 {
+		t.Errorf("pool worktrees shouldn't be orphans: %v", orphans)
+	}
+
+	// Filling again tops up rather than adding more.
+	if err := runPoolFill(poolFillCmd, []string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ = state.Load(dir); len(s.Pool) != 2 {
+		t.Fatalf("pool after refill = %v, want 2 entries", s.Pool)
+	}
+
+	createName, createFrom, createTemplate = "", "", ""
+	report, err := createWorktree(createCmd, "feature/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first.Path); !os.IsNotExist(err) {
+		t.Errorf("the claimed pool worktree should have moved, stat = %v", err)
+	}
+	if branch, err := git.BranchAt(report.Path); err != nil || branch != "feature/auth" {
+		t.Errorf("branch = %q, %v", branch, err)
+	}
+	s, _ = state.Load(dir)
+	if len(s.Pool) != 1 || s.InPool(first.Path) {
+		t.Errorf("pool after create = %v, want the other entry", s.Pool)
+	}
+	entry, _ := s.Get("auth")
+	if entry.Setup == nil || len(entry.Setup.Symlinks) != 1 {
+		t.Errorf("setup should record the pool's symlink: %+v", entry.Setup)
+	}
+
+	// --no-pool leaves the pool alone.
+	createNoPool = true
+	t.Cleanup(func() { createNoPool = false })
+	if _, err := createWorktree(createCmd, "feature/cart"); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ = state.Load(dir); len(s.Pool) != 1 {
+		t.Errorf("--no-pool claimed a pool worktree: %v", s.Pool)
+	}
+
+	if err := runPoolDrain(poolDrainCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = state.Load(dir)
+	if len(s.Pool) != 0 {
+		t.Errorf("pool after drain = %v", s.Pool)
+	}
+	if len(s.Worktrees) != 2 {
+		t.Errorf("drain should leave managed worktrees alone: %v", s.Worktrees)
+	}
+}
+
+func TestPoolClaimFailureReturnsWorktree(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	if err := runPoolFill(poolFillCmd, []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := state.Load(dir)
+	if len(s.Pool) != 1 {
+		t.Fatalf("pool = %v, want 1 entry", s.Pool)
+	}
+	pooled := s.Pool[0]
+
+	// The switch fails: there's no such base to start the branch from.
+	createName, createFrom, createTemplate = "", "no-such-base", ""
+	t.Cleanup(func() { createFrom = "" })
+	if _, err := createWorktree(createCmd, "feature/auth"); err == nil {
+		t.Fatal("create from a missing base should fail")
+	}
+	if _, err := os.Stat(pooled.Path); err != nil {
+		t.Errorf("the pool worktree should be back at %s: %v", pooled.Path, err)
+	}
+	s, _ = state.Load(dir)
+	if len(s.Pool) != 1 || !s.InPool(pooled.Path) {
+		t.Errorf("pool after the failed claim = %v, want %s back", s.Pool, pooled.Path)
+	}
+	if _, ok := s.Get("auth"); ok {
+		t.Error("a failed create shouldn't leave a state entry")
+	}
+}
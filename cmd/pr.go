@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	prAuthor string
+	prLimit  int
+	prRemote string
+	prName   string
+)
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	prCmd.AddCommand(prListCmd, prCheckoutCmd)
+	prCmd.PersistentFlags().StringVar(&prAuthor, "author", "", "only pull requests by this GitHub user (@me for yours)")
+	prCmd.PersistentFlags().IntVar(&prLimit, "limit", 30, "list at most this many pull requests")
+	prCmd.PersistentFlags().StringVar(&prRemote, "remote", "origin", "remote to fetch pull request branches from")
+	prCheckoutCmd.Flags().StringVar(&prName, "name", "", "alias for the worktree (default pr-<number>)")
+}
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Browse open pull requests and check them out as worktrees",
+	Long: `List the repository's open pull requests with the GitHub CLI (gh), and ask
+which one to check out as a worktree. Without a terminal to ask in, the list
+is only printed.
+
+'grove pr list' only lists; 'grove pr checkout <number>' checks one out
+without asking.`,
+	Args: cobra.NoArgs,
+	RunE: runPr,
+}
+
+var prListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List open pull requests and which have a worktree",
+	Args:  cobra.NoArgs,
+	RunE:  runPrList,
+}
+
+var prCheckoutCmd = &cobra.Command{
+	Use:   "checkout <number|url>",
+	Short: "Check out a pull request as a worktree named pr-<number>",
+	Long: `Create a worktree for a pull request, named pr-<number> unless --name says
+otherwise.
+
+A pull request from a branch of this repository gets a worktree on that
+branch, set up like 'grove create' — fetched from --remote and tracking it
+if it isn't local yet — so you can push fixes to it. One from a fork is
+checked out detached from pull/<number>/head, like 'grove review'.
+
+Either way the worktree is tagged "pr", and 'grove sync --prs' removes it
+once the pull request has merged. A pull request that already has a
+worktree isn't checked out twice.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPrCheckout,
+}
+
+// prRow is one line of grove pr list.
+type prRow struct {
+	Number   int    `json:"number"`
+	Title    string `json:"title"`
+	Branch   string `json:"branch"`
+	Author   string `json:"author"`
+	Fork     bool   `json:"fork,omitempty"`
+	Worktree string `json:"worktree,omitempty"` // alias of the worktree it's checked out in
+}
+
+func runPr(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	rows, err := listPRs(root)
+	if err != nil {
+		return err
+	}
+	if err := printPRs(rows); err != nil || len(rows) == 0 {
+		return err
+	}
+	if jsonOutput || !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return nil
+	}
+
+	answer := prompt("\nCheck out which pull request? (number, Enter for none)", "")
+	if answer == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("%q is not a pull request number — run 'grove pr checkout <number>'", answer)
+	}
+	return checkoutPR(root, cfg, n, "")
+}
+
+func runPrList(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	rows, err := listPRs(root)
+	if err != nil {
+		return err
+	}
+	return printPRs(rows)
+}
+
+func runPrCheckout(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	_, _, n := parseReviewTarget(args[0], prRemote)
+	if n == 0 {
+		return fmt.Errorf("%q is not a pull request — pass its number or URL", args[0])
+	}
+	return checkoutPR(root, cfg, n, prName)
+}
+
+// listPRs lists the open pull requests, with the worktree each is checked
+// out in, if any.
+func listPRs(root string) ([]prRow, error) {
+	prs, err := openPRs(prAuthor, prLimit)
+	if err != nil {
+		return nil, err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]prRow, 0, len(prs))
+	for _, pr := range prs {
+		alias, _ := prWorktree(s, pr)
+		rows = append(rows, prRow{
+			Number: pr.Number, Title: pr.Title, Branch: pr.HeadRefName,
+			Author: pr.Author.Login, Fork: pr.IsCrossRepo, Worktree: alias,
+		})
+	}
+	return rows, nil
+}
+
+func printPRs(rows []prRow) error {
+	if jsonOutput {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(rows) == 0 {
+		fmt.Println("No open pull requests.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PR\tTITLE\tBRANCH\tAUTHOR\tWORKTREE")
+	for _, r := range rows {
+		branch := r.Branch
+		if r.Fork {
+			branch += " (fork)"
+		}
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s\t%s\n", r.Number, shortTitle(r.Title), branch, r.Author, r.Worktree)
+	}
+	return w.Flush()
+}
+
+// shortTitle keeps long pull request titles from pushing the table wider
+// than a terminal.
+func shortTitle(title string) string {
+	const max = 50
+	if runes := []rune(title); len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return title
+}
+
+// prWorktree returns the alias of a worktree pr is checked out in: one
+// recorded for it, or, for a branch of this repository, one on its branch.
+func prWorktree(s state.State, pr githubPR) (string, bool) {
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias := range s.Worktrees {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		entry := s.Worktrees[alias]
+		if entryPR(entry) == pr.Number || (!pr.IsCrossRepo && entry.Branch == pr.HeadRefName) {
+			return alias, true
+		}
+	}
+	return "", false
+}
+
+// viewPR looks up pull request n.
+func viewPR(n int) (githubPR, error) {
+	var pr githubPR
+	out, err := ghOutput("pr", "view", strconv.Itoa(n), "--json", "number,title,headRefName,isCrossRepository,state,author")
+	if err != nil {
+		return pr, ghError(err)
+	}
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return pr, fmt.Errorf("unexpected output from gh pr view: %w", err)
+	}
+	return pr, nil
+}
+
+// checkoutPR creates the worktree alias (pr-<n> when empty) for pull
+// request n, unless it has one already.
+func checkoutPR(root string, cfg config.Config, n int, alias string) error {
+	pr, err := viewPR(n)
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	if have, ok := prWorktree(s, pr); ok {
+		fmt.Printf("PR #%d is already checked out in %s.\n  cd $(grove cd %s)\n", n, have, have)
+		return nil
+	}
+	if alias == "" {
+		alias = fmt.Sprintf("pr-%d", n)
+	}
+	if err := validateAlias(alias); err != nil {
+		return err
+	}
+	if s.AliasExists(alias) {
+		return errorf(state.ErrAliasExists, "alias %q already exists — pick another with --name, or remove that worktree first", alias)
+	}
+	if pr.State != "" && pr.State != "OPEN" {
+		fmt.Printf("Note: PR #%d is %s.\n", n, pr.State)
+	}
+
+	if !pr.IsCrossRepo {
+		fmt.Printf("PR #%d: %s\n", n, pr.Title)
+		report, err := syncCreate(prRemote, alias, pr.HeadRefName, "")
+		if err != nil {
+			return err
+		}
+		_, err = recordPR(root, report.Alias, n)
+		return err
+	}
+
+	// A fork's branch name means nothing here and may clash with ours, so
+	// check it out detached, as grove review does.
+	path, err := worktreePathFor(root, cfg, alias)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Fetching PR #%d from a fork: %s\n", n, pr.Title)
+	commit, err := git.FetchRef(prRemote, fmt.Sprintf("pull/%d/head", n))
+	if err != nil {
+		return err
+	}
+	if err := git.AddDetachedWorktree(path, commit); err != nil {
+		return err
+	}
+	err = state.Change(root, func(s *state.State) error {
+		if err := s.Add(alias, "", path); err != nil {
+			return err
+		}
+		s.Update(alias, func(e *state.WorktreeEntry) {
+			e.PR = n
+			e.Tags = []string{"pr"}
+		})
+		return nil
+	})
+	if err != nil {
+		git.RemoveWorktree(path, true)
+		return err
+	}
+	fmt.Printf(plain("  ✓ checked out PR #%d at %s (detached)\n"), n, displayPath(path))
+	fmt.Printf("\nWorktree %q ready.\n  cd $(grove cd %s)\n", alias, alias)
+	return nil
+}
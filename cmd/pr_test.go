@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestPRListAndCheckout(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	for _, args := range [][]string{
+		{"init", "--bare", origin},
+		{"remote", "add", "origin", origin},
+		{"push", "origin", "main"},
+		{"push", "origin", "main:refs/heads/feature/search"},
+		// A fork's pull request, only reachable as pull/8/head.
+		{"push", "origin", "main:refs/pull/8/head"},
+	} {
+		if out, err := gitCmd(dir, args...); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	for _, name := range []string{"pr-7", "pr-8"} {
+		wtPath := filepath.Join(filepath.Dir(dir), "testproject-"+name)
+		t.Cleanup(func() { gitCmd(dir, "worktree", "remove", "--force", wtPath) })
+	}
+
+	prs := map[string]string{
+		"7": `{"number": 7, "title": "Faster search", "headRefName": "feature/search", "isCrossRepository": false, "state": "OPEN", "author": {"login": "ana"}}`,
+		"8": `{"number": 8, "title": "Fix typo", "headRefName": "main", "isCrossRepository": true, "state": "OPEN", "author": {"login": "guest"}}`,
+	}
+	orig := ghOutput
+	t.Cleanup(func() { ghOutput = orig })
+	ghOutput = func(args ...string) ([]byte, error) {
+		switch strings.Join(args[:2], " ") {
+		case "pr list":
+			return []byte("[" + prs["7"] + "," + prs["8"] + "]"), nil
+		case "pr view":
+			return []byte(prs[args[2]]), nil
+		}
+		t.Fatalf("unexpected gh %v", args)
+		return nil, nil
+	}
+
+	for _, arg := range []string{"7", "https://github.com/acme/shop/pull/8"} {
+		if err := runPrCheckout(prCheckoutCmd, []string{arg}); err != nil {
+			t.Fatalf("checkout %s: %v", arg, err)
+		}
+	}
+	s, _ := state.Load(dir)
+	same, ok := s.Get("pr-7")
+	if !ok || same.Branch != "feature/search" || same.PR != 7 || !slices.Contains(same.Tags, "pr") {
+		t.Errorf("pr-7 = %+v, %v; want feature/search recorded for PR #7", same, ok)
+	}
+	fork, ok := s.Get("pr-8")
+	if !ok || fork.Branch != "" || fork.PR != 8 {
+		t.Errorf("pr-8 = %+v, %v; want a detached worktree for PR #8", fork, ok)
+	}
+
+	// Checking out again finds the existing worktree.
+	out := captureStdout(t, func() {
+		if err := runPrCheckout(prCheckoutCmd, []string{"7"}); err != nil {
+			t.Errorf("second checkout: %v", err)
+		}
+	})
+	if !strings.Contains(string(out), "already checked out in pr-7") {
+		t.Errorf("second checkout output:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := runPrList(prListCmd, nil); err != nil {
+			t.Errorf("list: %v", err)
+		}
+	})
+	for _, want := range []string{"#7  Faster search", "main (fork)", "pr-8"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("list lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trash"
+)
+
+var pruneExitCode bool
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVar(&pruneExitCode, "exit-code", false, "exit with code 6 when there is nothing to prune")
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Clean up stale worktree references",
+	Long: `Remove bookkeeping for worktrees whose directories no longer exist.
+
+Runs 'git worktree prune', drops grove aliases that point to missing paths
+(unless the worktree is locked, see 'grove lock'), and purges trash items
+older than trashDays.
+The only worktrees it removes from disk are ephemeral ones past their
+expiry (e.g. from 'grove review') that have no local changes and aren't
+locked, so it's safe to run unattended (see 'grove cron install'). They go
+through beforeRemove and the trash like with 'grove remove'.
+
+With --exit-code, grove exits with code 6 when there was nothing to prune.`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	root, err := config.FindRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(root)
+	if err != nil {
+		return err
+	}
+
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	stale := staleAliases(s)
+	for _, alias := range stale {
+		if err := s.Remove(alias); err != nil {
+			return err
+		}
+		fmt.Printf(plain("  ✓ dropped %s (path no longer exists)\n"), alias)
+	}
+	expired := removeExpired(root, cfg, &s, time.Now())
+	if err := dropAliases(root, append(stale, expired...)); err != nil {
+		return err
+	}
+
+	if err := git.PruneWorktrees(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Pruned %d stale alias(es).\n", len(stale))
+
+	// Expired trash is part of routine maintenance too.
+	var purged int
+	if cfg.TrashDays > 0 {
+		purged, err = trash.Purge(root, trashRetention(cfg))
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			fmt.Printf("Purged %d expired trash item(s).\n", purged)
+		}
+	}
+
+	if len(stale) == 0 && len(expired) == 0 && purged == 0 {
+		return nothingToDo(pruneExitCode)
+	}
+	return nil
+}
+
+// removeExpired removes worktrees whose Expires has passed and drops them
+// from s, with removeUnattended. Worktrees with local changes are kept —
+// someone may still be looking at them. Returns the removed aliases.
+func removeExpired(root string, cfg config.Config, s *state.State, now time.Time) []string {
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias, entry := range s.Worktrees {
+		if isExpired(entry, now) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	if len(aliases) == 0 {
+		return nil
+	}
+	guard, err := newMainGuard()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  warning: could not check expired worktrees: %v\n", err)
+		return nil
+	}
+
+	var removed []string
+	for _, alias := range aliases {
+		kept, err := removeUnattended(root, cfg, guard, alias, s.Worktrees[alias])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: could not remove %s: %v\n", alias, err)
+			continue
+		}
+		if kept != "" {
+			fmt.Printf("  kept %s (expired, but %s)\n", alias, kept)
+			continue
+		}
+		s.Remove(alias)
+		removed = append(removed, alias)
+		fmt.Printf(plain("  ✓ removed expired %s\n"), alias)
+	}
+	return removed
+}
+
+// staleAliases returns aliases whose worktree path no longer exists, sorted.
+// Locked worktrees are left out: their storage may only be unmounted.
+func staleAliases(s state.State) []string {
+	var stale []string
+	locked := lockedPaths()
+	for alias, entry := range s.Worktrees {
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) && !locked[entry.Path] {
+			stale = append(stale, alias)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trash"
+)
+
+func TestPruneExitCode(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Symlink: []string{}})
+	pruneExitCode = true
+	t.Cleanup(func() { pruneExitCode = false })
+
+	// Nothing stale yet.
+	if err := runPrune(pruneCmd, nil); !errors.Is(err, errNothingToDo) {
+		t.Fatalf("expected errNothingToDo, got %v", err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(t.TempDir(), "gone")
+	os.MkdirAll(gone, 0755)
+	if err := s.Add("gone", "feature/gone", gone); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Save(dir, s); err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(gone)
+
+	if err := runPrune(pruneCmd, nil); err != nil {
+		t.Fatalf("prune with a stale alias should succeed, got %v", err)
+	}
+}
+
+func TestPruneExpiredTearsDown(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:  "../",
+		Prefix:       "shop",
+		Symlink:      []string{},
+		BeforeRemove: "touch ../torn-down",
+		TrashDays:    7,
+	})
+	trustHooks = true
+	t.Cleanup(func() { trustHooks = false })
+	createName, createFrom, createTemplate = "", "", ""
+	if _, err := createWorktree(createCmd, "feature/old"); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := state.Load(dir)
+	s.Update("old", func(e *state.WorktreeEntry) { e.Expires = time.Now().Add(-time.Minute) })
+	if err := state.Save(dir, s); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPrune(pruneCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := state.Load(dir); s.AliasExists("old") {
+		t.Fatal("expired worktree not removed")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "torn-down")); err != nil {
+		t.Error("prune skipped the beforeRemove hook")
+	}
+	if items, _ := trash.List(dir); len(items) != 1 {
+		t.Errorf("trash after prune = %d items, want 1", len(items))
+	}
+}
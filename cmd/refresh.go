@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/listcache"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// snapshotMaxAge is how old a refresher snapshot may be before `grove list`
+// ignores it and checks every worktree live again — a refresher that died
+// shouldn't leave list showing hour-old data.
+const snapshotMaxAge = 10 * time.Minute
+
+var (
+	refreshDaemon   bool
+	refreshInterval time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(refreshCmd)
+	refreshCmd.Flags().BoolVar(&refreshDaemon, "daemon", false, "keep running and refresh every --interval until interrupted")
+	refreshCmd.Flags().DurationVar(&refreshInterval, "interval", 30*time.Second, "time between refreshes with --daemon")
+}
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Precompute list data into .grove/cache.json",
+	Long: `Compute status, ahead/behind and disk usage for every worktree and store
+them in .grove/cache.json. While the data is fresh, 'grove list' renders
+from it instantly and shows SYNC and SIZE columns plus how old the data is.
+
+With --daemon, grove keeps running and refreshes every --interval (30s by
+default) until interrupted. Run it in a spare terminal or in the background:
+
+  grove refresh --daemon &`,
+	Args: cobra.NoArgs,
+	RunE: runRefresh,
+}
+
+func runRefresh(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	if !refreshDaemon {
+		snap, err := refreshSnapshot(root)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Refreshed %d worktree(s).\n", len(snap.Worktrees))
+		return nil
+	}
+
+	if refreshInterval <= 0 {
+		return fmt.Errorf("--interval must be positive, got %s", refreshInterval)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Refreshing %s every %s — Ctrl-C to stop.\n", listcache.Path(root), refreshInterval)
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		// A failed round (e.g. a worktree removed mid-refresh) shouldn't stop the daemon.
+		if _, err := refreshSnapshot(root); err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: refresh failed: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshSnapshot computes list data for every worktree and saves it.
+func refreshSnapshot(root string) (listcache.Snapshot, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return listcache.Snapshot{}, err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return listcache.Snapshot{}, err
+	}
+	bases := make(map[string]string)
+	for _, entry := range s.Worktrees {
+		bases[entry.Path] = entry.Base
+	}
+
+	// Upstream tracking for every checked-out branch in one git call.
+	var branches []string
+	for _, wt := range worktrees {
+		if !strings.HasPrefix(wt.Branch, "(detached") {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	branchInfo := map[string]git.BranchInfo{}
+	if len(branches) > 0 {
+		if bi, err := git.BatchBranchInfo(branches); err == nil {
+			branchInfo = bi
+		}
+	}
+
+	infos := make([]listcache.Info, len(worktrees))
+	parallel(len(worktrees), func(i int) {
+		wt := worktrees[i]
+		info := listcache.Info{Status: "unknown"}
+		if status, err := git.QuickStatus(wt.Path); err == nil {
+			info.Status = status.String()
+		}
+
+		// Compare against the upstream when there is one, else the base
+		// the worktree was created from.
+		if bi, ok := branchInfo[wt.Branch]; ok && bi.Upstream != "" && !bi.Gone {
+			info.Ahead, info.Behind, info.Against = bi.Ahead, bi.Behind, bi.Upstream
+		} else if against := bases[wt.Path]; against != "" {
+			if ahead, behind, err := git.AheadBehind(wt.Path, against); err == nil {
+				info.Ahead, info.Behind, info.Against = ahead, behind, against
+			}
+		}
+
+		if size, err := files.DiskUsage(wt.Path); err == nil {
+			info.DiskBytes = size
+		}
+		infos[i] = info
+	})
+
+	snap := listcache.Snapshot{Refreshed: time.Now(), Worktrees: map[string]listcache.Info{}}
+	for i, wt := range worktrees {
+		snap.Worktrees[wt.Path] = infos[i]
+	}
+
+	return snap, listcache.Save(root, snap)
+}
+
+// listRows builds the rows for `grove list`, from the refresher's snapshot
+// when a fresh one exists. The returned time is when the snapshot was taken,
+// zero when the rows were computed live.
+func listRows(root string) ([]worktreeRow, time.Time, error) {
+	snap, ok, err := listcache.Load(root)
+	if err != nil || !ok || statusNoCache || time.Since(snap.Refreshed) > snapshotMaxAge {
+		rows, err := buildWorktreeRows(root)
+		return rows, time.Time{}, err
+	}
+
+	rows, err := buildRows(root, func(path string) string {
+		if info, ok := snap.Worktrees[path]; ok {
+			return info.Status
+		}
+		// Created after the last refresh.
+		if status, err := git.QuickStatus(path); err == nil {
+			return status.String()
+		}
+		return "unknown"
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for i := range rows {
+		if info, ok := snap.Worktrees[rows[i].Path]; ok {
+			rows[i].Cached = &info
+		}
+	}
+	return rows, snap.Refreshed, nil
+}
+
+// syncSummary formats ahead/behind counts, e.g. "↑2 ↓1".
+func syncSummary(info *listcache.Info) string {
+	if info == nil || info.Against == "" {
+		return "-"
+	}
+	return fmt.Sprintf(plain("↑%d ↓%d"), info.Ahead, info.Behind)
+}
+
+// humanBytes formats a byte count with a binary unit, e.g. "12.3 MiB".
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
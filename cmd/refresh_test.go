@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestRefreshSnapshotFeedsList(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/cached"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-cached")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+	gitCmd(wtPath, "commit", "--allow-empty", "-m", "work")
+
+	snap, err := refreshSnapshot(dir)
+	if err != nil {
+		t.Fatal("refreshSnapshot failed:", err)
+	}
+	info, ok := snap.Worktrees[wtPath]
+	if !ok {
+		t.Fatalf("no snapshot entry for %s: %v", wtPath, snap.Worktrees)
+	}
+	// No upstream, so ahead/behind is measured against the recorded base.
+	if info.Against != "main" || info.Ahead != 1 || info.Behind != 0 {
+		t.Errorf("sync = %+v, want +1 -0 against main", info)
+	}
+
+	rows, refreshed, err := listRows(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.IsZero() {
+		t.Fatal("expected list to use the fresh snapshot")
+	}
+	for _, r := range rows {
+		if r.Cached == nil {
+			t.Errorf("row %s has no cached data", r.Name)
+		}
+	}
+
+	statusNoCache = true
+	t.Cleanup(func() { statusNoCache = false })
+	if _, refreshed, _ := listRows(dir); !refreshed.IsZero() {
+		t.Error("expected --no-cache to bypass the snapshot")
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512 B",
+		2048:            "2.0 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for n, want := range tests {
+		if got := humanBytes(n); got != want {
+			t.Errorf("humanBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
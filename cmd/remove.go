@@ -3,18 +3,25 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/verbaux/grove/internal/config"
 	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/i18n"
 	"github.com/verbaux/grove/internal/state"
 )
 
-var removeForce bool
+var (
+	removeForce bool
+	removeYes   bool
+)
 
 func init() {
 	rootCmd.AddCommand(removeCmd)
 	removeCmd.Flags().BoolVar(&removeForce, "force", false, "remove even if there are uncommitted changes")
+	removeCmd.Flags().BoolVarP(&removeYes, "yes", "y", false, "don't ask to type the name when forcing out changes and unpushed commits")
 }
 
 var removeCmd = &cobra.Command{
@@ -23,7 +30,15 @@ var removeCmd = &cobra.Command{
 	Long: `Remove a worktree by alias.
 
 Checks for uncommitted changes and asks for confirmation before removing.
-Use --force to skip the check.`,
+Use --force to skip the check. Removing the worktree you are standing in
+also asks first, and prints the main worktree's path to cd to.
+
+What happens to uncommitted changes is set by "onDirtyRemove" in .groverc.json:
+prompt (default), block, stash (saved to git stash list) or force.
+
+Forcing out a worktree that has both uncommitted changes and commits that
+aren't pushed or on another branch asks you to type its name first. --yes
+skips the question, for scripts.`,
 	Args: cobra.ExactArgs(1),
 	ValidArgsFunction: completeAliases,
 	RunE: runRemove,
@@ -42,28 +57,97 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := config.Load(root)
+	if err != nil {
+		return err
+	}
+
+	policy, err := cfg.DirtyRemovePolicy()
+	if err != nil {
+		return err
+	}
+	if removeForce {
+		policy = config.DirtyForce
+	}
+
 	s, err := state.Load(root)
 	if err != nil {
 		return err
 	}
 
-	resolved, err := resolveWorktree(query, s)
+	if query, err = expandRowRef(root, query); err != nil {
+		return err
+	}
+	// An ambiguous name could remove the wrong worktree, so it's an error
+	// here rather than the warning other commands give.
+	resolved, err := lookupWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved != nil && len(resolved.Ambiguous) > 0 {
+		return fmt.Errorf("%s — write alias:%s or branch:%s to say which to remove", ambiguityText(query, resolved), query, query)
+	}
+	guard, err := newMainGuard()
 	if err != nil {
 		return err
 	}
 	if resolved == nil {
-		return fmt.Errorf("no worktree matching %q — run 'grove list' to see available worktrees", query)
+		// "grove remove main" or "grove remove ." deserve a clearer answer
+		// than "not found".
+		_, name := splitQueryKind(query)
+		abs, _ := filepath.Abs(name)
+		if err := guard.check(abs, query); err != nil {
+			return err
+		}
+		return errorf(state.ErrNotFound, "no worktree matching %q — run 'grove list' to see available worktrees", query)
+	}
+	if err := guard.check(resolved.Path, resolved.Branch); err != nil {
+		return err
 	}
 
 	label := resolved.Alias
 	if label == "" {
 		label = resolved.Branch
 	}
+	if entry, ok := s.Get(resolved.Alias); ok && !removeForce {
+		if c := claimedByOther(entry); c != nil {
+			return errorf(state.ErrClaimed, "%s is claimed by %s since %s — wait for 'grove release %s', or pass --force", label, c.Owner, c.Since.Format(time.DateTime), label)
+		}
+	}
+
+	// A locked worktree may be on storage that's only unmounted right now.
+	reason, locked := worktreeLock(resolved.Path)
+	if locked && !removeForce {
+		return errorf(git.ErrLocked, "%s is locked%s — run 'grove unlock %s' first, or pass --force", label, lockSuffix(reason), label)
+	}
+
+	// Removing the worktree we're standing in would leave the shell in a
+	// deleted directory, and the git calls below would fail half-way.
+	inside := isWithin(cwd, resolved.Path)
+	if inside {
+		fmt.Printf(i18n.T("You are inside worktree %q — removing it leaves your shell in a deleted directory.\n"), label)
+		if !removeForce {
+			answer := prompt(i18n.T("Remove anyway? [y/N]"), "n")
+			if answer != "y" && answer != "Y" {
+				fmt.Printf(i18n.T("Aborted. Leave it first: cd %s\n"), root)
+				return nil
+			}
+		}
+		if err := os.Chdir(root); err != nil {
+			return err
+		}
+	}
 
 	// If the path no longer exists on disk, the worktree was removed manually.
 	// Skip git commands and just clean up state.
 	if _, err := os.Stat(resolved.Path); os.IsNotExist(err) {
-		fmt.Printf("Worktree path %s no longer exists, cleaning up state.\n", resolved.Path)
+		fmt.Printf(i18n.T("Worktree path %s no longer exists, cleaning up state.\n"), resolved.Path)
+		// Otherwise git worktree prune would keep its record.
+		if locked {
+			if err := git.UnlockWorktree(resolved.Path); err != nil {
+				return err
+			}
+		}
 	} else {
 		status, err := git.Status(resolved.Path)
 		if err != nil {
@@ -71,35 +155,71 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		}
 
 		force := removeForce
-		if status != "clean" && !removeForce {
-			fmt.Printf("Worktree %q has %s.\n", label, status)
-			answer := prompt("Remove anyway? [y/N]", "n")
-			if answer != "y" && answer != "Y" {
-				fmt.Println("Aborted.")
+		var snapshot string
+		if !status.Clean() && policy == config.DirtyForce && !removeYes {
+			if err := confirmForcedRemove(label, resolved.Path, resolved.Branch, status); err != nil {
+				return err
+			}
+		}
+		if !status.Clean() {
+			proceed, snap, err := handleDirty(policy, label, resolved.Path, status)
+			if err != nil {
+				return err
+			}
+			if !proceed {
+				fmt.Print(i18n.T("Aborted.\n"))
 				return nil
 			}
+			snapshot = snap
 			force = true
 		}
 
+		if resolved.InState {
+			entry, _ := s.Get(resolved.Alias)
+			if _, err := teardownConfig(root, cfg, entry); err != nil {
+				return err
+			}
+			var trashed string
+			if cfg.TrashDays > 0 {
+				if trashed, err = moveToTrash(root, cfg, resolved.Alias, entry, snapshot); err != nil {
+					return fmt.Errorf("could not move %q to trash, not removing: %w", label, err)
+				}
+			}
+			if err := teardownWorktree(root, cfg, resolved.Alias, entry); err != nil {
+				if !removeForce {
+					if trashed != "" {
+						discardTrash(root, trashed)
+					}
+					return fmt.Errorf("%w — fix it, or use --force to remove anyway", err)
+				}
+				fmt.Fprintf(os.Stderr, i18n.T("  warning: %v\n"), err)
+			}
+		}
+
+		if locked {
+			if err := git.UnlockWorktree(resolved.Path); err != nil {
+				return err
+			}
+		}
 		if err := git.RemoveWorktree(resolved.Path, force); err != nil {
 			return err
 		}
-		fmt.Printf("  ✓ removed worktree at %s\n", resolved.Path)
+		fmt.Printf(plain(i18n.T("  ✓ removed worktree at %s\n")), resolved.Path)
 	}
 
 	if resolved.InState {
-		if err := s.Remove(resolved.Alias); err != nil {
-			return err
-		}
-		if err := state.Save(root, s); err != nil {
+		if err := dropAliases(root, []string{resolved.Alias}); err != nil {
 			return err
 		}
 	}
 
 	if err := git.PruneWorktrees(); err != nil {
-		fmt.Fprintf(os.Stderr, "  warning: git worktree prune failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("  warning: git worktree prune failed: %v\n"), err)
 	}
 
-	fmt.Printf("Worktree %q removed.\n", label)
+	fmt.Printf(i18n.T("Worktree %q removed.\n"), label)
+	if inside {
+		fmt.Printf(i18n.T("Your shell is still in the deleted directory — run: cd %s\n"), root)
+	}
 	return nil
 }
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trash"
+)
+
+// createDirtyWorktree creates a grove worktree for branch and leaves an
+// untracked file in it. Returns the worktree path.
+func createDirtyWorktree(t *testing.T, dir, branch string) string {
+	t.Helper()
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{branch}); err != nil {
+		t.Fatal(err)
+	}
+
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-"+branchAlias(branch))
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	if err := os.WriteFile(filepath.Join(wtPath, "wip.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return wtPath
+}
+
+func TestRemoveDirtyBlock(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:   "../",
+		Prefix:        "testproject",
+		Symlink:       []string{},
+		OnDirtyRemove: config.DirtyBlock,
+	})
+	wtPath := createDirtyWorktree(t, dir, "feature/blocked")
+
+	err := runRemove(removeCmd, []string{"blocked"})
+	if !errors.Is(err, git.ErrDirty) {
+		t.Fatalf("expected ErrDirty, got %v", err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("worktree should still exist: %v", err)
+	}
+}
+
+func TestRemoveDirtyStash(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:   "../",
+		Prefix:        "testproject",
+		Symlink:       []string{},
+		OnDirtyRemove: config.DirtyStash,
+	})
+	wtPath := createDirtyWorktree(t, dir, "feature/stashed")
+
+	if err := runRemove(removeCmd, []string{"stashed"}); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("expected worktree to be removed")
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AliasExists("stashed") {
+		t.Error("alias should be gone from state")
+	}
+
+	out, err := exec.Command("git", "-C", dir, "stash", "list").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git stash list: %s", out)
+	}
+	if !strings.Contains(string(out), "grove: removed stashed") {
+		t.Errorf("expected a grove stash entry, got %q", out)
+	}
+}
+
+func TestDirtyRemovePolicyInvalid(t *testing.T) {
+	setupIntegrationRepo(t, config.Config{
+		WorktreeDir:   "../",
+		Prefix:        "testproject",
+		OnDirtyRemove: "yolo",
+	})
+
+	if err := runRemove(removeCmd, []string{"anything"}); err == nil {
+		t.Fatal("expected error for invalid onDirtyRemove")
+	}
+}
+
+func TestRemoveForceSnapshotsChanges(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+	})
+	createDirtyWorktree(t, dir, "feature/forced")
+
+	removeForce = true
+	t.Cleanup(func() { removeForce = false })
+
+	if err := runRemove(removeCmd, []string{"forced"}); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "show", "refs/grove/trash/forced:wip.txt").CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected snapshot ref with wip.txt: %s", out)
+	}
+	if string(out) != "wip" {
+		t.Errorf("wip.txt in snapshot = %q, want %q", out, "wip")
+	}
+}
+
+func TestTrashRef(t *testing.T) {
+	for label, want := range map[string]string{
+		"forced":              "refs/grove/trash/forced",
+		"feature/auth":        "refs/grove/trash/feature/auth",
+		"(detached abc1234)":  "refs/grove/trash/detached-abc1234",
+		"a..b/.hidden/x.lock": "refs/grove/trash/a-b/hidden/x",
+		"~^:?*[":              "refs/grove/trash/worktree",
+	} {
+		got := trashRef(label)
+		if got != want {
+			t.Errorf("trashRef(%q) = %q, want %q", label, got, want)
+		}
+		if out, err := exec.Command("git", "check-ref-format", got).CombinedOutput(); err != nil {
+			t.Errorf("trashRef(%q) = %q isn't a valid ref: %s", label, got, out)
+		}
+	}
+}
+
+func TestRemoveRunsTeardown(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "dropped")
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:  "../",
+		Prefix:       "testproject",
+		Symlink:      []string{"node_modules"},
+		BeforeRemove: `test -L node_modules && echo "$GROVE_ALIAS" > ` + marker,
+	})
+	os.MkdirAll(filepath.Join(dir, "node_modules"), 0755)
+	// Like a real project, keep the symlink out of git status.
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules\n"), 0644)
+	gitCmd(dir, "add", ".gitignore")
+	gitCmd(dir, "commit", "-m", "ignore node_modules")
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/teardown"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-teardown")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	if err := runRemove(removeCmd, []string{"teardown"}); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	// The hook ran while the symlink was still there, with the alias in its env.
+	data, err := os.ReadFile(marker)
+	if err != nil || strings.TrimSpace(string(data)) != "teardown" {
+		t.Errorf("beforeRemove marker = %q, %v; want teardown", data, err)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("worktree should be removed")
+	}
+	// The symlink target in the main worktree is untouched.
+	if _, err := os.Stat(filepath.Join(dir, "node_modules")); err != nil {
+		t.Errorf("main node_modules should survive: %v", err)
+	}
+}
+
+func TestRemoveTeardownFailureBlocks(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:  "../",
+		Prefix:       "testproject",
+		Symlink:      []string{},
+		BeforeRemove: "exit 1",
+	})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/stuck"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-stuck")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	if err := runRemove(removeCmd, []string{"stuck"}); err == nil || !strings.Contains(err.Error(), "beforeRemove") {
+		t.Fatalf("expected beforeRemove error, got %v", err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Fatalf("worktree should still exist: %v", err)
+	}
+
+	removeForce = true
+	t.Cleanup(func() { removeForce = false })
+	if err := runRemove(removeCmd, []string{"stuck"}); err != nil {
+		t.Fatalf("remove --force should proceed past a failing hook: %v", err)
+	}
+}
+
+func TestRemoveTrashFailureSkipsTeardown(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "torn-down")
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:  "../",
+		Prefix:       "testproject",
+		Symlink:      []string{},
+		TrashDays:    7,
+		BeforeRemove: "touch " + marker,
+	})
+
+	createName, createFrom = "", ""
+	if err := runCreate(createCmd, []string{"feature/kept"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-kept")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	// A file where the trash directory goes makes moving to trash fail.
+	os.MkdirAll(filepath.Dir(trash.Dir(dir)), 0755)
+	if err := os.WriteFile(trash.Dir(dir), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runRemove(removeCmd, []string{"kept"}); err == nil || !strings.Contains(err.Error(), "trash") {
+		t.Fatalf("expected the trash step to fail, got %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("beforeRemove ran although the worktree was kept")
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Errorf("worktree should still exist: %v", err)
+	}
+}
+
+func TestRemoveTeardownFailureDiscardsTrash(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:  "../",
+		Prefix:       "testproject",
+		Symlink:      []string{},
+		TrashDays:    7,
+		BeforeRemove: "exit 1",
+	})
+
+	createName, createFrom = "", ""
+	if err := runCreate(createCmd, []string{"feature/stuck"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-stuck")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	if err := runRemove(removeCmd, []string{"stuck"}); err == nil {
+		t.Fatal("expected the failing beforeRemove to stop the removal")
+	}
+	if items, _ := trash.List(dir); len(items) != 0 {
+		t.Errorf("trash = %+v, want the item of the kept worktree dropped", items)
+	}
+}
+
+func TestRemoveFromInsideWorktree(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/inside"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-inside")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+	if err := os.Chdir(wtPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Declining the prompt keeps the worktree.
+	reader = bufio.NewReader(strings.NewReader("n\n"))
+	t.Cleanup(func() { reader = nil })
+	removeForce = false
+	if err := runRemove(removeCmd, []string{"inside"}); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Fatalf("worktree should still exist after declining: %v", err)
+	}
+
+	reader = bufio.NewReader(strings.NewReader("y\n"))
+	if err := runRemove(removeCmd, []string{"inside"}); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists: %v", err)
+	}
+	if cwd, _ := os.Getwd(); cwd != dir {
+		t.Errorf("cwd = %s, want grove to have moved to %s", cwd, dir)
+	}
+	if s, _ := state.Load(dir); s.AliasExists("inside") {
+		t.Error("alias still in state")
+	}
+}
+
+func TestRemoveAmbiguousName(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	// "auth" is the alias of one worktree and the branch of another.
+	createFrom = ""
+	createName = ""
+	if err := runCreate(createCmd, []string{"feature/auth"}); err != nil {
+		t.Fatal(err)
+	}
+	createName = "api"
+	t.Cleanup(func() { createName = "" })
+	if err := runCreate(createCmd, []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, alias := range []string{"auth", "api"} {
+		wtPath := filepath.Join(filepath.Dir(dir), "testproject-"+alias)
+		t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+	}
+
+	err := runRemove(removeCmd, []string{"auth"})
+	if err == nil || !strings.Contains(err.Error(), "branch:auth") {
+		t.Fatalf("remove auth: err = %v, want an ambiguity error", err)
+	}
+	if err := runRemove(removeCmd, []string{"branch:auth"}); err != nil {
+		t.Fatalf("remove branch:auth failed: %v", err)
+	}
+
+	s, _ := state.Load(dir)
+	if s.AliasExists("api") || !s.AliasExists("auth") {
+		t.Errorf("worktrees left = %v, want only auth", s.Worktrees)
+	}
+}
+
+func TestRemoveForceAsksForName(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	wtPath := createDirtyWorktree(t, dir, "feature/precious")
+	if out, err := gitCmd(wtPath, "commit", "--allow-empty", "-m", "only here"); err != nil {
+		t.Fatalf("commit: %v\n%s", err, out)
+	}
+
+	removeForce = true
+	t.Cleanup(func() { removeForce, removeYes, reader = false, false, nil })
+
+	reader = bufio.NewReader(strings.NewReader("y\n"))
+	if err := runRemove(removeCmd, []string{"precious"}); !errors.Is(err, git.ErrDirty) {
+		t.Fatalf("answering y: err = %v, want the name to be required", err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Fatalf("worktree should still be there: %v", err)
+	}
+
+	reader = bufio.NewReader(strings.NewReader("precious\n"))
+	if err := runRemove(removeCmd, []string{"precious"}); err != nil {
+		t.Fatalf("typing the name: %v", err)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("worktree should be removed once the name is typed")
+	}
+
+	// --yes goes ahead without asking.
+	wtPath = createDirtyWorktree(t, dir, "feature/scripted")
+	gitCmd(wtPath, "commit", "--allow-empty", "-m", "only here")
+	reader, removeYes = bufio.NewReader(strings.NewReader("")), true
+	if err := runRemove(removeCmd, []string{"scripted"}); err != nil {
+		t.Fatalf("--yes: %v", err)
+	}
+}
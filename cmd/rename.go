@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	renameMove   bool
+	renameBranch string
+)
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+	renameCmd.Flags().BoolVar(&renameMove, "move", false, "also move the worktree directory to match the new alias")
+	renameCmd.Flags().StringVar(&renameBranch, "branch", "", "also rename the branch checked out in the worktree")
+}
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <name> <new-alias>",
+	Short: "Rename a worktree, and optionally its directory and branch",
+	Long: `Give a managed worktree a new alias, keeping its checkout and any
+uncommitted changes.
+
+With --move, the worktree directory is moved (git worktree move) to the path
+grove create would have picked for the new alias. With --branch, the branch
+checked out in it is renamed too, and its upstream pointed at the new name,
+as 'grove rename-branch' does.
+
+Everything is checked before anything changes, so a taken alias or
+directory doesn't leave the worktree half renamed.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliases,
+	RunE:              runRename,
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	alias, err := resolveManaged(root, s, args[0])
+	if err != nil {
+		return err
+	}
+	newAlias := args[1]
+	if err := renameWorktree(root, cfg, s, alias, newAlias, renameBranch, renameMove); err != nil {
+		return err
+	}
+	fmt.Printf(plain("Renamed %s → %s.\n"), alias, newAlias)
+	return nil
+}
+
+// renameWorktree gives the worktree at alias the alias newAlias and, if
+// newBranch isn't empty, renames its branch to newBranch. With move, the
+// directory follows the new alias. Shared by rename and rename-branch.
+//
+// Everything that can be checked is checked before anything changes. Past
+// that, each change is recorded in state as soon as it's made, so a later
+// step failing doesn't leave state describing a branch that's gone.
+func renameWorktree(root string, cfg config.Config, s state.State, alias, newAlias, newBranch string, move bool) error {
+	entry, _ := s.Get(alias)
+
+	if err := guardMain(entry.Path, ""); err != nil {
+		return err
+	}
+	if c := claimedByOther(entry); c != nil {
+		return errorf(state.ErrClaimed, "%s is claimed by %s — wait for 'grove release %s' before renaming it", alias, c.Owner, alias)
+	}
+	if err := validateAlias(newAlias); err != nil {
+		return err
+	}
+	if newAlias != alias && s.AliasExists(newAlias) {
+		return errorf(state.ErrAliasExists, "alias %q already exists — pick another name, or remove that worktree first", newAlias)
+	}
+	newPath := entry.Path
+	if move {
+		var err error
+		if newPath, err = worktreePathFor(root, cfg, newAlias); err != nil {
+			return err
+		}
+		if newPath != entry.Path {
+			if _, err := os.Lstat(newPath); err == nil {
+				return fmt.Errorf("%s already exists — move it out of the way, or rename without moving it", displayPath(newPath))
+			}
+		}
+	}
+	if newBranch != "" && newBranch != entry.Branch {
+		if err := git.CheckBranchName(newBranch); err != nil {
+			return err
+		}
+		if git.BranchExists(newBranch) {
+			return fmt.Errorf("branch %q already exists — pick another name", newBranch)
+		}
+	}
+
+	if newBranch != "" && newBranch != entry.Branch {
+		if err := git.RenameBranch(entry.Branch, newBranch); err != nil {
+			return err
+		}
+		fmt.Printf(plain("  ✓ renamed branch %s → %s\n"), entry.Branch, newBranch)
+		if remote, err := git.RetargetUpstream(newBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: could not update upstream: %v\n", err)
+		} else if remote != "" {
+			fmt.Printf(plain("  ✓ upstream now %s/%s (push to publish it)\n"), remote, newBranch)
+		}
+		// Recorded now, so a failed move below keeps it on record.
+		err := state.Change(root, func(s *state.State) error {
+			return s.Update(alias, func(e *state.WorktreeEntry) { e.Branch = newBranch })
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if newPath != entry.Path {
+		if err := git.MoveWorktree(entry.Path, newPath); err != nil {
+			return err
+		}
+		fmt.Printf(plain("  ✓ moved worktree to %s\n"), displayPath(newPath))
+	}
+
+	err := state.Change(root, func(s *state.State) error {
+		if err := s.Update(alias, func(e *state.WorktreeEntry) { e.Path = newPath }); err != nil {
+			return err
+		}
+		if newAlias != alias {
+			return s.Rename(alias, newAlias)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if cwd, err := os.Getwd(); newPath != entry.Path && (err != nil || isWithin(cwd, entry.Path)) {
+		fmt.Printf("Your shell was inside the old directory — run: cd %s\n", displayPath(newPath))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var renameBranchAlias bool
+
+func init() {
+	rootCmd.AddCommand(renameBranchCmd)
+	renameBranchCmd.Flags().BoolVar(&renameBranchAlias, "rename-alias", false, "also rename the alias and worktree directory to match the new branch")
+}
+
+var renameBranchCmd = &cobra.Command{
+	Use:   "rename-branch <alias> <new-branch>",
+	Short: "Rename the branch checked out in a worktree",
+	Long: `Rename the branch checked out in a worktree and keep grove in sync.
+
+Grove will:
+  - Rename the branch with git branch -m
+  - Point the upstream (if any) at the new branch name on the same remote
+  - Update the worktree's entry in .grove/state.json
+
+With --rename-alias, the alias and worktree directory are renamed too,
+using the same naming rule as grove create ("feature/login" → "login").
+
+This is 'grove rename <alias> <alias> --branch <new-branch>', with --move
+to the new alias for --rename-alias. If a later step fails, the renamed
+branch is still recorded in state.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliases,
+	RunE:              runRenameBranch,
+}
+
+func runRenameBranch(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	alias, err := resolveManaged(root, s, args[0])
+	if err != nil {
+		return err
+	}
+	newBranch := args[1]
+
+	// Same as grove rename <alias> <alias> --branch <new-branch>, plus
+	// --move to the new alias with --rename-alias.
+	newAlias := alias
+	if renameBranchAlias {
+		newAlias = branchAlias(newBranch)
+	}
+	if err := renameWorktree(root, cfg, s, alias, newAlias, newBranch, renameBranchAlias); err != nil {
+		return err
+	}
+
+	fmt.Printf("Worktree %q is now on branch %q.\n", newAlias, newBranch)
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestRenameBranchWithAlias(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+	})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/old-name"}); err != nil {
+		t.Fatal(err)
+	}
+
+	oldPath := filepath.Join(filepath.Dir(dir), "testproject-old-name")
+	newPath := filepath.Join(filepath.Dir(dir), "testproject-new-name")
+	t.Cleanup(func() {
+		git.RemoveWorktree(oldPath, true)
+		git.RemoveWorktree(newPath, true)
+	})
+
+	renameBranchAlias = true
+	t.Cleanup(func() { renameBranchAlias = false })
+
+	if err := runRenameBranch(renameBranchCmd, []string{"old-name", "feature/new-name"}); err != nil {
+		t.Fatalf("rename-branch failed: %v", err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AliasExists("old-name") {
+		t.Error("old alias should be gone")
+	}
+	entry, ok := s.Get("new-name")
+	if !ok {
+		t.Fatal("expected new alias in state")
+	}
+	if entry.Branch != "feature/new-name" {
+		t.Errorf("branch = %q, want %q", entry.Branch, "feature/new-name")
+	}
+	if entry.Path != newPath {
+		t.Errorf("path = %q, want %q", entry.Path, newPath)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("expected worktree at new path: %v", err)
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worktrees[1].Branch != "feature/new-name" {
+		t.Errorf("git branch = %q, want %q", worktrees[1].Branch, "feature/new-name")
+	}
+}
+
+func TestRenameBranchKeepsStateOnFailedMove(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName, createFrom = "", ""
+	if err := runCreate(createCmd, []string{"feature/stuck"}); err != nil {
+		t.Fatal(err)
+	}
+	oldPath := filepath.Join(filepath.Dir(dir), "testproject-stuck")
+	t.Cleanup(func() {
+		gitCmd(dir, "worktree", "unlock", oldPath)
+		git.RemoveWorktree(oldPath, true)
+	})
+
+	// git won't move a locked worktree, so the rename fails after the
+	// branch has already been renamed.
+	if _, err := gitCmd(dir, "worktree", "lock", oldPath); err != nil {
+		t.Fatal(err)
+	}
+	renameBranchAlias = true
+	t.Cleanup(func() { renameBranchAlias = false })
+	if err := runRenameBranch(renameBranchCmd, []string{"stuck", "feature/unstuck"}); err == nil {
+		t.Fatal("expected the move of a locked worktree to fail")
+	}
+
+	s, _ := state.Load(dir)
+	if entry, ok := s.Get("stuck"); !ok || entry.Branch != "feature/unstuck" || entry.Path != oldPath {
+		t.Errorf("state = %+v, want the renamed branch at the old path", entry)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestRename(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	createName, createFrom = "", ""
+	for _, branch := range []string{"feature/draft", "feature/other"} {
+		if err := runCreate(createCmd, []string{branch}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldPath := filepath.Join(filepath.Dir(dir), "testproject-draft")
+	newPath := filepath.Join(filepath.Dir(dir), "testproject-checkout")
+	t.Cleanup(func() {
+		for _, p := range []string{oldPath, newPath, filepath.Join(filepath.Dir(dir), "testproject-other")} {
+			git.RemoveWorktree(p, true)
+		}
+	})
+	t.Cleanup(func() { renameMove, renameBranch = false, "" })
+
+	// Uncommitted work has to survive the rename.
+	os.WriteFile(filepath.Join(oldPath, "wip.txt"), []byte("wip"), 0644)
+
+	// Alias only: the directory stays.
+	if err := runRename(renameCmd, []string{"draft", "cart"}); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	s, _ := state.Load(dir)
+	if entry, ok := s.Get("cart"); !ok || entry.Path != oldPath || s.AliasExists("draft") {
+		t.Errorf("after alias rename: %+v, draft still there: %v", entry, s.AliasExists("draft"))
+	}
+
+	if err := runRename(renameCmd, []string{"cart", "other"}); !errors.Is(err, state.ErrAliasExists) {
+		t.Errorf("expected ErrAliasExists for a taken alias, got %v", err)
+	}
+
+	renameMove, renameBranch = true, "feature/checkout"
+	if err := runRename(renameCmd, []string{"cart", "checkout"}); err != nil {
+		t.Fatalf("rename --move --branch failed: %v", err)
+	}
+	s, _ = state.Load(dir)
+	entry, ok := s.Get("checkout")
+	if !ok || entry.Path != newPath || entry.Branch != "feature/checkout" {
+		t.Errorf("after full rename: %+v", entry)
+	}
+	if data, err := os.ReadFile(filepath.Join(newPath, "wip.txt")); err != nil || string(data) != "wip" {
+		t.Errorf("uncommitted file lost: %q, %v", data, err)
+	}
+	if branch, _ := git.BranchAt(newPath); branch != "feature/checkout" {
+		t.Errorf("branch at new path = %q", branch)
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/verbaux/grove/internal/state"
+)
+
+// renderVars are the values a "render" template can use: {{alias}},
+// {{branch}}, {{path}}, {{root}}, {{prefix}}, plus {{database}} and
+// {{namespace}} when those were set up — and the same values under the
+// names hooks see them by ({{GROVE_ALIAS}}, {{GROVE_DB_NAME}}, ...).
+func renderVars(root, prefix, alias, branch, path string, setup *state.Setup) map[string]string {
+	vars := map[string]string{
+		"alias":     alias,
+		"branch":    branch,
+		"path":      path,
+		"root":      root,
+		"prefix":    prefix,
+		"database":  setup.Database,
+		"namespace": setup.Namespace,
+	}
+	env := hookEnv(root, alias, branch, path)
+	if setup.Database != "" {
+		env = append(env, "GROVE_DB_NAME="+setup.Database)
+	}
+	if setup.Namespace != "" {
+		env = append(env, "GROVE_K8S_NAMESPACE="+setup.Namespace)
+	}
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		vars[k] = v
+	}
+	return vars
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	repairFromBackup bool
+	repairRebuild    bool
+)
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+	repairCmd.Flags().BoolVar(&repairFromBackup, "from-backup", false, "restore the newest readable backup from .grove/backups")
+	repairCmd.Flags().BoolVar(&repairRebuild, "rebuild", false, "rebuild the state from 'git worktree list'")
+	repairCmd.MarkFlagsMutuallyExclusive("from-backup", "rebuild")
+}
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Recover a damaged .grove/state.json",
+	Long: `Recover .grove/state.json when it can no longer be read — after a crash,
+a bad merge or a hand edit gone wrong.
+
+grove keeps the last 10 versions of the state in .grove/backups. By
+default repair restores the newest one that can be read, and rebuilds the
+state from 'git worktree list' when there is none. The damaged file is kept
+as .grove/state.json.corrupt.
+
+--from-backup restores a backup even when the state is fine, e.g. to undo
+the last change. --rebuild always rebuilds from git: aliases come from the
+worktree directory names, but setup details (symlinks, hooks, tags,
+templates) can't be recovered that way.`,
+	Args: cobra.NoArgs,
+	RunE: runRepair,
+}
+
+func runRepair(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	_, loadErr := state.Load(root)
+	if loadErr != nil && !errors.Is(loadErr, state.ErrCorrupt) {
+		return loadErr
+	}
+	if loadErr == nil && !repairFromBackup && !repairRebuild {
+		fmt.Println(".grove/state.json is fine — nothing to repair.")
+		return nil
+	}
+
+	var repaired state.State
+	var from string
+	if !repairRebuild {
+		repaired, from, err = newestBackup(root)
+		if err != nil {
+			return err
+		}
+		if from == "" && repairFromBackup {
+			return fmt.Errorf("no readable backup in .grove/backups — run 'grove repair --rebuild' to rebuild the state from git")
+		}
+	}
+	if from == "" {
+		if repaired, err = rebuildState(cfg); err != nil {
+			return err
+		}
+		from = "git worktree list"
+	}
+
+	// Not state.Change: the state it would load is what's being replaced.
+	unlock, err := state.Lock(root)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Keep the damaged file for a closer look, and out of the backups.
+	if loadErr != nil {
+		if err := os.Rename(state.Path(root), state.Path(root)+".corrupt"); err != nil {
+			return err
+		}
+		fmt.Printf("Moved the damaged state to %s\n", filepath.Join(".grove", "state.json.corrupt"))
+	}
+	if err := state.Save(root, repaired); err != nil {
+		return err
+	}
+
+	fmt.Printf(plain("  ✓ restored %d worktree(s) from %s\n"), len(repaired.Worktrees), from)
+	fmt.Println("Run 'grove list' to check them, and 'grove prune' to drop any whose directory is gone.")
+	return nil
+}
+
+// newestBackup returns the newest backup that can be read and its file
+// name, or an empty name when there is none.
+func newestBackup(root string) (state.State, string, error) {
+	paths, err := state.Backups(root)
+	if err != nil {
+		return state.State{}, "", err
+	}
+	for _, path := range paths {
+		s, err := state.LoadBackup(root, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: skipping backup: %v\n", err)
+			continue
+		}
+		return s, filepath.Base(path), nil
+	}
+	return state.State{}, "", nil
+}
+
+// rebuildState recreates the state from the worktrees git knows. Aliases
+// come from the directory names grove gives worktrees (<prefix>-<alias>),
+// falling back to the branch name.
+func rebuildState(cfg config.Config) (state.State, error) {
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return state.State{}, err
+	}
+	s := state.State{Worktrees: map[string]state.WorktreeEntry{}}
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.Prunable {
+			continue
+		}
+		alias := rebuiltAlias(cfg.Prefix, wt)
+		for n := 2; s.AliasExists(alias); n++ {
+			alias = fmt.Sprintf("%s-%d", rebuiltAlias(cfg.Prefix, wt), n)
+		}
+		if err := s.Add(alias, wt.Branch, wt.Path); err != nil {
+			return state.State{}, err
+		}
+	}
+	return s, nil
+}
+
+// rebuiltAlias guesses the alias a worktree was created with.
+func rebuiltAlias(prefix string, wt git.Worktree) string {
+	base := filepath.Base(wt.Path)
+	if prefix != "" {
+		if alias, ok := strings.CutPrefix(base, prefix+"-"); ok && validateAlias(alias) == nil {
+			return alias
+		}
+	}
+	if wt.Branch != "" && validateAlias(branchAlias(wt.Branch)) == nil {
+		return branchAlias(wt.Branch)
+	}
+	return base
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestRepair(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	t.Cleanup(func() {
+		for _, name := range []string{"auth", "billing"} {
+			rm := exec.Command("git", "worktree", "remove", "--force", filepath.Join(filepath.Dir(dir), "testproject-"+name))
+			rm.Dir = dir
+			rm.CombinedOutput()
+		}
+	})
+	createName, createFrom = "", ""
+	if _, err := createWorktree(createCmd, "feature/auth"); err != nil {
+		t.Fatal(err)
+	}
+	// Saved over the state with auth in it, so that's the newest backup.
+	createName = "billing"
+	if _, err := createWorktree(createCmd, "feature/payments"); err != nil {
+		t.Fatal(err)
+	}
+	createName = ""
+
+	corrupt := func() {
+		t.Helper()
+		if err := os.WriteFile(state.Path(dir), []byte("<<<<<<< HEAD\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repair := func(fromBackup, rebuild bool) state.State {
+		t.Helper()
+		repairFromBackup, repairRebuild = fromBackup, rebuild
+		defer func() { repairFromBackup, repairRebuild = false, false }()
+		captureStdout(t, func() {
+			if err := runRepair(repairCmd, nil); err != nil {
+				t.Fatalf("repair: %v", err)
+			}
+		})
+		s, err := state.Load(dir)
+		if err != nil {
+			t.Fatalf("state after repair: %v", err)
+		}
+		return s
+	}
+
+	corrupt()
+	s := repair(false, false)
+	if _, ok := s.Get("auth"); !ok || len(s.Worktrees) != 1 {
+		t.Errorf("restored from backup: %v", s.Worktrees)
+	}
+	if _, err := os.Stat(state.Path(dir) + ".corrupt"); err != nil {
+		t.Errorf("damaged state not kept: %v", err)
+	}
+
+	corrupt()
+	s = repair(false, true)
+	auth, ok := s.Get("auth")
+	if !ok || auth.Branch != "feature/auth" {
+		t.Errorf("rebuilt auth = %+v, %v", auth, ok)
+	}
+	if billing, ok := s.Get("billing"); !ok || billing.Branch != "feature/payments" {
+		t.Errorf("rebuilt billing = %+v, %v (alias should come from the directory)", billing, ok)
+	}
+}
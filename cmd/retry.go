@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/i18n"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(retryCmd)
+}
+
+var retryCmd = &cobra.Command{
+	Use:   "retry <name>",
+	Short: "Finish setting up a worktree whose setup failed",
+	Long: `Run the setup steps of a worktree that grove create kept after a hook
+failed ("onHookFailure": "keep", or --keep-on-failure): the step that failed,
+then the ones that hadn't run yet — installCommand, afterCreate, kube up,
+push, banner and read-only, whichever applied. Steps that had succeeded,
+like the checkout and the env files, aren't repeated.
+
+If a step fails again, the worktree stays as it is and 'grove retry' can be
+run once more.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runRetry,
+}
+
+// Setup steps grove create runs once the worktree is otherwise ready, in
+// order. When one fails and the worktree is kept, it and the ones after it
+// are recorded in Setup.Failed and Setup.Pending for grove retry.
+const (
+	stepInstall     = "installCommand"
+	stepAfterCreate = "afterCreate"
+	stepKubeUp      = "kubeUp"
+	stepPush        = "push"
+	stepBanner      = "banner"
+	stepReadOnly    = "readOnly"
+)
+
+// hookSteps are the steps whose failure onHookFailure "keep" survives; the
+// others fail for reasons a retry wouldn't fix.
+var hookSteps = map[string]bool{stepInstall: true, stepAfterCreate: true, stepKubeUp: true}
+
+// setupRun runs the late setup steps for one worktree.
+type setupRun struct {
+	root, alias, branch, path string
+	cfg                       config.Config
+	setup                     *state.Setup
+	entry                     state.WorktreeEntry // what the banner describes
+	banner                    string              // config.Banner* kind
+	out, hookOut              io.Writer
+	step                      func(name string, start time.Time) // timing, for create's report
+
+	upstream     string // set by a successful push
+	hookExitCode *int   // of the last afterCreate hook
+	kubeUp       bool   // kube up was attempted, so a rollback should run kube down
+}
+
+// run runs one step.
+func (r *setupRun) run(name string) error {
+	start := time.Now()
+	defer func() {
+		if r.step != nil {
+			r.step(stepReportName(name), start)
+		}
+	}()
+	env := hookEnv(r.root, r.alias, r.branch, r.path)
+
+	switch name {
+	case stepInstall:
+		env, done, err := r.withContext(env, name)
+		if err != nil {
+			return err
+		}
+		defer done()
+		err = runHook(r.cfg.Hooks, r.cfg.InstallCommand, r.path, env, r.hookOut)
+		r.setup.Hooks = append(r.setup.Hooks, state.HookRun{Name: "installCommand", Command: r.cfg.InstallCommand, ExitCode: exitCodeOf(err), Ran: start})
+		if err != nil {
+			return fmt.Errorf("installCommand failed: %w", err)
+		}
+		fmt.Fprint(r.out, plain(i18n.T("  ✓ installed\n")))
+
+	case stepAfterCreate:
+		hooks, err := hookCommands(r.root, r.cfg.AfterCreate, "after-create")
+		if err != nil {
+			return err
+		}
+		env, done, err := r.withContext(env, name)
+		if err != nil {
+			return err
+		}
+		defer done()
+		for _, hook := range hooks {
+			fmt.Fprintf(r.out, i18n.T("  running: %s\n"), hook.label)
+			start := time.Now()
+			err := runHook(r.cfg.Hooks, hook.command, r.path, append(env, kubeEnv(r.cfg.Kube, r.setup.Namespace)...), r.hookOut)
+			exitCode := exitCodeOf(err)
+			r.hookExitCode = &exitCode
+			r.setup.Hooks = append(r.setup.Hooks, state.HookRun{Name: "afterCreate", Command: hook.label, ExitCode: exitCode, Ran: start})
+			if err != nil {
+				return fmt.Errorf("afterCreate hook %s failed: %w", hook.label, err)
+			}
+		}
+		if len(hooks) > 0 {
+			fmt.Fprint(r.out, plain(i18n.T("  ✓ afterCreate done\n")))
+		}
+
+	case stepKubeUp:
+		r.kubeUp = true
+		if err := runKube(r.root, r.cfg, "up", r.cfg.Kube.Up, r.alias, r.branch, r.path, r.setup.Namespace, r.hookOut); err != nil {
+			return err
+		}
+		fmt.Fprint(r.out, plain(i18n.T("  ✓ kube up done\n")))
+
+	case stepPush:
+		// A failed push shouldn't throw away a fully set-up worktree — warn
+		// and let the user push by hand.
+		if err := git.PushUpstream(r.path, "origin", r.branch); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("  warning: push failed, run 'git push -u origin %s' later: %v\n"), r.branch, err)
+			return nil
+		}
+		r.upstream = "origin/" + r.branch
+		fmt.Fprintf(r.out, plain(i18n.T("  ✓ pushed and tracking %s\n")), r.upstream)
+
+	case stepBanner:
+		file, err := writeBanner(r.banner, r.root, r.alias, r.entry)
+		if err != nil {
+			return fmt.Errorf("writing the banner: %w", err)
+		}
+		fmt.Fprintf(r.out, plain(i18n.T("  ✓ wrote %s\n")), file)
+		r.setup.Banner = file
+
+	case stepReadOnly:
+		r.setup.ReadOnly = true
+		if err := files.SetReadOnly(r.path, true, r.setup.Hardlinks); err != nil {
+			return fmt.Errorf("making the worktree read-only: %w", err)
+		}
+		fmt.Fprint(r.out, plain(i18n.T("  ✓ files made read-only\n")))
+
+	default:
+		return fmt.Errorf("unknown setup step %q in state — run 'grove remove %s' and create the worktree again", name, r.alias)
+	}
+	return nil
+}
+
+// withContext adds GROVE_CONTEXT for the hooks of step to env.
+func (r *setupRun) withContext(env []string, step string) ([]string, func(), error) {
+	entry := r.entry
+	entry.Branch, entry.Path, entry.Setup = r.branch, r.path, r.setup
+	return withHookContext(env, hookContext{Hook: step, Root: r.root, Alias: r.alias, Worktree: entry, Config: r.cfg})
+}
+
+// stepReportName is the name a step has in grove create --json's timings.
+func stepReportName(name string) string {
+	switch name {
+	case stepInstall:
+		return "install"
+	case stepKubeUp:
+		return "kube"
+	}
+	return name
+}
+
+func exitCodeOf(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
+
+func runRetry(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	alias, err := resolveManaged(root, s, args[0])
+	if err != nil {
+		return err
+	}
+	entry, _ := s.Get(alias)
+	if entry.Setup == nil || entry.Setup.Failed == "" {
+		fmt.Printf("%s has no failed setup to retry.\n", alias)
+		return nil
+	}
+	if entry.Template != "" {
+		if cfg, _, err = cfg.WithTemplate(entry.Template); err != nil {
+			return err
+		}
+	}
+	hooks, err := createHooks(root, cfg)
+	if err != nil {
+		return err
+	}
+	if err := requireTrust(root, hooks); err != nil {
+		return err
+	}
+	banner, err := cfg.EffectiveBanner()
+	if err != nil {
+		return err
+	}
+
+	setup := *entry.Setup
+	r := &setupRun{
+		root: root, alias: alias, branch: entry.Branch, path: entry.Path,
+		cfg: cfg, setup: &setup, entry: entry, banner: banner,
+		out: os.Stdout, hookOut: os.Stdout,
+	}
+	steps := append([]string{setup.Failed}, setup.Pending...)
+	fmt.Printf("Retrying setup of %s from %s\n", alias, setup.Failed)
+	for i, name := range steps {
+		if err := r.run(name); err != nil {
+			setup.Failed, setup.Pending = name, steps[i+1:]
+			saveErr := state.Change(root, func(s *state.State) error {
+				return s.Update(alias, func(e *state.WorktreeEntry) { e.Setup = &setup })
+			})
+			if saveErr != nil {
+				return saveErr
+			}
+			return fmt.Errorf("%w — fix it and run 'grove retry %s' again", err, alias)
+		}
+	}
+
+	setup.Failed, setup.Pending = "", nil
+	setup.EnvHashes = envHashes(entry.Path, setup.EnvFiles)
+	err = state.Change(root, func(s *state.State) error {
+		return s.Update(alias, func(e *state.WorktreeEntry) {
+			e.Setup = &setup
+			if r.upstream != "" {
+				e.Upstream = r.upstream
+			}
+		})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Worktree %q ready.\n", alias)
+	return nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestRetryAfterKeptFailure(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir:   "../",
+		Prefix:        "testproject",
+		Symlink:       []string{},
+		AfterCreate:   "test -f ready.txt",
+		Banner:        config.BannerMOTD,
+		OnHookFailure: config.HookFailureKeep,
+	})
+
+	createName, createFrom = "", ""
+	err := runCreate(createCmd, []string{"feature/flaky"})
+	if err == nil || !strings.Contains(err.Error(), "grove retry flaky") {
+		t.Fatalf("expected a kept-worktree error, got %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-flaky")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	s, _ := state.Load(dir)
+	entry, ok := s.Get("flaky")
+	if !ok || entry.Setup == nil {
+		t.Fatal("the worktree should be kept in state")
+	}
+	if entry.Setup.Failed != stepAfterCreate || strings.Join(entry.Setup.Pending, ",") != stepBanner {
+		t.Errorf("failed = %q, pending = %v", entry.Setup.Failed, entry.Setup.Pending)
+	}
+	if _, err := os.Stat(filepath.Join(wtPath, ".grove-motd")); !os.IsNotExist(err) {
+		t.Error("the banner step shouldn't have run yet")
+	}
+
+	// Still failing: the worktree stays for another retry.
+	if err := runRetry(retryCmd, []string{"flaky"}); err == nil {
+		t.Fatal("expected retry to fail while the hook still fails")
+	}
+
+	os.WriteFile(filepath.Join(wtPath, "ready.txt"), nil, 0644)
+	if err := runRetry(retryCmd, []string{"flaky"}); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	s, _ = state.Load(dir)
+	entry, _ = s.Get("flaky")
+	if entry.Setup.Failed != "" || len(entry.Setup.Pending) != 0 {
+		t.Errorf("setup should be complete, got failed = %q, pending = %v", entry.Setup.Failed, entry.Setup.Pending)
+	}
+	if entry.Setup.Banner != ".grove-motd" {
+		t.Errorf("banner = %q, want the pending step to have run", entry.Setup.Banner)
+	}
+}
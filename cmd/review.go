@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	reviewRemote   string
+	reviewKeep     time.Duration
+	reviewNoOpen   bool
+	reviewReadOnly bool
+)
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+	reviewCmd.Flags().StringVar(&reviewRemote, "remote", "origin", "remote to fetch the branch or pull request from")
+	reviewCmd.Flags().DurationVar(&reviewKeep, "keep", 24*time.Hour, "how long before 'grove prune' may remove the review worktree")
+	reviewCmd.Flags().BoolVar(&reviewNoOpen, "no-open", false, "don't open the worktree in an editor")
+	reviewCmd.Flags().BoolVar(&reviewReadOnly, "read-only", false, "make the worktree's files read-only, so nothing is edited there by mistake")
+}
+
+var reviewCmd = &cobra.Command{
+	Use:   "review <remote-branch|pr>",
+	Short: "Check out a remote branch or pull request for review",
+	Long: `Fetch a remote branch or GitHub pull request and check it out in a new,
+detached worktree named review-<n>, then open it in your editor.
+
+A pull request can be given as a number (12 or #12) or a URL. Anything else
+is treated as a branch on --remote (origin by default).
+
+The worktree is detached — no local branch is created, so there's nothing
+to accidentally push. It is registered as ephemeral: once --keep (24h by
+default) has passed, 'grove prune' removes it if it has no local changes.
+
+With --read-only the checked-out files can't be edited; 'grove unlock-files'
+makes them writable again.
+
+The editor is picked the way 'grove open' picks it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReview,
+}
+
+// prURL matches pull request URLs like https://github.com/o/r/pull/12.
+var prURL = regexp.MustCompile(`/pull/(\d+)`)
+
+// parseReviewTarget splits the argument into the ref to fetch and a
+// description. pr is the pull request number, 0 for a branch.
+func parseReviewTarget(arg, remote string) (ref, desc string, pr int) {
+	num := strings.TrimPrefix(arg, "#")
+	if m := prURL.FindStringSubmatch(arg); m != nil {
+		num = m[1]
+	}
+	if n, err := strconv.Atoi(num); err == nil && n > 0 {
+		return fmt.Sprintf("pull/%d/head", n), fmt.Sprintf("PR #%d", n), n
+	}
+	branch := strings.TrimPrefix(arg, remote+"/")
+	return branch, remote + "/" + branch, 0
+}
+
+// reviewAlias picks review-<pr>, or the first free review-<n> for branches.
+func reviewAlias(s state.State, pr int) string {
+	if pr > 0 {
+		return fmt.Sprintf("review-%d", pr)
+	}
+	for n := 1; ; n++ {
+		alias := fmt.Sprintf("review-%d", n)
+		if !s.AliasExists(alias) {
+			return alias
+		}
+	}
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+
+	ref, desc, pr := parseReviewTarget(args[0], reviewRemote)
+	alias := reviewAlias(s, pr)
+	if entry, ok := s.Get(alias); ok {
+		return errorf(state.ErrAliasExists, "%s is already checked out for review at %s — remove it with 'grove remove %s' first", desc, entry.Path, alias)
+	}
+
+	worktreePath, err := worktreePathFor(root, cfg, alias)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Fetching %s...\n", desc)
+	commit, err := git.FetchRef(reviewRemote, ref)
+	if err != nil {
+		return err
+	}
+
+	if err := git.AddDetachedWorktree(worktreePath, commit); err != nil {
+		return err
+	}
+	fmt.Printf(plain("  ✓ checked out %s at %s (detached)\n"), desc, worktreePath)
+
+	if reviewReadOnly {
+		if err := files.SetReadOnly(worktreePath, true, nil); err != nil {
+			files.SetReadOnly(worktreePath, false, nil)
+			git.RemoveWorktree(worktreePath, true)
+			return fmt.Errorf("making the worktree read-only: %w", err)
+		}
+		fmt.Print(plain("  ✓ files made read-only\n"))
+	}
+
+	err = state.Change(root, func(s *state.State) error {
+		if err := s.Add(alias, "", worktreePath); err != nil {
+			return err
+		}
+		return s.Update(alias, func(e *state.WorktreeEntry) {
+			e.Review = desc
+			e.Tags = []string{"review"}
+			e.Expires = time.Now().Add(reviewKeep)
+			if reviewReadOnly {
+				e.Setup = &state.Setup{ReadOnly: true}
+			}
+		})
+	})
+	if err != nil {
+		files.SetReadOnly(worktreePath, false, nil)
+		git.RemoveWorktree(worktreePath, true)
+		return err
+	}
+
+	fmt.Printf("\nWorktree %q ready. 'grove prune' removes it after %s if it's unchanged.\n", alias, reviewKeep)
+	if reviewNoOpen {
+		fmt.Printf("  cd $(grove cd %s)\n", alias)
+		return nil
+	}
+	return openInEditor(editorCommand(cfg, ""), worktreePath)
+}
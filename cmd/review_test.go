@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestParseReviewTarget(t *testing.T) {
+	tests := []struct {
+		arg, ref, desc string
+		pr             int
+	}{
+		{"12", "pull/12/head", "PR #12", 12},
+		{"#7", "pull/7/head", "PR #7", 7},
+		{"https://github.com/o/r/pull/42/files", "pull/42/head", "PR #42", 42},
+		{"feature/auth", "feature/auth", "origin/feature/auth", 0},
+		{"origin/feature/auth", "feature/auth", "origin/feature/auth", 0},
+	}
+	for _, tt := range tests {
+		ref, desc, pr := parseReviewTarget(tt.arg, "origin")
+		if ref != tt.ref || desc != tt.desc || pr != tt.pr {
+			t.Errorf("parseReviewTarget(%q) = %q, %q, %d; want %q, %q, %d", tt.arg, ref, desc, pr, tt.ref, tt.desc, tt.pr)
+		}
+	}
+}
+
+func TestReviewAndExpire(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+
+	remote := t.TempDir()
+	gitCmd(dir, "init", "--bare", remote)
+	gitCmd(dir, "remote", "add", "origin", remote)
+	gitCmd(dir, "commit", "--allow-empty", "-m", "pr work")
+	gitCmd(dir, "push", "origin", "HEAD:refs/pull/12/head")
+	want, _ := gitCmd(dir, "rev-parse", "HEAD")
+	gitCmd(dir, "reset", "--hard", "HEAD~1")
+
+	reviewNoOpen = true
+	t.Cleanup(func() { reviewNoOpen = false })
+
+	if err := runReview(reviewCmd, []string{"#12"}); err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-review-12")
+	t.Cleanup(func() { gitCmd(dir, "worktree", "remove", "--force", wtPath) })
+
+	head, _ := gitCmd(wtPath, "rev-parse", "HEAD")
+	if strings.TrimSpace(string(head)) != strings.TrimSpace(string(want)) {
+		t.Errorf("review HEAD = %s, want %s", head, want)
+	}
+	if branch, _ := gitCmd(wtPath, "branch", "--show-current"); strings.TrimSpace(string(branch)) != "" {
+		t.Errorf("review worktree should be detached, on %q", branch)
+	}
+
+	s, _ := state.Load(dir)
+	entry, ok := s.Get("review-12")
+	if !ok || entry.Review != "PR #12" || entry.Expires.IsZero() {
+		t.Fatalf("review entry = %+v, %v", entry, ok)
+	}
+
+	// Not expired yet — prune leaves it alone.
+	if removed := removeExpired(dir, config.Config{}, &s, time.Now()); len(removed) != 0 {
+		t.Errorf("removed %v before expiry", removed)
+	}
+
+	// Expired but dirty — kept.
+	later := entry.Expires.Add(time.Minute)
+	os.WriteFile(filepath.Join(wtPath, "notes.txt"), []byte("x"), 0644)
+	if removed := removeExpired(dir, config.Config{}, &s, later); len(removed) != 0 {
+		t.Errorf("removed dirty worktree %v", removed)
+	}
+
+	os.Remove(filepath.Join(wtPath, "notes.txt"))
+	if removed := removeExpired(dir, config.Config{}, &s, later); len(removed) != 1 {
+		t.Fatalf("expected the expired review to be removed, got %v", removed)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("expired worktree still on disk")
+	}
+	if s.AliasExists("review-12") {
+		t.Error("expired alias still in state")
+	}
+}
@@ -1,14 +1,34 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/version"
 )
 
 var Version string
 
+// jsonOutput switches grove into machine-readable mode. For now this only
+// affects how errors are reported; see writeError.
+var jsonOutput bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "machine-readable output (errors are printed as JSON on stderr)")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "screen-reader friendly output: no color or symbols, labeled lines instead of tables")
+	rootCmd.PersistentFlags().StringVar(&traceOutput, "trace", "", "time each phase (config, git, files, hooks); prints a breakdown to stderr, or use --trace=FILE for a trace JSON")
+	rootCmd.PersistentFlags().Lookup("trace").NoOptDefVal = "-"
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", "send OpenTelemetry spans for git calls and setup phases to this OTLP/HTTP collector (default $OTEL_EXPORTER_OTLP_ENDPOINT)")
+	rootCmd.PersistentFlags().IntVarP(&jobsFlag, "jobs", "j", 0, "work on at most this many worktrees at once (default \"jobs\" in .groverc.json, else the number of CPUs up to 8)")
+	rootCmd.PersistentFlags().BoolVar(&trustHooks, "trust", false, "run hook commands from the project's config without asking for approval (see 'grove trust')")
+}
+
 var rootCmd = &cobra.Command{
 	Use:           "grove",
 	Short:         "Git worktree manager — work on multiple branches without the hassle",
@@ -19,12 +39,102 @@ var rootCmd = &cobra.Command{
   - Symlinks node_modules (no extra npm install)
 
 Get started with: grove init`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		startTrace(cmd)
+		root, cfg := startupConfig()
+		configureGit(root, cfg)
+		configurePlain()
+		configurePathStyle(cfg)
+		configureJobs(cfg)
+	},
+}
+
+// startupConfig finds and loads the project's .groverc.json once, for the
+// settings applied before every command. root is "" and cfg empty when
+// there is no config or it can't be read — commands like init run before
+// there is one, and the command itself reports a broken one.
+func startupConfig() (string, config.Config) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", config.Config{}
+	}
+	root, skipped, err := config.LocateRoot(cwd)
+	if err != nil {
+		return "", config.Config{}
+	}
+	if skipped != "" {
+		fmt.Fprintf(os.Stderr, "warning: %s in %s belongs to another project — using this repository's config in %s\n", config.FileName, skipped, root)
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return "", config.Config{}
+	}
+	if version.Newer(cfg.GroveVersion, version.Current) {
+		fmt.Fprintf(os.Stderr, "warning: %s was written by grove %s, newer than this one (%s) — settings it added are ignored; upgrade grove\n", config.FileName, cfg.GroveVersion, version.Current)
+	}
+	return root, cfg
+}
+
+// configureGit applies the git settings from cfg, the config at root, once
+// approved with grove trust, and the environment. GROVE_GIT and
+// GROVE_GIT_ARGS win over the config file so a one-off override doesn't
+// require editing it.
+func configureGit(root string, cfg config.Config) {
+	var gc config.GitConfig
+	git.FastStatus = false
+	if root != "" {
+		// .groverc.json is committed, so its git settings only
+		// apply once approved, like hook commands.
+		if trustedAll(root, gitHooks(cfg)) {
+			gc = cfg.Git
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: ignoring the \"git\" settings in %s, which you haven't approved — review them with 'grove trust'\n", config.FileName)
+		}
+		mode, err := cfg.EffectiveStatusMode()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		git.FastStatus = mode == config.StatusFast
+	}
+
+	bin := gc.Binary
+	if v := os.Getenv("GROVE_GIT"); v != "" {
+		bin = v
+	}
+
+	args := gc.Args
+	if v := os.Getenv("GROVE_GIT_ARGS"); v != "" {
+		args = append(append([]string{}, args...), strings.Fields(v)...)
+	}
+
+	keys := make([]string, 0, len(gc.Env))
+	for k := range gc.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+gc.Env[k])
+	}
+
+	git.Configure(bin, args, env)
 }
 
 func Execute() {
 	rootCmd.Version = Version
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	if Version != "unknown" {
+		version.Current = Version
+	}
+	err := rootCmd.Execute()
+	if errors.Is(err, config.ErrNoConfig) && offerInit() {
+		err = rootCmd.Execute()
+	}
+	finishTrace(os.Stderr, err)
+	if err != nil {
+		var ce commandExit
+		if !errors.Is(err, errNothingToDo) && !errors.As(err, &ce) {
+			writeError(os.Stderr, err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
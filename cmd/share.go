@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/manifest"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var shareRemove bool
+
+func init() {
+	rootCmd.AddCommand(shareCmd)
+	shareCmd.Flags().BoolVar(&shareRemove, "remove", false, "take the named worktrees off the shared list")
+}
+
+var shareCmd = &cobra.Command{
+	Use:   "share [name...]",
+	Short: "Recommend worktrees to the team in .grove/worktrees.yaml",
+	Long: `Add worktrees to .grove/worktrees.yaml, the list of worktrees the team
+keeps around — long-lived ones like a staging or release checkout. Each
+entry records the alias, branch, template and tags. Commit the file;
+teammates run 'grove sync --shared' to create the ones they're missing.
+
+Accepts aliases, branches or paths, and updates entries already listed.
+Without a name, every worktree with a branch is shared, except the
+short-lived ones from 'grove review', 'grove sync --prs' or with an
+expiry. --remove takes the named aliases off the list again.
+
+.grove/ is usually ignored by git. To commit the list, ignore .grove/*
+rather than .grove/ in .gitignore, then un-ignore the file with
+!.grove/worktrees.yaml — git can't re-include a file inside an ignored
+directory.`,
+	ValidArgsFunction: completeAliases,
+	RunE:              runShare,
+}
+
+func runShare(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	shared, err := manifest.Load(root)
+	if err != nil {
+		return err
+	}
+
+	if shareRemove {
+		if len(args) == 0 {
+			return fmt.Errorf("--remove needs the aliases to take off the list — run 'grove share --remove <alias>'")
+		}
+		for _, alias := range args {
+			i := manifestIndex(shared, alias)
+			if i < 0 {
+				return errorf(state.ErrNotFound, "%q isn't in %s — check the aliases listed there", alias, manifest.RelPath)
+			}
+			shared = append(shared[:i], shared[i+1:]...)
+			fmt.Printf(plain("  ✓ unshared %s\n"), alias)
+		}
+		return manifest.Save(root, shared)
+	}
+
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	aliases, err := shareAliases(root, s, args)
+	if err != nil {
+		return err
+	}
+	if len(aliases) == 0 {
+		return fmt.Errorf("no long-lived worktrees to share — name one with 'grove share <name>'")
+	}
+
+	for _, alias := range aliases {
+		entry := s.Worktrees[alias]
+		wt := manifest.Worktree{Alias: alias, Branch: entry.Branch, Template: entry.Template}
+		for _, tag := range entry.Tags {
+			// Tags grove sets itself for short-lived worktrees.
+			if tag != "pr" && tag != "review" {
+				wt.Tags = append(wt.Tags, tag)
+			}
+		}
+		if i := manifestIndex(shared, alias); i >= 0 {
+			shared[i] = wt
+		} else {
+			shared = append(shared, wt)
+		}
+		fmt.Printf(plain("  ✓ shared %s (%s)\n"), alias, entry.Branch)
+	}
+	if err := manifest.Save(root, shared); err != nil {
+		return err
+	}
+	fmt.Printf("\nCommit %s so teammates can run 'grove sync --shared'.\n", manifest.RelPath)
+	return nil
+}
+
+// shareAliases resolves the worktrees named in args, or picks every
+// long-lived one when there are none.
+func shareAliases(root string, s state.State, args []string) ([]string, error) {
+	var aliases []string
+	if len(args) == 0 {
+		for alias, entry := range s.Worktrees {
+			if entry.Branch != "" && entryPR(entry) == 0 && entry.Review == "" && entry.Expires.IsZero() {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Strings(aliases)
+		return aliases, nil
+	}
+
+	for _, arg := range args {
+		query, err := expandRowRef(root, arg)
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := resolveWorktree(query, s)
+		if err != nil {
+			return nil, err
+		}
+		if resolved == nil || !resolved.InState {
+			return nil, errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", arg)
+		}
+		if resolved.Branch == "" {
+			return nil, fmt.Errorf("%s has no branch checked out, so teammates couldn't recreate it — share a worktree on a branch", resolved.Alias)
+		}
+		aliases = append(aliases, resolved.Alias)
+	}
+	return aliases, nil
+}
+
+// manifestIndex returns the position of alias in worktrees, or -1.
+func manifestIndex(worktrees []manifest.Worktree, alias string) int {
+	for i, wt := range worktrees {
+		if wt.Alias == alias {
+			return i
+		}
+	}
+	return -1
+}
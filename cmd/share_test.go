@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/manifest"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestShareAndSyncShared(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	for _, args := range [][]string{
+		{"branch", "release/staging"},
+		{"branch", "feature/temp"},
+		{"branch", "docs/site"},
+	} {
+		if out, err := gitCmd(dir, args...); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	t.Cleanup(func() {
+		for _, name := range []string{"staging", "temp", "docs"} {
+			rm := exec.Command("git", "worktree", "remove", "--force", filepath.Join(filepath.Dir(dir), "testproject-"+name))
+			rm.Dir = dir
+			rm.CombinedOutput()
+		}
+	})
+
+	for _, branch := range []string{"release/staging", "feature/temp"} {
+		createName, createFrom = "", ""
+		if _, err := createWorktree(createCmd, branch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, _ := state.Load(dir)
+	s.Update("staging", func(e *state.WorktreeEntry) { e.Tags = []string{"long-lived"} })
+	s.Update("temp", func(e *state.WorktreeEntry) { e.Expires = time.Now().Add(time.Hour) })
+	if err := state.Save(dir, s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without names, only long-lived worktrees are shared.
+	captureStdout(t, func() {
+		if err := runShare(shareCmd, nil); err != nil {
+			t.Errorf("share: %v", err)
+		}
+	})
+	shared, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shared) != 1 || shared[0].Alias != "staging" || shared[0].Branch != "release/staging" || !slices.Contains(shared[0].Tags, "long-lived") {
+		t.Fatalf("shared = %+v", shared)
+	}
+
+	// A teammate shared one this clone doesn't have yet.
+	shared = append(shared, manifest.Worktree{Alias: "docs", Branch: "docs/site", Tags: []string{"docs"}})
+	if err := manifest.Save(dir, shared); err != nil {
+		t.Fatal(err)
+	}
+	syncShared = true
+	t.Cleanup(func() { syncShared = false })
+	out := captureStdout(t, func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Errorf("sync --shared: %v", err)
+		}
+	})
+	if !strings.Contains(string(out), "Created 1, removed 0") {
+		t.Errorf("output:\n%s", out)
+	}
+	s, _ = state.Load(dir)
+	if docs, ok := s.Get("docs"); !ok || docs.Branch != "docs/site" || !slices.Contains(docs.Tags, "docs") {
+		t.Errorf("docs = %+v, %v", docs, ok)
+	}
+
+	shareRemove = true
+	t.Cleanup(func() { shareRemove = false })
+	captureStdout(t, func() {
+		if err := runShare(shareCmd, []string{"docs"}); err != nil {
+			t.Errorf("share --remove: %v", err)
+		}
+	})
+	if shared, _ := manifest.Load(dir); len(shared) != 1 {
+		t.Errorf("after --remove, shared = %+v", shared)
+	}
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/statuscache"
+)
+
+var (
+	statusStdinBatch bool
+	statusAll        bool
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusStdinBatch, "stdin-batch", false, "read newline-separated paths from stdin and answer each on its own line")
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "answer for every worktree of the current project")
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status [path...]",
+	Short: "Print worktree status as JSON lines, for editors and scripts",
+	Long: `Print the status of the worktrees containing the given paths (default: the
+current directory), one JSON object per line:
+
+  {"path":"...","worktree":"...","alias":"auth","branch":"feature/auth","status":"clean",
+   "upstream":{"ref":"origin/feature/auth","ahead":2,"behind":0},
+   "base":{"ref":"main","ahead":5,"behind":1}}
+
+"upstream" compares the branch with the one it tracks, and is left out when
+it tracks none; "base" compares it with the branch a managed worktree was
+created from. --all answers for the main worktree and every worktree of the
+current project.
+
+Paths may be anywhere inside a worktree, and worktrees from different
+projects can be mixed. A path that can't be answered gets an "error" field
+instead of failing the whole batch.
+
+With --stdin-batch, paths are read from stdin, one per line, and each answer
+is written as soon as it is known — so an editor plugin can query many
+worktrees with a single process instead of one per worktree.`,
+	RunE: runStatus,
+}
+
+// worktreeStatus is one line of `grove status` output.
+type worktreeStatus struct {
+	Path     string      `json:"path"`               // as given
+	Worktree string      `json:"worktree,omitempty"` // root of the worktree containing path
+	Alias    string      `json:"alias,omitempty"`    // empty for worktrees grove doesn't manage
+	Branch   string      `json:"branch,omitempty"`
+	Status   string      `json:"status,omitempty"`
+	Upstream *divergence `json:"upstream,omitempty"`
+	Base     *divergence `json:"base,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// divergence is how far a worktree's HEAD and ref have moved apart.
+type divergence struct {
+	Ref    string `json:"ref"`
+	Ahead  int    `json:"ahead"`  // commits HEAD has that ref doesn't
+	Behind int    `json:"behind"` // commits ref has that HEAD doesn't
+}
+
+// divergenceFrom compares the worktree at path with ref, or returns nil if
+// there's no ref or it can't be compared, e.g. a base branch since deleted.
+func divergenceFrom(path, ref string) *divergence {
+	if ref == "" {
+		return nil
+	}
+	ahead, behind, err := git.AheadBehind(path, ref)
+	if err != nil {
+		return nil
+	}
+	return &divergence{Ref: ref, Ahead: ahead, Behind: behind}
+}
+
+// statusProject is the state and status cache of one project, loaded once
+// per batch however many of its worktrees are asked about.
+type statusProject struct {
+	state state.State
+	cache *statuscache.Cache
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	var paths []string
+	var in io.Reader
+	switch {
+	case statusAll:
+		if _, _, err := loadRootConfig(); err != nil {
+			return err
+		}
+		worktrees, err := git.ListWorktrees()
+		if err != nil {
+			return err
+		}
+		for _, wt := range worktrees {
+			paths = append(paths, wt.Path)
+		}
+	case statusStdinBatch:
+		in = cmd.InOrStdin()
+	default:
+		if paths = args; len(paths) == 0 {
+			paths = []string{"."}
+		}
+	}
+	return writeStatuses(os.Stdout, paths, in)
+}
+
+// writeStatuses answers each of paths, then each line of in (if not nil),
+// writing one JSON object per line to w. Status caches are saved at the end.
+func writeStatuses(w io.Writer, paths []string, in io.Reader) error {
+	projects := map[string]*statusProject{}
+	defer func() {
+		for _, p := range projects {
+			p.cache.Save()
+		}
+	}()
+
+	enc := json.NewEncoder(w)
+	for _, p := range paths {
+		if err := enc.Encode(lookupStatus(p, projects)); err != nil {
+			return err
+		}
+	}
+	if in == nil {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		p := strings.TrimSpace(scanner.Text())
+		if p == "" {
+			continue
+		}
+		if err := enc.Encode(lookupStatus(p, projects)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// lookupStatus builds the status line for the worktree containing path.
+func lookupStatus(path string, projects map[string]*statusProject) worktreeStatus {
+	result := worktreeStatus{Path: path}
+	fail := func(err error) worktreeStatus {
+		result.Error = err.Error()
+		return result
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fail(err)
+	}
+	dir := abs
+	if info, err := os.Stat(abs); err != nil {
+		return fail(err)
+	} else if !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+
+	top, err := git.Toplevel(dir)
+	if err != nil {
+		return fail(err)
+	}
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	result.Worktree = top
+
+	if result.Branch, err = git.BranchAt(top); err != nil {
+		return fail(err)
+	}
+
+	// Worktrees outside a grove project still get a status, just no alias
+	// and no caching.
+	root, err := config.FindRoot(top)
+	if err != nil {
+		st, err := git.QuickStatus(top)
+		if err != nil {
+			return fail(err)
+		}
+		result.Status = st.String()
+		result.Upstream = upstreamDivergence(top)
+		return result
+	}
+
+	project, ok := projects[root]
+	if !ok {
+		s, err := state.Load(root)
+		if err != nil {
+			return fail(err)
+		}
+		project = &statusProject{state: s, cache: statuscache.Open(root)}
+		projects[root] = project
+	}
+	base := ""
+	for alias, e := range project.state.Worktrees {
+		if samePath(e.Path, top) {
+			result.Alias, base = alias, e.Base
+			break
+		}
+	}
+
+	if result.Status, err = cachedStatus(project.cache, top); err != nil {
+		return fail(err)
+	}
+	result.Upstream = upstreamDivergence(top)
+	result.Base = divergenceFrom(top, base)
+	return result
+}
+
+// upstreamDivergence compares the worktree at path with the branch it
+// tracks, if any.
+func upstreamDivergence(path string) *divergence {
+	upstream, err := git.Upstream(path)
+	if err != nil {
+		return nil
+	}
+	return divergenceFrom(path, upstream)
+}
+
+// samePath reports whether a and b name the same directory, resolving
+// symlinks like /tmp → /private/tmp on macOS.
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+)
+
+func TestWriteStatusesBatch(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject"})
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/batch"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-batch")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+	os.MkdirAll(filepath.Join(wtPath, "src"), 0755)
+	os.WriteFile(filepath.Join(wtPath, "src", "new.go"), []byte("package src\n"), 0644)
+
+	missing := filepath.Join(dir, "does-not-exist")
+	in := strings.NewReader(filepath.Join(wtPath, "src", "new.go") + "\n\n" + missing + "\n")
+
+	var out bytes.Buffer
+	if err := writeStatuses(&out, []string{dir}, in); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), out.String())
+	}
+	var got []worktreeStatus
+	for _, line := range lines {
+		var st worktreeStatus
+		if err := json.Unmarshal([]byte(line), &st); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		got = append(got, st)
+	}
+
+	if got[0].Worktree != dir || got[0].Branch != "main" || got[0].Alias != "" || got[0].Error != "" {
+		t.Errorf("main worktree = %+v", got[0])
+	}
+	if got[1].Worktree != wtPath || got[1].Alias != "batch" || got[1].Branch != "feature/batch" || got[1].Status != "1 untracked" {
+		t.Errorf("managed worktree = %+v", got[1])
+	}
+	if got[2].Path != missing || got[2].Error == "" || got[2].Status != "" {
+		t.Errorf("missing path = %+v, want an error", got[2])
+	}
+}
+
+func TestStatusDivergence(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject"})
+
+	createName = ""
+	createFrom = "main"
+	t.Cleanup(func() { createFrom = "" })
+	if err := runCreate(createCmd, []string{"feature/diverge"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-diverge")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	// Two commits on the branch, one on main; the branch tracks main.
+	for _, name := range []string{"a.txt", "b.txt"} {
+		os.WriteFile(filepath.Join(wtPath, name), []byte(name), 0644)
+		gitCmd(wtPath, "add", name)
+		gitCmd(wtPath, "commit", "-m", name)
+	}
+	os.WriteFile(filepath.Join(dir, "main.txt"), []byte("main"), 0644)
+	gitCmd(dir, "add", "main.txt")
+	gitCmd(dir, "commit", "-m", "main")
+	gitCmd(wtPath, "branch", "--set-upstream-to=main")
+
+	var out bytes.Buffer
+	if err := writeStatuses(&out, []string{wtPath, dir}, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), out.String())
+	}
+	var managed, main worktreeStatus
+	json.Unmarshal([]byte(lines[0]), &managed)
+	json.Unmarshal([]byte(lines[1]), &main)
+
+	want := divergence{Ref: "main", Ahead: 2, Behind: 1}
+	if managed.Base == nil || *managed.Base != want {
+		t.Errorf("base = %+v, want %+v", managed.Base, want)
+	}
+	if managed.Upstream == nil || *managed.Upstream != want {
+		t.Errorf("upstream = %+v, want %+v", managed.Upstream, want)
+	}
+	if main.Upstream != nil || main.Base != nil {
+		t.Errorf("main worktree has no upstream or base, got %+v", main)
+	}
+}
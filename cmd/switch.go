@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/picker"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
+
+var switchCmd = &cobra.Command{
+	Use:   "switch [query]",
+	Short: "Pick a worktree interactively and print its path",
+	Long: `Show the main worktree and every managed worktree, by alias and branch,
+in a searchable list, and print the path of the one you pick for the shell
+to cd into:
+
+  gsw() { cd "$(grove switch "$@")"; }
+
+Type to narrow the list (letters match in order, so "fa" finds
+feature/auth), move with the arrow keys or Ctrl-P/Ctrl-N, and press Enter.
+Esc or Ctrl-C cancels. A query on the command line is typed in for you; if
+it matches exactly one worktree, its path is printed without asking. The
+list is drawn on stderr, so it works inside $(...).`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runSwitch,
+}
+
+func runSwitch(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	rows, err := switchRows(root)
+	if err != nil {
+		return err
+	}
+	items := switchItems(rows)
+
+	if query != "" {
+		if matches := picker.Match(query, items); len(matches) == 1 {
+			fmt.Println(displayPath(rows[matches[0]].Path))
+			return nil
+		} else if len(matches) == 0 {
+			return errorf(state.ErrNotFound, "no worktree matches %q — run 'grove list' to see available worktrees", query)
+		}
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return fmt.Errorf("grove switch needs a terminal to show the list — use 'grove cd <name>' in scripts")
+	}
+
+	picked, err := picker.Run(os.Stdin, os.Stderr, items, query)
+	if errors.Is(err, picker.ErrCancelled) {
+		return errors.New("no worktree picked")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(displayPath(rows[picked].Path))
+	return nil
+}
+
+// switchRows returns the rows grove switch offers: the main worktree and
+// the managed ones, in grove list order. Status is skipped; the picker has
+// to come up at once.
+func switchRows(root string) ([]worktreeRow, error) {
+	all, err := buildRows(root, func(string) string { return "" })
+	if err != nil {
+		return nil, err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	managed := make(map[string]bool, len(s.Worktrees))
+	for _, entry := range s.Worktrees {
+		managed[entry.Path] = true
+	}
+	var rows []worktreeRow
+	for _, row := range all {
+		if row.IsMain || managed[row.Path] {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// switchItems formats rows as aligned "alias  branch" lines, which is also
+// what the picker's query matches against. Paths are left out: every one
+// starts the same, so nearly any query would match them.
+func switchItems(rows []worktreeRow) []string {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r.Name))
+	}
+	items := make([]string, len(rows))
+	for i, r := range rows {
+		items[i] = strings.TrimRight(fmt.Sprintf("%-*s  %s", width, r.Name, r.Branch), " ")
+	}
+	return items
+}
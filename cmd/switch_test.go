@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestSwitch(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "shop"})
+	createName, createFrom, createTemplate = "", "", ""
+	auth, err := createWorktree(createCmd, "feature/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createWorktree(createCmd, "fix/login"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := switchRows(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := switchItems(rows)
+	if len(items) != 3 || items[0] != "main   main" || items[1] != "auth   feature/auth" {
+		t.Errorf("items = %q", items)
+	}
+
+	// A query with one match prints its path without asking.
+	out := captureStdout(t, func() {
+		if err := runSwitch(switchCmd, []string{"fa"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if strings.TrimSpace(string(out)) != auth.Path {
+		t.Errorf("grove switch fa = %q, want %s", out, auth.Path)
+	}
+
+	if err := runSwitch(switchCmd, []string{"zzz"}); !errors.Is(err, state.ErrNotFound) {
+		t.Errorf("no match = %v", err)
+	}
+	// Several matches need the picker, and tests have no terminal.
+	if err := runSwitch(switchCmd, nil); err == nil || !strings.Contains(err.Error(), "needs a terminal") {
+		t.Errorf("without a terminal = %v", err)
+	}
+}
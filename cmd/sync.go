@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/manifest"
+	"github.com/verbaux/grove/internal/state"
+)
+
+var (
+	syncPRs    bool
+	syncShared bool
+	syncRemote string
+	syncEnvs   bool
+	syncForce  bool
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().BoolVar(&syncPRs, "prs", false, "create worktrees for your open pull requests and remove those whose pull request merged")
+	syncCmd.Flags().BoolVar(&syncShared, "shared", false, "create the worktrees listed in "+manifest.RelPath+" that are missing here")
+	syncCmd.Flags().BoolVar(&syncEnvs, "env", false, "copy the main worktree's .env* files again into the named worktrees, or all of them; implied by naming worktrees")
+	syncCmd.Flags().BoolVar(&syncForce, "force", false, "with --env, overwrite env files that were changed in the worktree")
+	syncCmd.Flags().StringVar(&syncRemote, "remote", "origin", "remote the pull request branches are fetched from")
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [--prs|--shared|--env] [name...]",
+	Short: "Create the worktrees your pull requests or your team call for, or refresh env files",
+	Long: `Bring the project's worktrees in line with GitHub or with the team's
+shared list.
+
+With --shared, every worktree listed in .grove/worktrees.yaml (see 'grove
+share') that doesn't exist here is created, with its template and tags.
+Branches only on --remote are fetched and tracked.
+
+With --prs, using the GitHub CLI (gh):
+  - every open pull request you authored that has no worktree gets one,
+    set up like 'grove create <branch>' would, with the branch tracking
+    --remote
+  - worktrees created this way, and 'grove review' worktrees of pull
+    requests, are removed once their pull request has merged — unless they
+    have local changes
+
+Pull requests from forks are skipped; check them out with 'grove review'.
+
+With --env, the main worktree's .env* files are copied again into the named
+worktrees (all managed ones if none are named), since they're otherwise
+only copied on create. Each updated file is listed with the keys that were
+added (+), removed (-) or changed (~); values aren't shown. A file changed
+in the worktree since grove wrote it is skipped unless --force is given.
+Worktrees created with --env <profile> only get that profile's files, and
+database and kube values are set again after the copy. Naming worktrees
+implies --env: 'grove sync auth' is 'grove sync --env auth', and
+'grove sync --prs auth' refreshes auth's env files after the pull requests.
+
+The flags can be combined.`,
+	ValidArgsFunction: completeAliases,
+	RunE:              runSync,
+}
+
+// githubPR is what grove reads about a pull request from gh.
+type githubPR struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title,omitempty"`
+	HeadRefName string `json:"headRefName"`
+	IsCrossRepo bool   `json:"isCrossRepository"`
+	State       string `json:"state"` // OPEN, CLOSED or MERGED
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// ghOutput runs gh and returns its stdout. Tests replace it.
+var ghOutput = func(args ...string) ([]byte, error) {
+	if !hasGH() {
+		return nil, errors.New("pull requests are looked up with the GitHub CLI — install gh and run 'gh auth login'")
+	}
+	return exec.Command("gh", args...).Output()
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	// Only env files are synced per worktree, so names mean --env.
+	envs := syncEnvs || len(args) > 0
+	if !syncPRs && !syncShared && !envs {
+		return fmt.Errorf("nothing to sync — pass --prs to mirror your open pull requests, --shared for the team's worktrees, or --env for env files")
+	}
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	var created, removed, failed int
+	if syncPRs {
+		prs, err := myOpenPRs()
+		if err != nil {
+			return err
+		}
+
+		// Removing first frees the aliases of merged pull requests for new ones.
+		s, err := state.Load(root)
+		if err != nil {
+			return err
+		}
+		merged, err := removeMergedPRs(root, cfg, &s)
+		if err != nil {
+			return err
+		}
+		if removed = len(merged); removed > 0 {
+			if err := dropAliases(root, merged); err != nil {
+				return err
+			}
+			if err := git.PruneWorktrees(); err != nil {
+				fmt.Fprintf(os.Stderr, "  warning: git worktree prune failed: %v\n", err)
+			}
+		}
+
+		if created, failed, err = createOpenPRs(root, s, prs); err != nil {
+			return err
+		}
+	}
+	if syncShared {
+		n, f, err := createShared(root)
+		created, failed = created+n, failed+f
+		if err != nil {
+			return err
+		}
+	}
+
+	if envs {
+		updated, skipped, err := syncEnv(root, cfg, args, syncForce)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Updated %d env file(s), skipped %d.\n", updated, skipped)
+		if !syncPRs && !syncShared {
+			return nil
+		}
+	}
+
+	fmt.Printf("\nCreated %d, removed %d worktree(s).\n", created, removed)
+	if failed > 0 {
+		return fmt.Errorf("%d worktree(s) could not be created — see the errors above", failed)
+	}
+	return nil
+}
+
+// myOpenPRs lists the open pull requests the gh user authored.
+func myOpenPRs() ([]githubPR, error) {
+	return openPRs("@me", 100)
+}
+
+// openPRs lists up to limit open pull requests, by author if not empty,
+// lowest number first.
+func openPRs(author string, limit int) ([]githubPR, error) {
+	args := []string{"pr", "list", "--state", "open", "--limit", strconv.Itoa(limit),
+		"--json", "number,title,headRefName,isCrossRepository,state,author"}
+	if author != "" {
+		args = append(args, "--author", author)
+	}
+	out, err := ghOutput(args...)
+	if err != nil {
+		return nil, ghError(err)
+	}
+	var prs []githubPR
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return nil, fmt.Errorf("unexpected output from gh pr list: %w", err)
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Number < prs[j].Number })
+	return prs, nil
+}
+
+// prState returns the state of pull request n: OPEN, CLOSED or MERGED.
+func prState(n int) (string, error) {
+	out, err := ghOutput("pr", "view", strconv.Itoa(n), "--json", "state")
+	if err != nil {
+		return "", ghError(err)
+	}
+	var pr githubPR
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return "", fmt.Errorf("unexpected output from gh pr view: %w", err)
+	}
+	return pr.State, nil
+}
+
+// ghError adds what gh printed on stderr, which says why it failed.
+func ghError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	msg, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+	if msg == "" {
+		msg = err.Error()
+	}
+	return fmt.Errorf("gh failed: %s — check 'gh auth status'", msg)
+}
+
+// entryPR returns the pull request a worktree mirrors: the one grove sync
+// created it for, or the one grove review checked out. 0 for neither.
+func entryPR(entry state.WorktreeEntry) int {
+	if entry.PR > 0 {
+		return entry.PR
+	}
+	var n int
+	if _, err := fmt.Sscanf(entry.Review, "PR #%d", &n); err == nil {
+		return n
+	}
+	return 0
+}
+
+// createOpenPRs creates a worktree for each of prs that has none yet. A pull
+// request that can't be checked out is reported and skipped.
+func createOpenPRs(root string, s state.State, prs []githubPR) (created, failed int, err error) {
+	have := map[string]bool{}
+	havePR := map[int]bool{}
+	for _, entry := range s.Worktrees {
+		have[entry.Branch] = true
+		havePR[entryPR(entry)] = true
+	}
+
+	for _, pr := range prs {
+		if have[pr.HeadRefName] || havePR[pr.Number] {
+			continue
+		}
+		if pr.IsCrossRepo {
+			fmt.Printf("  skipped PR #%d (%s): from a fork — run 'grove review %d'\n", pr.Number, pr.HeadRefName, pr.Number)
+			continue
+		}
+
+		fmt.Printf("PR #%d: %s\n", pr.Number, pr.HeadRefName)
+		alias := branchAlias(pr.HeadRefName)
+		if s.AliasExists(alias) {
+			alias = fmt.Sprintf("pr-%d", pr.Number)
+		}
+		report, err := syncCreate(syncRemote, alias, pr.HeadRefName, "")
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			failed++
+			if errors.Is(err, errNotTrusted) {
+				return created, failed, err
+			}
+			continue
+		}
+
+		latest, err := recordPR(root, report.Alias, pr.Number)
+		if err != nil {
+			return created, failed, err
+		}
+		s = latest
+		created++
+		fmt.Printf(plain("  ✓ %s ready\n"), report.Alias)
+	}
+	return created, failed, nil
+}
+
+// recordPR marks the worktree alias as checked out for pull request n, on
+// top of the state createWorktree saved. Returns the updated state.
+func recordPR(root, alias string, n int) (state.State, error) {
+	var latest state.State
+	err := state.Change(root, func(s *state.State) error {
+		err := s.Update(alias, func(e *state.WorktreeEntry) {
+			e.PR = n
+			e.Tags = append(e.Tags, "pr")
+		})
+		latest = *s
+		return err
+	})
+	return latest, err
+}
+
+// removeMergedPRs removes the worktrees of merged pull requests from disk
+// and from s with removeUnattended, keeping any that are locked or have
+// local changes. Returns the removed aliases.
+func removeMergedPRs(root string, cfg config.Config, s *state.State) ([]string, error) {
+	aliases := make([]string, 0, len(s.Worktrees))
+	for alias, entry := range s.Worktrees {
+		if entryPR(entry) > 0 {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	guard, err := newMainGuard()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, alias := range aliases {
+		entry := s.Worktrees[alias]
+		pr := entryPR(entry)
+		st, err := prState(pr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: could not check PR #%d for %s: %v\n", pr, alias, err)
+			continue
+		}
+		if st != "MERGED" {
+			continue
+		}
+		kept, err := removeUnattended(root, cfg, guard, alias, entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: could not remove %s: %v\n", alias, err)
+			continue
+		}
+		if kept != "" {
+			fmt.Printf("  kept %s (PR #%d merged, but %s)\n", alias, pr, kept)
+			continue
+		}
+		s.Remove(alias)
+		removed = append(removed, alias)
+		fmt.Printf(plain("  ✓ removed %s (PR #%d merged)\n"), alias, pr)
+	}
+	return removed, nil
+}
+
+// syncCreate creates the worktree alias for branch like grove create would,
+// from template if not empty. A branch only on remote is fetched and
+// created from it, tracking it.
+func syncCreate(remote, alias, branch, template string) (createReport, error) {
+	resetCreateFlags()
+	defer resetCreateFlags()
+	createName, createTemplate = alias, template
+	// branch comes from the manifest or a pull request, and reaches git
+	// before any trust check.
+	if err := git.CheckBranchName(branch); err != nil {
+		return createReport{}, err
+	}
+	if !git.BranchExists(branch) {
+		if _, err := git.FetchRef(remote, branch); err != nil {
+			return createReport{}, err
+		}
+		createFrom = remote + "/" + branch
+	}
+	return createWorktree(createCmd, branch)
+}
+
+// createShared creates the worktrees in the project's manifest that don't
+// exist here yet, matched by alias or branch. One that can't be created is
+// reported and skipped.
+func createShared(root string) (created, failed int, err error) {
+	worktrees, err := manifest.Load(root)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(worktrees) == 0 {
+		fmt.Printf("No worktrees in %s — add some with 'grove share <name>'.\n", manifest.RelPath)
+		return 0, 0, nil
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return 0, 0, err
+	}
+	have := map[string]bool{}
+	for _, entry := range s.Worktrees {
+		have[entry.Branch] = true
+	}
+
+	for _, wt := range worktrees {
+		if s.AliasExists(wt.Alias) || have[wt.Branch] {
+			continue
+		}
+		fmt.Printf("%s: %s\n", wt.Alias, wt.Branch)
+		report, err := syncCreate(syncRemote, wt.Alias, wt.Branch, wt.Template)
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			failed++
+			if errors.Is(err, errNotTrusted) {
+				return created, failed, err
+			}
+			continue
+		}
+		if len(wt.Tags) > 0 {
+			err := state.Change(root, func(s *state.State) error {
+				return s.Update(report.Alias, func(e *state.WorktreeEntry) {
+					for _, tag := range wt.Tags {
+						if !slices.Contains(e.Tags, tag) {
+							e.Tags = append(e.Tags, tag)
+						}
+					}
+				})
+			})
+			if err != nil {
+				return created, failed, err
+			}
+		}
+		created++
+		fmt.Printf(plain("  ✓ %s ready\n"), report.Alias)
+	}
+	return created, failed, nil
+}
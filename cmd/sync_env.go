@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// envSyncResult is what grove sync --env did to one worktree.
+type envSyncResult struct {
+	Updated map[string][]string // env file → its DiffEnv against the old copy
+	Skipped []string            // env files changed in the worktree, left alone
+}
+
+// syncEnv brings the env files of the named worktrees (every managed one
+// when names is empty) up to date with the main worktree's. Returns how many
+// files were updated and skipped.
+func syncEnv(root string, cfg config.Config, names []string, force bool) (updated, skipped int, err error) {
+	var aliases []string
+	var results map[string]envSyncResult
+	err = state.Change(root, func(s *state.State) error {
+		if len(names) == 0 {
+			for alias := range s.Worktrees {
+				aliases = append(aliases, alias)
+			}
+			sort.Strings(aliases)
+		}
+		for _, name := range names {
+			alias, err := resolveManaged(root, *s, name)
+			if err != nil {
+				return err
+			}
+			aliases = append(aliases, alias)
+		}
+
+		results = map[string]envSyncResult{}
+		for _, alias := range aliases {
+			entry, _ := s.Get(alias)
+			result, setup, err := syncWorktreeEnv(root, cfg, alias, entry, force)
+			if err != nil {
+				return fmt.Errorf("%s: %w", alias, err)
+			}
+			results[alias] = result
+			s.Update(alias, func(e *state.WorktreeEntry) { e.Setup = setup })
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, alias := range aliases {
+		result := results[alias]
+		if len(result.Updated) == 0 && len(result.Skipped) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", alias)
+		var rels []string
+		for rel := range result.Updated {
+			rels = append(rels, rel)
+		}
+		sort.Strings(rels)
+		for _, rel := range rels {
+			diff := result.Updated[rel]
+			if len(diff) == 0 {
+				diff = []string{"formatting only"}
+			}
+			fmt.Printf("  ✓ %s: %s\n", rel, strings.Join(diff, " "))
+		}
+		for _, rel := range result.Skipped {
+			fmt.Printf("  skipped %s: changed in the worktree — pass --force to overwrite it\n", rel)
+		}
+		updated += len(result.Updated)
+		skipped += len(result.Skipped)
+	}
+	return updated, skipped, nil
+}
+
+// syncWorktreeEnv copies the main worktree's env files over those in entry's
+// worktree that grove wrote and nobody changed since, then sets the database
+// and kube values again. Returns the updated setup record.
+func syncWorktreeEnv(root string, cfg config.Config, alias string, entry state.WorktreeEntry, force bool) (envSyncResult, *state.Setup, error) {
+	result := envSyncResult{Updated: map[string][]string{}}
+	setup := &state.Setup{}
+	if entry.Setup != nil {
+		copied := *entry.Setup
+		setup = &copied
+	}
+
+	var patterns []string
+	if setup.EnvProfile != "" {
+		patterns, _ = cfg.EnvProfile(setup.EnvProfile)
+	}
+	found, err := files.FindEnvFiles(root)
+	if err != nil {
+		return result, setup, err
+	}
+
+	// What each file held before, to diff against once the values grove
+	// injects are back in.
+	before := map[string][]byte{}
+	var written []string
+	for _, rel := range found {
+		if len(patterns) > 0 && !files.MatchesAny(filepath.Base(rel), patterns) {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(root, rel))
+		if err != nil {
+			return result, setup, err
+		}
+		dst := filepath.Join(entry.Path, rel)
+		old, err := os.ReadFile(dst)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return result, setup, err
+		case bytes.Equal(old, src):
+			continue
+		case !force && locallyModified(old, setup.EnvHashes[rel]):
+			result.Skipped = append(result.Skipped, rel)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return result, setup, err
+		}
+		if err := os.WriteFile(dst, src, 0644); err != nil {
+			return result, setup, err
+		}
+		before[rel] = old
+		written = append(written, rel)
+		if !slices.Contains(setup.EnvFiles, rel) {
+			setup.EnvFiles = append(setup.EnvFiles, rel)
+		}
+	}
+	if len(written) == 0 {
+		return result, setup, nil
+	}
+
+	if setup.Database != "" {
+		vars := map[string]string{"name": setup.Database, "alias": alias, "branch": entry.Branch, "prefix": cfg.Prefix}
+		if err := injectEnv(entry.Path, written, cfg.Database.Env, vars); err != nil {
+			return result, setup, err
+		}
+	}
+	if setup.Namespace != "" {
+		vars := kubeVars(cfg, alias, entry.Branch, setup.Namespace)
+		if err := injectEnv(entry.Path, written, cfg.Kube.Env, vars); err != nil {
+			return result, setup, err
+		}
+	}
+
+	for _, rel := range written {
+		now, err := os.ReadFile(filepath.Join(entry.Path, rel))
+		if err != nil {
+			return result, setup, err
+		}
+		// Back where it was once the injected values are in: nothing to report.
+		if !bytes.Equal(now, before[rel]) {
+			result.Updated[rel] = files.DiffEnv(before[rel], now)
+		}
+	}
+	// Only the files written here are grove's again; a skipped one keeps
+	// its old hash, so it still counts as changed next time.
+	hashes := make(map[string]string, len(setup.EnvHashes)+len(written))
+	for rel, hash := range setup.EnvHashes {
+		hashes[rel] = hash
+	}
+	for rel, hash := range envHashes(entry.Path, written) {
+		hashes[rel] = hash
+	}
+	setup.EnvHashes = hashes
+	return result, setup, nil
+}
+
+// locallyModified reports whether an env file's contents differ from how
+// grove last wrote them. Without a record (worktrees from an older grove)
+// there's no telling, so it counts as modified.
+func locallyModified(data []byte, hash string) bool {
+	if hash == "" {
+		return true
+	}
+	return files.HashBytes(data) != hash
+}
+
+// envHashes hashes the env files in the worktree at path, for Setup.EnvHashes.
+func envHashes(path string, envFiles []string) map[string]string {
+	if len(envFiles) == 0 {
+		return nil
+	}
+	hashes := make(map[string]string, len(envFiles))
+	for _, rel := range envFiles {
+		if hash, err := files.HashFile(filepath.Join(path, rel)); err == nil {
+			hashes[rel] = hash
+		}
+	}
+	return hashes
+}
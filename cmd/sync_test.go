@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trash"
+)
+
+func TestEntryPR(t *testing.T) {
+	tests := []struct {
+		entry state.WorktreeEntry
+		want  int
+	}{
+		{state.WorktreeEntry{PR: 7}, 7},
+		{state.WorktreeEntry{Review: "PR #12"}, 12},
+		{state.WorktreeEntry{Review: "origin/feature/x"}, 0},
+		{state.WorktreeEntry{Branch: "feature/x"}, 0},
+	}
+	for _, tt := range tests {
+		if got := entryPR(tt.entry); got != tt.want {
+			t.Errorf("entryPR(%+v) = %d, want %d", tt.entry, got, tt.want)
+		}
+	}
+}
+
+func TestSyncPRs(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}})
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	for _, args := range [][]string{
+		{"init", "--bare", origin},
+		{"remote", "add", "origin", origin},
+		{"push", "origin", "main"},
+		// One pull request branch exists only on the remote, one locally.
+		{"push", "origin", "main:refs/heads/feature/remote"},
+		{"branch", "feature/local"},
+		{"branch", "feature/merged"},
+	} {
+		if out, err := gitCmd(dir, args...); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	t.Cleanup(func() {
+		for _, name := range []string{"remote", "local", "merged"} {
+			rm := exec.Command("git", "worktree", "remove", "--force", filepath.Join(filepath.Dir(dir), "testproject-"+name))
+			rm.Dir = dir
+			rm.CombinedOutput()
+		}
+	})
+
+	// A worktree from an earlier sync whose pull request has since merged.
+	createName, createFrom = "", ""
+	if _, err := createWorktree(createCmd, "feature/merged"); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := state.Load(dir)
+	s.Update("merged", func(e *state.WorktreeEntry) { e.PR = 3 })
+	if err := state.Save(dir, s); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := ghOutput
+	t.Cleanup(func() { ghOutput = orig })
+	ghOutput = func(args ...string) ([]byte, error) {
+		switch strings.Join(args[:2], " ") {
+		case "pr list":
+			return []byte(`[
+				{"number": 4, "headRefName": "feature/remote", "isCrossRepository": false, "state": "OPEN"},
+				{"number": 5, "headRefName": "feature/local", "isCrossRepository": false, "state": "OPEN"},
+				{"number": 6, "headRefName": "someone/fork", "isCrossRepository": true, "state": "OPEN"}
+			]`), nil
+		case "pr view":
+			if args[2] == "3" {
+				return []byte(`{"state": "MERGED"}`), nil
+			}
+			return []byte(`{"state": "OPEN"}`), nil
+		}
+		t.Fatalf("unexpected gh %v", args)
+		return nil, nil
+	}
+
+	syncPRs, syncRemote = true, "origin"
+	t.Cleanup(func() { syncPRs = false })
+	out := captureStdout(t, func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Errorf("sync: %v", err)
+		}
+	})
+	if !strings.Contains(string(out), "from a fork") || !strings.Contains(string(out), "Created 2, removed 1") {
+		t.Errorf("output:\n%s", out)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("merged"); ok {
+		t.Error("worktree of the merged pull request was kept")
+	}
+	remote, ok := s.Get("remote")
+	if !ok || remote.PR != 4 || !slices.Contains(remote.Tags, "pr") {
+		t.Errorf("remote = %+v, %v", remote, ok)
+	}
+	if upstream, _ := gitCmd(remote.Path, "rev-parse", "--abbrev-ref", "@{upstream}"); strings.TrimSpace(string(upstream)) != "origin/feature/remote" {
+		t.Errorf("upstream = %q, want origin/feature/remote", upstream)
+	}
+	if local, ok := s.Get("local"); !ok || local.PR != 5 {
+		t.Errorf("local = %+v, %v", local, ok)
+	}
+
+	// Nothing left to do the second time.
+	out = captureStdout(t, func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Errorf("second sync: %v", err)
+		}
+	})
+	if !strings.Contains(string(out), "Created 0, removed 0") {
+		t.Errorf("second sync output:\n%s", out)
+	}
+}
+
+func TestSyncEnv(t *testing.T) {
+	cfg := config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}}
+	dir := setupIntegrationRepo(t, cfg)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("A=1\nB=2\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.local"), []byte("X=1\n"), 0644)
+
+	createName = ""
+	createFrom = ""
+	if err := runCreate(createCmd, []string{"feature/drift"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-drift")
+	t.Cleanup(func() {
+		rm := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rm.Dir = dir
+		rm.CombinedOutput()
+	})
+
+	// Both change in main; .env.local was also edited in the worktree.
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("A=1\nB=3\nC=4\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.local"), []byte("X=2\n"), 0644)
+	os.WriteFile(filepath.Join(wtPath, ".env.local"), []byte("X=mine\n"), 0644)
+
+	out := captureStdout(t, func() {
+		updated, skipped, err := syncEnv(dir, cfg, nil, false)
+		if err != nil || updated != 1 || skipped != 1 {
+			t.Errorf("syncEnv = %d updated, %d skipped, %v; want 1, 1", updated, skipped, err)
+		}
+	})
+	if !strings.Contains(string(out), ".env: ~B +C") || !strings.Contains(string(out), "skipped .env.local") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wtPath, ".env")); string(data) != "A=1\nB=3\nC=4\n" {
+		t.Errorf(".env = %q", data)
+	}
+
+	// Still the worktree's own edit on a second run; --force overwrites it.
+	if _, skipped, _ := syncEnv(dir, cfg, []string{"drift"}, false); skipped != 1 {
+		t.Errorf("second sync skipped %d, want 1", skipped)
+	}
+	if updated, _, err := syncEnv(dir, cfg, []string{"drift"}, true); err != nil || updated != 1 {
+		t.Errorf("forced sync updated %d, %v; want 1", updated, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wtPath, ".env.local")); string(data) != "X=2\n" {
+		t.Errorf(".env.local = %q after --force", data)
+	}
+	// A name without --env still means the env files.
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("A=1\nB=3\nC=5\n"), 0644)
+	out = captureStdout(t, func() {
+		if err := runSync(syncCmd, []string{"drift"}); err != nil {
+			t.Errorf("grove sync drift: %v", err)
+		}
+	})
+	if !strings.Contains(string(out), ".env: ~C") {
+		t.Errorf("grove sync drift output:\n%s", out)
+	}
+}
+
+func TestRemoveMergedPRsChecksLockFirst(t *testing.T) {
+	cfg := config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}, BeforeRemove: "touch ../torn-down", TrashDays: 7}
+	dir := setupIntegrationRepo(t, cfg)
+	trustHooks = true
+	t.Cleanup(func() { trustHooks = false })
+	createName, createFrom = "", ""
+	report, err := createWorktree(createCmd, "feature/merged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := state.Load(dir)
+	s.Update("merged", func(e *state.WorktreeEntry) { e.PR = 3 })
+	if err := git.LockWorktree(report.Path, ""); err != nil {
+		t.Fatal(err)
+	}
+	orig := ghOutput
+	t.Cleanup(func() { ghOutput = orig })
+	ghOutput = func(args ...string) ([]byte, error) { return []byte(`{"state": "MERGED"}`), nil }
+	torn := filepath.Join(filepath.Dir(dir), "torn-down")
+
+	// Locked: kept, and nothing torn down.
+	if removed, err := removeMergedPRs(dir, cfg, &s); err != nil || len(removed) != 0 {
+		t.Fatalf("removeMergedPRs of a locked worktree = %v, %v", removed, err)
+	}
+	if _, err := os.Stat(torn); err == nil {
+		t.Fatal("beforeRemove ran for a worktree that was kept")
+	}
+
+	if err := git.UnlockWorktree(report.Path); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := removeMergedPRs(dir, cfg, &s); err != nil || len(removed) != 1 {
+		t.Fatalf("removeMergedPRs = %v, %v", removed, err)
+	}
+	if _, err := os.Stat(torn); err != nil {
+		t.Error("beforeRemove didn't run")
+	}
+	if items, _ := trash.List(dir); len(items) != 1 {
+		t.Errorf("trash = %d items, want 1", len(items))
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+// teardownConfig returns cfg with the template of entry applied, once the
+// hooks its removal runs are approved. It undoes nothing, so callers use it
+// to stop a removal before anything irreversible has happened.
+func teardownConfig(root string, cfg config.Config, entry state.WorktreeEntry) (config.Config, error) {
+	if entry.Template != "" {
+		// A template removed from the config since shouldn't block removal.
+		if tcfg, _, err := cfg.WithTemplate(entry.Template); err == nil {
+			cfg = tcfg
+		}
+	}
+
+	hooks, err := removeHooks(root, cfg)
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, requireTrust(root, hooks)
+}
+
+// teardownWorktree undoes what grove create set up, right before a managed
+// worktree is deleted: it runs beforeRemove (the template's, if the worktree
+// was created from one) and the .grove/hooks/pre-remove scripts, brings
+// down its Kubernetes namespace, drops its database, and removes the
+// symlinks recorded in its setup. A read-only worktree is made writable
+// first, or none of it could be deleted. Dropped databases and namespaces
+// don't come back, so it runs last, once everything that can still stop
+// the removal — teardownConfig, moveToTrash — has gone through.
+func teardownWorktree(root string, cfg config.Config, alias string, entry state.WorktreeEntry) error {
+	cfg, err := teardownConfig(root, cfg, entry)
+	if err != nil {
+		return err
+	}
+	if err := unlockFiles(entry); err != nil {
+		return err
+	}
+
+	beforeRemove, err := hookCommands(root, cfg.BeforeRemove, "pre-remove")
+	if err != nil {
+		return err
+	}
+	if len(beforeRemove) > 0 {
+		env := hookEnv(root, alias, entry.Branch, entry.Path)
+		if entry.Setup != nil {
+			env = append(env, kubeEnv(cfg.Kube, entry.Setup.Namespace)...)
+		}
+		env, done, err := withHookContext(env, hookContext{Hook: "beforeRemove", Root: root, Alias: alias, Worktree: entry, Config: cfg})
+		if err != nil {
+			return err
+		}
+		defer done()
+		for _, hook := range beforeRemove {
+			fmt.Printf("  running: %s\n", hook.label)
+			if err := runHook(cfg.Hooks, hook.command, entry.Path, env, os.Stdout); err != nil {
+				return fmt.Errorf("beforeRemove hook %s failed: %w", hook.label, err)
+			}
+		}
+	}
+
+	if entry.Setup != nil && entry.Setup.Namespace != "" {
+		if err := kubeDown(root, cfg, alias, entry.Branch, entry.Path, entry.Setup.Namespace, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	if entry.Setup != nil && entry.Setup.Database != "" {
+		if err := dropDatabase(root, cfg, alias, entry.Branch, entry.Path, entry.Setup.Database, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	if entry.Setup != nil {
+		for _, name := range entry.Setup.Symlinks {
+			// Only remove what is still grove's symlink — never a real
+			// directory someone put there since.
+			link := filepath.Join(entry.Path, name)
+			if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				if err := os.Remove(link); err != nil {
+					return fmt.Errorf("removing symlink %s: %w", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// removeUnattended removes the managed worktree alias the way grove remove
+// and grove clean do — trash, teardown, then git — for the commands that
+// run with nobody to ask, like grove prune from cron. A worktree that's
+// locked, claimed by someone else or has local changes is kept instead, and
+// kept says why.
+func removeUnattended(root string, cfg config.Config, guard mainGuard, alias string, entry state.WorktreeEntry) (kept string, err error) {
+	if _, locked := worktreeLock(entry.Path); locked {
+		return "locked", nil
+	}
+	if c := claimedByOther(entry); c != nil {
+		return "claimed by " + c.Owner, nil
+	}
+	if err := guard.check(entry.Path, ""); err != nil {
+		return "", err
+	}
+	status, err := git.Status(entry.Path)
+	if err != nil {
+		return "", err
+	}
+	if !status.Clean() {
+		return status.String(), nil
+	}
+	if _, err := teardownConfig(root, cfg, entry); err != nil {
+		return "", err
+	}
+	var trashed string
+	if cfg.TrashDays > 0 {
+		if trashed, err = moveToTrash(root, cfg, alias, entry, ""); err != nil {
+			return "", fmt.Errorf("could not move it to trash: %w", err)
+		}
+	}
+	if err := teardownWorktree(root, cfg, alias, entry); err != nil {
+		if trashed != "" {
+			discardTrash(root, trashed)
+		}
+		return "", err
+	}
+	return "", git.RemoveWorktree(entry.Path, false)
+}
+
+// unlockFiles undoes --read-only on a worktree. Worktrees that aren't
+// read-only are left as they are.
+func unlockFiles(entry state.WorktreeEntry) error {
+	if entry.Setup == nil || !entry.Setup.ReadOnly {
+		return nil
+	}
+	if err := files.SetReadOnly(entry.Path, false, entry.Setup.Hardlinks); err != nil {
+		return fmt.Errorf("making %s writable again: %w", entry.Path, err)
+	}
+	return nil
+}
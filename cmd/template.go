@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+)
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template [name]",
+	Short: "List worktree templates or show one",
+	Long: `List the templates defined in .groverc.json, or show the details of one.
+
+Templates are named setups for different kinds of work in the same repo:
+
+  "templates": {
+    "backend": {
+      "from": "develop",
+      "symlink": ["vendor"],
+      "copy": ["config/local.yml"],
+      "sparse": ["services/api", "libs"],
+      "afterCreate": "make deps",
+      "tags": ["backend"]
+    }
+  }
+
+Use one with: grove create feature/x --template backend`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE:              runTemplate,
+}
+
+func runTemplate(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	root, err := config.FindRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(root)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		_, tpl, err := cfg.WithTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		printTemplate(args[0], tpl)
+		return nil
+	}
+
+	if len(cfg.Templates) == 0 {
+		fmt.Printf("No templates defined. Add a \"templates\" section to %s.\n", config.FileName)
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Templates))
+	for name := range cfg.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func printTemplate(name string, tpl config.Template) {
+	fmt.Printf("Template %q\n", name)
+	if tpl.From != "" {
+		fmt.Printf("  From:         %s\n", tpl.From)
+	}
+	if tpl.Symlink != nil {
+		fmt.Printf("  Symlink:      %s\n", strings.Join(tpl.Symlink, ", "))
+	}
+	if len(tpl.Copy) > 0 {
+		fmt.Printf("  Copy:         %s\n", strings.Join(tpl.Copy, ", "))
+	}
+	if len(tpl.Sparse) > 0 {
+		fmt.Printf("  Sparse:       %s\n", strings.Join(tpl.Sparse, ", "))
+	}
+	if tpl.AfterCreate != "" {
+		fmt.Printf("  After create: %s\n", tpl.AfterCreate)
+	}
+	if len(tpl.Tags) > 0 {
+		fmt.Printf("  Tags:         %s\n", strings.Join(tpl.Tags, ", "))
+	}
+}
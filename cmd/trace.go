@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/trace"
+)
+
+// traceOutput is --trace: "-" prints a timing breakdown to stderr when the
+// command finishes, anything else is a file to write a trace JSON to.
+var traceOutput string
+
+// otelEndpoint is --otel-endpoint: an OTLP/HTTP collector, such as
+// http://localhost:4318, to send the command's spans to.
+var otelEndpoint string
+
+// tracedCommand and traceStart describe the command being traced.
+var (
+	tracedCommand string
+	traceStart    time.Time
+)
+
+// startTrace begins recording if --trace was given or spans are to be
+// exported. Called first thing in PersistentPreRun, so config loading is
+// part of the trace.
+func startTrace(cmd *cobra.Command) {
+	if traceOutput == "" && otelTracesURL() == "" {
+		return
+	}
+	tracedCommand = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	traceStart = time.Now()
+	trace.Enable()
+}
+
+// finishTrace reports what startTrace recorded, whether or not the command
+// succeeded — a slow failure is worth a trace too. cmdErr is the command's
+// error, if any.
+func finishTrace(stderr io.Writer, cmdErr error) {
+	if traceStart.IsZero() {
+		return
+	}
+	total := time.Since(traceStart)
+	spans := trace.Spans()
+	trace.Disable()
+
+	if endpoint := otelTracesURL(); endpoint != "" {
+		run := trace.Run{Command: tracedCommand, Version: Version, Start: traceStart, Total: total, Err: cmdErr, Parent: os.Getenv("TRACEPARENT")}
+		body, err := trace.OTLP(run, spans)
+		if err == nil {
+			err = trace.Export(endpoint, otelHeaders(), body)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: could not export spans: %v\n", err)
+		}
+	}
+
+	switch traceOutput {
+	case "":
+	case "-":
+		trace.WriteSummary(stderr, tracedCommand, total, spans)
+	default:
+		if err := trace.WriteJSON(traceOutput, tracedCommand, total, spans); err != nil {
+			fmt.Fprintf(stderr, "warning: could not write trace: %v\n", err)
+			return
+		}
+		fmt.Fprintf(stderr, "trace written to %s (open it in chrome://tracing or ui.perfetto.dev)\n", traceOutput)
+	}
+}
+
+// otelTracesURL returns where to send spans, or "" when export is off. The
+// flag wins over the standard OpenTelemetry variables: a full URL in
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, or a base URL in
+// OTEL_EXPORTER_OTLP_ENDPOINT. OTEL_SDK_DISABLED=true turns export off.
+func otelTracesURL() string {
+	base := otelEndpoint
+	if base == "" {
+		if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+			return ""
+		}
+		if full := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); full != "" {
+			return full
+		}
+		base = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if base == "" || strings.HasSuffix(base, "/v1/traces") {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/v1/traces"
+}
+
+// otelHeaders parses OTEL_EXPORTER_OTLP_HEADERS, "key=value" pairs separated
+// by commas with URL-encoded values, e.g. "authorization=Bearer%20abc".
+func otelHeaders() map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		if v, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = v
+		}
+		headers[strings.TrimSpace(key)] = value
+	}
+	return headers
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import "testing"
+
+func TestOtelTracesURL(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_SDK_DISABLED", "")
+	if got := otelTracesURL(); got != "" {
+		t.Errorf("export should be off by default, got %q", got)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	if got := otelTracesURL(); got != "http://collector:4318/v1/traces" {
+		t.Errorf("base endpoint: got %q", got)
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/custom")
+	if got := otelTracesURL(); got != "http://collector:4318/custom" {
+		t.Errorf("traces endpoint: got %q", got)
+	}
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	if got := otelTracesURL(); got != "" {
+		t.Errorf("OTEL_SDK_DISABLED: got %q", got)
+	}
+
+	otelEndpoint = "http://localhost:4318/v1/traces"
+	t.Cleanup(func() { otelEndpoint = "" })
+	if got := otelTracesURL(); got != otelEndpoint {
+		t.Errorf("the flag should win: got %q", got)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=Bearer%20abc, x-team = infra,broken")
+	headers := otelHeaders()
+	if headers["authorization"] != "Bearer abc" || headers["x-team"] != "infra" || len(headers) != 2 {
+		t.Errorf("headers = %v", headers)
+	}
+}
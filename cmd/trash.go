@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trash"
+)
+
+var trashEmptyExpired bool
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd, trashRestoreCmd, trashEmptyCmd)
+	trashEmptyCmd.Flags().BoolVar(&trashEmptyExpired, "expired", false, "only purge items older than trashDays")
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Inspect and restore removed worktrees",
+	Long: `With "trashDays" set in .groverc.json, remove and clean keep a copy of each
+worktree in .grove/trash/ instead of deleting it outright: the worktree's
+state entry, a bundle of its branch, and a patch of any uncommitted changes.
+
+Items older than trashDays are purged automatically the next time something
+is trashed, and by 'grove prune'.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List removed worktrees in the trash",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:               "restore <alias>",
+	Short:             "Bring a removed worktree back",
+	Long:              "Recreate the most recently trashed worktree with this alias at its original path, restoring the branch and any uncommitted changes.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTrash,
+	RunE:              runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete trashed worktrees",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashEmpty,
+}
+
+// moveToTrash records a managed worktree in .grove/trash before it is
+// deleted: its state entry, a bundle of its branch, and — if snapshot is
+// set — a patch of the uncommitted changes captured in that commit.
+// Returns the trash item's ID, for discardTrash should the removal not go
+// ahead after all.
+func moveToTrash(root string, cfg config.Config, alias string, entry state.WorktreeEntry, snapshot string) (string, error) {
+	item, err := trash.Create(root, trash.Item{
+		Alias:    alias,
+		Branch:   entry.Branch,
+		Path:     entry.Path,
+		Base:     entry.Base,
+		Upstream: entry.Upstream,
+		Template: entry.Template,
+		Tags:     entry.Tags,
+		Created:  entry.Created,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Leave out what the base branch already has, so the bundle only holds
+	// the branch's own commits. With nothing beyond base there's no bundle
+	// (git can't write an empty one), and restore recreates the branch from
+	// base. A base that's gone means bundling the whole branch.
+	exclude := entry.Base
+	if exclude == "" {
+		exclude, _ = git.DefaultBranch()
+	}
+	if entry.Branch != "" && exclude != entry.Branch {
+		own, err := git.CountCommits(exclude, "refs/heads/"+entry.Branch)
+		if exclude == "" || err != nil {
+			exclude, own = "", 1
+		}
+		if own > 0 {
+			if err := git.CreateBundle(trash.BundlePath(root, item.ID), entry.Branch, exclude); err != nil {
+				trash.Delete(root, item.ID)
+				return "", fmt.Errorf("saving the commits on %s: %w", entry.Branch, err)
+			}
+		}
+	}
+
+	if snapshot != "" {
+		patch, err := git.Diff(entry.Path, "HEAD", snapshot)
+		if err != nil {
+			trash.Delete(root, item.ID)
+			return "", err
+		}
+		if err := os.WriteFile(trash.PatchPath(root, item.ID), patch, 0644); err != nil {
+			trash.Delete(root, item.ID)
+			return "", err
+		}
+	}
+
+	fmt.Printf(plain("  ✓ moved %s to trash (restore with: grove trash restore %s)\n"), alias, alias)
+
+	if purged, err := trash.Purge(root, trashRetention(cfg)); err == nil && purged > 0 {
+		fmt.Printf(plain("  ✓ purged %d expired trash item(s)\n"), purged)
+	}
+	return item.ID, nil
+}
+
+// discardTrash drops the trash item moveToTrash made for a worktree that
+// ended up not being removed.
+func discardTrash(root, id string) {
+	if err := trash.Delete(root, id); err != nil {
+		fmt.Fprintf(os.Stderr, "  warning: could not drop trash item %s: %v\n", id, err)
+	}
+}
+
+func trashRetention(cfg config.Config) time.Duration {
+	return time.Duration(cfg.TrashDays) * 24 * time.Hour
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	items, err := trash.List(root)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	for _, item := range items {
+		line := fmt.Sprintf(plain("  %s → %s  removed %s"), item.Alias, item.Branch, item.Removed.Format("2006-01-02 15:04"))
+		if cfg.TrashDays > 0 {
+			left := time.Until(item.Removed.Add(trashRetention(cfg)))
+			if left > 0 {
+				line += fmt.Sprintf(", expires in %dd", int(left.Hours()/24)+1)
+			} else {
+				line += ", expired"
+			}
+		}
+		if _, err := os.Stat(trash.PatchPath(root, item.ID)); err == nil {
+			line += "  [uncommitted changes]"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	alias := args[0]
+
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	item, ok, err := trash.Find(root, alias)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errorf(state.ErrNotFound, "no trashed worktree with alias %q — run 'grove trash list' to see what can be restored", alias)
+	}
+
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	if s.AliasExists(alias) {
+		return errorf(state.ErrAliasExists, "alias %q already exists — remove or rename it before restoring", alias)
+	}
+	if _, err := os.Stat(item.Path); err == nil {
+		return fmt.Errorf("%s already exists — move it away before restoring", item.Path)
+	}
+
+	if !git.BranchExists(item.Branch) {
+		if _, err := os.Stat(trash.BundlePath(root, item.ID)); err == nil {
+			if err := git.FetchBundle(trash.BundlePath(root, item.ID), item.Branch); err != nil {
+				return err
+			}
+			fmt.Printf(plain("  ✓ restored branch %s from bundle\n"), item.Branch)
+		}
+	}
+
+	if err := git.AddWorktree(item.Path, item.Branch, item.Base); err != nil {
+		return err
+	}
+	fmt.Printf(plain("  ✓ recreated worktree at %s\n"), item.Path)
+
+	if _, err := os.Stat(trash.PatchPath(root, item.ID)); err == nil {
+		if err := git.ApplyPatch(item.Path, trash.PatchPath(root, item.ID)); err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: could not re-apply uncommitted changes (patch kept in %s): %v\n", trash.ItemDir(root, item.ID), err)
+		} else {
+			fmt.Println(plain("  ✓ re-applied uncommitted changes"))
+		}
+	}
+
+	err = state.Change(root, func(s *state.State) error {
+		if err := s.Add(alias, item.Branch, item.Path); err != nil {
+			return err
+		}
+		return s.Update(alias, func(e *state.WorktreeEntry) {
+			e.Created = item.Created
+			e.Base = item.Base
+			e.Upstream = item.Upstream
+			e.Template = item.Template
+			e.Tags = item.Tags
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := trash.Delete(root, item.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "  warning: could not delete trash item: %v\n", err)
+	}
+
+	fmt.Printf("Worktree %q restored.\n", alias)
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+
+	if trashEmptyExpired {
+		if cfg.TrashDays <= 0 {
+			return errors.New("trashDays is not set in .groverc.json — nothing expires")
+		}
+		purged, err := trash.Purge(root, trashRetention(cfg))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purged %d expired item(s).\n", purged)
+		return nil
+	}
+
+	items, err := trash.List(root)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	answer := prompt(fmt.Sprintf("Permanently delete %d trashed worktree(s)? [y/N]", len(items)), "n")
+	if answer != "y" && answer != "Y" {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	for _, item := range items {
+		if err := trash.Delete(root, item.ID); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Deleted %d item(s).\n", len(items))
+	return nil
+}
+
+func completeTrash(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	items, err := trash.List(root)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := make(map[string]bool)
+	var aliases []string
+	for _, item := range items {
+		if !seen[item.Alias] {
+			seen[item.Alias] = true
+			aliases = append(aliases, item.Alias)
+		}
+	}
+	return aliases, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+	"github.com/verbaux/grove/internal/trash"
+)
+
+func TestTrashRemoveAndRestore(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		TrashDays:   7,
+	})
+	wtPath := createDirtyWorktree(t, dir, "feature/trashed")
+
+	// Commit something on the branch, then delete the branch after removal
+	// so restore has to use the bundle.
+	if err := os.WriteFile(filepath.Join(wtPath, "committed.txt"), []byte("c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "committed.txt"}, {"commit", "-m", "work"}} {
+		if out, err := gitCmd(wtPath, args...); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	removeForce, removeYes = true, true
+	t.Cleanup(func() { removeForce, removeYes = false, false })
+	if err := runRemove(removeCmd, []string{"trashed"}); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	removeForce, removeYes = false, false
+
+	items, err := trash.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Alias != "trashed" {
+		t.Fatalf("expected one trash item for trashed, got %+v", items)
+	}
+
+	if out, err := gitCmd(dir, "branch", "-D", "feature/trashed"); err != nil {
+		t.Fatalf("branch -D: %s", out)
+	}
+
+	if err := runTrashRestore(trashRestoreCmd, []string{"trashed"}); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(wtPath, "committed.txt")); err != nil {
+		t.Errorf("committed file missing after restore: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(wtPath, "wip.txt"))
+	if err != nil || string(data) != "wip" {
+		t.Errorf("uncommitted file not restored: %q, %v", data, err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.AliasExists("trashed") {
+		t.Error("expected alias back in state")
+	}
+	if items, _ := trash.List(dir); len(items) != 0 {
+		t.Errorf("expected trash item to be consumed, got %d", len(items))
+	}
+
+	git.RemoveWorktree(wtPath, true)
+}
+
+// gitCmd runs git in dir and returns combined output.
+func gitCmd(dir string, args ...string) ([]byte, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	return c.CombinedOutput()
+}
+
+func TestTrashBundlesWhenBaseIsGone(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{WorktreeDir: "../", Prefix: "testproject", Symlink: []string{}, TrashDays: 7})
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-orphaned")
+	t.Cleanup(func() { git.RemoveWorktree(wtPath, true) })
+
+	if out, err := gitCmd(dir, "branch", "release/old"); err != nil {
+		t.Fatalf("git branch: %s", out)
+	}
+	createName, createFrom = "", "release/old"
+	t.Cleanup(func() { createFrom = "" })
+	if err := runCreate(createCmd, []string{"feature/orphaned"}); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := gitCmd(wtPath, "commit", "--allow-empty", "-m", "work"); err != nil {
+		t.Fatalf("commit: %s", out)
+	}
+	// The branch it was created from is gone: the whole branch has to be
+	// bundled instead of nothing.
+	if out, err := gitCmd(dir, "branch", "-D", "release/old"); err != nil {
+		t.Fatalf("git branch -D: %s", out)
+	}
+
+	cfg, _ := config.Load(dir)
+	s, _ := state.Load(dir)
+	entry, _ := s.Get("orphaned")
+	if _, err := moveToTrash(dir, cfg, "orphaned", entry, ""); err != nil {
+		t.Fatalf("moveToTrash: %v", err)
+	}
+	items, _ := trash.List(dir)
+	if len(items) != 1 {
+		t.Fatalf("trash items = %+v", items)
+	}
+	if _, err := os.Stat(trash.BundlePath(dir, items[0].ID)); err != nil {
+		t.Errorf("no bundle of the branch's commits: %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/trust"
+)
+
+var (
+	trustHooks  bool
+	trustRevoke bool
+)
+
+func init() {
+	rootCmd.AddCommand(trustCmd)
+	trustCmd.Flags().BoolVar(&trustRevoke, "revoke", false, "forget every approval for this project")
+}
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Approve the hook commands this project runs",
+	Long: `Show every command .groverc.json and .grove/hooks make grove run — hooks,
+installCommand, database, kube and verify commands, for each template too,
+and the "git" settings — and approve them on this machine.
+
+.groverc.json is committed, so a pull can change what 'grove create' and
+'grove remove' run. Grove asks before running a command it hasn't seen
+before, or one that changed since it was approved, much like direnv's allow.
+Approvals are kept per project in <user config dir>/grove/trusted.json
+(GROVE_TRUST_STORE overrides the location).
+
+Pass --trust to any command to run hooks without asking, e.g. in CI.`,
+	Args: cobra.NoArgs,
+	RunE: runTrust,
+}
+
+func runTrust(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	path, err := trust.Path()
+	if err != nil {
+		return err
+	}
+	store, err := trust.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if trustRevoke {
+		if store.Revoke(root) {
+			if err := trust.Save(path, store); err != nil {
+				return err
+			}
+		}
+		fmt.Println("Forgot all approved commands for this project.")
+		return nil
+	}
+
+	hooks, err := projectHooks(root, cfg)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		fmt.Println("This project doesn't run any hook commands.")
+		return nil
+	}
+	changed := false
+	for _, h := range hooks {
+		mark := "  "
+		if store.Allow(root, h.key) {
+			mark, changed = plain("+ "), true
+		}
+		fmt.Printf("%s%s\n", mark, h.label)
+	}
+	if changed {
+		if err := trust.Save(path, store); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\nTrusted %d command(s). Grove asks again if any of them changes.\n", len(hooks))
+	return nil
+}
+
+// trustedHook is a command grove runs from the project's config, with the
+// text its approval is recorded under.
+type trustedHook struct {
+	label string
+	key   string
+}
+
+// errNotTrusted is returned when the user declines to run hooks they
+// haven't approved.
+var errNotTrusted = errors.New("hook commands not approved — review them and run 'grove trust', or pass --trust")
+
+// requireTrust makes sure every one of hooks was approved for the project,
+// asking for the ones that weren't. Approving stores them; declining is an
+// error, so nothing runs. --trust skips the check.
+func requireTrust(root string, hooks []trustedHook) error {
+	if trustHooks || len(hooks) == 0 {
+		return nil
+	}
+	path, err := trust.Path()
+	if err != nil {
+		return err
+	}
+	store, err := trust.Load(path)
+	if err != nil {
+		return err
+	}
+
+	var untrusted []trustedHook
+	for _, h := range hooks {
+		if !store.Trusted(root, h.key) {
+			untrusted = append(untrusted, h)
+		}
+	}
+	if len(untrusted) == 0 {
+		return nil
+	}
+
+	// stderr, so the prompt doesn't end up in create --json's output.
+	fmt.Fprintln(os.Stderr, "This project runs commands you haven't approved on this machine:")
+	for _, h := range untrusted {
+		fmt.Fprintf(os.Stderr, "  %s\n", h.label)
+	}
+	answer := promptTo(os.Stderr, "Run them? [y/N]", "n")
+	if answer != "y" && answer != "Y" {
+		return errNotTrusted
+	}
+	for _, h := range untrusted {
+		store.Allow(root, h.key)
+	}
+	return trust.Save(path, store)
+}
+
+// trustedAll reports, without asking, whether every one of hooks was
+// approved for the project, or --trust was given.
+func trustedAll(root string, hooks []trustedHook) bool {
+	if trustHooks || len(hooks) == 0 {
+		return true
+	}
+	path, err := trust.Path()
+	if err != nil {
+		return false
+	}
+	store, err := trust.Load(path)
+	if err != nil {
+		return false
+	}
+	for _, h := range hooks {
+		if !store.Trusted(root, h.key) {
+			return false
+		}
+	}
+	return true
+}
+
+// gitHooks lists the git settings in cfg. git.binary runs in place of git,
+// and git.args and git.env can make git run commands of their own, like
+// -c core.fsmonitor=... or GIT_SSH_COMMAND.
+func gitHooks(cfg config.Config) []trustedHook {
+	var hooks []trustedHook
+	if cfg.Git.Binary != "" {
+		hooks = append(hooks, configHook("git.binary", cfg.Git.Binary))
+	}
+	if len(cfg.Git.Args) > 0 {
+		hooks = append(hooks, configHook("git.args", strings.Join(cfg.Git.Args, " ")))
+	}
+	keys := make([]string, 0, len(cfg.Git.Env))
+	for k := range cfg.Git.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		hooks = append(hooks, configHook("git.env", k+"="+cfg.Git.Env[k]))
+	}
+	return hooks
+}
+
+// createHooks lists what grove create may run for cfg, a config with any
+// template already applied.
+func createHooks(root string, cfg config.Config) ([]trustedHook, error) {
+	var hooks []trustedHook
+	if cfg.InstallCommand != "" {
+		hooks = append(hooks, configHook("installCommand", cfg.InstallCommand))
+	}
+	after, err := scriptHooks(root, "afterCreate", cfg.AfterCreate, "after-create")
+	if err != nil {
+		return nil, err
+	}
+	hooks = append(hooks, after...)
+	// A create that fails after these rolls them back with drop and down.
+	if cfg.Database.Name != "" && cfg.Database.Create != "" {
+		hooks = append(hooks, configHook("database.create", cfg.Database.Create))
+		if cfg.Database.Drop != "" {
+			hooks = append(hooks, configHook("database.drop", cfg.Database.Drop))
+		}
+	}
+	if cfg.Kube.Namespace != "" && cfg.Kube.Up != "" {
+		hooks = append(hooks, configHook("kube.up", cfg.Kube.Up))
+		if cfg.Kube.Down != "" {
+			hooks = append(hooks, configHook("kube.down", cfg.Kube.Down))
+		}
+	}
+	return withWrapper(cfg, hooks), nil
+}
+
+// removeHooks lists what tearing down a worktree created with cfg may run.
+func removeHooks(root string, cfg config.Config) ([]trustedHook, error) {
+	hooks, err := scriptHooks(root, "beforeRemove", cfg.BeforeRemove, "pre-remove")
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database.Name != "" && cfg.Database.Drop != "" {
+		hooks = append(hooks, configHook("database.drop", cfg.Database.Drop))
+	}
+	if cfg.Kube.Namespace != "" && cfg.Kube.Down != "" {
+		hooks = append(hooks, configHook("kube.down", cfg.Kube.Down))
+	}
+	return withWrapper(cfg, hooks), nil
+}
+
+// verifyHooks lists what grove verify may run.
+func verifyHooks(root string, cfg config.Config) ([]trustedHook, error) {
+	if cfg.Verify == "" {
+		return nil, nil
+	}
+	return withWrapper(cfg, []trustedHook{configHook("verify", cfg.Verify)}), nil
+}
+
+// projectHooks lists everything the project may run, for every template,
+// without duplicates.
+func projectHooks(root string, cfg config.Config) ([]trustedHook, error) {
+	configs := []config.Config{cfg}
+	names := make([]string, 0, len(cfg.Templates))
+	for name := range cfg.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tcfg, _, _ := cfg.WithTemplate(name)
+		configs = append(configs, tcfg)
+	}
+
+	hooks := gitHooks(cfg)
+	seen := map[string]bool{}
+	for _, h := range hooks {
+		seen[h.key] = true
+	}
+	for _, c := range configs {
+		for _, list := range []func(string, config.Config) ([]trustedHook, error){createHooks, removeHooks, verifyHooks} {
+			found, err := list(root, c)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range found {
+				if !seen[h.key] {
+					seen[h.key] = true
+					hooks = append(hooks, h)
+				}
+			}
+		}
+	}
+	return hooks, nil
+}
+
+func configHook(name, command string) trustedHook {
+	return trustedHook{label: name + ": " + command, key: command}
+}
+
+// scriptHooks turns hookCommands into trustedHooks, with name the config
+// key of the configured command. A script is approved
+// by its contents, not just its name, so editing it needs a new approval.
+func scriptHooks(root, name, configured, event string) ([]trustedHook, error) {
+	commands, err := hookCommands(root, configured, event)
+	if err != nil {
+		return nil, err
+	}
+	hooks := make([]trustedHook, 0, len(commands))
+	for _, c := range commands {
+		if c.script == "" {
+			hooks = append(hooks, configHook(name, c.command))
+			continue
+		}
+		key := "script " + c.label
+		if data, err := os.ReadFile(c.script); err == nil {
+			key += "\n" + string(data)
+		}
+		hooks = append(hooks, trustedHook{label: c.label, key: key})
+	}
+	return hooks, nil
+}
+
+// withWrapper adds the hooks.wrapper and hooks.container every hook runs
+// under, if any — they are as much commands from the config as the hooks
+// themselves.
+func withWrapper(cfg config.Config, hooks []trustedHook) []trustedHook {
+	if len(hooks) == 0 {
+		return hooks
+	}
+	if len(cfg.Hooks.Wrapper) > 0 {
+		hooks = append(hooks, configHook("hooks.wrapper", strings.Join(cfg.Hooks.Wrapper, " ")))
+	}
+	if len(cfg.Hooks.Container) > 0 {
+		hooks = append(hooks, configHook("hooks.container", strings.Join(cfg.Hooks.Container, " ")))
+	}
+	return hooks
+}
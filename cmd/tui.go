@@ -0,0 +1,509 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/config"
+)
+
+// tuiInterval is how often the dashboard runs git status again.
+const tuiInterval = 2 * time.Second
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen dashboard of the worktrees, with their status kept live",
+	Long: `Show every worktree full-screen, with its branch, path and git status,
+refreshed every couple of seconds, and act on the one under the cursor:
+
+  ↑/↓ or k/j   move
+  Enter or c   quit and print its path, for the shell to cd into
+  o            open it in your editor (see 'grove open')
+  d            remove it, after asking (see 'grove remove')
+  n            create a worktree: type the branch, then Enter
+  s            copy the env files into it again (see 'grove sync --env')
+  r            refresh now
+  q or Esc     quit
+
+Like grove switch, the dashboard is drawn on stderr and only the path you
+pick goes to stdout, so a shell function can take you there:
+
+  gt() { cd "$(grove tui)"; }
+
+Commands run from the dashboard print their output and ask their questions
+as they normally would, on stderr; press Enter afterwards to get back.`,
+	Args: cobra.NoArgs,
+	RunE: runTui,
+}
+
+func runTui(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	if plainOutput {
+		return fmt.Errorf("grove tui draws a full-screen table — with --plain, use 'grove list' and 'grove switch'")
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return fmt.Errorf("grove tui needs a terminal — use 'grove list' in scripts")
+	}
+	rows, err := buildWorktreeRows(root)
+	if err != nil {
+		return err
+	}
+
+	// Styles are for stderr, which stays a terminal inside $(...).
+	d := &dashboard{title: displayPath(root), renderer: lipgloss.NewRenderer(os.Stderr)}
+	d.setRows(rows, time.Now())
+	screen := &tuiScreen{in: os.Stdin, out: os.Stderr}
+	if err := screen.enter(); err != nil {
+		return err
+	}
+
+	// mu guards d and the screen. work is held by a refresh or a command,
+	// so git status never runs while a command changes the worktrees.
+	var mu, work sync.Mutex
+	defer func() {
+		mu.Lock()
+		screen.leave()
+		mu.Unlock()
+	}()
+	stop := make(chan struct{})
+	defer close(stop)
+	refresh := func() {
+		rows, err := buildWorktreeRows(root)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			d.message = err.Error()
+		} else {
+			d.setRows(rows, time.Now())
+		}
+		screen.draw(d)
+	}
+	go func() {
+		ticker := time.NewTicker(tuiInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if work.TryLock() {
+				refresh()
+				work.Unlock()
+			}
+		}
+	}()
+
+	mu.Lock()
+	screen.draw(d)
+	mu.Unlock()
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, k := range decodeTuiKeys(buf[:n]) {
+			mu.Lock()
+			action, arg := d.handle(k)
+			screen.draw(d)
+			mu.Unlock()
+
+			switch action {
+			case tuiQuit:
+				return nil
+			case tuiCd:
+				mu.Lock()
+				screen.leave()
+				mu.Unlock()
+				fmt.Println(displayPath(arg))
+				return nil
+			case tuiRefresh:
+				work.Lock()
+				refresh()
+				work.Unlock()
+			case tuiOpen, tuiRemove, tuiCreate, tuiSync:
+				work.Lock()
+				err := screen.suspend(func() error { return runTuiAction(cfg, action, arg, os.Stderr) })
+				refresh()
+				work.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// runTuiAction runs the grove command behind a dashboard key, with the
+// terminal back to normal and its output going to w. Its errors are shown
+// rather than returned, so the dashboard carries on.
+func runTuiAction(cfg config.Config, action tuiAction, arg string, w io.Writer) error {
+	var err error
+	switch action {
+	case tuiOpen:
+		editor := editorCommand(cfg, "")
+		if editor == "" {
+			err = errors.New("no editor configured — set \"editor\" in .groverc.json or GROVE_EDITOR")
+			break
+		}
+		if err = openInEditor(editor, arg); err == nil {
+			return nil // straight back, nothing to read
+		}
+	case tuiRemove:
+		err = runGrove(w, "remove", "path:"+arg)
+	case tuiCreate:
+		// Created only: the dashboard comes back afterwards, so the
+		// "open" setting's editor, tmux window or shell is skipped.
+		err = runGrove(w, "create", "--open", config.OpenNone, arg)
+	case tuiSync:
+		err = runGrove(w, "sync", "--env", "path:"+arg)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) { // grove printed its own
+		fmt.Fprintf(w, "Error: %v\n", err)
+	}
+	promptTo(w, "\nPress Enter to go back to the dashboard", "")
+	return nil
+}
+
+// runGrove runs grove itself with args, its output going to w. Run apart
+// from the dashboard, a command can't leave its flags set or print on the
+// stdout that's kept for the path printed on quitting.
+func runGrove(w io.Writer, args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if trustHooks {
+		args = append([]string{"--trust"}, args...)
+	}
+	c := exec.Command(exe, args...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, w, w
+	return c.Run()
+}
+
+// tuiAction is what a key press asks runTui to do.
+type tuiAction int
+
+const (
+	tuiNone tuiAction = iota
+	tuiQuit
+	tuiCd
+	tuiOpen
+	tuiRemove
+	tuiCreate
+	tuiSync
+	tuiRefresh
+)
+
+// tuiKey is a decoded key press.
+type tuiKey struct {
+	r    rune   // a printable character, or 0
+	name string // "up", "down", "enter", "esc", "backspace", "interrupt", or ""
+}
+
+// decodeTuiKeys splits the bytes of one read from the terminal into key
+// presses.
+func decodeTuiKeys(b []byte) []tuiKey {
+	var keys []tuiKey
+	for len(b) > 0 {
+		switch {
+		case len(b) >= 3 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O'):
+			switch b[2] {
+			case 'A':
+				keys = append(keys, tuiKey{name: "up"})
+			case 'B':
+				keys = append(keys, tuiKey{name: "down"})
+			}
+			b = b[3:]
+			continue
+		case b[0] == 0x1b:
+			keys = append(keys, tuiKey{name: "esc"})
+		case b[0] == 3: // Ctrl-C
+			keys = append(keys, tuiKey{name: "interrupt"})
+		case b[0] == '\r', b[0] == '\n':
+			keys = append(keys, tuiKey{name: "enter"})
+		case b[0] == 0x7f, b[0] == 8:
+			keys = append(keys, tuiKey{name: "backspace"})
+		case b[0] >= 0x20:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, tuiKey{r: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// dashboard is grove tui's state, separate from the terminal so it can be
+// driven by tests.
+type dashboard struct {
+	title     string
+	rows      []worktreeRow
+	refreshed time.Time
+	cursor    int
+	mode      string // "", "create" (typing a branch) or "remove" (confirming)
+	input     string
+	message   string             // shown in place of the key help until the next key
+	renderer  *lipgloss.Renderer // nil for lipgloss's default
+}
+
+// setRows replaces the rows, keeping the cursor on the same worktree if it's
+// still there.
+func (d *dashboard) setRows(rows []worktreeRow, now time.Time) {
+	path := ""
+	if r := d.selected(); r != nil {
+		path = r.Path
+	}
+	d.rows, d.refreshed = rows, now
+	d.cursor = min(d.cursor, max(len(rows)-1, 0))
+	for i, r := range rows {
+		if r.Path == path {
+			d.cursor = i
+		}
+	}
+}
+
+func (d *dashboard) selected() *worktreeRow {
+	if d.cursor < len(d.rows) {
+		return &d.rows[d.cursor]
+	}
+	return nil
+}
+
+// handle applies k, and returns what runTui should do about it: for tuiCd,
+// tuiOpen, tuiRemove and tuiSync, with the selected worktree's path; for tuiCreate,
+// with the branch typed.
+func (d *dashboard) handle(k tuiKey) (tuiAction, string) {
+	if k.name == "interrupt" {
+		return tuiQuit, ""
+	}
+	switch d.mode {
+	case "create":
+		switch {
+		case k.name == "enter":
+			branch := strings.TrimSpace(d.input)
+			d.mode, d.input = "", ""
+			if branch != "" {
+				return tuiCreate, branch
+			}
+		case k.name == "esc":
+			d.mode, d.input = "", ""
+		case k.name == "backspace" && d.input != "":
+			_, size := utf8.DecodeLastRuneInString(d.input)
+			d.input = d.input[:len(d.input)-size]
+		case k.r != 0:
+			d.input += string(k.r)
+		}
+		return tuiNone, ""
+	case "remove":
+		d.mode = ""
+		if (k.r == 'y' || k.r == 'Y') && d.selected() != nil {
+			return tuiRemove, d.selected().Path
+		}
+		return tuiNone, ""
+	}
+
+	d.message = ""
+	row := d.selected()
+	switch {
+	case k.name == "up" || k.r == 'k':
+		if d.cursor > 0 {
+			d.cursor--
+		}
+	case k.name == "down" || k.r == 'j':
+		if d.cursor < len(d.rows)-1 {
+			d.cursor++
+		}
+	case k.name == "esc" || k.r == 'q':
+		return tuiQuit, ""
+	case (k.name == "enter" || k.r == 'c') && row != nil:
+		return tuiCd, row.Path
+	case k.r == 'o' && row != nil:
+		return tuiOpen, row.Path
+	case k.r == 'd' && row != nil:
+		if row.IsMain {
+			d.message = "The main worktree can't be removed."
+			break
+		}
+		d.mode = "remove"
+	case k.r == 'n':
+		d.mode = "create"
+	case k.r == 's' && row != nil:
+		if row.IsMain {
+			d.message = "The main worktree is where env files are copied from."
+			break
+		}
+		return tuiSync, row.Path
+	case k.r == 'r':
+		return tuiRefresh, ""
+	}
+	return tuiNone, ""
+}
+
+// view renders the dashboard to fit width × height, one string per line.
+func (d *dashboard) view(width, height int) []string {
+	r := d.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	titleStyle := r.NewStyle().Bold(true)
+	dim := r.NewStyle().Foreground(lipgloss.Color("241"))
+	selectedStyle := r.NewStyle().Reverse(true)
+	mainStyle := r.NewStyle().Foreground(lipgloss.Color("33"))   // blue
+	cleanStyle := r.NewStyle().Foreground(lipgloss.Color("34"))  // green
+	dirtyStyle := r.NewStyle().Foreground(lipgloss.Color("214")) // orange
+
+	nameW, branchW, statusW := len("NAME"), len("BRANCH"), len("STATUS")
+	for _, r := range d.rows {
+		nameW = max(nameW, utf8.RuneCountInString(r.Name))
+		branchW = max(branchW, utf8.RuneCountInString(r.Branch))
+		statusW = max(statusW, utf8.RuneCountInString(tuiStatus(r.Status)))
+	}
+	pad := func(s string, w int) string {
+		return s + strings.Repeat(" ", max(w-utf8.RuneCountInString(s), 0)+2)
+	}
+
+	lines := []string{
+		titleStyle.Render(clip("grove — "+d.title, width)) + dim.Render(clip(fmt.Sprintf("  %d worktree(s), updated %s", len(d.rows), d.refreshed.Format(time.TimeOnly)), width-utf8.RuneCountInString("grove — "+d.title))),
+		"",
+		dim.Render(clip("  "+pad("NAME", nameW)+pad("BRANCH", branchW)+pad("STATUS", statusW)+"PATH", width)),
+	}
+
+	// Keep the cursor in view: three lines above the rows, two below.
+	visible := max(height-5, 1)
+	start := 0
+	if d.cursor >= visible {
+		start = d.cursor - visible + 1
+	}
+	for i := start; i < len(d.rows) && i < start+visible; i++ {
+		r := d.rows[i]
+		status := tuiStatus(r.Status)
+		marker := "  "
+		if i == d.cursor {
+			marker = "▸ "
+		}
+		line := clip(marker+pad(r.Name, nameW)+pad(r.Branch, branchW)+pad(status, statusW)+displayPath(r.Path), width)
+		switch {
+		case i == d.cursor:
+			line = selectedStyle.Render(line)
+		case r.IsMain:
+			line = mainStyle.Render(line)
+		case r.Status == "clean":
+			line = strings.Replace(line, status, cleanStyle.Render(status), 1)
+		default:
+			line = strings.Replace(line, status, dirtyStyle.Render(status), 1)
+		}
+		lines = append(lines, line)
+	}
+	if len(d.rows) == 0 {
+		lines = append(lines, dim.Render("  No worktrees — press n to create one."))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case d.mode == "create":
+		lines = append(lines, clip("New worktree for branch: "+d.input+"█", width))
+	case d.mode == "remove":
+		lines = append(lines, clip(fmt.Sprintf("Remove %s (%s)? [y/N]", d.selected().Name, d.selected().Branch), width))
+	case d.message != "":
+		lines = append(lines, clip(d.message, width))
+	default:
+		lines = append(lines, dim.Render(clip("↑/↓ move  enter cd  o open  n new  d remove  s sync env  r refresh  q quit", width)))
+	}
+	return lines
+}
+
+// tuiStatus is how a row's status reads in the dashboard, as in grove list.
+func tuiStatus(status string) string {
+	if status == "clean" {
+		return "✓ clean"
+	}
+	return status
+}
+
+// clip cuts s to at most n runes, so no line of the dashboard wraps.
+func clip(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:max(n, 0)])
+}
+
+// tuiScreen is the terminal grove tui draws on: raw mode, in the alternate
+// screen, so quitting leaves the shell's scrollback as it was.
+type tuiScreen struct {
+	in    *os.File
+	out   *os.File
+	state *term.State // saved by enter, nil when not in raw mode
+}
+
+func (s *tuiScreen) enter() error {
+	state, err := term.MakeRaw(s.in.Fd())
+	if err != nil {
+		return err
+	}
+	s.state = state
+	fmt.Fprint(s.out, "\x1b[?1049h\x1b[?25l")
+	return nil
+}
+
+// leave puts the terminal back; it's safe to call more than once.
+func (s *tuiScreen) leave() {
+	if s.state == nil {
+		return
+	}
+	fmt.Fprint(s.out, "\x1b[?25h\x1b[?1049l")
+	term.Restore(s.in.Fd(), s.state)
+	s.state = nil
+}
+
+// suspend runs fn with the terminal back to normal, then returns to the
+// dashboard.
+func (s *tuiScreen) suspend(fn func() error) error {
+	s.leave()
+	if err := fn(); err != nil {
+		return err
+	}
+	return s.enter()
+}
+
+func (s *tuiScreen) draw(d *dashboard) {
+	if s.state == nil {
+		return
+	}
+	width, height, err := term.GetSize(s.in.Fd())
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+	for i, line := range d.view(width-1, height) {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(line + "\x1b[K")
+	}
+	sb.WriteString("\x1b[J")
+	fmt.Fprint(s.out, sb.String())
+}
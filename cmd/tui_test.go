@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeTuiKeys(t *testing.T) {
+	got := decodeTuiKeys([]byte("\x1b[Bj\r\x1b\x7f\x03é"))
+	want := []tuiKey{{name: "down"}, {r: 'j'}, {name: "enter"}, {name: "esc"}, {name: "backspace"}, {name: "interrupt"}, {r: 'é'}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeTuiKeys = %v, want %v", got, want)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	d := &dashboard{title: "~/proj"}
+	d.setRows([]worktreeRow{
+		{Name: "main", Branch: "main", Path: "/p/proj", Status: "clean", IsMain: true},
+		{Name: "auth", Branch: "feature/auth", Path: "/p/proj-auth", Status: "2 modified"},
+		{Name: "api", Branch: "feature/api", Path: "/p/proj-api", Status: "clean"},
+	}, time.Now())
+	press := func(keys ...tuiKey) (tuiAction, string) {
+		t.Helper()
+		var action tuiAction
+		var arg string
+		for _, k := range keys {
+			action, arg = d.handle(k)
+		}
+		return action, arg
+	}
+	char := func(r rune) tuiKey { return tuiKey{r: r} }
+
+	// The main worktree can't be removed, and nothing is asked.
+	if action, _ := press(char('d')); action != tuiNone || d.mode != "" || d.message == "" {
+		t.Errorf("d on main: action %v, mode %q, message %q", action, d.mode, d.message)
+	}
+	if action, _ := press(char('s')); action != tuiNone || d.message == "" {
+		t.Errorf("s on main: action %v, message %q", action, d.message)
+	}
+
+	if action, arg := press(char('j'), tuiKey{name: "enter"}); action != tuiCd || arg != "/p/proj-auth" {
+		t.Errorf("j Enter = %v %q, want cd to /p/proj-auth", action, arg)
+	}
+	if action, arg := press(char('o')); action != tuiOpen || arg != "/p/proj-auth" {
+		t.Errorf("o = %v %q, want open /p/proj-auth", action, arg)
+	}
+	if action, arg := press(char('s')); action != tuiSync || arg != "/p/proj-auth" {
+		t.Errorf("s = %v %q, want env sync of /p/proj-auth", action, arg)
+	}
+
+	// Removing asks first; anything but y backs out.
+	if action, _ := press(char('d'), char('n')); action != tuiNone {
+		t.Errorf("d n = %v, want nothing", action)
+	}
+	if action, _ := press(char('d')); action != tuiNone || !strings.Contains(strings.Join(d.view(80, 24), "\n"), "Remove auth (feature/auth)? [y/N]") {
+		t.Errorf("d should ask to confirm, view:\n%s", strings.Join(d.view(80, 24), "\n"))
+	}
+	if action, arg := press(char('y')); action != tuiRemove || arg != "/p/proj-auth" {
+		t.Errorf("d y = %v %q, want remove /p/proj-auth", action, arg)
+	}
+
+	// Keys typed while creating go into the branch name.
+	if action, arg := press(char('n'), char('f'), char('i'), char('x'), char('q'), tuiKey{name: "backspace"}, tuiKey{name: "enter"}); action != tuiCreate || arg != "fix" {
+		t.Errorf("n fixq⌫ Enter = %v %q, want create fix", action, arg)
+	}
+	if action, _ := press(char('n'), char('x'), tuiKey{name: "esc"}); action != tuiNone || d.mode != "" {
+		t.Errorf("Esc should cancel creating, got %v in mode %q", action, d.mode)
+	}
+
+	// A refresh that drops rows keeps the cursor on the same worktree.
+	d.setRows([]worktreeRow{
+		{Name: "main", Branch: "main", Path: "/p/proj", Status: "clean", IsMain: true},
+		{Name: "auth", Branch: "feature/auth", Path: "/p/proj-auth", Status: "clean"},
+	}, time.Now())
+	if row := d.selected(); row == nil || row.Name != "auth" {
+		t.Errorf("selected after refresh = %v, want auth", row)
+	}
+	press(char('j'), char('j'))
+	if d.cursor != 1 {
+		t.Errorf("cursor = %d, want it to stop at the last row", d.cursor)
+	}
+
+	view := strings.Join(d.view(80, 24), "\n")
+	for _, want := range []string{"grove — ~/proj", "feature/auth", "✓ clean", "q quit"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+	for _, line := range d.view(20, 24) {
+		if n := len([]rune(line)); n > 20 {
+			t.Errorf("line %q is %d wide, want at most 20", line, n)
+		}
+	}
+
+	if action, _ := press(char('q')); action != tuiQuit {
+		t.Errorf("q = %v, want quit", action)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(unlockFilesCmd)
+}
+
+var unlockFilesCmd = &cobra.Command{
+	Use:   "unlock-files <name>",
+	Short: "Make a read-only worktree's files writable again",
+	Long: `Undo --read-only from 'grove create' or 'grove review': give the
+worktree's files and directories their write permission back. Accepts an
+alias, branch or path.
+
+Files the worktree shares with the main worktree through symlinks or hard
+links are never touched.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runUnlockFiles,
+}
+
+func runUnlockFiles(cmd *cobra.Command, args []string) error {
+	root, _, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	query, err := expandRowRef(root, args[0])
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil || !resolved.InState {
+		return errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", args[0])
+	}
+
+	entry, _ := s.Get(resolved.Alias)
+	var hardlinks []string
+	if entry.Setup != nil {
+		hardlinks = entry.Setup.Hardlinks
+	}
+	// Unlock even if state doesn't say read-only: the permissions may have
+	// been changed by hand, and unlocking twice is harmless.
+	if err := files.SetReadOnly(entry.Path, false, hardlinks); err != nil {
+		return fmt.Errorf("making %s writable again: %w", entry.Path, err)
+	}
+	if entry.Setup != nil && entry.Setup.ReadOnly {
+		err := state.Change(root, func(s *state.State) error {
+			return s.Update(resolved.Alias, func(e *state.WorktreeEntry) {
+				if e.Setup != nil {
+					e.Setup.ReadOnly = false
+				}
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Files in %s are writable again.\n", resolved.Alias)
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/files"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestCreateReadOnlyAndUnlock(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+	})
+	os.WriteFile(filepath.Join(dir, "README"), []byte("x"), 0644)
+	gitCmd(dir, "add", "README")
+	gitCmd(dir, "commit", "-m", "readme")
+
+	createName = ""
+	createFrom = ""
+	createReadOnly = true
+	t.Cleanup(func() { createReadOnly = false })
+	if err := runCreate(createCmd, []string{"feature/locked"}); err != nil {
+		t.Fatalf("create --read-only failed: %v", err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-locked")
+	t.Cleanup(func() { files.SetReadOnly(wtPath, false, nil) })
+
+	writable := func() bool {
+		info, err := os.Stat(filepath.Join(wtPath, "README"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()&0200 != 0
+	}
+	if writable() {
+		t.Fatal("expected README to be read-only after create --read-only")
+	}
+	s, _ := state.Load(dir)
+	if e, _ := s.Get("locked"); e.Setup == nil || !e.Setup.ReadOnly {
+		t.Fatalf("expected read-only to be recorded, got %+v", e.Setup)
+	}
+
+	if err := runUnlockFiles(unlockFilesCmd, []string{"locked"}); err != nil {
+		t.Fatalf("unlock-files failed: %v", err)
+	}
+	if !writable() {
+		t.Error("expected README to be writable after unlock-files")
+	}
+	s, _ = state.Load(dir)
+	if e, _ := s.Get("locked"); e.Setup.ReadOnly {
+		t.Error("expected unlock-files to clear read-only in state")
+	}
+}
+
+func TestRemoveReadOnlyWorktree(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "testproject",
+		Symlink:     []string{},
+	})
+
+	createName = ""
+	createFrom = ""
+	createReadOnly = true
+	t.Cleanup(func() { createReadOnly = false })
+	if err := runCreate(createCmd, []string{"feature/ro"}); err != nil {
+		t.Fatal(err)
+	}
+	wtPath := filepath.Join(filepath.Dir(dir), "testproject-ro")
+	t.Cleanup(func() { files.SetReadOnly(wtPath, false, nil) })
+
+	if err := runRemove(removeCmd, []string{"ro"}); err != nil {
+		t.Fatalf("remove of a read-only worktree failed: %v", err)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("worktree should be removed")
+	}
+}
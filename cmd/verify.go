@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <name>",
+	Short: "Check that a worktree is set up correctly",
+	Long: `Check that a worktree still matches what grove create set up, and exit
+non-zero if it doesn't — so CI and agents can gate on a sandbox being ready:
+
+  - the directory exists and has the branch from .grove/state.json checked out
+  - the .env, copied and rendered files grove created are there
+  - symlinks are still symlinks, and their targets exist
+  - every hook grove ran (afterCreate, installCommand, ...) succeeded
+
+Then "verify" from .groverc.json, if set, runs in the worktree, e.g. a
+script that checks required variables or pings a database; a non-zero exit
+fails verification. It needs approval like any hook (see 'grove trust').
+
+Adopted worktrees have no setup record, so only the directory, branch and
+verify command are checked. Accepts an alias, branch or path; --json prints
+the checks for scripts.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE:              runVerify,
+}
+
+// verifyCheck is one check of grove verify. An empty Problem means it passed.
+type verifyCheck struct {
+	Name    string `json:"name"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// verifyReport is the --json shape of `grove verify`.
+type verifyReport struct {
+	Alias  string        `json:"alias"`
+	OK     bool          `json:"ok"`
+	Checks []verifyCheck `json:"checks"`
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	root, cfg, err := loadRootConfig()
+	if err != nil {
+		return err
+	}
+	s, err := state.Load(root)
+	if err != nil {
+		return err
+	}
+	query, err := expandRowRef(root, args[0])
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveWorktree(query, s)
+	if err != nil {
+		return err
+	}
+	if resolved == nil || !resolved.InState {
+		return errorf(state.ErrNotFound, "no managed worktree matches %q — run 'grove list' to see available worktrees", args[0])
+	}
+	alias := resolved.Alias
+	entry, _ := s.Get(alias)
+	if entry.Template != "" {
+		if cfg, _, err = cfg.WithTemplate(entry.Template); err != nil {
+			return err
+		}
+	}
+
+	checks := verifyWorktree(entry)
+	if cfg.Verify != "" && checks[0].Problem == "" {
+		hooks, _ := verifyHooks(root, cfg)
+		if err := requireTrust(root, hooks); err != nil {
+			return err
+		}
+		// Keep stdout for the report when it's JSON.
+		var out io.Writer = os.Stdout
+		if jsonOutput {
+			out = os.Stderr
+		}
+		check := verifyCheck{Name: "verify command"}
+		env, done, err := withHookContext(hookEnv(root, alias, entry.Branch, entry.Path), hookContext{Hook: "verify", Root: root, Alias: alias, Worktree: entry, Config: cfg})
+		if err != nil {
+			return err
+		}
+		if err := runHook(cfg.Hooks, cfg.Verify, entry.Path, env, out); err != nil {
+			check.Problem = fmt.Sprintf("%s: %v", cfg.Verify, err)
+		}
+		done()
+		checks = append(checks, check)
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.Problem != "" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(verifyReport{Alias: alias, OK: failed == 0, Checks: checks}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, c := range checks {
+			if c.Problem == "" {
+				fmt.Printf(plain("  ✓ %s\n"), c.Name)
+			} else {
+				fmt.Printf(plain("  ✗ %s: %s\n"), c.Name, c.Problem)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s failed %d of %d check(s) — fix them, or recreate it with 'grove remove %s' and 'grove create'", alias, failed, len(checks), alias)
+	}
+	if !jsonOutput {
+		fmt.Printf("\n%s is set up correctly.\n", alias)
+	}
+	return nil
+}
+
+// verifyWorktree checks entry against the worktree on disk. The first check
+// is always the directory; when it fails, there's nothing else to check.
+func verifyWorktree(entry state.WorktreeEntry) []verifyCheck {
+	dir := verifyCheck{Name: "directory"}
+	if info, err := os.Stat(entry.Path); err != nil || !info.IsDir() {
+		dir.Problem = fmt.Sprintf("%s is missing — run 'grove prune' to drop the entry", entry.Path)
+		return []verifyCheck{dir}
+	}
+	checks := []verifyCheck{dir}
+
+	if entry.Branch != "" {
+		check := verifyCheck{Name: "branch " + entry.Branch}
+		if branch, err := git.BranchAt(entry.Path); err != nil {
+			check.Problem = err.Error()
+		} else if branch != entry.Branch {
+			check.Problem = fmt.Sprintf("%s is checked out instead — run 'git switch %s' in the worktree", branch, entry.Branch)
+		}
+		checks = append(checks, check)
+	}
+
+	if entry.Setup == nil {
+		return checks
+	}
+	for _, files := range []struct {
+		name  string
+		paths []string
+	}{
+		{"env files", entry.Setup.EnvFiles},
+		{"copied files", entry.Setup.Copied},
+		{"rendered files", entry.Setup.Rendered},
+		{"hardlinked directories", entry.Setup.Hardlinks},
+	} {
+		if len(files.paths) == 0 {
+			continue
+		}
+		var missing []string
+		for _, rel := range files.paths {
+			if _, err := os.Lstat(filepath.Join(entry.Path, rel)); err != nil {
+				missing = append(missing, rel)
+			}
+		}
+		check := verifyCheck{Name: files.name}
+		if len(missing) > 0 {
+			check.Problem = "missing " + strings.Join(missing, ", ")
+		}
+		checks = append(checks, check)
+	}
+
+	if len(entry.Setup.Symlinks) > 0 {
+		var broken []string
+		for _, rel := range entry.Setup.Symlinks {
+			path := filepath.Join(entry.Path, rel)
+			if info, err := os.Lstat(path); err != nil {
+				broken = append(broken, rel+" is missing")
+			} else if info.Mode()&os.ModeSymlink == 0 {
+				broken = append(broken, rel+" is no longer a symlink")
+			} else if _, err := os.Stat(path); err != nil {
+				target, _ := os.Readlink(path)
+				broken = append(broken, fmt.Sprintf("%s points to %s, which doesn't exist", rel, target))
+			}
+		}
+		check := verifyCheck{Name: "symlinks"}
+		if len(broken) > 0 {
+			check.Problem = strings.Join(broken, "; ")
+		}
+		checks = append(checks, check)
+	}
+
+	for _, h := range entry.Setup.Hooks {
+		check := verifyCheck{Name: "hook " + h.Name}
+		if h.ExitCode != 0 {
+			check.Problem = fmt.Sprintf("%s exited with %d — run it again in the worktree", h.Command, h.ExitCode)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
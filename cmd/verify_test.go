@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verbaux/grove/internal/config"
+	"github.com/verbaux/grove/internal/state"
+)
+
+func TestVerify(t *testing.T) {
+	dir := setupIntegrationRepo(t, config.Config{
+		WorktreeDir: "../",
+		Prefix:      "shop",
+		Symlink:     []string{"node_modules"},
+		AfterCreate: "true",
+	})
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=3000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	createName, createFrom, createTemplate = "", "", ""
+	report, err := createWorktree(createCmd, "feature/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := state.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := s.Get("auth")
+	problems := func() []string {
+		var found []string
+		for _, c := range verifyWorktree(entry) {
+			if c.Problem != "" {
+				found = append(found, c.Name+": "+c.Problem)
+			}
+		}
+		return found
+	}
+	if got := problems(); len(got) != 0 {
+		t.Fatalf("a fresh worktree should verify, got %v", got)
+	}
+	if err := runVerify(verifyCmd, []string{"auth"}); err != nil {
+		t.Errorf("runVerify: %v", err)
+	}
+
+	os.Remove(filepath.Join(report.Path, ".env"))
+	os.Remove(filepath.Join(dir, "node_modules"))
+	got := strings.Join(problems(), "\n")
+	for _, want := range []string{"env files: missing .env", "symlinks: node_modules points to"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if err := runVerify(verifyCmd, []string{"auth"}); err == nil || !strings.Contains(err.Error(), "failed 2 of") {
+		t.Errorf("expected verification to fail 2 checks, got %v", err)
+	}
+
+	// The config's verify command runs last; its exit status counts.
+	cfg, _ := config.Load(dir)
+	cfg.Verify = "test -f ready"
+	if err := config.Save(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(report.Path, ".env"), nil, 0644)
+	os.MkdirAll(filepath.Join(dir, "node_modules"), 0755)
+	if err := runVerify(verifyCmd, []string{"auth"}); err == nil || !strings.Contains(err.Error(), "failed 1 of") {
+		t.Errorf("expected the verify command to fail, got %v", err)
+	}
+	os.WriteFile(filepath.Join(report.Path, "ready"), nil, 0644)
+	if err := runVerify(verifyCmd, []string{"auth"}); err != nil {
+		t.Errorf("expected the verify command to pass, got %v", err)
+	}
+
+	entry.Path = filepath.Join(dir, "gone")
+	if checks := verifyWorktree(entry); len(checks) != 1 || checks[0].Problem == "" {
+		t.Errorf("a missing directory should be the only check, got %+v", checks)
+	}
+}
@@ -3,20 +3,291 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
+	"runtime"
+
+	"github.com/verbaux/grove/internal/git"
+	"github.com/verbaux/grove/internal/trace"
+	"github.com/verbaux/grove/internal/version"
 )
 
 const FileName = ".groverc.json"
 
+// ErrNoConfig is returned when no .groverc.json can be found for the project.
+var ErrNoConfig = errors.New("no .groverc.json found — run 'grove init' first")
+
 // Config maps directly to .groverc.json.
 type Config struct {
-	WorktreeDir string   `json:"worktreeDir"`
-	Prefix      string   `json:"prefix"`
-	Symlink     []string `json:"symlink"`
-	AfterCreate string   `json:"afterCreate"`
+	Schema         string              `json:"$schema,omitempty" doc:"JSON Schema for editors, e.g. \"./.groverc.schema.json\" (see grove config schema)"`
+	WorktreeDir    string              `json:"worktreeDir" doc:"where worktrees are created, relative to the project root"`
+	Prefix         string              `json:"prefix" doc:"prefix of worktree directory names: <prefix>-<alias>"`
+	Symlink        []string            `json:"symlink" doc:"files and directories shared into each worktree, e.g. [\"node_modules\"]"`
+	LinkMode       string              `json:"linkMode,omitempty" doc:"how symlink entries are shared: symlink|hardlink (per-file hard links, pnpm-style)" enum:"symlink|hardlink" default:"symlink"`
+	AfterCreate    string              `json:"afterCreate" doc:"command run in a new worktree, e.g. \"npm ci\""`
+	InstallCommand string              `json:"installCommand,omitempty" doc:"run in a new worktree instead when a symlink source (e.g. node_modules) is missing"`
+	BeforeRemove   string              `json:"beforeRemove,omitempty" doc:"run in the worktree before remove/clean deletes it"`
+	Verify         string              `json:"verify,omitempty" doc:"command grove verify runs in the worktree after its own checks, e.g. \"npm run check-env\"; non-zero fails verification"`
+	Push           bool                `json:"push,omitempty" doc:"push new branches with -u origin by default"`
+	OnHookFailure  string              `json:"onHookFailure,omitempty" doc:"when installCommand, afterCreate or kube up fails in grove create: rollback (remove the worktree) or keep (finish later with grove retry)" enum:"rollback|keep" default:"rollback"`
+	OnDirtyRemove  string              `json:"onDirtyRemove,omitempty" doc:"remove/clean on uncommitted changes: prompt|block|stash|force" enum:"prompt|block|stash|force" default:"prompt"`
+	StatusMode     string              `json:"statusMode,omitempty" doc:"status shown by list and status: full|fast (fast skips untracked files)" enum:"full|fast" default:"full"`
+	TrashDays      int                 `json:"trashDays,omitempty" doc:"keep removed worktrees in .grove/trash for this many days (0 = off)"`
+	Open           string              `json:"open,omitempty" doc:"where grove create takes you afterwards: none|editor|tmux|shell" enum:"none|editor|tmux|shell" default:"none"`
+	Editor         string              `json:"editor,omitempty" doc:"editor command grove open and --open editor use, e.g. \"code --new-window\"; GROVE_EDITOR overrides it"`
+	Jobs           int                 `json:"jobs,omitempty" doc:"how many worktrees grove works on at once (list, clean, refresh, exec --all, create --from-file); 0 = the number of CPUs, at most 8"`
+	Banner         string              `json:"banner,omitempty" doc:"describe each new worktree in a generated file: none|markdown (WORKTREE.md)|motd (.grove-motd, for the shell to print)" enum:"none|markdown|motd" default:"none"`
+	PathStyle      string              `json:"pathStyle,omitempty" doc:"how printed paths are written, for editors and git on the other side of WSL: auto|wsl|windows|unix" enum:"auto|wsl|windows|unix" default:"auto"`
+	Templates      map[string]Template `json:"templates,omitempty" doc:"named worktree setups, selected with grove create --template"`
+	Git            GitConfig           `json:"git,omitzero" doc:"how grove invokes git"`
+	Hooks          HookConfig          `json:"hooks,omitzero" doc:"resource limits and sandboxing for hook commands"`
+	Database       DatabaseConfig      `json:"database,omitzero" doc:"a database per worktree"`
+	Kube           KubeConfig          `json:"kube,omitzero" doc:"a Kubernetes namespace per worktree"`
+	EnvProfiles    map[string][]string `json:"envProfiles,omitempty" doc:"env files (names or globs) grove create --env <profile> copies, e.g. {\"staging\": [\".env\", \".env.staging\"]}; default .env and .env.<profile>"`
+	Render         []string            `json:"render,omitempty" doc:"template files (globs) rendered into each new worktree, e.g. \"docker-compose.override.yml.tmpl\""`
+	GroveVersion   string              `json:"groveVersion,omitempty" doc:"grove that last wrote the file; stamped by Save"`
+}
+
+// DatabaseConfig gives each worktree its own database. Commands and values
+// are templates: {{name}} is the database name, and {{alias}}, {{branch}} and
+// {{prefix}} describe the worktree.
+type DatabaseConfig struct {
+	Name   string            `json:"name,omitempty" doc:"database name, e.g. \"app_{{alias}}\""`
+	Create string            `json:"create,omitempty" doc:"command run on grove create, e.g. \"createdb {{name}}\""`
+	Drop   string            `json:"drop,omitempty" doc:"command run on remove, e.g. \"dropdb --if-exists {{name}}\""`
+	Env    map[string]string `json:"env,omitempty" doc:"set in the copied env files, e.g. {\"DATABASE_URL\": \"postgres://localhost/{{name}}\"}"`
+}
+
+// KubeConfig gives each worktree its own Kubernetes namespace, so Tilt or
+// Skaffold dev environments don't collide. Values are templates like in
+// DatabaseConfig, with {{namespace}} and {{context}} available.
+type KubeConfig struct {
+	Namespace string            `json:"namespace,omitempty" doc:"namespace name, e.g. \"{{prefix}}-{{alias}}\""`
+	Context   string            `json:"context,omitempty" doc:"kubectl context, e.g. \"kind-dev\""`
+	Up        string            `json:"up,omitempty" doc:"command run on grove create, e.g. \"tilt ci --namespace {{namespace}}\""`
+	Down      string            `json:"down,omitempty" doc:"command run on remove, e.g. \"tilt down\""`
+	Env       map[string]string `json:"env,omitempty" doc:"set in the copied env files, e.g. {\"KUBE_NAMESPACE\": \"{{namespace}}\"}"`
+}
+
+// HookConfig limits the resources hook commands (afterCreate) may take, so
+// several worktrees set up in parallel don't starve the rest of the machine,
+// and can sandbox them for teams that don't want shared configs to run with
+// full access.
+type HookConfig struct {
+	Nice      int      `json:"nice,omitempty" doc:"run hooks at this niceness (1–19); also lowers I/O priority where ionice exists"`
+	Wrapper   []string `json:"wrapper,omitempty" doc:"command the hook runs under, e.g. [\"systemd-run\", \"--user\", \"--scope\", \"-p\", \"MemoryMax=4G\"]"`
+	NoNetwork bool     `json:"noNetwork,omitempty" doc:"run hooks without network access (unshare on Linux, sandbox-exec on macOS)"`
+	CleanEnv  bool     `json:"cleanEnv,omitempty" doc:"give hooks only a minimal environment plus GROVE_* and passEnv, not grove's own"`
+	PassEnv   []string `json:"passEnv,omitempty" doc:"variables kept with cleanEnv, e.g. [\"NPM_TOKEN\"]"`
+	Container []string `json:"container,omitempty" doc:"run hooks in a container: argv before \"sh -c <hook>\", with {{path}} for the worktree and {{gitdir}} for the repository's git directory, e.g. [\"docker\", \"run\", \"--rm\", \"-v\", \"{{path}}:{{path}}\", \"-w\", \"{{path}}\", \"node:20\"]"`
+}
+
+// GitConfig controls how grove invokes git. Useful on machines with several
+// git installs or special credential/transport setups.
+type GitConfig struct {
+	Binary string            `json:"binary,omitempty" doc:"path to the git executable (default: \"git\" on PATH)"`
+	Args   []string          `json:"args,omitempty" doc:"global args before every subcommand, e.g. [\"-c\", \"protocol.version=2\"]"`
+	Env    map[string]string `json:"env,omitempty" doc:"extra environment, e.g. {\"GIT_SSH_COMMAND\": \"ssh -i ~/.ssh/work\"}"`
+}
+
+// Template is a named worktree setup, selected with `grove create --template`.
+// Empty fields fall back to the top-level config.
+type Template struct {
+	From         string   `json:"from,omitempty" doc:"base branch for new branches"`
+	Symlink      []string `json:"symlink,omitempty" doc:"replaces the top-level symlink list"`
+	Copy         []string `json:"copy,omitempty" doc:"extra files/dirs (globs) to copy, on top of .env*"`
+	AfterCreate  string   `json:"afterCreate,omitempty" doc:"replaces the top-level afterCreate"`
+	BeforeRemove string   `json:"beforeRemove,omitempty" doc:"replaces the top-level beforeRemove"`
+	Sparse       []string `json:"sparse,omitempty" doc:"sparse-checkout directories (cone mode)"`
+	Tags         []string `json:"tags,omitempty" doc:"recorded on the worktree in state"`
+	Expire       string   `json:"expire,omitempty" doc:"expiry for worktrees from this template, e.g. \"7d\" (see grove expire)"`
+}
+
+// WithTemplate returns a copy of c with the named template's overrides applied.
+func (c Config) WithTemplate(name string) (Config, Template, error) {
+	tpl, ok := c.Templates[name]
+	if !ok {
+		return c, Template{}, fmt.Errorf("no template named %q in %s — run 'grove template' to see available templates", name, FileName)
+	}
+	if tpl.Symlink != nil {
+		c.Symlink = tpl.Symlink
+	}
+	if tpl.AfterCreate != "" {
+		c.AfterCreate = tpl.AfterCreate
+	}
+	if tpl.BeforeRemove != "" {
+		c.BeforeRemove = tpl.BeforeRemove
+	}
+	return c, tpl, nil
+}
+
+// EnvProfile returns the env file names (or globs) grove create --env
+// copies for profile: the envProfiles entry if there is one, else .env and
+// .env.<profile>. The second result reports whether profile is configured.
+func (c Config) EnvProfile(profile string) ([]string, bool) {
+	if names, ok := c.EnvProfiles[profile]; ok {
+		return names, true
+	}
+	return []string{".env", ".env." + profile}, false
+}
+
+// Policies for OnDirtyRemove.
+const (
+	DirtyPrompt = "prompt"
+	DirtyBlock  = "block"
+	DirtyStash  = "stash"
+	DirtyForce  = "force"
+)
+
+// DirtyRemovePolicy returns the effective OnDirtyRemove policy,
+// defaulting to DirtyPrompt. Returns an error for unknown values.
+func (c Config) DirtyRemovePolicy() (string, error) {
+	switch c.OnDirtyRemove {
+	case "":
+		return DirtyPrompt, nil
+	case DirtyPrompt, DirtyBlock, DirtyStash, DirtyForce:
+		return c.OnDirtyRemove, nil
+	default:
+		return "", fmt.Errorf("invalid onDirtyRemove %q in %s — use prompt, block, stash or force", c.OnDirtyRemove, FileName)
+	}
+}
+
+// Modes for LinkMode.
+const (
+	LinkSymlink  = "symlink"
+	LinkHardlink = "hardlink"
+)
+
+// EffectiveLinkMode returns the LinkMode to use, defaulting to LinkSymlink.
+// Returns an error for unknown values.
+func (c Config) EffectiveLinkMode() (string, error) {
+	switch c.LinkMode {
+	case "":
+		return LinkSymlink, nil
+	case LinkSymlink, LinkHardlink:
+		return c.LinkMode, nil
+	default:
+		return "", fmt.Errorf("invalid linkMode %q in %s — use symlink or hardlink", c.LinkMode, FileName)
+	}
+}
+
+// Modes for StatusMode.
+const (
+	StatusFull = "full"
+	StatusFast = "fast"
+)
+
+// EffectiveStatusMode returns the StatusMode to use, defaulting to
+// StatusFull. Returns an error for unknown values.
+func (c Config) EffectiveStatusMode() (string, error) {
+	switch c.StatusMode {
+	case "":
+		return StatusFull, nil
+	case StatusFull, StatusFast:
+		return c.StatusMode, nil
+	default:
+		return "", fmt.Errorf("invalid statusMode %q in %s — use full or fast", c.StatusMode, FileName)
+	}
+}
+
+// Ways to open a new worktree, for Open.
+const (
+	OpenNone   = "none"
+	OpenEditor = "editor"
+	OpenTmux   = "tmux"
+	OpenShell  = "shell"
+)
+
+// EffectiveOpen returns the Open action to use, defaulting to OpenNone.
+// Returns an error for unknown values.
+func (c Config) EffectiveOpen() (string, error) {
+	switch c.Open {
+	case "":
+		return OpenNone, nil
+	case OpenNone, OpenEditor, OpenTmux, OpenShell:
+		return c.Open, nil
+	default:
+		return "", fmt.Errorf("invalid open %q in %s — use none, editor, tmux or shell", c.Open, FileName)
+	}
+}
+
+// Policies for OnHookFailure.
+const (
+	HookFailureRollback = "rollback"
+	HookFailureKeep     = "keep"
+)
+
+// EffectiveHookFailure returns the OnHookFailure policy, defaulting to
+// HookFailureRollback. Returns an error for unknown values.
+func (c Config) EffectiveHookFailure() (string, error) {
+	switch c.OnHookFailure {
+	case "":
+		return HookFailureRollback, nil
+	case HookFailureRollback, HookFailureKeep:
+		return c.OnHookFailure, nil
+	default:
+		return "", fmt.Errorf("invalid onHookFailure %q in %s — use rollback or keep", c.OnHookFailure, FileName)
+	}
+}
+
+// Kinds of generated banner file, for Banner.
+const (
+	BannerNone     = "none"
+	BannerMarkdown = "markdown"
+	BannerMOTD     = "motd"
+)
+
+// EffectiveBanner returns the Banner kind to use, defaulting to BannerNone.
+// Returns an error for unknown values.
+func (c Config) EffectiveBanner() (string, error) {
+	switch c.Banner {
+	case "":
+		return BannerNone, nil
+	case BannerNone, BannerMarkdown, BannerMOTD:
+		return c.Banner, nil
+	default:
+		return "", fmt.Errorf("invalid banner %q in %s — use none, markdown or motd", c.Banner, FileName)
+	}
+}
+
+// Styles for PathStyle.
+const (
+	PathAuto    = "auto"    // as the system grove runs on spells them
+	PathWSL     = "wsl"     // /mnt/c/code/shop
+	PathWindows = "windows" // C:\code\shop
+	PathUnix    = "unix"    // C:/code/shop — forward slashes, no translation
+)
+
+// EffectivePathStyle returns the PathStyle to use, defaulting to PathAuto.
+// Returns an error for unknown values.
+func (c Config) EffectivePathStyle() (string, error) {
+	switch c.PathStyle {
+	case "":
+		return PathAuto, nil
+	case PathAuto, PathWSL, PathWindows, PathUnix:
+		return c.PathStyle, nil
+	default:
+		return "", fmt.Errorf("invalid pathStyle %q in %s — use auto, wsl, windows or unix", c.PathStyle, FileName)
+	}
+}
+
+// MaxDefaultJobs caps the default for Jobs: past a handful of parallel git
+// processes a laptop's disk, not its CPUs, is the bottleneck.
+const MaxDefaultJobs = 8
+
+// EffectiveJobs returns how many worktrees to work on at once: Jobs, or
+// the number of CPUs up to MaxDefaultJobs when it's unset.
+// Returns an error for negative values.
+func (c Config) EffectiveJobs() (int, error) {
+	switch {
+	case c.Jobs > 0:
+		return c.Jobs, nil
+	case c.Jobs == 0:
+		return min(runtime.NumCPU(), MaxDefaultJobs), nil
+	default:
+		return 0, fmt.Errorf("invalid jobs %d in %s — use 1 or more, or 0 for the number of CPUs", c.Jobs, FileName)
+	}
 }
 
 // Default returns a config with sensible defaults.
@@ -32,12 +303,13 @@ func Default() Config {
 
 // Load reads .groverc.json from dir.
 func Load(dir string) (Config, error) {
+	defer trace.Begin("config", "load "+FileName)()
 	path := filepath.Join(dir, FileName)
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return Config{}, errors.New("no .groverc.json found — run 'grove init' first")
+			return Config{}, ErrNoConfig
 		}
 		return Config{}, err
 	}
@@ -53,10 +325,35 @@ func Load(dir string) (Config, error) {
 // FindRoot walks up from dir until it finds a directory containing .groverc.json.
 // Like how git finds .git — you can run grove commands from any subdirectory.
 func FindRoot(dir string) (string, error) {
+	root, _, err := LocateRoot(dir)
+	return root, err
+}
+
+// LocateRoot is FindRoot, also returning the .groverc.json directory it
+// passed over, if any. When walking up from dir crosses into another git
+// repository — dir is in a worktree of project B placed inside project A —
+// the nearest .groverc.json may be A's. LocateRoot then prefers the config
+// of dir's own repository and returns A's root as skipped, so callers can
+// warn that the two conflict.
+func LocateRoot(dir string) (root, skipped string, err error) {
+	defer trace.Begin("config", "find root")()
+	crossed := false // passed a .git, so anything further up may be another project
 	current := dir
 	for {
 		if _, err := os.Stat(filepath.Join(current, FileName)); err == nil {
-			return current, nil
+			if !crossed {
+				return current, "", nil
+			}
+			// A worktree inside its own project's directory crosses a .git
+			// too, so ask git which repository dir really belongs to.
+			own, err := findRootViaGit(dir)
+			if err != nil || sameDir(own, current) {
+				return current, "", nil
+			}
+			return own, current, nil
+		}
+		if _, err := os.Lstat(filepath.Join(current, ".git")); err == nil {
+			crossed = true
 		}
 
 		parent := filepath.Dir(current)
@@ -70,21 +367,30 @@ func FindRoot(dir string) (string, error) {
 	// may be a sibling directory rather than a parent. Ask git for
 	// the common .git dir and check if its parent has .groverc.json.
 	if root, err := findRootViaGit(dir); err == nil {
-		return root, nil
+		return root, "", nil
 	}
 
-	return "", errors.New("no .groverc.json found — run 'grove init' first")
+	return "", "", ErrNoConfig
+}
+
+// sameDir reports whether a and b are the same directory once symlinks are
+// resolved.
+func sameDir(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	return a == b
 }
 
 func findRootViaGit(dir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
-	cmd.Dir = dir
-	out, err := cmd.Output()
+	gitDir, err := git.CommonDir(dir)
 	if err != nil {
 		return "", err
 	}
 
-	gitDir := strings.TrimSpace(string(out))
 	if !filepath.IsAbs(gitDir) {
 		gitDir = filepath.Join(dir, gitDir)
 	}
@@ -103,8 +409,15 @@ func findRootViaGit(dir string) (string, error) {
 
 // Save writes config to .groverc.json in dir.
 // 0644 = owner can read/write, everyone else can read.
+// Returns an error matching version.ErrNewer if cfg was written by a newer grove.
 func Save(dir string, cfg Config) error {
 	path := filepath.Join(dir, FileName)
+	if version.Newer(cfg.GroveVersion, version.Current) {
+		return fmt.Errorf("%s was %w (%s, this is %s) — upgrade grove before changing it", FileName, version.ErrNewer, cfg.GroveVersion, version.Current)
+	}
+	if version.Current != "" {
+		cfg.GroveVersion = version.Current
+	}
 
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
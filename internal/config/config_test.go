@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -148,6 +149,80 @@ func TestFindRootWorktree(t *testing.T) {
 	}
 }
 
+func TestLocateRootNestedProject(t *testing.T) {
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %s\n%s", args, err, out)
+		}
+	}
+	newRepo := func(dir string) {
+		t.Helper()
+		run(dir, "init")
+		run(dir, "config", "user.email", "test@test.com")
+		run(dir, "config", "user.name", "Test")
+		os.WriteFile(filepath.Join(dir, "README"), []byte("x"), 0644)
+		run(dir, "add", ".")
+		run(dir, "commit", "-m", "init")
+	}
+
+	// Project A, with project B's worktree placed inside it.
+	outer, _ := filepath.EvalSymlinks(t.TempDir())
+	inner, _ := filepath.EvalSymlinks(t.TempDir())
+	newRepo(outer)
+	newRepo(inner)
+	wt := filepath.Join(outer, "b-feature")
+	run(inner, "worktree", "add", wt, "-b", "feature")
+	t.Cleanup(func() {
+		cmd := exec.Command("git", "worktree", "remove", "--force", wt)
+		cmd.Dir = inner
+		cmd.Run()
+	})
+	if err := Save(outer, Default()); err != nil {
+		t.Fatal(err)
+	}
+
+	// B has no config of its own: A's is all there is.
+	root, skipped, err := LocateRoot(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != outer || skipped != "" {
+		t.Errorf("without B's config: LocateRoot = %q, %q; want %q, \"\"", root, skipped, outer)
+	}
+
+	// With B's config, B wins and A's is reported as skipped.
+	if err := Save(inner, Default()); err != nil {
+		t.Fatal(err)
+	}
+	root, skipped, err = LocateRoot(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != inner || skipped != outer {
+		t.Errorf("with B's config: LocateRoot = %q, %q; want %q, %q", root, skipped, inner, outer)
+	}
+
+	// A worktree of A inside A's own directory is not a conflict.
+	own := filepath.Join(outer, "a-feature")
+	run(outer, "worktree", "add", own, "-b", "feature")
+	t.Cleanup(func() {
+		cmd := exec.Command("git", "worktree", "remove", "--force", own)
+		cmd.Dir = outer
+		cmd.Run()
+	})
+	root, skipped, err = LocateRoot(own)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != outer || skipped != "" {
+		t.Errorf("own worktree: LocateRoot = %q, %q; want %q, \"\"", root, skipped, outer)
+	}
+}
+
 func TestFindRootNoConfig(t *testing.T) {
 	dir := t.TempDir()
 
@@ -155,4 +230,94 @@ func TestFindRootNoConfig(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error when no .groverc.json exists anywhere")
 	}
+	if !errors.Is(err, ErrNoConfig) {
+		t.Errorf("expected ErrNoConfig, got %v", err)
+	}
+}
+
+func TestWithTemplate(t *testing.T) {
+	cfg := Config{
+		Symlink:     []string{"node_modules"},
+		AfterCreate: "npm install",
+		Templates: map[string]Template{
+			"backend": {From: "develop", Symlink: []string{"vendor"}, Tags: []string{"api"}},
+			"bare":    {},
+		},
+	}
+
+	got, tpl, err := cfg.WithTemplate("backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.From != "develop" {
+		t.Errorf("From = %q, want %q", tpl.From, "develop")
+	}
+	if len(got.Symlink) != 1 || got.Symlink[0] != "vendor" {
+		t.Errorf("Symlink = %v, want [vendor]", got.Symlink)
+	}
+	// Empty template fields keep the top-level value.
+	if got.AfterCreate != "npm install" {
+		t.Errorf("AfterCreate = %q, want %q", got.AfterCreate, "npm install")
+	}
+
+	got, _, err = cfg.WithTemplate("bare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Symlink) != 1 || got.Symlink[0] != "node_modules" {
+		t.Errorf("Symlink = %v, want [node_modules]", got.Symlink)
+	}
+
+	if _, _, err := cfg.WithTemplate("missing"); err == nil {
+		t.Fatal("expected error for unknown template")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	s := Schema()
+	if s["$schema"] != SchemaDialect {
+		t.Errorf("$schema = %v", s["$schema"])
+	}
+
+	// Every field must be in the schema and documented, so a new option
+	// can't be added without showing up in editors.
+	var walk func(path string, schema map[string]any)
+	walk = func(path string, schema map[string]any) {
+		props, _ := schema["properties"].(map[string]any)
+		for name, p := range props {
+			prop := p.(map[string]any)
+			if prop["description"] == nil {
+				t.Errorf("%s%s has no doc tag", path, name)
+			}
+			if prop["type"] == "object" && prop["properties"] != nil {
+				walk(path+name+".", prop)
+			}
+			if items, ok := prop["additionalProperties"].(map[string]any); ok {
+				walk(path+name+".*.", items)
+			}
+		}
+	}
+	walk("", s)
+
+	props := s["properties"].(map[string]any)
+	for _, name := range []string{"worktreeDir", "linkMode", "$schema", "groveVersion"} {
+		if props[name] == nil {
+			t.Errorf("schema is missing %s", name)
+		}
+	}
+	linkMode := props["linkMode"].(map[string]any)
+	if linkMode["default"] != LinkSymlink || len(linkMode["enum"].([]string)) != 2 {
+		t.Errorf("linkMode = %v", linkMode)
+	}
+	if def := props["worktreeDir"].(map[string]any)["default"]; def != Default().WorktreeDir {
+		t.Errorf("worktreeDir default = %v", def)
+	}
+	hooks := props["hooks"].(map[string]any)["properties"].(map[string]any)
+	if hooks["container"].(map[string]any)["type"] != "array" {
+		t.Errorf("hooks.container = %v", hooks["container"])
+	}
+	templates := props["templates"].(map[string]any)["additionalProperties"].(map[string]any)
+	if templates["properties"].(map[string]any)["expire"] == nil {
+		t.Errorf("templates.*.expire missing")
+	}
 }
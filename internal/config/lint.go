@@ -0,0 +1,211 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Severities of a Finding. Errors are what breaks grove for everyone who
+// pulls the config; warnings are likely mistakes grove can live with.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is one problem Lint found. Field is the dotted path of the
+// setting, e.g. "templates.web.expire", or empty for the file as a whole.
+type Finding struct {
+	Field    string `json:"field,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Lint checks the contents of a .groverc.json without loading anything
+// else: that it parses, has no unknown or mistyped settings, and that
+// settings with a fixed set of values have one of them. It reports every
+// problem rather than stopping at the first, in a stable order.
+func Lint(data []byte) []Finding {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []Finding{{Severity: SeverityError, Message: jsonErrorMessage(data, err)}}
+	}
+
+	var findings []Finding
+	unknownKeys(raw, reflect.TypeOf(Config{}), "", &findings)
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			findings = append(findings, Finding{Field: typeErr.Field, Severity: SeverityError, Message: fmt.Sprintf("expected %s, got %s", typeName(typeErr.Type), typeErr.Value)})
+		} else {
+			findings = append(findings, Finding{Severity: SeverityError, Message: err.Error()})
+		}
+		return findings
+	}
+
+	for field, check := range map[string]func() error{
+		"onDirtyRemove": func() error { _, err := cfg.DirtyRemovePolicy(); return err },
+		"linkMode":      func() error { _, err := cfg.EffectiveLinkMode(); return err },
+		"statusMode":    func() error { _, err := cfg.EffectiveStatusMode(); return err },
+		"open":          func() error { _, err := cfg.EffectiveOpen(); return err },
+		"banner":        func() error { _, err := cfg.EffectiveBanner(); return err },
+		"onHookFailure": func() error { _, err := cfg.EffectiveHookFailure(); return err },
+		"pathStyle":     func() error { _, err := cfg.EffectivePathStyle(); return err },
+		"jobs":          func() error { _, err := cfg.EffectiveJobs(); return err },
+	} {
+		if err := check(); err != nil {
+			findings = append(findings, Finding{Field: field, Severity: SeverityError, Message: err.Error()})
+		}
+	}
+	if n := cfg.Hooks.Nice; n < 0 || n > 19 {
+		findings = append(findings, Finding{Field: "hooks.nice", Severity: SeverityError, Message: fmt.Sprintf("invalid nice %d — use 1 to 19, or leave it out", n)})
+	}
+	lintPaths("symlink", cfg.Symlink, &findings)
+	lintPaths("render", cfg.Render, &findings)
+	for name, tpl := range cfg.Templates {
+		lintPaths("templates."+name+".symlink", tpl.Symlink, &findings)
+		lintPaths("templates."+name+".copy", tpl.Copy, &findings)
+	}
+
+	sortFindings(findings)
+	return findings
+}
+
+// lintPaths checks a list of paths grove resolves inside the project: they
+// must stay inside it, and listing one twice is likely a merge leftover.
+func lintPaths(field string, paths []string, findings *[]Finding) {
+	seen := map[string]bool{}
+	for _, p := range paths {
+		clean := path.Clean(filepath.ToSlash(p))
+		switch {
+		case p == "":
+			*findings = append(*findings, Finding{Field: field, Severity: SeverityError, Message: "empty path"})
+		case path.IsAbs(clean) || filepath.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../"):
+			*findings = append(*findings, Finding{Field: field, Severity: SeverityError, Message: fmt.Sprintf("%q is outside the project — use a path relative to the project root", p)})
+		case seen[clean]:
+			*findings = append(*findings, Finding{Field: field, Severity: SeverityWarning, Message: fmt.Sprintf("%q is listed twice", p)})
+		}
+		seen[clean] = true
+	}
+}
+
+// unknownKeys reports keys in raw that t, a struct type, doesn't have —
+// usually a typo that makes grove silently ignore the setting.
+func unknownKeys(raw map[string]any, t reflect.Type, prefix string, findings *[]Finding) {
+	fields := map[string]reflect.Type{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+	for key, value := range raw {
+		ft, ok := fields[key]
+		if !ok {
+			msg := "unknown setting — grove ignores it"
+			if near := closest(key, fields); near != "" {
+				msg += fmt.Sprintf("; did you mean %q?", near)
+			}
+			*findings = append(*findings, Finding{Field: prefix + key, Severity: SeverityError, Message: msg})
+			continue
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			if m, ok := value.(map[string]any); ok {
+				unknownKeys(m, ft, prefix+key+".", findings)
+			}
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct:
+			if m, ok := value.(map[string]any); ok {
+				for name, v := range m {
+					if inner, ok := v.(map[string]any); ok {
+						unknownKeys(inner, ft.Elem(), prefix+key+"."+name+".", findings)
+					}
+				}
+			}
+		}
+	}
+}
+
+// closest returns the field name that key most likely meant: one that
+// matches ignoring case, or is one or two edits away.
+func closest(key string, fields map[string]reflect.Type) string {
+	best := ""
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if editDistance(strings.ToLower(name), strings.ToLower(key)) <= 2 && (best == "" || name < best) {
+			best = name
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// jsonErrorMessage adds the line and column to a syntax error.
+func jsonErrorMessage(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return "not valid JSON: " + err.Error()
+	}
+	// Offset counts the offending byte too.
+	before := data[:min(max(int(syntaxErr.Offset)-1, 0), len(data))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("not valid JSON at line %d, column %d: %v", line, col, err)
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int:
+		return "a number"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// sortFindings orders findings by field, then message.
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Field != findings[j].Field {
+			return findings[i].Field < findings[j].Field
+		}
+		return findings[i].Message < findings[j].Message
+	})
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []string // "field: severity: message substring"
+	}{
+		{"valid", `{"worktreeDir": "../", "prefix": "shop", "symlink": ["node_modules"]}`, nil},
+		{"syntax", "{\n  \"prefix\": \"shop\",\n}", []string{": error: not valid JSON at line 3, column 1"}},
+		{"typo", `{"afterCreat": "npm ci"}`, []string{`afterCreat: error: unknown setting — grove ignores it; did you mean "afterCreate"?`}},
+		{"nested typo", `{"hooks": {"nce": 5}, "templates": {"web": {"sparce": ["web"]}}}`, []string{
+			`hooks.nce: error: unknown setting — grove ignores it; did you mean "nice"?`,
+			`templates.web.sparce: error: unknown setting — grove ignores it; did you mean "sparse"?`,
+		}},
+		{"wrong type", `{"symlink": "node_modules"}`, []string{"symlink: error: expected a list, got string"}},
+		{"enum", `{"linkMode": "copy", "jobs": -1}`, []string{"jobs: error: invalid jobs -1", `linkMode: error: invalid linkMode "copy"`}},
+		{"paths", `{"symlink": ["node_modules", "node_modules", "../shared"], "templates": {"web": {"copy": ["/etc/hosts"]}}}`, []string{
+			`symlink: error: "../shared" is outside the project`,
+			`symlink: warning: "node_modules" is listed twice`,
+			`templates.web.copy: error: "/etc/hosts" is outside the project`,
+		}},
+	}
+	for _, tt := range tests {
+		got := Lint([]byte(tt.json))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: %d findings, want %d: %+v", tt.name, len(got), len(tt.want), got)
+			continue
+		}
+		for i, f := range got {
+			line := f.Field + ": " + f.Severity + ": " + f.Message
+			if !strings.HasPrefix(line, tt.want[i]) {
+				t.Errorf("%s: finding %d = %q, want %q...", tt.name, i, line, tt.want[i])
+			}
+		}
+	}
+}
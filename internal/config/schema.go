@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaDialect is the JSON Schema version Schema follows.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns the JSON Schema for .groverc.json. It is built from the
+// struct tags on Config, so it can't fall behind the fields grove reads:
+// json gives the names, doc the descriptions, enum the allowed values
+// ("a|b|c"), and default the value used when the field is empty. Values
+// set by Default are listed as defaults too.
+func Schema() map[string]any {
+	s := typeSchema(reflect.TypeOf(Config{}), reflect.ValueOf(Default()))
+	s["$schema"] = SchemaDialect
+	s["title"] = FileName
+	return s
+}
+
+// typeSchema describes values of type t; defaults, if valid, holds the
+// default values of a struct's fields.
+func typeSchema(t reflect.Type, defaults reflect.Value) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), reflect.Value{})}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), reflect.Value{})}
+	case reflect.Struct:
+		props := map[string]any{}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			var fieldDefaults reflect.Value
+			if defaults.IsValid() {
+				fieldDefaults = defaults.Field(i)
+			}
+			p := typeSchema(f.Type, fieldDefaults)
+			if doc := f.Tag.Get("doc"); doc != "" {
+				p["description"] = doc
+			}
+			if enum := f.Tag.Get("enum"); enum != "" {
+				p["enum"] = strings.Split(enum, "|")
+			}
+			if def, ok := f.Tag.Lookup("default"); ok {
+				p["default"] = def
+			} else if fieldDefaults.IsValid() && !fieldDefaults.IsZero() && f.Type.Kind() != reflect.Struct {
+				p["default"] = fieldDefaults.Interface()
+			}
+			props[name] = p
+		}
+		// Unknown keys are ignored by grove, which is exactly why editors
+		// should flag them: they're usually typos.
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	}
+	panic(fmt.Sprintf("config: no JSON Schema for %s", t))
+}
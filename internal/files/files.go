@@ -1,12 +1,17 @@
 package files
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/verbaux/grove/internal/trace"
 )
 
 // ErrSymlinkDestinationConflict indicates that symlink destination already exists
@@ -66,8 +71,11 @@ func FindEnvFiles(srcDir string) ([]string, error) {
 }
 
 // CopyEnvFiles copies all .env* files from srcDir to dstDir,
-// preserving the directory structure.
-func CopyEnvFiles(srcDir, dstDir string) ([]string, error) {
+// preserving the directory structure. If patterns are given, only files
+// whose name (not path) matches one of them are copied, so ".env.staging"
+// picks up apps/api/.env.staging too.
+func CopyEnvFiles(srcDir, dstDir string, patterns ...string) ([]string, error) {
+	defer trace.Begin("files", "copy .env files")()
 	files, err := FindEnvFiles(srcDir)
 	if err != nil {
 		return nil, err
@@ -75,6 +83,9 @@ func CopyEnvFiles(srcDir, dstDir string) ([]string, error) {
 
 	var copied []string
 	for _, rel := range files {
+		if len(patterns) > 0 && !MatchesAny(filepath.Base(rel), patterns) {
+			continue
+		}
 		src := filepath.Join(srcDir, rel)
 		dst := filepath.Join(dstDir, rel)
 
@@ -92,8 +103,9 @@ func CopyEnvFiles(srcDir, dstDir string) ([]string, error) {
 // Returns (false, nil) if src doesn't exist — caller can decide whether to warn.
 // Returns (false, err) if dst already exists but is not a symlink (conflict).
 func Symlink(srcDir, dstDir, name string) (bool, error) {
+	defer trace.Begin("files", "symlink "+name)()
 	src := filepath.Join(srcDir, name)
-	dst := filepath.Join(dstDir, name)
+	dst := longPath(filepath.Join(dstDir, name))
 
 	if info, err := os.Lstat(dst); err == nil {
 		// dst exists — only ok if it's already a symlink (idempotent)
@@ -106,7 +118,7 @@ func Symlink(srcDir, dstDir, name string) (bool, error) {
 	}
 
 	// src doesn't exist — skip silently (e.g. node_modules not yet installed)
-	if _, err := os.Stat(src); os.IsNotExist(err) {
+	if _, err := os.Stat(longPath(src)); os.IsNotExist(err) {
 		return false, nil
 	} else if err != nil {
 		return false, err
@@ -118,8 +130,139 @@ func Symlink(srcDir, dstDir, name string) (bool, error) {
 	return true, os.Symlink(src, dst)
 }
 
+// ErrDestinationExists indicates that HardlinkTree's destination already
+// exists in the worktree.
+var ErrDestinationExists = errors.New("destination already exists")
+
+// HardlinkTree recreates the directory srcDir/name at dstDir/name with every
+// regular file hard-linked rather than copied, the way pnpm does: nearly free
+// on disk, yet files added, deleted or replaced in one tree don't show up in
+// the other. Symlinks inside the tree are recreated as they are. Files on
+// another filesystem, where hard links are impossible, are copied instead.
+// Returns (true, nil) if the tree was created.
+// Returns (false, nil) if src doesn't exist — caller can decide whether to warn.
+// Returns (false, err) wrapping ErrDestinationExists if dst already exists.
+func HardlinkTree(srcDir, dstDir, name string) (bool, error) {
+	defer trace.Begin("files", "hardlink "+name)()
+	src := longPath(filepath.Join(srcDir, name))
+	dst := longPath(filepath.Join(dstDir, name))
+
+	if _, err := os.Lstat(dst); err == nil {
+		return false, fmt.Errorf("cannot hardlink %s: %w", name, ErrDestinationExists)
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			if err := os.Link(path, target); err == nil {
+				return nil
+			}
+			if err := copyFile(path, target); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm())
+		}
+		// Sockets, pipes and devices have no place in a dependency tree.
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DiskUsage returns the total size in bytes of the regular files under dir.
+// Symlinks aren't followed, so a shared node_modules counts once — in the
+// main worktree, not in every worktree linking to it.
+func DiskUsage(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// SetReadOnly removes (or, with readOnly false, restores) the write
+// permission on everything under dir, so nothing can be edited or added by
+// accident. The owner gets write permission back; group and others don't.
+// The worktree's .git file and symlinks are left alone, and so are the
+// top-level entries in skip — hard-linked trees share their files with the
+// main worktree, which must stay writable. On Windows only files can be made
+// read-only, not directories.
+func SetReadOnly(dir string, readOnly bool, skip []string) error {
+	skipped := map[string]bool{".git": true}
+	for _, name := range skip {
+		skipped[filepath.Clean(name)] = true
+	}
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if rel, _ := filepath.Rel(dir, path); skipped[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := info.Mode().Perm()
+		if readOnly {
+			mode &^= 0222
+		} else {
+			mode |= 0200
+		}
+		return os.Chmod(path, mode)
+	})
+}
+
 // copyFile copies a single file from src to dst, creating parent directories as needed.
 func copyFile(src, dst string) error {
+	src, dst = longPath(src), longPath(dst)
 	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 		return err
 	}
@@ -145,3 +288,267 @@ func copyFile(src, dst string) error {
 func isEnvFile(name string) bool {
 	return strings.HasPrefix(name, ".env")
 }
+
+// MatchesAny reports whether name matches one of the glob patterns.
+func MatchesAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyPaths copies files and directories matching the given glob patterns
+// from srcDir to dstDir. Patterns are relative to srcDir; directories are
+// copied recursively. Patterns that match nothing are skipped.
+// Returns the relative paths of all copied files.
+func CopyPaths(srcDir, dstDir string, patterns []string) ([]string, error) {
+	defer trace.Begin("files", "copy "+strings.Join(patterns, " "))()
+	var copied []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(srcDir, pattern))
+		if err != nil {
+			return copied, fmt.Errorf("copy pattern %q: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				rel, err := filepath.Rel(srcDir, path)
+				if err != nil {
+					return err
+				}
+				if err := copyFile(path, filepath.Join(dstDir, rel)); err != nil {
+					return err
+				}
+				copied = append(copied, rel)
+				return nil
+			})
+			if err != nil {
+				return copied, err
+			}
+		}
+	}
+	return copied, nil
+}
+
+// RenderSuffix is stripped from the name of a rendered template file.
+const RenderSuffix = ".tmpl"
+
+// RenderPaths renders the files matching the given glob patterns from srcDir
+// into dstDir: each file's content is passed through render and written to
+// the same relative path, minus a trailing RenderSuffix, keeping its mode.
+// Patterns that match nothing are skipped. Returns the relative paths of the
+// written files.
+func RenderPaths(srcDir, dstDir string, patterns []string, render func(string) string) ([]string, error) {
+	defer trace.Begin("files", "render "+strings.Join(patterns, " "))()
+	var rendered []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(srcDir, pattern))
+		if err != nil {
+			return rendered, fmt.Errorf("render pattern %q: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return rendered, err
+			}
+			if info.IsDir() {
+				return rendered, fmt.Errorf("render %s: is a directory", pattern)
+			}
+			data, err := os.ReadFile(match)
+			if err != nil {
+				return rendered, err
+			}
+			rel, err := filepath.Rel(srcDir, strings.TrimSuffix(match, RenderSuffix))
+			if err != nil {
+				return rendered, err
+			}
+			dst := longPath(filepath.Join(dstDir, rel))
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return rendered, err
+			}
+			if err := os.WriteFile(dst, []byte(render(string(data))), info.Mode().Perm()); err != nil {
+				return rendered, err
+			}
+			rendered = append(rendered, rel)
+		}
+	}
+	return rendered, nil
+}
+
+// SetEnvVars rewrites KEY=value lines in the env file at path, setting each
+// key in vars that the file already defines ("export KEY=" lines included).
+// With appendMissing, keys the file doesn't define are added at the end and
+// the file is created if needed. Returns the keys that were set.
+func SetEnvVars(path string, vars map[string]string, appendMissing bool) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !(appendMissing && errors.Is(err, os.ErrNotExist)) {
+		return nil, err
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	done := make(map[string]bool)
+	var set []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		exported := strings.HasPrefix(trimmed, "export ")
+		key, _, ok := strings.Cut(strings.TrimPrefix(trimmed, "export "), "=")
+		key = strings.TrimSpace(key)
+		value, want := vars[key]
+		if !ok || !want {
+			continue
+		}
+		lines[i] = key + "=" + value
+		if exported {
+			lines[i] = "export " + lines[i]
+		}
+		if !done[key] {
+			done[key] = true
+			set = append(set, key)
+		}
+	}
+
+	if appendMissing {
+		keys := make([]string, 0, len(vars))
+		for key := range vars {
+			if !done[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			lines = append(lines, key+"="+vars[key])
+			set = append(set, key)
+		}
+	}
+
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// ReadEnvFile parses a dotenv file into KEY=value entries, in file order,
+// ready for an exec.Cmd's Env. Blank lines and # comments are skipped, as is
+// a leading "export ". Values may be quoted: single quotes are taken
+// literally, double quotes understand \n, \" and \\. Unquoted values end at
+// " #", where a trailing comment starts.
+func ReadEnvFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value, got %q", path, i+1, line)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1])
+		default:
+			if comment := strings.Index(value, " #"); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+		}
+		env = append(env, key+"="+value)
+	}
+	return env, nil
+}
+
+// CaseInsensitive reports whether the filesystem holding dir ignores case
+// in file names, as it does by default on macOS and Windows. It creates a
+// probe file with a lower-case name and looks it up in upper case.
+func CaseInsensitive(dir string) (bool, error) {
+	f, err := os.CreateTemp(dir, ".grove-case-*")
+	if err != nil {
+		return false, err
+	}
+	probe := f.Name()
+	f.Close()
+	defer os.Remove(probe)
+
+	_, err = os.Stat(filepath.Join(dir, strings.ToUpper(filepath.Base(probe))))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// HashFile returns the hex SHA-256 of the file at path.
+func HashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return HashBytes(data), nil
+}
+
+// HashBytes returns the hex SHA-256 of data.
+func HashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// DiffEnv compares two versions of a dotenv file by key, for showing what
+// changed without printing values, which are often secrets. Each entry is
+// the key prefixed with "+" (added), "-" (removed) or "~" (changed), sorted
+// by key. Lines that aren't KEY=value are ignored.
+func DiffEnv(old, new []byte) []string {
+	before, after := envValues(old), envValues(new)
+	var diff []string
+	for key, value := range after {
+		if was, ok := before[key]; !ok {
+			diff = append(diff, "+"+key)
+		} else if was != value {
+			diff = append(diff, "~"+key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			diff = append(diff, "-"+key)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i][1:] < diff[j][1:] })
+	return diff
+}
+
+// envValues maps each key of a dotenv file to its raw value.
+func envValues(data []byte) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
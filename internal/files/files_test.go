@@ -4,6 +4,9 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -71,6 +74,31 @@ func TestCopyEnvFiles(t *testing.T) {
 	}
 }
 
+func TestCopyEnvFilesPatterns(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	touch(t, src, ".env")
+	touch(t, src, ".env.staging")
+	touch(t, src, ".env.production")
+	touch(t, src, "apps/api/.env.staging")
+	touch(t, src, "apps/api/.env.local")
+
+	copied, err := CopyEnvFiles(src, dst, ".env", ".env.staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".env", ".env.staging", filepath.Join("apps", "api", ".env.staging")}
+	sort.Strings(copied)
+	sort.Strings(want)
+	if strings.Join(copied, ",") != strings.Join(want, ",") {
+		t.Errorf("copied = %v, want %v", copied, want)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".env.production")); !os.IsNotExist(err) {
+		t.Error(".env.production should have been skipped")
+	}
+}
+
 func TestSymlink(t *testing.T) {
 	src := t.TempDir()
 	dst := t.TempDir()
@@ -94,6 +122,51 @@ func TestSymlink(t *testing.T) {
 	}
 }
 
+func TestHardlinkTree(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	pkg := filepath.Join(src, "node_modules", "pkg")
+	os.MkdirAll(filepath.Join(src, "node_modules", ".bin"), 0755)
+	os.MkdirAll(pkg, 0755)
+	os.WriteFile(filepath.Join(pkg, "index.js"), []byte("module.exports = 1\n"), 0644)
+	os.Symlink("../pkg/index.js", filepath.Join(src, "node_modules", ".bin", "pkg"))
+
+	created, err := HardlinkTree(src, dst, "node_modules")
+	if err != nil {
+		t.Fatal("HardlinkTree failed:", err)
+	}
+	if !created {
+		t.Error("expected created=true")
+	}
+
+	dstFile := filepath.Join(dst, "node_modules", "pkg", "index.js")
+	srcInfo, _ := os.Stat(filepath.Join(pkg, "index.js"))
+	dstInfo, err := os.Stat(dstFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Error("expected index.js to be a hard link to the original")
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "node_modules", ".bin", "pkg")); err != nil || link != "../pkg/index.js" {
+		t.Errorf("bin symlink = %q, %v; want it recreated as is", link, err)
+	}
+
+	// A file added on one side stays there.
+	os.WriteFile(filepath.Join(dst, "node_modules", "pkg", "postinstall.log"), []byte("ok"), 0644)
+	if _, err := os.Stat(filepath.Join(pkg, "postinstall.log")); !os.IsNotExist(err) {
+		t.Error("a file written in the worktree showed up in the main tree")
+	}
+
+	if _, err := HardlinkTree(src, dst, "node_modules"); !errors.Is(err, ErrDestinationExists) {
+		t.Errorf("second HardlinkTree error = %v, want ErrDestinationExists", err)
+	}
+	if created, err := HardlinkTree(src, dst, "missing"); created || err != nil {
+		t.Errorf("missing source = %v, %v; want false, nil", created, err)
+	}
+}
+
 func TestSymlinkIdempotent(t *testing.T) {
 	src := t.TempDir()
 	dst := t.TempDir()
@@ -141,6 +214,33 @@ func TestSymlinkConflict(t *testing.T) {
 	}
 }
 
+func TestCopyPaths(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	touch(t, src, "config/local.yml")
+	touch(t, src, "fixtures/a.json")
+	touch(t, src, "fixtures/nested/b.json")
+	touch(t, src, "README.md")
+
+	copied, err := CopyPaths(src, dst, []string{"config/*.yml", "fixtures", "nope/*"})
+	if err != nil {
+		t.Fatal("CopyPaths failed:", err)
+	}
+	if len(copied) != 3 {
+		t.Fatalf("expected 3 copied, got %d: %v", len(copied), copied)
+	}
+
+	for _, rel := range []string{"config/local.yml", "fixtures/a.json", "fixtures/nested/b.json"} {
+		if _, err := os.Stat(filepath.Join(dst, filepath.FromSlash(rel))); err != nil {
+			t.Errorf("expected %s to be copied: %v", rel, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "README.md")); !os.IsNotExist(err) {
+		t.Error("README.md should not have been copied")
+	}
+}
+
 // touch creates a file (and any needed parent dirs) with empty content.
 func touch(t *testing.T, dir string, rel string) {
 	t.Helper()
@@ -150,3 +250,190 @@ func touch(t *testing.T, dir string, rel string) {
 		t.Fatal(err)
 	}
 }
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	shared := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("12345"), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("123"), 0644)
+	os.WriteFile(filepath.Join(shared, "big.bin"), make([]byte, 1000), 0644)
+	os.Symlink(shared, filepath.Join(dir, "node_modules"))
+
+	got, err := DiskUsage(dir)
+	if err != nil {
+		t.Fatal("DiskUsage failed:", err)
+	}
+	// The symlinked directory isn't followed.
+	if got != 8 {
+		t.Errorf("DiskUsage = %d, want 8", got)
+	}
+}
+
+func TestSetEnvVars(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	os.WriteFile(path, []byte("PORT=3000\nDB_NAME=app\nexport DATABASE_URL=postgres://localhost/app\n"), 0644)
+
+	vars := map[string]string{"DB_NAME": "app_auth", "DATABASE_URL": "postgres://localhost/app_auth", "DB_USER": "dev"}
+
+	set, err := SetEnvVars(path, vars, false)
+	if err != nil {
+		t.Fatal("SetEnvVars failed:", err)
+	}
+	if len(set) != 2 {
+		t.Errorf("set = %v, want DB_NAME and DATABASE_URL", set)
+	}
+	data, _ := os.ReadFile(path)
+	want := "PORT=3000\nDB_NAME=app_auth\nexport DATABASE_URL=postgres://localhost/app_auth\n"
+	if string(data) != want {
+		t.Errorf("content = %q, want %q", data, want)
+	}
+
+	// appendMissing adds keys the file doesn't have, and creates files.
+	other := filepath.Join(dir, "new", ".env")
+	os.MkdirAll(filepath.Dir(other), 0755)
+	if _, err := SetEnvVars(other, map[string]string{"DB_USER": "dev"}, true); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(other)
+	if string(data) != "DB_USER=dev\n" {
+		t.Errorf("created content = %q", data)
+	}
+}
+
+func TestRenderPaths(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "deploy"), 0755)
+	os.WriteFile(filepath.Join(src, "docker-compose.override.yml.tmpl"), []byte("name: app-{{alias}}\n"), 0644)
+	os.WriteFile(filepath.Join(src, "deploy", "run.sh.tmpl"), []byte("echo {{alias}}\n"), 0755)
+
+	render := func(s string) string { return strings.ReplaceAll(s, "{{alias}}", "auth") }
+	rendered, err := RenderPaths(src, dst, []string{"*.tmpl", "deploy/*.tmpl", "missing/*"}, render)
+	if err != nil {
+		t.Fatal("RenderPaths failed:", err)
+	}
+	if len(rendered) != 2 {
+		t.Fatalf("rendered = %v, want 2 files", rendered)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dst, "docker-compose.override.yml"))
+	if string(data) != "name: app-auth\n" {
+		t.Errorf("content = %q", data)
+	}
+	info, err := os.Stat(filepath.Join(dst, "deploy", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("mode = %v, want the template's executable bit kept", info.Mode())
+	}
+}
+
+func TestSetReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	shared := t.TempDir()
+
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: elsewhere\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "node_modules"), 0755)
+	os.WriteFile(filepath.Join(dir, "node_modules", "linked.js"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(shared, "shared.txt"), []byte("x"), 0644)
+	os.Symlink(shared, filepath.Join(dir, "vendor"))
+
+	perm := func(path string) os.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	if err := SetReadOnly(dir, true, []string{"node_modules"}); err != nil {
+		t.Fatal("SetReadOnly failed:", err)
+	}
+	t.Cleanup(func() { SetReadOnly(dir, false, nil) })
+
+	for _, path := range []string{dir, filepath.Join(dir, "src"), filepath.Join(dir, "src", "main.go")} {
+		if perm(path)&0222 != 0 {
+			t.Errorf("%s is still writable: %v", path, perm(path))
+		}
+	}
+	// .git, skipped trees and whatever symlinks point at stay writable.
+	for _, path := range []string{filepath.Join(dir, ".git"), filepath.Join(dir, "node_modules", "linked.js"), filepath.Join(shared, "shared.txt")} {
+		if perm(path)&0200 == 0 {
+			t.Errorf("%s should be left alone: %v", path, perm(path))
+		}
+	}
+
+	if err := SetReadOnly(dir, false, []string{"node_modules"}); err != nil {
+		t.Fatal("SetReadOnly(false) failed:", err)
+	}
+	if got := perm(filepath.Join(dir, "src", "main.go")); got != 0644 {
+		t.Errorf("main.go = %v after unlocking, want 0644", got)
+	}
+	if got := perm(filepath.Join(dir, "src")); got != 0755 {
+		t.Errorf("src = %v after unlocking, want 0755", got)
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# database
+DATABASE_URL=postgres://localhost/app
+export API_KEY="abc\"123"
+GREETING='hello # not a comment'
+PORT=3000 # dev port
+MULTI="a\nb"
+EMPTY=
+`
+	os.WriteFile(path, []byte(content), 0644)
+
+	got, err := ReadEnvFile(path)
+	if err != nil {
+		t.Fatal("ReadEnvFile failed:", err)
+	}
+	want := []string{
+		"DATABASE_URL=postgres://localhost/app",
+		`API_KEY=abc"123`,
+		"GREETING=hello # not a comment",
+		"PORT=3000",
+		"MULTI=a\nb",
+		"EMPTY=",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ReadEnvFile = %q, want %q", got, want)
+	}
+
+	os.WriteFile(path, []byte("NOT A VAR\n"), 0644)
+	if _, err := ReadEnvFile(path); err == nil {
+		t.Error("expected an error for a line without =")
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	dir := t.TempDir()
+	insensitive, err := CaseInsensitive(dir)
+	if err != nil {
+		t.Fatal("CaseInsensitive failed:", err)
+	}
+	if runtime.GOOS == "linux" && insensitive {
+		t.Error("expected the Linux temp dir to be case-sensitive")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("probe file left behind: %v", entries)
+	}
+}
+
+func TestDiffEnv(t *testing.T) {
+	old := []byte("# db\nA=1\nB=2\nexport D=4\n")
+	new := []byte("A=1\nB=3\nC=3\n")
+	got := strings.Join(DiffEnv(old, new), " ")
+	if want := "~B +C -D"; got != want {
+		t.Errorf("DiffEnv = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package files
+
+import "strings"
+
+// maxPath is the length from which Windows APIs reject a path that lacks
+// the \\?\ prefix. MAX_PATH is 260, but directories must leave room for an
+// 8.3 file name, so CreateDirectory gives up at 248.
+const maxPath = 248
+
+// extendedLength returns a long absolute Windows path in its \\?\ form,
+// which lifts the MAX_PATH limit: C:\deep\... becomes \\?\C:\deep\... and
+// a UNC share \\server\share\... becomes \\?\UNC\server\share\.... Short,
+// relative and already prefixed paths are returned unchanged. The path
+// must be clean, since Windows doesn't resolve "." or ".." after \\?\.
+func extendedLength(path string) string {
+	if len(path) < maxPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	path = strings.ReplaceAll(path, "/", `\`)
+	if share, ok := strings.CutPrefix(path, `\\`); ok {
+		return `\\?\UNC\` + share
+	}
+	if len(path) >= 3 && path[1] == ':' && path[2] == '\\' {
+		return `\\?\` + path
+	}
+	return path
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package files
+
+// longPath returns path unchanged; only Windows limits path length this
+// way. See longpath_windows.go.
+func longPath(path string) string {
+	return path
+}
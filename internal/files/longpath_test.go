@@ -0,0 +1,25 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtendedLength(t *testing.T) {
+	deep := strings.Repeat(`\segment`, 40)
+	tests := []struct {
+		path, want string
+	}{
+		{`C:\code\shop`, `C:\code\shop`},
+		{`C:\code` + deep, `\\?\C:\code` + deep},
+		{`C:/code` + strings.ReplaceAll(deep, `\`, "/"), `\\?\C:\code` + deep},
+		{`\\server\share` + deep, `\\?\UNC\server\share` + deep},
+		{`\\?\C:\code` + deep, `\\?\C:\code` + deep},
+		{`code` + deep, `code` + deep},
+	}
+	for _, tt := range tests {
+		if got := extendedLength(tt.path); got != tt.want {
+			t.Errorf("extendedLength(%.20q…) = %.30q…, want %.30q…", tt.path, got, tt.want)
+		}
+	}
+}
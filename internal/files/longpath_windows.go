@@ -0,0 +1,12 @@
+package files
+
+import "path/filepath"
+
+// longPath returns path in a form Windows accepts past MAX_PATH. Worktree
+// prefixes on top of deep monorepo paths regularly cross it.
+func longPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return extendedLength(path)
+}
@@ -0,0 +1,58 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// deepName is a relative path well past MAX_PATH once joined to a temp dir.
+var deepName = strings.Repeat("monorepo-package-directory\\", 12) + "config"
+
+func TestCopyPathsLongPath(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	touch(t, src, deepName+"\\local.yml")
+
+	copied, err := CopyPaths(src, dst, []string{"monorepo-package-directory"})
+	if err != nil {
+		t.Fatal("CopyPaths failed:", err)
+	}
+	if len(copied) != 1 {
+		t.Fatalf("expected 1 copied, got %d: %v", len(copied), copied)
+	}
+	if _, err := os.Stat(filepath.Join(dst, deepName, "local.yml")); err != nil {
+		t.Errorf("expected the deep file to be copied: %v", err)
+	}
+}
+
+func TestSymlinkLongPath(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	touch(t, src, deepName+"\\node_modules\\.keep")
+
+	created, err := Symlink(src, dst, filepath.Join(deepName, "node_modules"))
+	if err != nil {
+		if strings.Contains(err.Error(), "privilege") {
+			t.Skip("creating symlinks needs Developer Mode or an elevated shell")
+		}
+		t.Fatal("Symlink failed:", err)
+	}
+	if !created {
+		t.Fatal("expected the symlink to be created")
+	}
+	info, err := os.Lstat(filepath.Join(dst, deepName, "node_modules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("expected node_modules to be a symlink")
+	}
+}
+
+func TestExtendedLengthUNC(t *testing.T) {
+	if got := longPath(`\\server\share\` + deepName); !strings.HasPrefix(got, `\\?\UNC\server\share\`) {
+		t.Errorf("longPath kept a long UNC path as %.40q…", got)
+	}
+}
@@ -1,16 +1,133 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/verbaux/grove/internal/trace"
 )
 
+// ErrDirty is returned when git refuses to remove a worktree because it has
+// uncommitted changes. Pass force=true to RemoveWorktree to override.
+var ErrDirty = errors.New("worktree has uncommitted changes")
+
+// ErrLocked is returned when git refuses to remove a worktree locked with
+// LockWorktree. UnlockWorktree it first.
+var ErrLocked = errors.New("worktree is locked")
+
+// Progress lets long-running commands (currently worktree add) write git's
+// own progress output straight to the terminal instead of capturing it.
+// Only enable it when stderr is a TTY — git decides whether to draw progress
+// by checking its own stderr, so it must be the real terminal.
+var Progress bool
+
+// FastStatus makes QuickStatus skip untracked files, which dominate git
+// status in very large repositories. Set from "statusMode" in the config.
+var FastStatus bool
+
+// binary, globalArgs and extraEnv control how every git process is started.
+// Set them once at startup with Configure.
+var (
+	binary     = "git"
+	globalArgs []string
+	extraEnv   []string
+)
+
+// Configure overrides the git executable and adds global args (placed before
+// the subcommand, e.g. "-c", "protocol.version=2") and environment variables
+// ("KEY=value") to every git invocation. An empty bin keeps "git".
+func Configure(bin string, args, env []string) {
+	if bin == "" {
+		bin = "git"
+	}
+	binary = bin
+	globalArgs = args
+	extraEnv = env
+}
+
+// Binary returns the configured git executable.
+func Binary() string {
+	return binary
+}
+
+// GlobalArgs returns the configured global args placed before every subcommand.
+func GlobalArgs() []string {
+	return globalArgs
+}
+
+// Version returns the output of `git --version`, e.g. "git version 2.43.0".
+func Version() (string, error) {
+	return run("--version")
+}
+
+// repoEnvVars are variables that make git operate on a specific repository
+// regardless of the working directory. Grove always locates the repo from
+// the directory it runs in, so these are scrubbed from every child process —
+// otherwise a GIT_DIR exported by a script or git hook would silently point
+// grove's commands at the wrong repo.
+var repoEnvVars = map[string]bool{
+	"GIT_DIR":              true,
+	"GIT_WORK_TREE":        true,
+	"GIT_COMMON_DIR":       true,
+	"GIT_INDEX_FILE":       true,
+	"GIT_OBJECT_DIRECTORY": true,
+	"GIT_PREFIX":           true,
+}
+
+// Environ returns the current environment without repoEnvVars.
+// Use it for any child process that may run git, including user hooks.
+func Environ() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if repoEnvVars[key] {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// ScrubbedVars returns the names of repoEnvVars set in the current
+// environment, sorted — i.e. what Environ is hiding from git.
+func ScrubbedVars() []string {
+	var names []string
+	for name := range repoEnvVars {
+		if _, ok := os.LookupEnv(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// command builds an *exec.Cmd for git with the configured binary, global
+// args and environment. All git processes must be created through here.
+// Variables from Configure are applied after scrubbing, so setting GIT_DIR
+// there explicitly is still honored.
+func command(args ...string) *exec.Cmd {
+	cmd := exec.Command(binary, append(append(append([]string{}, platformArgs...), globalArgs...), args...)...)
+	cmd.Env = append(Environ(), extraEnv...)
+	return cmd
+}
+
+// traced starts a trace span for a git call; see grove --trace.
+func traced(args []string) func() {
+	return trace.Begin("git", strings.Join(args, " "))
+}
+
 // run executes a git command and returns its stdout.
 // All git operations go through this — one place to debug if something breaks.
 func run(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
+	defer traced(args)()
+	cmd := command(args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
@@ -18,11 +135,29 @@ func run(args ...string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// runAttached executes a git command with stdout/stderr connected to the
+// terminal, so git's progress output is visible. The output isn't captured,
+// so errors only carry the exit status — git has already printed the reason.
+func runAttached(args ...string) error {
+	defer traced(args)()
+	cmd := command(args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
 // Worktree holds info about a single worktree from `git worktree list`.
 type Worktree struct {
-	Path   string
-	Branch string
-	IsMain bool
+	Path       string
+	Branch     string
+	Head       string // commit checked out, full SHA
+	IsMain     bool
+	Locked     bool   // `git worktree lock`: prune and remove leave it alone
+	LockReason string // as given to git worktree lock --reason, may be empty
+	Prunable   bool   // its directory is gone; `git worktree prune` would drop it
 }
 
 // AddWorktree creates a new worktree. If the branch doesn't exist, it creates it.
@@ -34,24 +169,233 @@ func AddWorktree(path, branch, from string) error {
 		return err
 	}
 
-	if branchExists(branch) {
-		_, err = run("worktree", "add", absPath, branch)
+	// "--" keeps a branch or from starting with "-" from being read as an option.
+	var args []string
+	if BranchExists(branch) {
+		args = []string{"worktree", "add", "--", absPath, branch}
 	} else if from != "" {
-		_, err = run("worktree", "add", "-b", branch, absPath, from)
+		args = []string{"worktree", "add", "-b", branch, "--", absPath, from}
 	} else {
-		_, err = run("worktree", "add", "-b", branch, absPath)
+		args = []string{"worktree", "add", "-b", branch, "--", absPath}
+	}
+
+	if Progress {
+		return runAttached(args...)
+	}
+	_, err = run(args...)
+	return err
+}
+
+// AddDetachedWorktree creates a worktree at path with a detached HEAD at
+// commit, without creating a branch.
+func AddDetachedWorktree(path, commit string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	args := []string{"worktree", "add", "--detach", absPath, commit}
+	if Progress {
+		return runAttached(args...)
 	}
+	_, err = run(args...)
 	return err
 }
 
+// SwitchWorktree checks out branch in the worktree at path, creating it
+// from from — or from the current HEAD when from is empty — if it doesn't
+// exist yet. It's AddWorktree for a worktree that's already there.
+func SwitchWorktree(path, branch, from string) error {
+	if BranchExists(branch) {
+		_, err := run("-C", path, "switch", branch)
+		return err
+	}
+	if from == "" {
+		// Resolve here: in the worktree, HEAD is whatever it has checked out.
+		head, err := run("rev-parse", "HEAD")
+		if err != nil {
+			return err
+		}
+		from = head
+	}
+	_, err := run("-C", path, "switch", "-c", branch, from)
+	return err
+}
+
+// ResolveCommit returns the commit ref points to.
+func ResolveCommit(ref string) (string, error) {
+	return run("rev-parse", "--verify", ref+"^{commit}")
+}
+
+// FetchRef fetches a single ref from remote and returns the commit it
+// points to, e.g. FetchRef("origin", "pull/12/head"). ref is never read as
+// an option, so it may come from a file in the repository.
+func FetchRef(remote, ref string) (string, error) {
+	if _, err := run("fetch", remote, "--", ref); err != nil {
+		return "", err
+	}
+	return run("rev-parse", "FETCH_HEAD")
+}
+
 // RemoveWorktree removes a worktree by path.
 // Pass force=true to remove even if there are uncommitted changes.
 func RemoveWorktree(path string, force bool) error {
+	args := []string{"worktree", "remove", path}
 	if force {
-		_, err := run("worktree", "remove", "--force", path)
-		return err
+		args = []string{"worktree", "remove", "--force", path}
+	}
+	_, err := run(args...)
+	switch {
+	case err == nil:
+		return nil
+	case strings.Contains(err.Error(), "locked working tree"):
+		return fmt.Errorf("%w: %v", ErrLocked, err)
+	case !force && strings.Contains(err.Error(), "contains modified or untracked files"):
+		return fmt.Errorf("%w: %v", ErrDirty, err)
+	}
+	return err
+}
+
+// LockWorktree locks the worktree at path, so git worktree prune and remove
+// leave it alone while its directory is unavailable. reason may be empty.
+func LockWorktree(path, reason string) error {
+	args := []string{"worktree", "lock", path}
+	if reason != "" {
+		args = []string{"worktree", "lock", "--reason", reason, path}
 	}
-	_, err := run("worktree", "remove", path)
+	_, err := run(args...)
+	return err
+}
+
+// UnlockWorktree undoes LockWorktree.
+func UnlockWorktree(path string) error {
+	_, err := run("worktree", "unlock", path)
+	return err
+}
+
+// Stash saves all uncommitted changes in the worktree at path, including
+// untracked files, to the repository's stash list with the given message.
+// The stash is shared by all worktrees, so it survives removing this one.
+func Stash(path, message string) error {
+	_, err := run("-C", path, "stash", "push", "--include-untracked", "-m", message)
+	return err
+}
+
+// Snapshot records everything in the worktree at path — tracked changes and
+// untracked (non-ignored) files — as a commit on top of HEAD and points ref
+// at it, without touching the worktree, its index or the stash list.
+// The ref keeps a reflog, so earlier snapshots under the same name survive.
+// Returns the snapshot commit hash.
+func Snapshot(path, ref, message string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "grove-snapshot-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Stage into a throwaway index so the worktree's real index is untouched.
+	indexEnv := "GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")
+	runWithIndex := func(args ...string) (string, error) {
+		defer traced(args)()
+		cmd := command(args...)
+		cmd.Env = append(cmd.Env, indexEnv)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	if _, err := runWithIndex("-C", path, "add", "--all"); err != nil {
+		return "", err
+	}
+	tree, err := runWithIndex("-C", path, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := run("-C", path, "commit-tree", tree, "-p", "HEAD", "-m", message)
+	if err != nil {
+		return "", err
+	}
+	if _, err := run("-C", path, "update-ref", "--create-reflog", "-m", message, ref, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// CreateBundle writes branch to a bundle file. If exclude is set, commits
+// reachable from it are left out (`git bundle create file branch --not exclude`),
+// keeping the bundle small.
+func CreateBundle(file, branch, exclude string) error {
+	args := []string{"bundle", "create", file, "refs/heads/" + branch}
+	if exclude != "" {
+		args = append(args, "--not", exclude)
+	}
+	_, err := run(args...)
+	return err
+}
+
+// FetchBundle restores branch from a bundle file created by CreateBundle.
+func FetchBundle(file, branch string) error {
+	_, err := run("fetch", file, "refs/heads/"+branch+":refs/heads/"+branch)
+	return err
+}
+
+// Diff returns a binary-safe patch between two commits, as seen from the
+// worktree at path. The output is returned verbatim — patches need their
+// trailing newline, so this doesn't go through run.
+func Diff(path, from, to string) ([]byte, error) {
+	defer traced([]string{"-C", path, "diff", "--binary", from, to})()
+	cmd := command("-C", path, "diff", "--binary", from, to)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s %s: %w", from, to, err)
+	}
+	return out, nil
+}
+
+// ApplyPatch applies a patch file to the worktree at path, leaving the
+// changes unstaged.
+func ApplyPatch(path, patchFile string) error {
+	_, err := run("-C", path, "apply", "--binary", patchFile)
+	return err
+}
+
+// ApplyStash applies a stash entry (e.g. "stash@{0}") to the worktree at
+// path without dropping it. The stash list is shared by all worktrees.
+func ApplyStash(path, ref string) error {
+	_, err := run("-C", path, "stash", "apply", ref)
+	return err
+}
+
+// FindStash returns the ref of the newest stash whose message contains
+// message, or "" if none does.
+func FindStash(message string) (string, error) {
+	out, err := run("stash", "list", "--format=%gd%x00%gs")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		ref, subject, ok := strings.Cut(line, "\x00")
+		if ok && strings.Contains(subject, message) {
+			return ref, nil
+		}
+	}
+	return "", nil
+}
+
+// SparseCheckout restricts the worktree at path to the given directories
+// using cone-mode sparse-checkout. Top-level files are always kept.
+func SparseCheckout(path string, dirs []string) error {
+	args := append([]string{"-C", path, "sparse-checkout", "set", "--cone"}, dirs...)
+	_, err := run(args...)
+	return err
+}
+
+// PushUpstream pushes branch from the worktree at path to remote and sets it
+// as the branch's upstream (`git push -u <remote> <branch>`).
+func PushUpstream(path, remote, branch string) error {
+	_, err := run("-C", path, "push", "-u", remote, branch)
 	return err
 }
 
@@ -64,116 +408,600 @@ func PruneWorktrees() error {
 // ListWorktrees parses output of `git worktree list` into structured data.
 // The first entry is always the main worktree.
 func ListWorktrees() ([]Worktree, error) {
-	// --porcelain gives machine-readable output, one key-value pair per line,
-	// worktrees separated by blank lines.
-	out, err := run("worktree", "list", "--porcelain")
+	// --porcelain -z gives machine-readable output, one NUL-terminated
+	// key-value pair per attribute, worktrees separated by an empty one — safe
+	// for paths and lock reasons containing newlines. Git before 2.36 has no
+	// -z; fall back to the newline-separated form there.
+	args := []string{"worktree", "list", "--porcelain", "-z"}
+	end := traced(args)
+	out, err := command(args...).Output()
+	end()
+	if err == nil {
+		return parseWorktrees(string(out), "\x00"), nil
+	}
+	text, err := run("worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, err
 	}
+	return parseWorktrees(text, "\n"), nil
+}
 
+// parseWorktrees parses `git worktree list --porcelain` output whose
+// attributes are terminated by sep.
+func parseWorktrees(out, sep string) []Worktree {
 	var worktrees []Worktree
 	var current Worktree
-	var currentHead string
 	var detached bool
 
-	for _, line := range strings.Split(out, "\n") {
-		switch {
-		case strings.HasPrefix(line, "worktree "):
-			current.Path = strings.TrimPrefix(line, "worktree ")
-		case strings.HasPrefix(line, "HEAD "):
-			currentHead = strings.TrimPrefix(line, "HEAD ")
-		case strings.HasPrefix(line, "branch refs/heads/"):
-			current.Branch = strings.TrimPrefix(line, "branch refs/heads/")
-		case line == "detached":
-			detached = true
-		case line == "":
-			// Blank line = end of one worktree entry
-			if current.Path != "" {
-				if detached && current.Branch == "" {
-					// Show short commit hash so the user knows where they are
-					if len(currentHead) >= 7 {
-						current.Branch = "(detached " + currentHead[:7] + ")"
-					} else {
-						current.Branch = "(detached)"
-					}
+	flush := func() {
+		if current.Path != "" {
+			if detached && current.Branch == "" {
+				// Show short commit hash so the user knows where they are
+				if len(current.Head) >= 7 {
+					current.Branch = "(detached " + current.Head[:7] + ")"
+				} else {
+					current.Branch = "(detached)"
 				}
-				worktrees = append(worktrees, current)
-			}
-			current = Worktree{}
-			currentHead = ""
-			detached = false
-		}
-	}
-	// Last entry (no trailing blank line)
-	if current.Path != "" {
-		if detached && current.Branch == "" {
-			if len(currentHead) >= 7 {
-				current.Branch = "(detached " + currentHead[:7] + ")"
-			} else {
-				current.Branch = "(detached)"
 			}
+			worktrees = append(worktrees, current)
+		}
+		current = Worktree{}
+		detached = false
+	}
+
+	for _, line := range strings.Split(out, sep) {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			current.Path = value
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			detached = true
+		case "locked":
+			current.Locked = true
+			current.LockReason = value
+		case "prunable":
+			current.Prunable = true
+		case "":
+			// Empty attribute = end of one worktree entry
+			flush()
 		}
-		worktrees = append(worktrees, current)
 	}
+	// Last entry (no trailing separator)
+	flush()
 
 	// First worktree in git's output is always the main one
 	if len(worktrees) > 0 {
 		worktrees[0].IsMain = true
 	}
+	return worktrees
+}
 
-	return worktrees, nil
+// WorktreeStatus counts the uncommitted changes in a worktree, as reported
+// by git status.
+type WorktreeStatus struct {
+	Conflicted int // unmerged files: a merge, rebase or cherry-pick is in progress
+	Staged     int
+	Renamed    int
+	Copied     int
+	Modified   int
+	Untracked  int
 }
 
-// Status returns a short status summary for a worktree path.
-// Returns "clean" or a breakdown like "2 staged, 1 modified, 3 untracked".
-func Status(worktreePath string) (string, error) {
-	cmd := exec.Command("git", "-C", worktreePath, "status", "--porcelain")
+// Clean reports whether there are no changes at all.
+func (s WorktreeStatus) Clean() bool {
+	return s == WorktreeStatus{}
+}
+
+// String returns "clean" or a breakdown like "2 staged, 1 modified, 3 untracked".
+func (s WorktreeStatus) String() string {
+	var parts []string
+	if s.Conflicted == 1 {
+		parts = append(parts, "1 conflict")
+	} else if s.Conflicted > 1 {
+		parts = append(parts, fmt.Sprintf("%d conflicts", s.Conflicted))
+	}
+	for _, c := range []struct {
+		n    int
+		what string
+	}{
+		{s.Staged, "staged"},
+		{s.Renamed, "renamed"},
+		{s.Copied, "copied"},
+		{s.Modified, "modified"},
+		{s.Untracked, "untracked"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.what))
+		}
+	}
+	if len(parts) == 0 {
+		return "clean"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Status counts the uncommitted changes in the worktree at worktreePath.
+// Untracked files are always counted, so it's safe for deciding whether a
+// worktree can be removed.
+func Status(worktreePath string) (WorktreeStatus, error) {
+	return status(worktreePath, true)
+}
+
+// QuickStatus is Status for display, e.g. in grove list. With FastStatus
+// set it leaves out untracked files — unless the repository has fsmonitor or
+// the untracked cache configured, which make finding them cheap.
+func QuickStatus(worktreePath string) (WorktreeStatus, error) {
+	return status(worktreePath, !FastStatus || cheapUntracked(worktreePath))
+}
+
+// cheapUntracked reports whether the repository at path has core.fsmonitor
+// or core.untrackedCache turned on.
+func cheapUntracked(path string) bool {
+	out, err := run("-C", path, "config", "--get-regexp", `^core\.(fsmonitor|untrackedcache)$`)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(out, "\n") {
+		_, value, _ := strings.Cut(line, " ")
+		if value != "" && value != "false" && value != "no" && value != "off" && value != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+func status(worktreePath string, withUntracked bool) (WorktreeStatus, error) {
+	// --no-optional-locks: don't refresh the index behind the user's back.
+	// grove list runs from prompts and watch loops; taking index.lock there
+	// would make an IDE's concurrent git commands fail with "index.lock exists".
+	args := []string{"--no-optional-locks", "-C", worktreePath, "status", "--porcelain"}
+	if !withUntracked {
+		args = append(args, "--untracked-files=no")
+	}
+	defer traced(args)()
+	cmd := command(args...)
 	out, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("git status in %s: %w", worktreePath, err)
+		return WorktreeStatus{}, fmt.Errorf("git status in %s: %w", worktreePath, err)
 	}
 
 	// git status --porcelain: each line starts with two status chars XY.
 	// X = staging area, Y = working tree.
-	// "??" = untracked file.
+	// "??" = untracked file; U on either side, AA or DD = unmerged (conflict);
+	// R and C = renamed and copied.
 	// We split on newlines and skip empty lines — do NOT TrimSpace on the whole
 	// output, as leading spaces in lines like " M file.txt" are meaningful status chars.
-	var staged, modified, untracked int
+	var st WorktreeStatus
 	for _, line := range strings.Split(string(out), "\n") {
 		if len(line) < 2 {
 			continue
 		}
 		x, y := line[0], line[1]
 		if x == '?' && y == '?' {
-			untracked++
+			st.Untracked++
+			continue
+		}
+		if x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D') {
+			st.Conflicted++
 			continue
 		}
-		if x != ' ' {
-			staged++
+		switch x {
+		case ' ':
+		case 'R':
+			st.Renamed++
+		case 'C':
+			st.Copied++
+		default:
+			st.Staged++
 		}
 		if y != ' ' {
-			modified++
+			st.Modified++
 		}
 	}
+	return st, nil
+}
 
-	if staged == 0 && modified == 0 && untracked == 0 {
-		return "clean", nil
+// StatusKey returns a cheap fingerprint of a worktree's HEAD and index, read
+// straight from the git directory without running git. It changes on
+// checkout, commit, add, reset and the like — but not on plain edits to
+// tracked files, so callers caching Status must also bound the cache's age.
+func StatusKey(worktreePath string) (string, error) {
+	gitDir := filepath.Join(worktreePath, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	// Linked worktrees have a .git file pointing at their git directory.
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return "", err
+		}
+		dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return "", fmt.Errorf("%s: not a gitdir file", gitDir)
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(worktreePath, dir)
+		}
+		gitDir = dir
 	}
 
-	var parts []string
-	if staged > 0 {
-		parts = append(parts, fmt.Sprintf("%d staged", staged))
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(head))
+	// A fresh repo may not have an index yet — that's still a valid state.
+	if index, err := os.Stat(filepath.Join(gitDir, "index")); err == nil {
+		key += fmt.Sprintf(" %d %d", index.ModTime().UnixNano(), index.Size())
+	}
+	return key, nil
+}
+
+// MoveWorktree moves a worktree to a new path with `git worktree move`.
+func MoveWorktree(oldPath, newPath string) error {
+	_, err := run("worktree", "move", oldPath, newPath)
+	return err
+}
+
+// RenameBranch renames a local branch. Git updates any worktree that has it
+// checked out, and moves the branch's config section (including upstream).
+func RenameBranch(oldName, newName string) error {
+	_, err := run("branch", "-m", oldName, newName)
+	return err
+}
+
+// DeleteBranch deletes a local branch. With force it goes even if git
+// doesn't consider it merged (git branch -D).
+func DeleteBranch(branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := run("branch", flag, branch)
+	return err
+}
+
+// RetargetUpstream points a branch's upstream at the same-named branch on its
+// existing remote, so the next `git push` publishes under the new name.
+// Returns the remote name, or "" if the branch has no upstream configured.
+func RetargetUpstream(branch string) (string, error) {
+	remote, err := run("config", "--get", "branch."+branch+".remote")
+	if err != nil || remote == "" {
+		return "", nil
+	}
+	if _, err := run("config", "branch."+branch+".merge", "refs/heads/"+branch); err != nil {
+		return "", err
+	}
+	return remote, nil
+}
+
+// BranchDescription returns branch.<branch>.description, as set by
+// SetBranchDescription or 'git branch --edit-description', or "".
+func BranchDescription(branch string) string {
+	desc, err := run("config", "--get", "branch."+branch+".description")
+	if err != nil {
+		return ""
+	}
+	return desc
+}
+
+// SetBranchDescription records desc as the branch's description in the
+// repository's config, where it outlives grove's state.
+func SetBranchDescription(branch, desc string) error {
+	_, err := run("config", "branch."+branch+".description", desc)
+	return err
+}
+
+// BranchDescriptions returns the description of every branch that has one,
+// by branch name.
+func BranchDescriptions() (map[string]string, error) {
+	args := []string{"config", "-z", "--get-regexp", `^branch\..*\.description$`}
+	end := traced(args)
+	out, err := command(args...).Output()
+	end()
+	descs := map[string]string{}
+	if err != nil {
+		// Exit code 1 means no branch has one.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return descs, nil
+		}
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	// -z: "key\nvalue" items, NUL-terminated, so descriptions may span lines.
+	for _, item := range strings.Split(string(out), "\x00") {
+		key, value, _ := strings.Cut(item, "\n")
+		name, ok := strings.CutPrefix(key, "branch.")
+		if name, found := strings.CutSuffix(name, ".description"); ok && found {
+			descs[name] = strings.TrimSpace(value)
+		}
+	}
+	return descs, nil
+}
+
+// CurrentBranch returns the branch checked out in the current directory,
+// or "HEAD" when detached.
+func CurrentBranch() (string, error) {
+	return run("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// BranchAt is CurrentBranch for the worktree containing dir.
+func BranchAt(dir string) (string, error) {
+	return run("-C", dir, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// Toplevel returns the root of the worktree containing dir.
+func Toplevel(dir string) (string, error) {
+	return run("-C", dir, "rev-parse", "--show-toplevel")
+}
+
+// LastCommitTime returns the committer date of HEAD in the worktree at path.
+func LastCommitTime(path string) (time.Time, error) {
+	out, err := run("-C", path, "log", "-1", "--format=%ct")
+	if err != nil {
+		return time.Time{}, err
+	}
+	unix, err := strconv.ParseInt(out, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected git log output %q", out)
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// AheadCount returns how many commits HEAD of the worktree at path has
+// that base doesn't (`git rev-list --count base..HEAD`).
+func AheadCount(path, base string) (int, error) {
+	out, err := run("-C", path, "rev-list", "--count", base+"..HEAD")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// UnpushedCount returns how many commits HEAD of the worktree at path has
+// that are on no remote and on no other local branch — work that exists
+// only on branch. An empty branch means a detached HEAD.
+func UnpushedCount(path, branch string) (int, error) {
+	args := []string{"-C", path, "rev-list", "--count", "HEAD", "--not", "--remotes"}
+	if branch != "" {
+		args = append(args, "--exclude="+branch)
+	}
+	out, err := run(append(args, "--branches")...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// AheadBehind returns how many commits HEAD of the worktree at path has that
+// ref doesn't, and the reverse (`git rev-list --left-right --count HEAD...ref`).
+func AheadBehind(path, ref string) (ahead, behind int, err error) {
+	out, err := run("-C", path, "rev-list", "--left-right", "--count", "HEAD..."+ref)
+	if err != nil {
+		return 0, 0, err
+	}
+	a, b, ok := strings.Cut(out, "\t")
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	if ahead, err = strconv.Atoi(a); err != nil {
+		return 0, 0, err
+	}
+	if behind, err = strconv.Atoi(b); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// BranchInfo is what BatchBranchInfo reports about one local branch.
+type BranchInfo struct {
+	Upstream  string    // e.g. "origin/feature/auth", "" if none is configured
+	Ahead     int       // commits the branch has that its upstream doesn't
+	Behind    int       // commits the upstream has that the branch doesn't
+	Gone      bool      // the upstream is configured but was deleted, e.g. after a merged PR
+	Committed time.Time // committer date of the branch's last commit
+	Subject   string    // subject line of the branch's last commit
+}
+
+// branchInfoFormat has one NUL-separated field per BranchInfo value. The
+// name is lstrip=2 rather than short, which turns into "heads/x" when a tag
+// x exists too.
+const branchInfoFormat = "%(refname:lstrip=2)%00%(upstream:short)%00%(upstream:track,nobracket)%00%(committerdate:unix)%00%(subject)"
+
+// BatchBranchInfo returns upstream tracking, last-commit date and subject
+// for the given local branches — every local branch when none are given —
+// with a single git for-each-ref call instead of several per branch.
+// Branches that don't exist are left out of the map.
+func BatchBranchInfo(branches []string) (map[string]BranchInfo, error) {
+	args, want := branchRefArgs([]string{"for-each-ref", "--format=" + branchInfoFormat}, branches)
+	out, err := run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]BranchInfo)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x00", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		// Patterns match by prefix, so refs/heads/feat also lists feat/x.
+		name := fields[0]
+		if len(want) > 0 && !want[name] {
+			continue
+		}
+		info := BranchInfo{Upstream: fields[1], Subject: fields[4]}
+		info.Ahead, info.Behind, info.Gone = parseTrack(fields[2])
+		if unix, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			info.Committed = time.Unix(unix, 0)
+		}
+		infos[name] = info
+	}
+	return infos, nil
+}
+
+// BatchAheadOf returns how many commits each of the given local branches
+// has that base doesn't, like AheadCount, with a single git for-each-ref
+// call. It needs git 2.41 or later for %(ahead-behind); older versions
+// return an error, and callers fall back to AheadCount. Branches that don't
+// exist are left out of the map.
+func BatchAheadOf(base string, branches []string) (map[string]int, error) {
+	args, want := branchRefArgs([]string{"for-each-ref", "--format=%(refname:lstrip=2)%00%(ahead-behind:" + base + ")"}, branches)
+	out, err := run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ahead := make(map[string]int)
+	for _, line := range strings.Split(out, "\n") {
+		name, counts, ok := strings.Cut(line, "\x00")
+		if !ok || (len(want) > 0 && !want[name]) {
+			continue
+		}
+		a, _, _ := strings.Cut(counts, " ")
+		n, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected for-each-ref output %q", line)
+		}
+		ahead[name] = n
+	}
+	return ahead, nil
+}
+
+// branchRefArgs appends the refs/heads patterns for branches to args — all
+// of refs/heads when there are none — and returns the set of names asked
+// for. Patterns match by prefix, so refs/heads/feat also lists feat/x;
+// callers skip the names not in the set.
+func branchRefArgs(args, branches []string) ([]string, map[string]bool) {
+	if len(branches) == 0 {
+		args = append(args, "refs/heads/")
 	}
-	if modified > 0 {
-		parts = append(parts, fmt.Sprintf("%d modified", modified))
+	want := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		want[b] = true
+		args = append(args, "refs/heads/"+b)
 	}
-	if untracked > 0 {
-		parts = append(parts, fmt.Sprintf("%d untracked", untracked))
+	return args, want
+}
+
+// parseTrack parses %(upstream:track,nobracket): "ahead 1, behind 2",
+// "ahead 1", "behind 2", "gone", or "" when in sync or without upstream.
+func parseTrack(track string) (ahead, behind int, gone bool) {
+	if track == "gone" {
+		return 0, 0, true
+	}
+	for _, part := range strings.Split(track, ", ") {
+		what, n, _ := strings.Cut(part, " ")
+		count, _ := strconv.Atoi(n)
+		switch what {
+		case "ahead":
+			ahead = count
+		case "behind":
+			behind = count
+		}
+	}
+	return ahead, behind, false
+}
+
+// Upstream returns the upstream of the branch checked out at path,
+// e.g. "origin/feature/auth". Errors if none is configured.
+func Upstream(path string) (string, error) {
+	return run("-C", path, "rev-parse", "--abbrev-ref", "@{upstream}")
+}
+
+// DefaultBranch returns the repository's default branch name.
+// Prefers origin/HEAD (what the remote considers default), falling back to
+// the branch checked out in the main worktree.
+func DefaultBranch() (string, error) {
+	if ref, err := run("symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(ref, "origin/"), nil
+	}
+
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	if len(worktrees) == 0 || worktrees[0].Branch == "" || strings.HasPrefix(worktrees[0].Branch, "(detached") {
+		return "", fmt.Errorf("could not determine default branch — set origin/HEAD with 'git remote set-head origin --auto'")
+	}
+	return worktrees[0].Branch, nil
+}
+
+// OwnCommits returns how many commits branch has gained since it was
+// created: those after the oldest entry of its reflog. A branch that never
+// had a commit of its own is reachable from wherever it started, so only
+// this tells it apart from merged work. Errors when there's no reflog.
+func OwnCommits(branch string) (int, error) {
+	ref := "refs/heads/" + branch
+	out, err := run("reflog", "show", "--format=%H", ref, "--")
+	if err != nil {
+		return 0, err
+	}
+	entries := strings.Fields(out)
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("%s has no reflog", branch)
+	}
+	return CountCommits(entries[len(entries)-1], ref)
+}
+
+// CountCommits returns how many commits to has that from doesn't
+// (`git rev-list --count from..to`).
+func CountCommits(from, to string) (int, error) {
+	out, err := run("rev-list", "--count", from+".."+to, "--")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// IsMerged reports whether every commit on branch is reachable from into.
+func IsMerged(branch, into string) (bool, error) {
+	defer traced([]string{"merge-base", "--is-ancestor", branch, into})()
+	cmd := command("merge-base", "--is-ancestor", branch, into)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	// Exit code 1 means "not an ancestor"; anything else is a real failure
+	// (e.g. unknown ref).
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("git merge-base --is-ancestor %s %s: %w", branch, into, err)
+}
+
+// RefExists reports whether ref resolves to a commit, e.g. "origin/main".
+func RefExists(ref string) bool {
+	_, err := run("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// CommonDir returns the shared .git directory for the repository containing
+// dir — the main repo's .git even when dir is inside a linked worktree.
+// The result may be relative to dir.
+func CommonDir(dir string) (string, error) {
+	return run("-C", dir, "rev-parse", "--git-common-dir")
+}
+
+// CheckBranchName returns an error unless name is a valid branch name, as
+// `git check-ref-format --branch` decides. Names starting with "-" are
+// invalid, so a checked name is never read as an option.
+func CheckBranchName(name string) error {
+	if _, err := run("check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
 	}
-	return strings.Join(parts, ", "), nil
+	return nil
 }
 
-func branchExists(branch string) bool {
+// BranchExists reports whether a local branch with this name exists.
+func BranchExists(branch string) bool {
 	_, err := run("rev-parse", "--verify", "refs/heads/"+branch)
 	return err == nil
 }
@@ -1,10 +1,13 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // setupTestRepo creates a real git repo in a temp directory with one commit.
@@ -69,6 +72,60 @@ func TestListWorktrees(t *testing.T) {
 	}
 }
 
+func TestListWorktreesLockedAndPrunable(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	locked := filepath.Join(t.TempDir(), "locked")
+	gone := filepath.Join(t.TempDir(), "gone")
+	gitIn(t, dir, "worktree", "add", "-b", "locked", locked)
+	gitIn(t, dir, "worktree", "lock", "--reason", "on a USB stick", locked)
+	gitIn(t, dir, "worktree", "add", "-b", "gone", gone)
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		gitIn(t, dir, "worktree", "unlock", locked)
+		gitIn(t, dir, "worktree", "remove", "--force", locked)
+	})
+
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktrees) != 3 {
+		t.Fatalf("expected 3 worktrees, got %d", len(worktrees))
+	}
+	if len(worktrees[0].Head) != 40 {
+		t.Errorf("main HEAD = %q, want a full SHA", worktrees[0].Head)
+	}
+	if w := worktrees[1]; !w.Locked || w.LockReason != "on a USB stick" || w.Prunable {
+		t.Errorf("locked worktree = %+v", w)
+	}
+	if w := worktrees[2]; !w.Prunable || w.Locked {
+		t.Errorf("removed worktree = %+v, want prunable", w)
+	}
+}
+
+func TestParseWorktreesNUL(t *testing.T) {
+	out := "worktree /code/app\x00HEAD 1111111111111111111111111111111111111111\x00branch refs/heads/main\x00\x00" +
+		"worktree /code/odd\nname\x00HEAD 2222222222222222222222222222222222222222\x00detached\x00locked two\nlines\x00\x00"
+
+	worktrees := parseWorktrees(out, "\x00")
+	if len(worktrees) != 2 {
+		t.Fatalf("expected 2 worktrees, got %d: %+v", len(worktrees), worktrees)
+	}
+	if w := worktrees[0]; w.Path != "/code/app" || w.Branch != "main" || !w.IsMain {
+		t.Errorf("main = %+v", w)
+	}
+	w := worktrees[1]
+	if w.Path != "/code/odd\nname" {
+		t.Errorf("path = %q, want the newline kept", w.Path)
+	}
+	if w.Branch != "(detached 2222222)" || !w.Locked || w.LockReason != "two\nlines" {
+		t.Errorf("detached = %+v", w)
+	}
+}
+
 func TestAddAndRemoveWorktree(t *testing.T) {
 	setupTestRepo(t)
 
@@ -109,7 +166,7 @@ func TestStatusClean(t *testing.T) {
 	if err != nil {
 		t.Fatal("Status failed:", err)
 	}
-	if status != "clean" {
+	if !status.Clean() {
 		t.Errorf("status = %q, want %q", status, "clean")
 	}
 }
@@ -143,7 +200,7 @@ func TestStatusModified(t *testing.T) {
 	if err != nil {
 		t.Fatal("Status failed:", err)
 	}
-	if status != "1 modified" {
+	if status != (WorktreeStatus{Modified: 1}) {
 		t.Errorf("status = %q, want %q", status, "1 modified")
 	}
 }
@@ -159,11 +216,142 @@ func TestStatusUntracked(t *testing.T) {
 	if err != nil {
 		t.Fatal("Status failed:", err)
 	}
-	if status != "1 untracked" {
+	if status != (WorktreeStatus{Untracked: 1}) {
 		t.Errorf("status = %q, want %q", status, "1 untracked")
 	}
 }
 
+func TestStatusLeavesIndexAlone(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	file := filepath.Join(dir, "tracked.txt")
+	if err := os.WriteFile(file, []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", "tracked.txt")
+	gitIn(t, dir, "commit", "-m", "add tracked")
+
+	// A stale stat entry is what makes a plain git status rewrite the index.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, future, future); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(dir, ".git", "index")
+	before, err := os.Stat(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := before.ModTime().Add(-time.Minute)
+	if err := os.Chtimes(index, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := Status(dir); err != nil || !status.Clean() {
+		t.Fatalf("Status = %q, %v; want clean", status, err)
+	}
+	after, err := os.Stat(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !after.ModTime().Equal(past) {
+		t.Error("Status rewrote the index; it should run with --no-optional-locks")
+	}
+}
+
+func TestQuickStatusFast(t *testing.T) {
+	dir := setupTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	FastStatus = true
+	t.Cleanup(func() { FastStatus = false })
+
+	if status, err := QuickStatus(dir); err != nil || !status.Clean() {
+		t.Errorf("QuickStatus = %q, %v; want clean (untracked skipped)", status, err)
+	}
+	// Status is used for safety checks and must never skip untracked files.
+	if status, err := Status(dir); err != nil || status.Untracked != 1 {
+		t.Errorf("Status = %q, %v; want 1 untracked", status, err)
+	}
+
+	// With the untracked cache on, untracked files are cheap — keep them.
+	gitIn(t, dir, "config", "core.untrackedCache", "true")
+	if status, err := QuickStatus(dir); err != nil || status.Untracked != 1 {
+		t.Errorf("QuickStatus with untracked cache = %q, %v; want 1 untracked", status, err)
+	}
+}
+
+func TestStatusRenamed(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	if err := os.WriteFile(filepath.Join(dir, "old.txt"), []byte("some content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", "old.txt")
+	gitIn(t, dir, "commit", "-m", "add old")
+	gitIn(t, dir, "mv", "old.txt", "new.txt")
+
+	status, err := Status(dir)
+	if err != nil {
+		t.Fatal("Status failed:", err)
+	}
+	if status != (WorktreeStatus{Renamed: 1}) {
+		t.Errorf("status = %q, want %q", status, "1 renamed")
+	}
+}
+
+func TestStatusConflicts(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	file := filepath.Join(dir, "shared.txt")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("base\n")
+	gitIn(t, dir, "add", "shared.txt")
+	gitIn(t, dir, "commit", "-m", "base")
+	gitIn(t, dir, "checkout", "-b", "other")
+	write("theirs\n")
+	gitIn(t, dir, "commit", "-am", "theirs")
+	gitIn(t, dir, "checkout", "-")
+	write("ours\n")
+	gitIn(t, dir, "commit", "-am", "ours")
+
+	// The merge is expected to stop with a conflict.
+	merge := exec.Command("git", "merge", "other")
+	merge.Dir = dir
+	merge.Run()
+
+	status, err := Status(dir)
+	if err != nil {
+		t.Fatal("Status failed:", err)
+	}
+	if status != (WorktreeStatus{Conflicted: 1}) {
+		t.Errorf("status = %q, want %q", status, "1 conflict")
+	}
+}
+
+func TestWorktreeStatusString(t *testing.T) {
+	tests := []struct {
+		status WorktreeStatus
+		want   string
+	}{
+		{WorktreeStatus{}, "clean"},
+		{WorktreeStatus{Conflicted: 1}, "1 conflict"},
+		{WorktreeStatus{Conflicted: 2, Renamed: 1}, "2 conflicts, 1 renamed"},
+		{WorktreeStatus{Staged: 2, Modified: 1, Untracked: 3}, "2 staged, 1 modified, 3 untracked"},
+	}
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
 func TestStatusStaged(t *testing.T) {
 	dir := setupTestRepo(t)
 
@@ -176,7 +364,427 @@ func TestStatusStaged(t *testing.T) {
 	if err != nil {
 		t.Fatal("Status failed:", err)
 	}
-	if status != "1 staged" {
+	if status != (WorktreeStatus{Staged: 1}) {
 		t.Errorf("status = %q, want %q", status, "1 staged")
 	}
 }
+
+func TestRemoveWorktreeDirty(t *testing.T) {
+	setupTestRepo(t)
+
+	wtPath := filepath.Join(t.TempDir(), "dirty-worktree")
+	if err := AddWorktree(wtPath, "dirty-branch", ""); err != nil {
+		t.Fatal("AddWorktree failed:", err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "new.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := RemoveWorktree(wtPath, false)
+	if !errors.Is(err, ErrDirty) {
+		t.Fatalf("expected ErrDirty, got %v", err)
+	}
+
+	if err := RemoveWorktree(wtPath, true); err != nil {
+		t.Fatal("forced RemoveWorktree failed:", err)
+	}
+}
+
+func TestAheadCount(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	base, err := CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wtPath := filepath.Join(t.TempDir(), "ahead-worktree")
+	if err := AddWorktree(wtPath, "ahead-branch", ""); err != nil {
+		t.Fatal("AddWorktree failed:", err)
+	}
+	gitIn(t, wtPath, "commit", "--allow-empty", "-m", "one")
+	gitIn(t, wtPath, "commit", "--allow-empty", "-m", "two")
+
+	ahead, err := AheadCount(wtPath, base)
+	if err != nil {
+		t.Fatal("AheadCount failed:", err)
+	}
+	if ahead != 2 {
+		t.Errorf("ahead = %d, want 2", ahead)
+	}
+
+	ahead, err = AheadCount(dir, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ahead != 0 {
+		t.Errorf("main ahead = %d, want 0", ahead)
+	}
+}
+
+func TestIsMerged(t *testing.T) {
+	setupTestRepo(t)
+
+	base, err := CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wtPath := filepath.Join(t.TempDir(), "merge-worktree")
+	if err := AddWorktree(wtPath, "merge-branch", ""); err != nil {
+		t.Fatal("AddWorktree failed:", err)
+	}
+	gitIn(t, wtPath, "commit", "--allow-empty", "-m", "work")
+
+	merged, err := IsMerged("merge-branch", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged {
+		t.Error("expected branch with new commit to be unmerged")
+	}
+
+	gitIn(t, ".", "merge", "--ff-only", "merge-branch")
+
+	merged, err = IsMerged("merge-branch", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged {
+		t.Error("expected branch to be merged after fast-forward")
+	}
+
+	if _, err := IsMerged("no-such-branch", base); err == nil {
+		t.Error("expected error for unknown branch")
+	}
+}
+
+func TestDefaultBranchFallsBackToMainWorktree(t *testing.T) {
+	setupTestRepo(t)
+	gitIn(t, ".", "checkout", "-b", "trunk")
+
+	got, err := DefaultBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "trunk" {
+		t.Errorf("DefaultBranch = %q, want %q", got, "trunk")
+	}
+}
+
+func TestAddWorktreeWithProgress(t *testing.T) {
+	setupTestRepo(t)
+
+	Progress = true
+	t.Cleanup(func() { Progress = false })
+
+	wtPath := filepath.Join(t.TempDir(), "progress-worktree")
+	if err := AddWorktree(wtPath, "progress-branch", ""); err != nil {
+		t.Fatal("AddWorktree failed:", err)
+	}
+	if _, err := os.Stat(wtPath); err != nil {
+		t.Fatalf("expected worktree to exist: %v", err)
+	}
+
+	// Errors still surface even though output isn't captured.
+	if err := AddWorktree(wtPath, "progress-branch", ""); err == nil {
+		t.Fatal("expected error adding a worktree at an existing path")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	setupTestRepo(t)
+	t.Cleanup(func() { Configure("", nil, nil) })
+
+	Configure("", []string{"-c", "user.name=Configured"}, []string{"GIT_AUTHOR_EMAIL=env@test.com"})
+
+	name, err := run("config", "user.name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Configured" {
+		t.Errorf("user.name = %q, want %q (global args not applied)", name, "Configured")
+	}
+
+	ident, err := run("var", "GIT_AUTHOR_IDENT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ident, "env@test.com") {
+		t.Errorf("author ident = %q, want it to contain env@test.com (env not applied)", ident)
+	}
+
+	Configure(filepath.Join(t.TempDir(), "no-such-git"), nil, nil)
+	if _, err := ListWorktrees(); err == nil {
+		t.Error("expected error with a missing git binary")
+	}
+}
+
+func TestGitDirIsScrubbed(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	// An exported GIT_DIR (e.g. from a git hook) must not redirect grove.
+	other := setupOtherRepo(t)
+	t.Setenv("GIT_DIR", filepath.Join(other, ".git"))
+	t.Setenv("GIT_WORK_TREE", other)
+
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worktrees[0].Path != dir {
+		t.Errorf("main worktree = %q, want %q (GIT_DIR leaked into git calls)", worktrees[0].Path, dir)
+	}
+
+	for _, kv := range Environ() {
+		if strings.HasPrefix(kv, "GIT_DIR=") || strings.HasPrefix(kv, "GIT_WORK_TREE=") {
+			t.Errorf("Environ() still contains %s", kv)
+		}
+	}
+}
+
+func TestGitDirFromConfigureIsHonored(t *testing.T) {
+	setupTestRepo(t)
+	other := setupOtherRepo(t)
+	t.Cleanup(func() { Configure("", nil, nil) })
+
+	// Explicitly configured env is applied after scrubbing.
+	Configure("", nil, []string{"GIT_DIR=" + filepath.Join(other, ".git")})
+
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worktrees[0].Path != other {
+		t.Errorf("main worktree = %q, want %q", worktrees[0].Path, other)
+	}
+}
+
+// setupOtherRepo creates a second repo without changing the working directory.
+func setupOtherRepo(t *testing.T) string {
+	t.Helper()
+	other, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, other, "init")
+	return other
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	tracked := filepath.Join(dir, "tracked.txt")
+	if err := os.WriteFile(tracked, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", "tracked.txt")
+	gitIn(t, dir, "commit", "-m", "add tracked")
+
+	if err := os.WriteFile(tracked, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := Snapshot(dir, "refs/grove/trash/test", "snapshot")
+	if err != nil {
+		t.Fatal("Snapshot failed:", err)
+	}
+
+	// The worktree itself is untouched.
+	status, err := Status(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != (WorktreeStatus{Modified: 1, Untracked: 1}) {
+		t.Errorf("status after snapshot = %q, want %q", status, "1 modified, 1 untracked")
+	}
+
+	got, err := run("show", "refs/grove/trash/test:new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "new" {
+		t.Errorf("untracked file in snapshot = %q, want %q", got, "new")
+	}
+	got, err = run("show", "refs/grove/trash/test:tracked.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "changed" {
+		t.Errorf("tracked file in snapshot = %q, want %q", got, "changed")
+	}
+
+	ref, err := run("rev-parse", "refs/grove/trash/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != commit {
+		t.Errorf("ref = %q, want %q", ref, commit)
+	}
+}
+
+func TestStatusKey(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	before, err := StatusKey(dir)
+	if err != nil {
+		t.Fatal("StatusKey failed:", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("x"), 0644)
+	gitIn(t, dir, "add", "a.txt")
+
+	after, err := StatusKey(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Error("expected key to change after staging a file")
+	}
+
+	// Linked worktrees resolve their .git file.
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	if err := AddWorktree(wtPath, "feature/key", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StatusKey(wtPath); err != nil {
+		t.Errorf("StatusKey in linked worktree failed: %v", err)
+	}
+}
+
+func TestAheadBehind(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitIn(t, dir, "branch", "other")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "ahead 1")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "ahead 2")
+	gitIn(t, dir, "checkout", "-q", "other")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "behind 1")
+	gitIn(t, dir, "checkout", "-q", "-")
+
+	ahead, behind, err := AheadBehind(dir, "other")
+	if err != nil {
+		t.Fatal("AheadBehind failed:", err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Errorf("AheadBehind = +%d -%d, want +2 -1", ahead, behind)
+	}
+}
+
+func TestBatchBranchInfo(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitIn(t, dir, "branch", "-M", "main")
+	gitIn(t, dir, "branch", "feat")
+	gitIn(t, dir, "branch", "fix/x")
+	gitIn(t, dir, "branch", "--set-upstream-to=main", "feat")
+	gitIn(t, dir, "checkout", "-q", "feat")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "work on feat")
+	gitIn(t, dir, "checkout", "-q", "main")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "main moves on")
+	gitIn(t, dir, "branch", "old")
+	gitIn(t, dir, "config", "branch.old.remote", ".")
+	gitIn(t, dir, "config", "branch.old.merge", "refs/heads/deleted")
+
+	infos, err := BatchBranchInfo([]string{"feat", "old", "fix"})
+	if err != nil {
+		t.Fatal("BatchBranchInfo failed:", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("got %d branches, want 2 (no fix, nor fix/x matched by prefix): %+v", len(infos), infos)
+	}
+
+	feat := infos["feat"]
+	if feat.Upstream != "main" || feat.Ahead != 1 || feat.Behind != 1 || feat.Gone {
+		t.Errorf("feat = %+v, want upstream main, +1 -1", feat)
+	}
+	if feat.Subject != "work on feat" || feat.Committed.IsZero() {
+		t.Errorf("feat last commit = %q at %v", feat.Subject, feat.Committed)
+	}
+	if !infos["old"].Gone {
+		t.Errorf("old = %+v, want its upstream gone", infos["old"])
+	}
+
+	// A tag of the same name makes %(refname:short) say "heads/feat".
+	gitIn(t, dir, "tag", "feat")
+	all, err := BatchBranchInfo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := all["fix/x"]; !ok || len(all) != 4 {
+		t.Errorf("all branches = %d entries, want 4 including fix/x", len(all))
+	}
+	if _, ok := all["feat"]; !ok {
+		t.Errorf("all branches = %v, want feat despite the tag", all)
+	}
+}
+
+func TestBatchAheadOf(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitIn(t, dir, "branch", "-M", "main")
+	gitIn(t, dir, "checkout", "-q", "-b", "feat")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "one")
+	gitIn(t, dir, "commit", "--allow-empty", "-m", "two")
+	gitIn(t, dir, "checkout", "-q", "main")
+
+	ahead, err := BatchAheadOf("main", []string{"feat", "main", "fe"})
+	if err != nil && strings.Contains(err.Error(), "ahead-behind") {
+		t.Skip("git is older than 2.41")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if len(ahead) != 2 || ahead["feat"] != 2 || ahead["main"] != 0 {
+		t.Errorf("ahead = %v, want feat 2 and main 0", ahead)
+	}
+}
+
+func TestBranchDescriptions(t *testing.T) {
+	setupTestRepo(t)
+
+	descs, err := BranchDescriptions()
+	if err != nil || len(descs) != 0 {
+		t.Fatalf("BranchDescriptions with none set = %v, %v", descs, err)
+	}
+	if got := BranchDescription("feature/cache.v2"); got != "" {
+		t.Errorf("BranchDescription before setting = %q", got)
+	}
+
+	// Dots in the branch name and newlines in the text survive.
+	if err := SetBranchDescription("feature/cache.v2", "spike: new cache layer\nsee #12"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetBranchDescription("fix", "login redirect"); err != nil {
+		t.Fatal(err)
+	}
+	if got := BranchDescription("feature/cache.v2"); got != "spike: new cache layer\nsee #12" {
+		t.Errorf("BranchDescription = %q", got)
+	}
+	descs, err = BranchDescriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"feature/cache.v2": "spike: new cache layer\nsee #12", "fix": "login redirect"}
+	if len(descs) != len(want) || descs["feature/cache.v2"] != want["feature/cache.v2"] || descs["fix"] != want["fix"] {
+		t.Errorf("BranchDescriptions = %q, want %q", descs, want)
+	}
+}
+
+func TestRefsAreNeverOptions(t *testing.T) {
+	dir := setupTestRepo(t)
+	marker := filepath.Join(dir, "pwned")
+	evil := "--upload-pack=touch " + marker + "; git-upload-pack"
+
+	if err := CheckBranchName(evil); err == nil {
+		t.Error("CheckBranchName accepted an option")
+	}
+	if err := CheckBranchName("feature/auth"); err != nil {
+		t.Errorf("CheckBranchName(feature/auth) = %v", err)
+	}
+	if _, err := FetchRef(".", evil); err == nil {
+		t.Error("FetchRef of an option-like ref succeeded")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("FetchRef ran --upload-pack from the ref")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package git
+
+// platformArgs go before globalArgs on every git call; see
+// longpaths_windows.go.
+var platformArgs []string
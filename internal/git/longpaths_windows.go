@@ -0,0 +1,7 @@
+package git
+
+// platformArgs go before globalArgs on every git call. Git for Windows
+// stops at 260-character paths unless core.longpaths is set, which deep
+// monorepo files inside a prefixed worktree directory easily exceed.
+// Configured git args come after, so "-c core.longpaths=false" still wins.
+var platformArgs = []string{"-c", "core.longpaths=true"}
@@ -0,0 +1,142 @@
+// Package i18n translates grove's user-facing messages. Messages are keyed
+// by their English format string, gettext-style, so English needs no
+// catalog and a missing translation falls back to English.
+//
+// Catalogs are JSON objects mapping English formats to translated ones,
+// read from <dir>/<locale>.json — e.g. locales/de.json:
+//
+//	{"Worktree %q removed.\n": "Worktree %q entfernt.\n"}
+//
+// Verbs and their order must stay the same as in the English format;
+// translations that change them are dropped. The catalogs in locales/ are
+// built into grove, and a file in the catalog directory replaces the
+// built-in one for its locale.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//go:embed locales/*.json
+var builtin embed.FS
+
+var (
+	mu      sync.Mutex
+	loaded  bool
+	catalog map[string]string
+)
+
+// Locale returns the locale grove should use: GROVE_LANG, else the usual
+// LC_ALL, LC_MESSAGES and LANG, normalized to e.g. "de" or "pt_BR".
+// "C", "POSIX" and unset all mean English ("en").
+func Locale() string {
+	for _, key := range []string{"GROVE_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return normalize(v)
+		}
+	}
+	return "en"
+}
+
+// normalize strips encoding and modifier ("de_DE.UTF-8@euro" → "de_DE").
+func normalize(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return "en"
+	}
+	return strings.ReplaceAll(locale, "-", "_")
+}
+
+// Dir returns where catalogs are looked up. GROVE_LOCALE_DIR overrides the
+// default of <user config dir>/grove/locales.
+func Dir() (string, error) {
+	if d := os.Getenv("GROVE_LOCALE_DIR"); d != "" {
+		return d, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "grove", "locales"), nil
+}
+
+// Load reads the catalog for locale from dir, or the built-in one, trying
+// the full locale ("pt_BR") before the language alone ("pt"). English, or
+// no catalog at all, is an empty catalog. Translations whose verbs don't
+// match their English format are left out.
+func Load(dir, locale string) (map[string]string, error) {
+	if locale == "en" || strings.HasPrefix(locale, "en_") {
+		return nil, nil
+	}
+	lang, _, _ := strings.Cut(locale, "_")
+	for _, name := range []string{locale, lang} {
+		data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			data, err = builtin.ReadFile("locales/" + name + ".json")
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var c map[string]string
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, errors.New(name + ".json is not valid JSON: " + err.Error())
+		}
+		for msg, t := range c {
+			if !slices.Equal(verbs(msg), verbs(t)) {
+				delete(c, msg)
+			}
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+// verbs lists the fmt verbs of format in order, with their flags, width and
+// precision ("%-8s", "%q"). "%%" isn't a verb.
+func verbs(format string) []string {
+	var list []string
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[j]) >= 0 {
+			j++
+		}
+		if j < len(format) && format[j] != '%' {
+			list = append(list, format[i:j+1])
+		}
+		i = j
+	}
+	return list
+}
+
+// T returns the translation of the English format string msg for the
+// current locale, or msg itself. The catalog is loaded on first use; a
+// broken one is ignored so grove keeps working in English.
+func T(msg string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	if !loaded {
+		loaded = true
+		if dir, err := Dir(); err == nil {
+			catalog, _ = Load(dir, Locale())
+		}
+	}
+	if t, ok := catalog[msg]; ok && t != "" {
+		return t
+	}
+	return msg
+}
@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocale(t *testing.T) {
+	for _, key := range []string{"GROVE_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(key, "")
+	}
+	if got := Locale(); got != "en" {
+		t.Errorf("Locale with nothing set = %q, want en", got)
+	}
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	if got := Locale(); got != "pt_BR" {
+		t.Errorf("Locale from LANG = %q, want pt_BR", got)
+	}
+	t.Setenv("GROVE_LANG", "de")
+	if got := Locale(); got != "de" {
+		t.Errorf("GROVE_LANG should win, got %q", got)
+	}
+	t.Setenv("GROVE_LANG", "C")
+	if got := Locale(); got != "en" {
+		t.Errorf("Locale for C = %q, want en", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "pt.json"), []byte(`{"Aborted.\n": "Cancelado.\n"}`), 0644)
+
+	c, err := Load(dir, "pt_BR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c["Aborted.\n"] != "Cancelado.\n" {
+		t.Errorf("pt_BR should fall back to pt.json, got %v", c)
+	}
+
+	if c, err := Load(dir, "fr"); err != nil || c != nil {
+		t.Errorf("missing catalog = %v, %v; want empty", c, err)
+	}
+	if c, err := Load(dir, "en_US"); err != nil || c != nil {
+		t.Errorf("English = %v, %v; want no catalog", c, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{`), 0644)
+	if _, err := Load(dir, "de"); err == nil {
+		t.Error("invalid catalog should be an error")
+	}
+}
+
+func TestLoadDropsMismatchedVerbs(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{
+		"Worktree %q removed.\n": "Worktree %q supprimé.\n",
+		"Removed %d of %d worktree(s).\n": "%d worktree(s) supprimé(s).\n",
+		"Creating worktree for branch %q at %s\n": "Création de %s pour %q\n",
+		"Saved 100%% of %s\n": "%s sauvegardé à 100%%\n"
+	}`), 0644)
+
+	c, err := Load(dir, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c["Worktree %q removed.\n"] == "" || c["Saved 100%% of %s\n"] == "" {
+		t.Errorf("matching translations were dropped: %v", c)
+	}
+	for _, msg := range []string{"Removed %d of %d worktree(s).\n", "Creating worktree for branch %q at %s\n"} {
+		if t2, ok := c[msg]; ok {
+			t.Errorf("%q → %q has other verbs and should be dropped", msg, t2)
+		}
+	}
+}
+
+func TestBuiltinCatalog(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Load(dir, "de_AT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c["Worktree %q removed.\n"] != "Worktree %q entfernt.\n" {
+		t.Errorf("de_AT should get the built-in de catalog, got %v", c["Worktree %q removed.\n"])
+	}
+	// Every entry has to survive the verb check.
+	data, _ := builtin.ReadFile("locales/de.json")
+	var all map[string]string
+	if err := json.Unmarshal(data, &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != len(all) {
+		t.Errorf("built-in de: %d of %d translations kept", len(c), len(all))
+	}
+
+	// A catalog on disk replaces the built-in one.
+	os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"Aborted.\n": "Abbruch.\n"}`), 0644)
+	if c, _ := Load(dir, "de"); len(c) != 1 || c["Aborted.\n"] != "Abbruch.\n" {
+		t.Errorf("catalog on disk = %v, want only its own entry", c)
+	}
+}
@@ -0,0 +1,77 @@
+// Package listcache stores list data precomputed by `grove refresh`, so
+// `grove list` can render without running git in every worktree.
+package listcache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/verbaux/grove/internal/atomicfile"
+)
+
+// fileName lives next to state.json in the project's .grove directory.
+const fileName = ".grove/cache.json"
+
+// Info is what the refresher records about one worktree.
+type Info struct {
+	Status    string `json:"status"`
+	Ahead     int    `json:"ahead"`
+	Behind    int    `json:"behind"`
+	Against   string `json:"against,omitempty"` // ref ahead/behind was computed against; "" if none
+	DiskBytes int64  `json:"diskBytes"`
+}
+
+// Snapshot is the content of .grove/cache.json.
+// Worktrees is keyed by worktree path.
+type Snapshot struct {
+	Refreshed time.Time       `json:"refreshed"`
+	Worktrees map[string]Info `json:"worktrees"`
+}
+
+// Path returns the cache file for the project at root.
+func Path(root string) string {
+	return filepath.Join(root, filepath.FromSlash(fileName))
+}
+
+// Load reads the snapshot for the project at root. The bool is false when
+// no refresher has written one yet.
+func Load(root string) (Snapshot, bool, error) {
+	data, err := os.ReadFile(Path(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Snapshot{}, false, nil
+		}
+		return Snapshot{}, false, err
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, false, errors.New(".grove/cache.json is not valid JSON: " + err.Error())
+	}
+	if s.Worktrees == nil {
+		s.Worktrees = map[string]Info{}
+	}
+	return s, true, nil
+}
+
+// Save writes the snapshot for the project at root. It renames a temp file
+// into place, so `grove list` never reads a half-written cache while the
+// refresher is running.
+func Save(root string, s Snapshot) error {
+	path := Path(root)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	return atomicfile.Write(path, data, 0644)
+}
@@ -0,0 +1,35 @@
+package listcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	root := t.TempDir()
+
+	if _, ok, err := Load(root); err != nil || ok {
+		t.Fatalf("Load of missing cache = %v, %v; want false, nil", ok, err)
+	}
+
+	want := Snapshot{
+		Refreshed: time.Now().Truncate(time.Second),
+		Worktrees: map[string]Info{
+			"/code/app-auth": {Status: "1 modified", Ahead: 2, Behind: 1, Against: "origin/feature/auth", DiskBytes: 4096},
+		},
+	}
+	if err := Save(root, want); err != nil {
+		t.Fatal("Save failed:", err)
+	}
+
+	got, ok, err := Load(root)
+	if err != nil || !ok {
+		t.Fatalf("Load = %v, %v", ok, err)
+	}
+	if !got.Refreshed.Equal(want.Refreshed) {
+		t.Errorf("Refreshed = %v, want %v", got.Refreshed, want.Refreshed)
+	}
+	if got.Worktrees["/code/app-auth"] != want.Worktrees["/code/app-auth"] {
+		t.Errorf("info = %+v, want %+v", got.Worktrees["/code/app-auth"], want.Worktrees["/code/app-auth"])
+	}
+}
@@ -0,0 +1,182 @@
+// Package manifest reads and writes .grove/worktrees.yaml, the committed
+// list of worktrees a team recommends (grove share, grove sync --shared).
+//
+// The file is YAML so it diffs and reviews well, but only the small subset
+// Save writes is understood — a list of flat entries — which keeps grove
+// free of a YAML dependency.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RelPath is where the manifest lives, relative to the project root.
+const RelPath = ".grove/worktrees.yaml"
+
+// Worktree is one recommended worktree.
+type Worktree struct {
+	Alias    string
+	Branch   string
+	Template string   // optional: grove create --template
+	Tags     []string // optional: added to the worktree's tags
+}
+
+// Path returns the manifest's location for the project at root.
+func Path(root string) string {
+	return filepath.Join(root, RelPath)
+}
+
+// Load reads the manifest of the project at root. A missing file is an
+// empty manifest.
+func Load(root string) ([]Worktree, error) {
+	data, err := os.ReadFile(Path(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Save writes worktrees to the manifest of the project at root, sorted by
+// alias so the file changes as little as possible between runs.
+func Save(root string, worktrees []Worktree) error {
+	sorted := append([]Worktree(nil), worktrees...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Alias < sorted[j].Alias })
+
+	var b bytes.Buffer
+	b.WriteString("# Worktrees recommended for this project. Create the missing ones with\n")
+	b.WriteString("# 'grove sync --shared'; add yours with 'grove share <name>'.\n")
+	b.WriteString("worktrees:\n")
+	for _, wt := range sorted {
+		fmt.Fprintf(&b, "  - alias: %s\n", quote(wt.Alias))
+		fmt.Fprintf(&b, "    branch: %s\n", quote(wt.Branch))
+		if wt.Template != "" {
+			fmt.Fprintf(&b, "    template: %s\n", quote(wt.Template))
+		}
+		if len(wt.Tags) > 0 {
+			tags := make([]string, len(wt.Tags))
+			for i, tag := range wt.Tags {
+				tags[i] = quote(tag)
+			}
+			fmt.Fprintf(&b, "    tags: [%s]\n", strings.Join(tags, ", "))
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(Path(root)), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(Path(root), b.Bytes(), 0644)
+}
+
+// Parse reads a manifest in the format Save writes. Comments and blank
+// lines are allowed anywhere, and values may be quoted or not.
+func Parse(data []byte) ([]Worktree, error) {
+	var worktrees []Worktree
+	inList := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			if trimmed != "worktrees:" {
+				return nil, fmt.Errorf("%s:%d: unexpected %q — the manifest holds only a \"worktrees:\" list", RelPath, n, trimmed)
+			}
+			inList = true
+			continue
+		}
+		if !inList {
+			return nil, fmt.Errorf("%s:%d: entry outside the \"worktrees:\" list", RelPath, n)
+		}
+		if rest, ok := strings.CutPrefix(trimmed, "- "); ok {
+			worktrees = append(worktrees, Worktree{})
+			trimmed = strings.TrimSpace(rest)
+		} else if len(worktrees) == 0 {
+			return nil, fmt.Errorf("%s:%d: expected a list entry starting with \"- \"", RelPath, n)
+		}
+		if err := setField(&worktrees[len(worktrees)-1], trimmed); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", RelPath, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for i, wt := range worktrees {
+		if wt.Alias == "" || wt.Branch == "" {
+			return nil, fmt.Errorf("%s: entry %d needs both alias and branch", RelPath, i+1)
+		}
+		// The branch ends up on git's command line; git would read one
+		// starting with "-" as an option.
+		if strings.HasPrefix(wt.Branch, "-") {
+			return nil, fmt.Errorf("%s: entry %d: %q is not a valid branch name", RelPath, i+1, wt.Branch)
+		}
+		if seen[wt.Alias] {
+			return nil, fmt.Errorf("%s: alias %q is listed twice", RelPath, wt.Alias)
+		}
+		seen[wt.Alias] = true
+	}
+	return worktrees, nil
+}
+
+// setField applies one "key: value" line to wt.
+func setField(wt *Worktree, line string) error {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return fmt.Errorf("expected \"key: value\", got %q", line)
+	}
+	value = strings.TrimSpace(value)
+	switch strings.TrimSpace(key) {
+	case "alias":
+		wt.Alias = unquote(value)
+	case "branch":
+		wt.Branch = unquote(value)
+	case "template":
+		wt.Template = unquote(value)
+	case "tags":
+		list, ok := strings.CutPrefix(value, "[")
+		if list, ok = strings.CutSuffix(list, "]"); !ok {
+			return fmt.Errorf("tags must be a list like [backend, infra], got %q", value)
+		}
+		wt.Tags = nil
+		for _, tag := range strings.Split(list, ",") {
+			if tag = unquote(strings.TrimSpace(tag)); tag != "" {
+				wt.Tags = append(wt.Tags, tag)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown key %q — use alias, branch, template or tags", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// quote returns s as a YAML scalar, quoted only when it has to be.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#,[]{}\"'&*!|>%@`") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil && strings.HasPrefix(s, `"`) {
+		return u
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	root := t.TempDir()
+
+	got, err := Load(root)
+	if err != nil || got != nil {
+		t.Fatalf("Load of missing manifest = %v, %v", got, err)
+	}
+
+	want := []Worktree{
+		{Alias: "staging", Branch: "release/staging", Tags: []string{"long-lived"}},
+		{Alias: "api", Branch: "develop", Template: "backend", Tags: []string{"backend", "team: core"}},
+	}
+	if err := Save(root, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Saved sorted by alias.
+	want[0], want[1] = want[1], want[0]
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(`# hand-written
+worktrees:
+  - alias: 'docs'
+    branch: "docs/site"   
+    # a comment inside
+    tags: []
+  - branch: main
+    alias: main
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Worktree{{Alias: "docs", Branch: "docs/site"}, {Alias: "main", Branch: "main"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse = %+v, want %+v", got, want)
+	}
+
+	for input, wantErr := range map[string]string{
+		"other: 1\n":                 `unexpected "other: 1"`,
+		"worktrees:\n  - alias: a\n": "needs both alias and branch",
+		"worktrees:\n  - alias: a\n    colour: red\n":                            `unknown key "colour"`,
+		"worktrees:\n  - alias: a\n    tags: backend\n":                          "tags must be a list",
+		"worktrees:\n  - alias: a\n    branch: x\n  - alias: a\n    branch: y\n": "listed twice",
+		"worktrees:\n  - alias: a\n    branch: --upload-pack=evil\n":             "not a valid branch name",
+	} {
+		if _, err := Parse([]byte(input)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", input, err, wantErr)
+		}
+	}
+}
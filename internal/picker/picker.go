@@ -0,0 +1,254 @@
+// Package picker is a small interactive fuzzy finder for the terminal: type
+// to narrow a list, move with the arrow keys, Enter to pick. It draws on the
+// given writer (stderr, so stdout stays free for the answer) and reads keys
+// from the terminal in raw mode.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// ErrCancelled is returned by Run when the user presses Esc or Ctrl-C.
+var ErrCancelled = errors.New("cancelled")
+
+// maxRows is how many matches are shown at once.
+const maxRows = 10
+
+// Match returns the indexes of the items that contain query's characters in
+// order (case-insensitively), best matches first: contiguous runs and
+// matches at the start of a word rank higher. An empty query matches
+// everything, in the original order.
+func Match(query string, items []string) []int {
+	type scored struct{ index, score int }
+	var found []scored
+	for i, item := range items {
+		if score, ok := score(strings.ToLower(query), strings.ToLower(item)); ok {
+			found = append(found, scored{i, score})
+		}
+	}
+	// Insertion sort keeps equal scores in their original order.
+	for i := 1; i < len(found); i++ {
+		for j := i; j > 0 && found[j].score > found[j-1].score; j-- {
+			found[j], found[j-1] = found[j-1], found[j]
+		}
+	}
+	out := make([]int, len(found))
+	for i, f := range found {
+		out[i] = f.index
+	}
+	return out
+}
+
+// score matches query against item as a subsequence.
+func score(query, item string) (int, bool) {
+	total, pos, prev := 0, 0, -2
+	for _, q := range query {
+		i := strings.IndexRune(item[pos:], q)
+		if i < 0 {
+			return 0, false
+		}
+		at := pos + i
+		total++
+		if at == prev+1 {
+			total += 2 // contiguous
+		}
+		if at == 0 || !isWordRune(lastRune(item[:at])) {
+			total += 3 // start of a word
+		}
+		prev = at
+		pos = at + utf8.RuneLen(q)
+	}
+	return total, true
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// model is the picker's state, separate from the terminal so it can be
+// driven by tests.
+type model struct {
+	items   []string
+	query   string
+	matches []int
+	cursor  int
+}
+
+func newModel(items []string, query string) *model {
+	m := &model{items: items, query: query}
+	m.filter()
+	return m
+}
+
+func (m *model) filter() {
+	m.matches = Match(m.query, m.items)
+	m.cursor = 0
+}
+
+// key is a decoded key press.
+type key struct {
+	r    rune   // a printable character, or 0
+	name string // "up", "down", "enter", "backspace", "cancel", or ""
+}
+
+// handle applies k and reports whether the picker is done, with the picked
+// item's index, or -1 when cancelled.
+func (m *model) handle(k key) (done bool, picked int) {
+	switch k.name {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down":
+		if m.cursor < len(m.matches)-1 {
+			m.cursor++
+		}
+	case "backspace":
+		if m.query != "" {
+			_, size := utf8.DecodeLastRuneInString(m.query)
+			m.query = m.query[:len(m.query)-size]
+			m.filter()
+		}
+	case "enter":
+		if len(m.matches) > 0 {
+			return true, m.matches[m.cursor]
+		}
+	case "cancel":
+		return true, -1
+	default:
+		if k.r != 0 {
+			m.query += string(k.r)
+			m.filter()
+		}
+	}
+	return false, 0
+}
+
+// view renders the prompt and the visible matches, one string per line.
+func (m *model) view() []string {
+	lines := []string{"> " + m.query}
+	start := 0
+	if m.cursor >= maxRows {
+		start = m.cursor - maxRows + 1
+	}
+	for i := start; i < len(m.matches) && i < start+maxRows; i++ {
+		marker := "  "
+		if i == m.cursor {
+			marker = "▸ "
+		}
+		lines = append(lines, marker+m.items[m.matches[i]])
+	}
+	lines = append(lines, fmt.Sprintf("  %d/%d", len(m.matches), len(m.items)))
+	return lines
+}
+
+// decode splits the bytes of one read from the terminal into key presses.
+func decode(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch {
+		case len(b) >= 3 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O'):
+			switch b[2] {
+			case 'A':
+				keys = append(keys, key{name: "up"})
+			case 'B':
+				keys = append(keys, key{name: "down"})
+			}
+			b = b[3:]
+			continue
+		case b[0] == 0x1b, b[0] == 3: // Esc, Ctrl-C
+			keys = append(keys, key{name: "cancel"})
+		case b[0] == '\r', b[0] == '\n':
+			keys = append(keys, key{name: "enter"})
+		case b[0] == 0x7f, b[0] == 8:
+			keys = append(keys, key{name: "backspace"})
+		case b[0] == 16: // Ctrl-P
+			keys = append(keys, key{name: "up"})
+		case b[0] == 14: // Ctrl-N
+			keys = append(keys, key{name: "down"})
+		case b[0] >= 0x20:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{r: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// Run shows items on out, reads keys from in (a terminal), and returns the
+// index of the item picked, starting with query typed in. Returns
+// ErrCancelled if the user backs out.
+func Run(in *os.File, out io.Writer, items []string, query string) (int, error) {
+	old, err := term.MakeRaw(in.Fd())
+	if err != nil {
+		return -1, err
+	}
+	defer term.Restore(in.Fd(), old)
+
+	width, _, err := term.GetSize(in.Fd())
+	if err != nil || width <= 0 {
+		width = 80
+	}
+
+	m := newModel(items, query)
+	drawn := 0
+	// erase goes back to the top of what was drawn, and clears it.
+	erase := func() {
+		if drawn > 1 {
+			fmt.Fprintf(out, "\x1b[%dA", drawn-1)
+		}
+		fmt.Fprint(out, "\r\x1b[J")
+	}
+	draw := func() {
+		erase()
+		lines := m.view()
+		for i, line := range lines {
+			// A wrapped line would throw off the redraw above.
+			lines[i] = truncate(line, width-1)
+		}
+		fmt.Fprint(out, strings.Join(lines, "\r\n"))
+		drawn = len(lines)
+	}
+	draw()
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			erase()
+			return -1, err
+		}
+		for _, k := range decode(buf[:n]) {
+			if done, picked := m.handle(k); done {
+				erase()
+				if picked < 0 {
+					return -1, ErrCancelled
+				}
+				return picked, nil
+			}
+		}
+		draw()
+	}
+}
+
+// truncate cuts s to at most n runes.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:max(n, 0)])
+}
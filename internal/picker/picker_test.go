@@ -0,0 +1,72 @@
+package picker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	items := []string{"main     main", "auth     feature/auth", "login    fix/login-redirect", "api      feature/api"}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{0, 1, 2, 3}},
+		{"auth", []int{1}},
+		{"AUTH", []int{1}},
+		{"fa", []int{1, 3}}, // equally good matches keep their order
+		{"api", []int{3}},
+		{"xyz", []int{}},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.query, items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// A match at the start of a word ranks first.
+	if got := Match("cart", []string{"scart", "cart"}); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("Match(cart) = %v, want [1 0]", got)
+	}
+}
+
+func TestModel(t *testing.T) {
+	m := newModel([]string{"main", "auth", "api"}, "")
+	press := func(keys ...key) (bool, int) {
+		for _, k := range keys {
+			if done, picked := m.handle(k); done {
+				return done, picked
+			}
+		}
+		return false, 0
+	}
+
+	if done, picked := press(key{name: "down"}, key{name: "down"}, key{name: "down"}, key{name: "enter"}); !done || picked != 2 {
+		t.Errorf("down past the end = %v, %d; want the last item", done, picked)
+	}
+
+	m = newModel([]string{"main", "auth", "api"}, "a")
+	press(decode([]byte("u"))...)
+	if got := m.view()[0]; got != "> au" {
+		t.Errorf("prompt = %q", got)
+	}
+	if done, picked := press(key{name: "enter"}); !done || picked != 1 {
+		t.Errorf("enter = %v, %d; want auth", done, picked)
+	}
+
+	press(key{name: "backspace"}, key{name: "backspace"})
+	if m.query != "" || len(m.matches) != 3 {
+		t.Errorf("after backspace: query %q, %d matches", m.query, len(m.matches))
+	}
+	if done, picked := press(decode([]byte{0x1b})...); !done || picked != -1 {
+		t.Errorf("Esc = %v, %d; want cancelled", done, picked)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	got := decode([]byte("a\x1b[B\x1b[A\x7f\ré\x03"))
+	want := []key{{r: 'a'}, {name: "down"}, {name: "up"}, {name: "backspace"}, {name: "enter"}, {r: 'é'}, {name: "cancel"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decode = %+v, want %+v", got, want)
+	}
+}
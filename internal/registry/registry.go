@@ -0,0 +1,141 @@
+// Package registry keeps a machine-wide list of grove projects so commands
+// can work across repositories (grove list --all-repos).
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/verbaux/grove/internal/atomicfile"
+)
+
+const fileName = "projects.json"
+
+// Project is one grove-initialized repository.
+type Project struct {
+	Name       string    `json:"name"` // config prefix, or the root's directory name
+	Root       string    `json:"root"`
+	Registered time.Time `json:"registered"`
+}
+
+// Registry is the top-level structure of projects.json.
+type Registry struct {
+	Projects []Project `json:"projects"`
+	Declined []string  `json:"declined,omitempty"` // repo roots where the user turned down setting up grove
+}
+
+// Path returns the location of the registry file. GROVE_REGISTRY overrides
+// the default of <user config dir>/grove/projects.json.
+func Path() (string, error) {
+	if p := os.Getenv("GROVE_REGISTRY"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "grove", fileName), nil
+}
+
+// Load reads the registry at path. A missing file is an empty registry.
+func Load(path string) (Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Registry{}, nil
+		}
+		return Registry{}, err
+	}
+
+	var r Registry
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Registry{}, errors.New(path + " is not valid JSON: " + err.Error())
+	}
+	return r, nil
+}
+
+// Save writes the registry to path, creating its directory if needed.
+// Like state.Save it writes a temp file and renames it into place, since
+// several grove processes in different repos may touch it at once.
+func Save(path string, r Registry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	return atomicfile.Write(path, data, 0644)
+}
+
+// Register records the project at root under name. Returns true if the
+// registry changed (new project, or an existing one was renamed), so callers
+// can skip the write otherwise.
+func (r *Registry) Register(name, root string) bool {
+	for i, p := range r.Projects {
+		if p.Root == root {
+			if p.Name == name {
+				return false
+			}
+			r.Projects[i].Name = name
+			r.sort()
+			return true
+		}
+	}
+
+	r.Projects = append(r.Projects, Project{Name: name, Root: root, Registered: time.Now()})
+	r.sort()
+	return true
+}
+
+// Find returns the projects registered under name. More than one means
+// several repositories share a name (e.g. two clones of the same project).
+func (r *Registry) Find(name string) []Project {
+	var found []Project
+	for _, p := range r.Projects {
+		if p.Name == name {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// Decline remembers that the user doesn't want grove set up in the repo at
+// root, so commands stop offering to run grove init there. Returns true if
+// the registry changed.
+func (r *Registry) Decline(root string) bool {
+	if r.IsDeclined(root) {
+		return false
+	}
+	r.Declined = append(r.Declined, root)
+	sort.Strings(r.Declined)
+	return true
+}
+
+// IsDeclined reports whether Decline was called for root.
+func (r *Registry) IsDeclined(root string) bool {
+	for _, d := range r.Declined {
+		if d == root {
+			return true
+		}
+	}
+	return false
+}
+
+// sort keeps projects ordered by name, then root, so output is stable.
+func (r *Registry) sort() {
+	sort.Slice(r.Projects, func(i, j int) bool {
+		if r.Projects[i].Name != r.Projects[j].Name {
+			return r.Projects[i].Name < r.Projects[j].Name
+		}
+		return r.Projects[i].Root < r.Projects[j].Root
+	})
+}
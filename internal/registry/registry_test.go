@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grove", "projects.json")
+
+	r, err := Load(path)
+	if err != nil {
+		t.Fatal("Load of missing registry failed:", err)
+	}
+	if len(r.Projects) != 0 {
+		t.Fatalf("expected empty registry, got %v", r.Projects)
+	}
+
+	if !r.Register("shop", "/code/shop") {
+		t.Error("expected first Register to report a change")
+	}
+	if !r.Register("blog", "/code/blog") {
+		t.Error("expected Register of a new root to report a change")
+	}
+	if r.Register("shop", "/code/shop") {
+		t.Error("expected re-registering the same project to be a no-op")
+	}
+	if !r.Register("store", "/code/shop") {
+		t.Error("expected renaming a project to report a change")
+	}
+
+	if err := Save(path, r); err != nil {
+		t.Fatal("Save failed:", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal("Load failed:", err)
+	}
+
+	if len(loaded.Projects) != 2 {
+		t.Fatalf("expected 2 projects, got %v", loaded.Projects)
+	}
+	// Sorted by name.
+	if loaded.Projects[0].Name != "blog" || loaded.Projects[1].Name != "store" {
+		t.Errorf("projects = %v, want blog then store", loaded.Projects)
+	}
+	if loaded.Projects[1].Root != "/code/shop" {
+		t.Errorf("root = %q, want /code/shop", loaded.Projects[1].Root)
+	}
+}
+
+func TestFind(t *testing.T) {
+	var r Registry
+	r.Register("shop", "/code/shop")
+	r.Register("shop", "/tmp/shop-clone")
+	r.Register("blog", "/code/blog")
+
+	if got := r.Find("blog"); len(got) != 1 || got[0].Root != "/code/blog" {
+		t.Errorf("Find(blog) = %v", got)
+	}
+	if got := r.Find("shop"); len(got) != 2 {
+		t.Errorf("Find(shop) = %v, want 2 projects", got)
+	}
+	if got := r.Find("nope"); len(got) != 0 {
+		t.Errorf("Find(nope) = %v, want none", got)
+	}
+}
+
+func TestDecline(t *testing.T) {
+	var r Registry
+	if r.IsDeclined("/code/app") {
+		t.Fatal("fresh registry declines /code/app")
+	}
+	if !r.Decline("/code/app") {
+		t.Error("first Decline reported no change")
+	}
+	if r.Decline("/code/app") {
+		t.Error("second Decline reported a change")
+	}
+	if !r.IsDeclined("/code/app") || r.IsDeclined("/code/other") {
+		t.Errorf("Declined = %v", r.Declined)
+	}
+}
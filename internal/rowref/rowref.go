@@ -0,0 +1,69 @@
+// Package rowref remembers the row numbers of the last `grove list`, so
+// other commands can take "@3" instead of an alias.
+package rowref
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileName lives next to state.json in the project's .grove directory.
+const fileName = ".grove/last-list.json"
+
+// TTL is how long a listing's numbers stay valid. Worktrees come and go, so
+// "@3" from yesterday's list could mean something else today.
+const TTL = 15 * time.Minute
+
+// ErrStale is returned when there's no listing recent enough to use.
+var ErrStale = errors.New("no recent 'grove list'")
+
+// Listing is the content of .grove/last-list.json: the worktree paths in the
+// order grove list showed them, row 1 first.
+type Listing struct {
+	Listed time.Time `json:"listed"`
+	Paths  []string  `json:"paths"`
+}
+
+// Path returns the listing file for the project at root.
+func Path(root string) string {
+	return filepath.Join(root, filepath.FromSlash(fileName))
+}
+
+// Save records the rows grove list just printed.
+func Save(root string, paths []string, now time.Time) error {
+	path := Path(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(Listing{Listed: now, Paths: paths}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Lookup returns the path shown in row n of the last listing. ok is false
+// when n is out of range; ErrStale means there is no listing younger than TTL.
+func Lookup(root string, n int, now time.Time) (path string, ok bool, err error) {
+	data, err := os.ReadFile(Path(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, ErrStale
+	}
+	if err != nil {
+		return "", false, err
+	}
+	var l Listing
+	if err := json.Unmarshal(data, &l); err != nil {
+		return "", false, errors.New(".grove/last-list.json is not valid JSON: " + err.Error())
+	}
+	if now.Sub(l.Listed) > TTL {
+		return "", false, ErrStale
+	}
+	if n < 1 || n > len(l.Paths) {
+		return "", false, nil
+	}
+	return l.Paths[n-1], true, nil
+}
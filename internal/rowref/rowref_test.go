@@ -0,0 +1,31 @@
+package rowref
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLookup(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	if _, _, err := Lookup(root, 1, now); !errors.Is(err, ErrStale) {
+		t.Fatalf("Lookup without a listing: err = %v, want ErrStale", err)
+	}
+
+	if err := Save(root, []string{"/code/app", "/code/app-auth"}, now); err != nil {
+		t.Fatal("Save failed:", err)
+	}
+
+	path, ok, err := Lookup(root, 2, now.Add(time.Minute))
+	if err != nil || !ok || path != "/code/app-auth" {
+		t.Errorf("Lookup(2) = %q, %v, %v", path, ok, err)
+	}
+	if _, ok, err := Lookup(root, 3, now); ok || err != nil {
+		t.Errorf("Lookup(3) = %v, %v; want out of range", ok, err)
+	}
+	if _, _, err := Lookup(root, 1, now.Add(TTL+time.Second)); !errors.Is(err, ErrStale) {
+		t.Errorf("Lookup after TTL: err = %v, want ErrStale", err)
+	}
+}
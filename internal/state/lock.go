@@ -0,0 +1,81 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const lockName = "state.lock"
+
+// lockTimeout is how long Lock waits for another grove to finish.
+const lockTimeout = 10 * time.Second
+
+// staleLock is the age past which a lock file is assumed to be left behind
+// by a grove that crashed. Nothing holds the lock for more than a
+// load-modify-save.
+const staleLock = time.Minute
+
+// Lock takes the project's state lock, so a load-modify-save of
+// .grove/state.json can't interleave with another grove's. Call the
+// returned function to release it.
+func Lock(dir string) (func(), error) {
+	dirPath := filepath.Join(dir, stateDir)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dirPath, lockName)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintln(f, os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(path)
+			return nil, fmt.Errorf("%s is held by process %s — wait for it to finish, or delete the file if that process is gone", path, strings.TrimSpace(string(holder)))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// StaleLock returns the path of a lock file left behind by a grove that
+// crashed, or "" when there is none. Lock clears such a file itself once it
+// gets to it; this is for reporting it earlier.
+func StaleLock(dir string) string {
+	path := filepath.Join(dir, stateDir, lockName)
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+		return path
+	}
+	return ""
+}
+
+// Change loads the state at dir, applies fn and saves the result, all under
+// Lock. Nothing is saved if fn returns an error.
+func Change(dir string, fn func(*State) error) error {
+	unlock, err := Lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	if err := fn(&s); err != nil {
+		return err
+	}
+	return Save(dir, s)
+}
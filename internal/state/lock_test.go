@@ -0,0 +1,62 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChange(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, State{Worktrees: map[string]WorktreeEntry{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every concurrent change lands; none overwrites another.
+	var wg sync.WaitGroup
+	for _, alias := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Change(dir, func(s *State) error {
+				return s.Add(alias, alias, filepath.Join(dir, alias))
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Worktrees) != 8 {
+		t.Errorf("worktrees = %d, want 8", len(s.Worktrees))
+	}
+	if _, err := os.Stat(filepath.Join(dir, stateDir, lockName)); !os.IsNotExist(err) {
+		t.Errorf("lock should be released, stat = %v", err)
+	}
+}
+
+func TestLockBreaksStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, stateDir, lockName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := Lock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
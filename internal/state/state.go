@@ -1,36 +1,133 @@
 package state
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
+
+	"github.com/verbaux/grove/internal/atomicfile"
+	"github.com/verbaux/grove/internal/trace"
+	"github.com/verbaux/grove/internal/version"
 )
 
 const stateDir = ".grove"
 const fileName = "state.json"
 
+// Sentinel errors for alias lookups. Callers match them with errors.Is.
+var (
+	ErrAliasExists = errors.New("already exists")
+	ErrNotFound    = errors.New("not found")
+)
+
+// ErrClaimed is returned when a worktree is claimed by someone else
+// (see WorktreeEntry.Claim).
+var ErrClaimed = errors.New("claimed")
+
+// ErrCorrupt is returned by Load when state.json can't be parsed.
+var ErrCorrupt = errors.New("not valid JSON")
+
+// KeepBackups is how many earlier versions of state.json Save keeps in
+// .grove/backups.
+const KeepBackups = 10
+
+const backupDir = "backups"
+
 // WorktreeEntry holds info about one grove-managed worktree.
 type WorktreeEntry struct {
-	Branch  string    `json:"branch"`
-	Path    string    `json:"path"`
-	Created time.Time `json:"created"`
+	Branch   string    `json:"branch"`
+	Path     string    `json:"path"`
+	Created  time.Time `json:"created"`
+	Template string    `json:"template,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	Upstream string    `json:"upstream,omitempty"`     // e.g. "origin/feature/auth", set by create --push
+	Base     string    `json:"base,omitempty"`         // branch or commit the worktree's branch was created from
+	Review   string    `json:"review,omitempty"`       // what a `grove review` worktree checks out, e.g. "PR #12"
+	Note     string    `json:"note,omitempty"`         // what the worktree is for, from grove create --note
+	PR       int       `json:"pr,omitempty"`           // pull request `grove sync --prs` created the worktree for
+	Expires  time.Time `json:"expires,omitzero"`       // `grove prune` removes the worktree after this, if it's clean
+	Setup    *Setup    `json:"setup,omitempty"`        // what grove create did to the worktree; nil for adopted ones
+	Claim    *Claim    `json:"claim,omitempty"`        // who is using the worktree, set by grove claim
+	Version  string    `json:"groveVersion,omitempty"` // grove that added the entry
+}
+
+// Setup records what grove create set up in a worktree, so it can be
+// inspected (grove info) and undone precisely later.
+type Setup struct {
+	EnvFiles   []string          `json:"envFiles,omitempty"`   // .env* files copied, relative to the worktree
+	EnvProfile string            `json:"envProfile,omitempty"` // profile passed to grove create --env
+	EnvHashes  map[string]string `json:"envHashes,omitempty"`  // SHA-256 of each env file as grove last wrote it, to tell local edits apart (grove sync --env)
+	Copied     []string          `json:"copied,omitempty"`     // extra files copied by the template
+	Rendered   []string          `json:"rendered,omitempty"`   // files rendered from the "render" templates
+	Symlinks   []string          `json:"symlinks,omitempty"`   // symlinks created into the main worktree
+	Hardlinks  []string          `json:"hardlinks,omitempty"`  // directories recreated with hard-linked files (linkMode "hardlink")
+	Sparse     []string          `json:"sparse,omitempty"`     // sparse-checkout directories
+	Applied    string            `json:"applied,omitempty"`    // patch file or stash applied with --apply
+	Database   string            `json:"database,omitempty"`   // per-worktree database created from the "database" config
+	Namespace  string            `json:"namespace,omitempty"`  // per-worktree Kubernetes namespace from the "kube" config
+	Banner     string            `json:"banner,omitempty"`     // generated description file, e.g. "WORKTREE.md" (see the "banner" config)
+	ReadOnly   bool              `json:"readOnly,omitempty"`   // files were made read-only (--read-only); undone by grove unlock-files
+	Hooks      []HookRun         `json:"hooks,omitempty"`
+	Failed     string            `json:"failed,omitempty"`  // setup step that failed in a worktree kept for grove retry
+	Pending    []string          `json:"pending,omitempty"` // steps after Failed that haven't run yet
+}
+
+// HookRun is one hook command grove ran for a worktree.
+type HookRun struct {
+	Name     string    `json:"name"` // e.g. "afterCreate"
+	Command  string    `json:"command"`
+	ExitCode int       `json:"exitCode"`
+	Ran      time.Time `json:"ran"`
+}
+
+// Claim marks a worktree as in use by one user or automation job, so
+// another one doesn't enter it too.
+type Claim struct {
+	Owner string    `json:"owner"`
+	Since time.Time `json:"since"`
+}
+
+// PoolEntry is a blank worktree waiting in the pool: detached at the
+// default branch, with dependencies already linked.
+type PoolEntry struct {
+	Path     string    `json:"path"`
+	Commit   string    `json:"commit"`           // what the worktree has checked out
+	Linked   []string  `json:"linked,omitempty"` // cfg.Symlink entries already linked
+	LinkMode string    `json:"linkMode,omitempty"`
+	Created  time.Time `json:"created"`
 }
 
 // State is the top-level structure of .grove/state.json.
 // The map key is the alias (e.g. "auth").
 type State struct {
 	Worktrees map[string]WorktreeEntry `json:"worktrees"`
+	// Ignored lists worktree paths grove was told to forget. They aren't
+	// reported as orphans, so clean never removes them.
+	Ignored []string `json:"ignored,omitempty"`
+	// Pool lists the blank worktrees `grove pool fill` created for grove
+	// create to claim. They aren't reported as orphans either.
+	Pool []PoolEntry `json:"pool,omitempty"`
+	// Version is the grove that last wrote the file. Save refuses to
+	// overwrite a file from a newer grove, which may hold fields this one
+	// would drop.
+	Version string `json:"groveVersion,omitempty"`
+}
+
+// Path returns the location of the state file for the project at dir.
+func Path(dir string) string {
+	return filepath.Join(dir, stateDir, fileName)
 }
 
 // Load reads .grove/state.json from dir.
 // If the file doesn't exist, returns an empty state (not an error).
 // This is different from config.Load — missing state is normal (no worktrees yet).
 func Load(dir string) (State, error) {
-	path := filepath.Join(dir, stateDir, fileName)
-
-	data, err := os.ReadFile(path)
+	defer trace.Begin("state", "load state.json")()
+	data, err := os.ReadFile(Path(dir))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return State{Worktrees: map[string]WorktreeEntry{}}, nil
@@ -38,9 +135,17 @@ func Load(dir string) (State, error) {
 		return State{}, err
 	}
 
+	s, err := parse(data)
+	if err != nil {
+		return State{}, fmt.Errorf(".grove/state.json is %w (%v) — run 'grove repair' to restore it from a backup or rebuild it from git", ErrCorrupt, err)
+	}
+	return s.absolute(dir), nil
+}
+
+func parse(data []byte) (State, error) {
 	var s State
 	if err := json.Unmarshal(data, &s); err != nil {
-		return State{}, errors.New(".grove/state.json is not valid JSON: " + err.Error())
+		return State{}, err
 	}
 
 	// Ensure map is initialized even if JSON had "worktrees": null
@@ -51,56 +156,174 @@ func Load(dir string) (State, error) {
 	return s, nil
 }
 
-// Save writes state to .grove/state.json, creating the .grove directory if needed.
-// Uses an atomic write (temp file + rename) so a concurrent reader never sees a partial file.
-func Save(dir string, s State) error {
-	dirPath := filepath.Join(dir, stateDir)
-
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return err
+// Backups returns the paths of the backups of the state of the project at
+// dir, newest first.
+func Backups(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, stateDir, backupDir, "state-*.json"))
+	if err != nil {
+		return nil, err
 	}
+	// The names hold the time they were taken, so they sort by age.
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	return paths, nil
+}
 
-	data, err := json.MarshalIndent(s, "", "  ")
+// LoadBackup reads a state backup returned by Backups for the project at
+// dir.
+func LoadBackup(dir, path string) (State, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
+		return State{}, err
+	}
+	s, err := parse(data)
+	if err != nil {
+		return State{}, fmt.Errorf("%s is %w (%v)", filepath.Base(path), ErrCorrupt, err)
+	}
+	return s.absolute(dir), nil
+}
+
+// absolute resolves the relative paths in s against the project at dir, so
+// in memory paths are always absolute. Absolute paths, from state written
+// before paths were relative, are kept.
+func (s State) absolute(dir string) State {
+	root := resolvedRoot(dir)
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(root, path)
+	}
+	for alias, entry := range s.Worktrees {
+		entry.Path = abs(entry.Path)
+		s.Worktrees[alias] = entry
+	}
+	for i, path := range s.Ignored {
+		s.Ignored[i] = abs(path)
+	}
+	for i := range s.Pool {
+		s.Pool[i].Path = abs(s.Pool[i].Path)
+	}
+	return s
+}
+
+// relative returns a copy of s with its paths relative to the project at
+// dir, where that's possible (not across Windows drives). Stored that way,
+// the state survives the project being moved, mounted elsewhere, or synced
+// to another machine. Paths are relative to the root with symlinks
+// resolved, since that's how git and grove spell worktree paths.
+func (s State) relative(dir string) State {
+	root := resolvedRoot(dir)
+	rel := func(path string) string {
+		if r, err := filepath.Rel(root, path); err == nil && path != "" {
+			return filepath.ToSlash(r)
+		}
+		return path
+	}
+	worktrees := make(map[string]WorktreeEntry, len(s.Worktrees))
+	for alias, entry := range s.Worktrees {
+		entry.Path = rel(entry.Path)
+		worktrees[alias] = entry
+	}
+	s.Worktrees = worktrees
+	if s.Ignored != nil {
+		ignored := make([]string, len(s.Ignored))
+		for i, path := range s.Ignored {
+			ignored[i] = rel(path)
+		}
+		s.Ignored = ignored
+	}
+	if s.Pool != nil {
+		pool := make([]PoolEntry, len(s.Pool))
+		for i, entry := range s.Pool {
+			entry.Path = rel(entry.Path)
+			pool[i] = entry
+		}
+		s.Pool = pool
+	}
+	return s
+}
+
+func resolvedRoot(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return dir
+}
+
+// backup copies the state file about to be replaced by data into
+// .grove/backups, unless it's missing or unchanged, and drops all but the
+// newest KeepBackups copies.
+func backup(dirPath string, data []byte) error {
+	old, err := os.ReadFile(filepath.Join(dirPath, fileName))
+	if err != nil || bytes.Equal(old, data) {
+		return nil
+	}
+	backups := filepath.Join(dirPath, backupDir)
+	if err := os.MkdirAll(backups, 0755); err != nil {
+		return err
+	}
+	name := "state-" + time.Now().UTC().Format("20060102T150405.000000000") + ".json"
+	if err := os.WriteFile(filepath.Join(backups, name), old, 0644); err != nil {
 		return err
 	}
-	data = append(data, '\n')
 
-	// Write to a temp file in the same directory, then rename into place.
-	// os.Rename is atomic on the same filesystem, so readers always see a complete file.
-	tmp, err := os.CreateTemp(dirPath, "state-*.tmp")
+	paths, err := Backups(filepath.Dir(dirPath))
 	if err != nil {
 		return err
 	}
-	tmpName := tmp.Name()
+	for _, path := range paths[min(len(paths), KeepBackups):] {
+		os.Remove(path)
+	}
+	return nil
+}
 
-	if _, err := tmp.Write(data); err != nil {
-		tmp.Close()
-		os.Remove(tmpName)
-		return err
+// Save writes state to .grove/state.json, creating the .grove directory if needed.
+// Uses an atomic write (temp file + rename) so a concurrent reader never sees a partial file.
+// Returns an error matching version.ErrNewer if s was written by a newer grove.
+// Save doesn't take the lock: change the state with Change instead, so two
+// groves can't overwrite each other's changes.
+func Save(dir string, s State) error {
+	defer trace.Begin("state", "save state.json")()
+	if version.Newer(s.Version, version.Current) {
+		return fmt.Errorf(".grove/state.json was %w (%s, this is %s) — upgrade grove to change worktrees in this project", version.ErrNewer, s.Version, version.Current)
+	}
+	if version.Current != "" {
+		s.Version = version.Current
 	}
-	if err := tmp.Close(); err != nil {
-		os.Remove(tmpName)
+	dirPath := filepath.Join(dir, stateDir)
+
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
 		return err
 	}
 
-	if err := os.Rename(tmpName, filepath.Join(dirPath, fileName)); err != nil {
-		os.Remove(tmpName)
+	data, err := json.MarshalIndent(s.relative(dir), "", "  ")
+	if err != nil {
 		return err
 	}
-	return nil
+	data = append(data, '\n')
+
+	if err := backup(dirPath, data); err != nil {
+		return fmt.Errorf("backing up .grove/state.json: %w", err)
+	}
+
+	// Readers always see a complete file.
+	return atomicfile.Write(filepath.Join(dirPath, fileName), data, 0644)
 }
 
 // Add registers a new worktree alias. Returns an error if the alias is taken.
 func (s *State) Add(alias, branch, path string) error {
 	if _, exists := s.Worktrees[alias]; exists {
-		return errors.New("alias \"" + alias + "\" already exists")
+		return fmt.Errorf("alias %q %w", alias, ErrAliasExists)
 	}
 
 	s.Worktrees[alias] = WorktreeEntry{
 		Branch:  branch,
 		Path:    path,
 		Created: time.Now(),
+		Version: version.Current,
 	}
 	return nil
 }
@@ -108,13 +331,79 @@ func (s *State) Add(alias, branch, path string) error {
 // Remove deletes a worktree alias. Returns an error if the alias doesn't exist.
 func (s *State) Remove(alias string) error {
 	if _, exists := s.Worktrees[alias]; !exists {
-		return errors.New("alias \"" + alias + "\" not found")
+		return fmt.Errorf("alias %q %w", alias, ErrNotFound)
 	}
 
 	delete(s.Worktrees, alias)
 	return nil
 }
 
+// Update applies fn to the entry for alias and stores the result.
+// Returns an error if the alias doesn't exist.
+func (s *State) Update(alias string, fn func(e *WorktreeEntry)) error {
+	entry, exists := s.Worktrees[alias]
+	if !exists {
+		return fmt.Errorf("alias %q %w", alias, ErrNotFound)
+	}
+
+	fn(&entry)
+	s.Worktrees[alias] = entry
+	return nil
+}
+
+// Rename moves an entry to a new alias. Returns an error if oldAlias doesn't
+// exist or newAlias is already taken.
+func (s *State) Rename(oldAlias, newAlias string) error {
+	entry, exists := s.Worktrees[oldAlias]
+	if !exists {
+		return fmt.Errorf("alias %q %w", oldAlias, ErrNotFound)
+	}
+	if _, taken := s.Worktrees[newAlias]; taken {
+		return fmt.Errorf("alias %q %w", newAlias, ErrAliasExists)
+	}
+
+	delete(s.Worktrees, oldAlias)
+	s.Worktrees[newAlias] = entry
+	return nil
+}
+
+// Ignore marks a worktree path as deliberately unmanaged.
+func (s *State) Ignore(path string) {
+	if !s.IsIgnored(path) {
+		s.Ignored = append(s.Ignored, path)
+	}
+}
+
+// Unignore clears a path previously passed to Ignore.
+func (s *State) Unignore(path string) {
+	for i, p := range s.Ignored {
+		if p == path {
+			s.Ignored = append(s.Ignored[:i], s.Ignored[i+1:]...)
+			return
+		}
+	}
+}
+
+// IsIgnored reports whether path was marked with Ignore.
+func (s *State) IsIgnored(path string) bool {
+	for _, p := range s.Ignored {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// InPool reports whether path is a worktree waiting in the pool.
+func (s *State) InPool(path string) bool {
+	for _, p := range s.Pool {
+		if p.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
 // Get looks up a worktree by alias.
 // Returns the entry and true if found, zero value and false if not.
 func (s *State) Get(alias string) (WorktreeEntry, bool) {
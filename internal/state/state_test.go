@@ -1,7 +1,14 @@
 package state
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/verbaux/grove/internal/version"
 )
 
 func TestSaveAndLoad(t *testing.T) {
@@ -49,9 +56,13 @@ func TestAddDuplicateAlias(t *testing.T) {
 	if err := s.Add("auth", "feature/auth", "/tmp/a"); err != nil {
 		t.Fatal(err)
 	}
-	if err := s.Add("auth", "feature/other", "/tmp/b"); err == nil {
+	err := s.Add("auth", "feature/other", "/tmp/b")
+	if err == nil {
 		t.Fatal("expected error when adding duplicate alias")
 	}
+	if !errors.Is(err, ErrAliasExists) {
+		t.Errorf("expected ErrAliasExists, got %v", err)
+	}
 }
 
 func TestRemove(t *testing.T) {
@@ -69,7 +80,186 @@ func TestRemove(t *testing.T) {
 func TestRemoveNonexistent(t *testing.T) {
 	s := State{Worktrees: map[string]WorktreeEntry{}}
 
-	if err := s.Remove("nope"); err == nil {
+	err := s.Remove("nope")
+	if err == nil {
 		t.Fatal("expected error when removing nonexistent alias")
 	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	s := State{Worktrees: map[string]WorktreeEntry{}}
+	s.Add("auth", "feature/auth", "/tmp/a")
+	s.Add("billing", "feature/billing", "/tmp/b")
+
+	if err := s.Rename("auth", "login"); err != nil {
+		t.Fatal("Rename failed:", err)
+	}
+	if s.AliasExists("auth") {
+		t.Error("old alias should not exist after rename")
+	}
+	if entry, ok := s.Get("login"); !ok || entry.Branch != "feature/auth" {
+		t.Errorf("expected login → feature/auth, got %+v", entry)
+	}
+
+	if err := s.Rename("login", "billing"); !errors.Is(err, ErrAliasExists) {
+		t.Errorf("expected ErrAliasExists, got %v", err)
+	}
+	if err := s.Rename("nope", "other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	s := State{Worktrees: map[string]WorktreeEntry{}}
+
+	s.Ignore("/code/app-main2")
+	s.Ignore("/code/app-main2")
+	if !s.IsIgnored("/code/app-main2") || len(s.Ignored) != 1 {
+		t.Fatalf("Ignored = %v, want one entry", s.Ignored)
+	}
+
+	s.Unignore("/code/app-main2")
+	if s.IsIgnored("/code/app-main2") {
+		t.Error("expected path to be unignored")
+	}
+}
+
+func TestSaveVersion(t *testing.T) {
+	dir := t.TempDir()
+	old := version.Current
+	version.Current = "v0.5.0"
+	t.Cleanup(func() { version.Current = old })
+
+	s := State{Worktrees: map[string]WorktreeEntry{}}
+	s.Add("auth", "feature/auth", "/tmp/project-auth")
+	if err := Save(dir, s); err != nil {
+		t.Fatal("Save failed:", err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal("Load failed:", err)
+	}
+	if loaded.Version != "v0.5.0" {
+		t.Errorf("state version = %q, want v0.5.0", loaded.Version)
+	}
+	if e, _ := loaded.Get("auth"); e.Version != "v0.5.0" {
+		t.Errorf("entry version = %q, want v0.5.0", e.Version)
+	}
+
+	// A file from a newer grove is left alone rather than rewritten
+	// without the fields this grove doesn't know.
+	loaded.Version = "v0.6.0"
+	if err := Save(dir, loaded); !errors.Is(err, version.ErrNewer) {
+		t.Fatalf("Save of newer state: err = %v, want ErrNewer", err)
+	}
+	if again, _ := Load(dir); again.Version != "v0.5.0" {
+		t.Errorf("state was rewritten: version = %q", again.Version)
+	}
+}
+
+func TestSaveKeepsBackups(t *testing.T) {
+	dir := t.TempDir()
+
+	for i := range KeepBackups + 3 {
+		s := State{Worktrees: map[string]WorktreeEntry{}}
+		s.Add(fmt.Sprintf("wt%d", i), "main", "/tmp/x")
+		if err := Save(dir, s); err != nil {
+			t.Fatal(err)
+		}
+		// Saving the same state again doesn't add a backup.
+		if err := Save(dir, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := Backups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != KeepBackups {
+		t.Fatalf("kept %d backups, want %d", len(backups), KeepBackups)
+	}
+	newest, err := LoadBackup(dir, backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := newest.Get(fmt.Sprintf("wt%d", KeepBackups+1)); !ok {
+		t.Errorf("newest backup = %+v, want the state before the last save", newest.Worktrees)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Dir(Path(dir)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(dir), []byte(`{"worktrees": {`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Load = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestRelativePaths(t *testing.T) {
+	parent, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(parent, "shop")
+	s := State{Worktrees: map[string]WorktreeEntry{}}
+	s.Add("auth", "feature/auth", filepath.Join(parent, "shop-auth"))
+	s.Ignore(filepath.Join(parent, "scratch"))
+	s.Pool = []PoolEntry{{Path: filepath.Join(parent, "shop-pool-1")}}
+	if err := Save(root, s); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(Path(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"path": "../shop-auth"`) || strings.Contains(string(data), parent) {
+		t.Errorf("state.json should hold relative paths:\n%s", data)
+	}
+	if entry, _ := s.Get("auth"); entry.Path != filepath.Join(parent, "shop-auth") {
+		t.Errorf("Save changed the caller's state: %s", entry.Path)
+	}
+
+	// The whole tree moves, and the state follows.
+	moved := filepath.Join(parent, "elsewhere")
+	if err := os.MkdirAll(moved, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(root, filepath.Join(moved, "shop")); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(filepath.Join(moved, "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := loaded.Get("auth"); entry.Path != filepath.Join(moved, "shop-auth") {
+		t.Errorf("path after move = %s", entry.Path)
+	}
+	if !loaded.IsIgnored(filepath.Join(moved, "scratch")) {
+		t.Errorf("ignored after move = %v", loaded.Ignored)
+	}
+	if !loaded.InPool(filepath.Join(moved, "shop-pool-1")) {
+		t.Errorf("pool after move = %v", loaded.Pool)
+	}
+
+	// State from before paths were relative still loads.
+	if err := os.WriteFile(Path(filepath.Join(moved, "shop")), []byte(`{"worktrees": {"old": {"branch": "x", "path": "/srv/shop-old"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = Load(filepath.Join(moved, "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := loaded.Get("old"); entry.Path != "/srv/shop-old" {
+		t.Errorf("absolute path = %s", entry.Path)
+	}
 }
@@ -0,0 +1,93 @@
+// Package statuscache remembers `git status` summaries between grove runs,
+// so repeated `grove list` and prompt calls don't rerun git status in every
+// worktree.
+package statuscache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/verbaux/grove/internal/atomicfile"
+)
+
+// fileName lives next to state.json in the project's .grove directory.
+const fileName = ".grove/status-cache.json"
+
+// entry is one cached status, valid while Key matches the worktree.
+type entry struct {
+	Key     string    `json:"key"`
+	Status  string    `json:"status"`
+	Checked time.Time `json:"checked"`
+}
+
+// Cache maps worktree paths to their last known status. It's safe for
+// concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]entry
+	changed bool
+}
+
+// Path returns the cache file for the project at root.
+func Path(root string) string {
+	return filepath.Join(root, filepath.FromSlash(fileName))
+}
+
+// Open loads the cache for the project at root. A missing or unreadable
+// cache is simply empty — it only ever costs a git status call.
+func Open(root string) *Cache {
+	c := &Cache{path: Path(root), entries: map[string]entry{}}
+	if data, err := os.ReadFile(c.path); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+// Get returns the cached status for path if it was recorded under key no
+// longer than maxAge ago.
+func (c *Cache) Get(path, key string, maxAge time.Duration, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	if !ok || e.Key != key || now.Sub(e.Checked) > maxAge {
+		return "", false
+	}
+	return e.Status, true
+}
+
+// Put records a freshly computed status.
+func (c *Cache) Put(path, key, status string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = entry{Key: key, Status: status, Checked: now}
+	c.changed = true
+}
+
+// Save writes the cache back if anything was added. Like state.Save it
+// renames a temp file into place so a concurrent reader never sees half a file.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed {
+		return nil
+	}
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(c.path, data, 0644); err != nil {
+		return err
+	}
+	c.changed = false
+	return nil
+}
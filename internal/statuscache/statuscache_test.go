@@ -0,0 +1,34 @@
+package statuscache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	c := Open(root)
+	if _, ok := c.Get("/wt", "k1", time.Minute, now); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+
+	c.Put("/wt", "k1", "2 modified", now)
+	if err := c.Save(); err != nil {
+		t.Fatal("Save failed:", err)
+	}
+
+	c = Open(root)
+	if got, ok := c.Get("/wt", "k1", time.Minute, now.Add(time.Second)); !ok || got != "2 modified" {
+		t.Errorf("Get = %q, %v; want \"2 modified\", true", got, ok)
+	}
+	// A different key means HEAD or the index moved.
+	if _, ok := c.Get("/wt", "k2", time.Minute, now); ok {
+		t.Error("expected a miss when the key changed")
+	}
+	// Too old to trust for edits that don't touch the index.
+	if _, ok := c.Get("/wt", "k1", time.Minute, now.Add(2*time.Minute)); ok {
+		t.Error("expected a miss when the entry is older than maxAge")
+	}
+}
@@ -0,0 +1,194 @@
+package trace
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Run describes the traced command for OTLP export.
+type Run struct {
+	Command string
+	Version string
+	Start   time.Time // when Enable was called; span offsets count from here
+	Total   time.Duration
+	Err     error // the command's error, marks the root span as failed
+
+	// Parent is a W3C traceparent ("00-<trace id>-<span id>-<flags>") to
+	// attach the spans to, e.g. from the CI job or agent that ran grove.
+	// Empty starts a new trace.
+	Parent string
+}
+
+// OTLP spans follow the OpenTelemetry protocol's JSON encoding
+// (opentelemetry-proto, ExportTraceServiceRequest): IDs are hex, times
+// are nanoseconds since the epoch as decimal strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID      string          `json:"traceId"`
+		SpanID       string          `json:"spanId"`
+		ParentSpanID string          `json:"parentSpanId,omitempty"`
+		Name         string          `json:"name"`
+		Kind         int             `json:"kind"`
+		Start        string          `json:"startTimeUnixNano"`
+		End          string          `json:"endTimeUnixNano"`
+		Attributes   []otlpAttribute `json:"attributes,omitempty"`
+		Status       *otlpStatus     `json:"status,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		String string `json:"stringValue"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 2 = error
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// Span kinds and status codes from the OpenTelemetry protocol.
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+	statusError      = 2
+)
+
+// OTLP encodes run and its spans as an OTLP/HTTP JSON export request: one
+// root span for the command, with every recorded span as its child.
+func OTLP(run Run, spans []Span) ([]byte, error) {
+	traceID, parentID, err := parseTraceparent(run.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if traceID == "" {
+		traceID = randomID(16)
+	}
+	rootID := randomID(8)
+
+	root := otlpSpan{
+		TraceID:      traceID,
+		SpanID:       rootID,
+		ParentSpanID: parentID,
+		Name:         "grove " + run.Command,
+		Kind:         spanKindInternal,
+		Start:        unixNano(run.Start),
+		End:          unixNano(run.Start.Add(run.Total)),
+		Attributes:   []otlpAttribute{attr("grove.command", run.Command)},
+	}
+	if run.Err != nil {
+		root.Status = &otlpStatus{Code: statusError, Message: run.Err.Error()}
+	}
+	out := []otlpSpan{root}
+	for _, s := range spans {
+		kind := spanKindInternal
+		if s.Category == "git" || s.Category == "hook" {
+			kind = spanKindClient // another process does the work
+		}
+		start := run.Start.Add(s.Start)
+		out = append(out, otlpSpan{
+			TraceID:      traceID,
+			SpanID:       randomID(8),
+			ParentSpanID: rootID,
+			Name:         s.Category + " " + s.Name,
+			Kind:         kind,
+			Start:        unixNano(start),
+			End:          unixNano(start.Add(s.Duration)),
+			Attributes:   []otlpAttribute{attr("grove.category", s.Category), attr("grove.detail", s.Name)},
+		})
+	}
+
+	return json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{
+			attr("service.name", "grove"),
+			attr("service.version", run.Version),
+		}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "grove", Version: run.Version}, Spans: out}},
+	}}})
+}
+
+// exportTimeout bounds how long grove waits for the collector; a slow or
+// absent collector mustn't hold up the command that was traced.
+const exportTimeout = 5 * time.Second
+
+// Export posts an OTLP request from OTLP to url, a collector's traces
+// endpoint such as http://localhost:4318/v1/traces, with extra headers
+// (e.g. for authentication).
+func Export(url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := (&http.Client{Timeout: exportTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s %s", url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// parseTraceparent returns the trace and span IDs of a W3C traceparent, or
+// empty strings for an empty one.
+func parseTraceparent(tp string) (traceID, spanID string, err error) {
+	if tp == "" {
+		return "", "", nil
+	}
+	parts := strings.Split(tp, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 || !isHex(parts[1]) || !isHex(parts[2]) {
+		return "", "", fmt.Errorf("invalid traceparent %q — expected 00-<32 hex digits>-<16 hex digits>-<flags>", tp)
+	}
+	return strings.ToLower(parts[1]), strings.ToLower(parts[2]), nil
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func randomID(bytes int) string {
+	b := make([]byte, bytes)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func attr(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{String: value}}
+}
@@ -0,0 +1,90 @@
+package trace
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOTLP(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	run := Run{
+		Command: "create",
+		Version: "1.2.3",
+		Start:   start,
+		Total:   2 * time.Second,
+		Err:     errors.New("hook failed"),
+		Parent:  "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	body, err := OTLP(run, []Span{
+		{Category: "git", Name: "worktree add", Start: 100 * time.Millisecond, Duration: 300 * time.Millisecond},
+		{Category: "setup", Name: "symlink", Start: time.Second, Duration: 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var req otlpRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatal(err)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want the command and 2 children", len(spans))
+	}
+	root := spans[0]
+	if root.Name != "grove create" || root.ParentSpanID != "b7ad6b7169203331" || root.Status == nil || root.Status.Code != statusError {
+		t.Errorf("root span = %+v", root)
+	}
+	for _, s := range spans {
+		if s.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+			t.Errorf("%s: trace id %s, want the traceparent's", s.Name, s.TraceID)
+		}
+	}
+	git := spans[1]
+	if git.ParentSpanID != root.SpanID || git.Kind != spanKindClient || git.Start != "1700000000100000000" || git.End != "1700000000400000000" {
+		t.Errorf("git span = %+v", git)
+	}
+	if spans[2].Kind != spanKindInternal {
+		t.Errorf("setup span kind = %d", spans[2].Kind)
+	}
+
+	if _, err := OTLP(Run{Parent: "garbage"}, nil); err == nil {
+		t.Error("expected an error for an invalid traceparent")
+	}
+	body, _ = OTLP(Run{Command: "list", Start: start}, nil)
+	var fresh otlpRequest
+	json.Unmarshal(body, &fresh)
+	if root := fresh.ResourceSpans[0].ScopeSpans[0].Spans[0]; len(root.TraceID) != 32 || root.ParentSpanID != "" {
+		t.Errorf("without a traceparent, expected a new trace: %+v", root)
+	}
+}
+
+func TestExport(t *testing.T) {
+	var got http.Header
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		if r.URL.Path != "/v1/traces" {
+			http.Error(w, "no such endpoint", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	if err := Export(srv.URL+"/v1/traces", map[string]string{"Authorization": "Bearer abc"}, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Content-Type") != "application/json" || got.Get("Authorization") != "Bearer abc" || gotBody != "{}" {
+		t.Errorf("headers %v, body %q", got, gotBody)
+	}
+	if err := Export(srv.URL+"/wrong", nil, []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 error, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package trace records how long grove spends in each phase of a command —
+// config loading, git calls, file copies, hooks — for `grove --trace`.
+// Recording is off until Enable is called, and costs nothing then.
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Span is one timed phase.
+type Span struct {
+	Category string        // "config", "state", "git", "files" or "hook"
+	Name     string        // e.g. the git arguments or the hook command
+	Start    time.Duration // offset from Enable
+	Duration time.Duration
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	began   time.Time
+	spans   []Span
+)
+
+// Enable starts recording. Spans are timed relative to this moment.
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	began = time.Now()
+	spans = nil
+}
+
+// Disable stops recording and drops what was recorded.
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	spans = nil
+}
+
+// Begin starts a span and returns the function that ends it, so callers can
+// write `defer trace.Begin("git", "status")()`. Spans may be recorded from
+// several goroutines at once.
+func Begin(category, name string) func() {
+	mu.Lock()
+	on := enabled
+	mu.Unlock()
+	if !on {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if enabled {
+			spans = append(spans, Span{Category: category, Name: name, Start: start.Sub(began), Duration: time.Since(start)})
+		}
+	}
+}
+
+// Add records a span the caller timed itself, e.g. a setup phase that's
+// already measured for other reasons.
+func Add(category, name string, start time.Time, duration time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled {
+		spans = append(spans, Span{Category: category, Name: name, Start: start.Sub(began), Duration: duration})
+	}
+}
+
+// Spans returns the spans recorded so far, in the order they ended.
+func Spans() []Span {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Span(nil), spans...)
+}
+
+// slowest is how many individual spans WriteSummary lists.
+const slowest = 10
+
+// WriteSummary prints a per-category breakdown of spans, then the slowest
+// individual spans. total is the whole command's duration; time not covered
+// by any span is reported as "other". Nested or parallel spans (git calls
+// inside config lookup, statuses checked concurrently) can add up to more
+// than total.
+func WriteSummary(w io.Writer, command string, total time.Duration, spans []Span) {
+	type category struct {
+		name  string
+		count int
+		sum   time.Duration
+	}
+	byName := map[string]*category{}
+	var cats []*category
+	var covered time.Duration
+	for _, s := range spans {
+		c, ok := byName[s.Category]
+		if !ok {
+			c = &category{name: s.Category}
+			byName[s.Category] = c
+			cats = append(cats, c)
+		}
+		c.count++
+		c.sum += s.Duration
+		covered += s.Duration
+	}
+	sort.SliceStable(cats, func(i, j int) bool { return cats[i].sum > cats[j].sum })
+
+	fmt.Fprintf(w, "trace: grove %s took %s\n", command, round(total))
+	for _, c := range cats {
+		calls := "calls"
+		if c.count == 1 {
+			calls = "call"
+		}
+		fmt.Fprintf(w, "  %-8s %4d %-5s %10s\n", c.name, c.count, calls, round(c.sum))
+	}
+	if other := total - covered; other > 0 {
+		fmt.Fprintf(w, "  %-8s %10s %10s\n", "other", "", round(other))
+	}
+
+	if len(spans) == 0 {
+		return
+	}
+	sorted := append([]Span(nil), spans...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Duration > sorted[j].Duration })
+	if len(sorted) > slowest {
+		sorted = sorted[:slowest]
+	}
+	fmt.Fprintln(w, "slowest:")
+	for _, s := range sorted {
+		fmt.Fprintf(w, "  %10s  %-6s %s\n", round(s.Duration), s.Category, s.Name)
+	}
+}
+
+// round keeps durations readable: whole milliseconds, or microseconds below
+// a millisecond.
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// event is one entry in the Chrome trace event format, which
+// chrome://tracing, Perfetto and speedscope open directly.
+type event struct {
+	Name     string `json:"name"`
+	Category string `json:"cat"`
+	Phase    string `json:"ph"`
+	Start    int64  `json:"ts"`  // microseconds
+	Duration int64  `json:"dur"` // microseconds
+	PID      int    `json:"pid"`
+	TID      int    `json:"tid"`
+}
+
+// WriteJSON writes spans to path in the Chrome trace event format, with the
+// whole command as the outermost span.
+func WriteJSON(path, command string, total time.Duration, spans []Span) error {
+	events := []event{{Name: "grove " + command, Category: "command", Phase: "X", Duration: total.Microseconds(), PID: 1, TID: 1}}
+	for _, s := range spans {
+		events = append(events, event{
+			Name:     s.Name,
+			Category: s.Category,
+			Phase:    "X",
+			Start:    s.Start.Microseconds(),
+			Duration: s.Duration.Microseconds(),
+			PID:      1,
+			TID:      1,
+		})
+	}
+
+	data, err := json.MarshalIndent(map[string]any{"traceEvents": events}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
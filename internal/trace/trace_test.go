@@ -0,0 +1,93 @@
+package trace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBeginDisabled(t *testing.T) {
+	Disable()
+	Begin("git", "status")()
+	if got := Spans(); len(got) != 0 {
+		t.Errorf("recorded %d spans while disabled", len(got))
+	}
+}
+
+func TestBeginRecords(t *testing.T) {
+	Enable()
+	t.Cleanup(Disable)
+
+	end := Begin("git", "status --porcelain")
+	Begin("hook", "npm ci")()
+	end()
+
+	got := Spans()
+	if len(got) != 2 {
+		t.Fatalf("got %d spans, want 2", len(got))
+	}
+	if got[0].Category != "hook" || got[1].Name != "status --porcelain" {
+		t.Errorf("spans = %+v", got)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	spans := []Span{
+		{Category: "git", Name: "worktree add", Duration: 300 * time.Millisecond},
+		{Category: "hook", Name: "npm ci", Duration: 2 * time.Second},
+		{Category: "git", Name: "rev-parse HEAD", Duration: 5 * time.Millisecond},
+	}
+
+	var sb strings.Builder
+	WriteSummary(&sb, "create", 2500*time.Millisecond, spans)
+	out := sb.String()
+
+	// Compare with runs of spaces collapsed; the column widths aren't the point.
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	flat := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"grove create took 2.5s",
+		"hook 1 call 2s",
+		"git 2 calls 305ms",
+		"other 195ms",
+	} {
+		if !strings.Contains(flat, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	// Categories are sorted by total time, slowest first.
+	if strings.Index(out, "hook ") > strings.Index(out, "git ") {
+		t.Errorf("hook should be listed before git:\n%s", out)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.json")
+	spans := []Span{{Category: "git", Name: "status", Start: time.Millisecond, Duration: 2 * time.Millisecond}}
+	if err := WriteJSON(path, "list", 10*time.Millisecond, spans); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		TraceEvents []event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.TraceEvents) != 2 {
+		t.Fatalf("got %d events, want 2", len(doc.TraceEvents))
+	}
+	if e := doc.TraceEvents[1]; e.Name != "status" || e.Start != 1000 || e.Duration != 2000 || e.Phase != "X" {
+		t.Errorf("event = %+v", e)
+	}
+}
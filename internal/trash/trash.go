@@ -0,0 +1,176 @@
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trashDir lives next to state.json in the project's .grove directory.
+const trashDir = ".grove/trash"
+
+const (
+	itemFile   = "item.json"
+	bundleFile = "branch.bundle"
+	patchFile  = "changes.patch"
+)
+
+// Item describes one removed worktree kept in the trash.
+// Each item is a directory under .grove/trash/ named by its ID, holding
+// item.json plus an optional branch bundle and patch of uncommitted changes.
+type Item struct {
+	ID       string    `json:"id"`
+	Alias    string    `json:"alias"`
+	Branch   string    `json:"branch"`
+	Path     string    `json:"path"`
+	Base     string    `json:"base,omitempty"`
+	Upstream string    `json:"upstream,omitempty"`
+	Template string    `json:"template,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	Created  time.Time `json:"created"`
+	Removed  time.Time `json:"removed"`
+}
+
+// Dir returns the trash directory for the project at root.
+func Dir(root string) string {
+	return filepath.Join(root, filepath.FromSlash(trashDir))
+}
+
+// ItemDir returns the directory holding an item's files.
+func ItemDir(root, id string) string {
+	return filepath.Join(Dir(root), id)
+}
+
+// BundlePath returns where the branch bundle for an item is stored.
+// The file only exists if a bundle was written.
+func BundlePath(root, id string) string {
+	return filepath.Join(ItemDir(root, id), bundleFile)
+}
+
+// PatchPath returns where the uncommitted-changes patch for an item is stored.
+// The file only exists if the worktree was dirty.
+func PatchPath(root, id string) string {
+	return filepath.Join(ItemDir(root, id), patchFile)
+}
+
+// Create allocates a new trash item directory and writes item.json.
+// item.ID and item.Removed are filled in and returned. The caller writes the
+// bundle and patch files into ItemDir afterwards.
+func Create(root string, item Item) (Item, error) {
+	item.Removed = time.Now()
+	// Aliases from --name may contain slashes; keep the ID a single path segment.
+	item.ID = fmt.Sprintf("%s-%d", strings.ReplaceAll(item.Alias, "/", "_"), item.Removed.UnixNano())
+
+	if err := writeItem(root, item); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// writeItem creates the item's directory and writes item.json into it.
+func writeItem(root string, item Item) error {
+	dir := ItemDir(root, item.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(filepath.Join(dir, itemFile), data, 0644); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	return nil
+}
+
+// List returns all items in the trash, newest first.
+// A missing trash directory means an empty trash, not an error.
+func List(root string) ([]Item, error) {
+	entries, err := os.ReadDir(Dir(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []Item
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(Dir(root), e.Name(), itemFile))
+		if err != nil {
+			// Half-written item (e.g. interrupted remove) — skip it.
+			continue
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			continue
+		}
+		item.ID = e.Name()
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Removed.After(items[j].Removed)
+	})
+	return items, nil
+}
+
+// Find returns the most recently trashed item for alias.
+func Find(root, alias string) (Item, bool, error) {
+	items, err := List(root)
+	if err != nil {
+		return Item{}, false, err
+	}
+	for _, item := range items {
+		if item.Alias == alias {
+			return item, true, nil
+		}
+	}
+	return Item{}, false, nil
+}
+
+// Delete permanently removes an item and its files.
+func Delete(root, id string) error {
+	return os.RemoveAll(ItemDir(root, id))
+}
+
+// Expired returns the items removed more than retention ago.
+func Expired(items []Item, retention time.Duration, now time.Time) []Item {
+	var expired []Item
+	for _, item := range items {
+		if now.Sub(item.Removed) > retention {
+			expired = append(expired, item)
+		}
+	}
+	return expired
+}
+
+// Purge deletes every item removed more than retention ago and returns
+// how many were deleted.
+func Purge(root string, retention time.Duration) (int, error) {
+	items, err := List(root)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int
+	for _, item := range Expired(items, retention, time.Now()) {
+		if err := Delete(root, item.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
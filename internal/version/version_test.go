@@ -0,0 +1,37 @@
+package version
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		v, than string
+		want    bool
+	}{
+		{"v0.5.0", "v0.4.9", true},
+		{"v1.0.0", "v0.10.0", true},
+		{"v0.10.0", "v0.9.0", true},
+		{"v0.4.0", "v0.4.0", false},
+		{"v0.4.0", "v0.5.0", false},
+		{"v0.5.0-rc.1", "v0.4.0", true},
+		{"v0.5.0", "3f2a9c1e-dirty", false},
+		{"3f2a9c1e", "v0.1.0", false},
+		{"", "v0.1.0", false},
+		{"v0.5.0", "", false},
+		{"v1.2", "v1.1.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.v, tt.than); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.v, tt.than, got, tt.want)
+		}
+	}
+}
+
+func TestIsRelease(t *testing.T) {
+	for v, want := range map[string]bool{
+		"v0.5.0": true, "v1.2.3-rc.1": true, "3f2a9c1e-dirty": false, "unknown": false, "": false,
+	} {
+		if got := IsRelease(v); got != want {
+			t.Errorf("IsRelease(%q) = %v, want %v", v, got, want)
+		}
+	}
+}